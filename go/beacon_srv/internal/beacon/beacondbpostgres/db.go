@@ -0,0 +1,492 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacondbpostgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/scionproto/scion/go/beacon_srv/internal/beacon"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/infra/modules/db"
+)
+
+var _ beacon.DB = (*Backend)(nil)
+
+type Backend struct {
+	db *sql.DB
+	*executor
+}
+
+// New returns a new PostgreSQL backend connecting to the database described by
+// the connection string. If the schema does not exist yet, it is created. If
+// the schema version of the stored database is different from the one in
+// schema.go, an error is returned.
+func New(connection string, ia addr.IA) (*Backend, error) {
+	db, err := db.NewPostgres(connection, SchemaName, Schema, SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{
+		executor: &executor{
+			db: db,
+			ia: ia,
+		},
+		db: db,
+	}, nil
+}
+
+// SetMaxOpenConns sets the maximum number of open connections.
+func (b *Backend) SetMaxOpenConns(maxOpenConns int) {
+	b.db.SetMaxOpenConns(maxOpenConns)
+}
+
+// SetMaxIdleConns sets the maximum number of idle connections.
+func (b *Backend) SetMaxIdleConns(maxIdleConns int) {
+	b.db.SetMaxIdleConns(maxIdleConns)
+}
+
+// BeginTransaction begins a transaction on the database.
+func (b *Backend) BeginTransaction(ctx context.Context,
+	opts *sql.TxOptions) (beacon.Transaction, error) {
+
+	b.Lock()
+	defer b.Unlock()
+	tx, err := b.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, common.NewBasicError("Failed to create transaction", err)
+	}
+	return &transaction{
+		executor: &executor{
+			db: tx,
+			ia: b.ia,
+		},
+		tx: tx,
+	}, nil
+}
+
+// Close closes the database.
+func (b *Backend) Close() error {
+	return b.db.Close()
+}
+
+var _ (beacon.Transaction) = (*transaction)(nil)
+
+type transaction struct {
+	*executor
+	tx *sql.Tx
+}
+
+func (tx *transaction) Commit() error {
+	tx.Lock()
+	defer tx.Unlock()
+	return tx.tx.Commit()
+}
+
+func (tx *transaction) Rollback() error {
+	tx.Lock()
+	defer tx.Unlock()
+	return tx.tx.Rollback()
+}
+
+var _ (beacon.DBReadWrite) = (*executor)(nil)
+
+type executor struct {
+	sync.RWMutex
+	db db.Sqler
+	ia addr.IA
+}
+
+type beaconMeta struct {
+	RowID       int64
+	InfoTime    time.Time
+	LastUpdated time.Time
+}
+
+func (e *executor) CandidateBeacons(ctx context.Context, setSize int,
+	usage beacon.Usage) (<-chan beacon.BeaconOrErr, error) {
+
+	e.RLock()
+	defer e.RUnlock()
+	if e.db == nil {
+		return nil, common.NewBasicError("No database open", nil)
+	}
+	query := `SELECT Beacon, InIntfID FROM Beacons
+				WHERE (Usage & $1) = $1 ORDER BY HopsLength ASC LIMIT $2`
+	rows, err := e.db.QueryContext(ctx, query, usage, setSize)
+	if err != nil {
+		return nil, common.NewBasicError("Error selecting beacons", err)
+	}
+	defer rows.Close()
+	beacons := make([]beacon.Beacon, 0, setSize)
+	var errors []error
+	// Read all beacons that are available into memory first to free the lock.
+	for rows.Next() {
+		var rawBeacon []byte
+		var inIntfId common.IFIDType
+		if err = rows.Scan(&rawBeacon, &inIntfId); err != nil {
+			errors = append(errors, err)
+			continue
+		}
+		s, err := seg.NewSegFromRaw(common.RawBytes(rawBeacon))
+		if err != nil {
+			errors = append(errors, common.NewBasicError("Unable to parse beacon", err))
+			continue
+		}
+		beacons = append(beacons, beacon.Beacon{Segment: s, InIfId: inIntfId})
+	}
+	if err := rows.Err(); err != nil {
+		errors = append(errors, err)
+	}
+	results := make(chan beacon.BeaconOrErr)
+	go func() {
+		defer close(results)
+		for _, b := range beacons {
+			results <- beacon.BeaconOrErr{Beacon: b}
+		}
+		for _, e := range errors {
+			results <- beacon.BeaconOrErr{Err: e}
+			return
+		}
+	}()
+	return results, nil
+}
+
+// GetBeacons returns all beacons that match the query parameters.
+func (e *executor) GetBeacons(ctx context.Context,
+	params *beacon.QueryParams) ([]*beacon.QueryResult, error) {
+
+	e.RLock()
+	defer e.RUnlock()
+	if e.db == nil {
+		return nil, common.NewBasicError("No database open", nil)
+	}
+	stmt, args := buildQuery(params)
+	rows, err := e.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, common.NewBasicError("Error looking up beacons", err, "q", stmt)
+	}
+	defer rows.Close()
+	var res []*beacon.QueryResult
+	for rows.Next() {
+		var rawBeacon []byte
+		var inIntfId common.IFIDType
+		var usage beacon.Usage
+		var lastUpdated int64
+		if err := rows.Scan(&rawBeacon, &inIntfId, &usage, &lastUpdated); err != nil {
+			return nil, common.NewBasicError("Error reading DB response", err)
+		}
+		s, err := seg.NewSegFromRaw(common.RawBytes(rawBeacon))
+		if err != nil {
+			return nil, common.NewBasicError("Unable to parse beacon", err)
+		}
+		res = append(res, &beacon.QueryResult{
+			Beacon:      beacon.Beacon{Segment: s, InIfId: inIntfId},
+			Usage:       usage,
+			LastUpdated: time.Unix(0, lastUpdated),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.NewBasicError("Error reading DB response", err)
+	}
+	return res, nil
+}
+
+func buildQuery(params *beacon.QueryParams) (string, []interface{}) {
+	var args []interface{}
+	// arg adds the value to the arguments and returns the matching placeholder.
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := []string{"SELECT Beacon, InIntfID, Usage, LastUpdated FROM Beacons"}
+	orderBy := "ORDER BY LastUpdated, RowID"
+	if params == nil {
+		return strings.Join(append(query, orderBy), "\n"), args
+	}
+	where := []string{}
+	if len(params.StartsAt) > 0 {
+		subQ := []string{}
+		for _, ia := range params.StartsAt {
+			if ia.A == 0 {
+				subQ = append(subQ, fmt.Sprintf("(StartIsd=%s)", arg(ia.I)))
+			} else {
+				subQ = append(subQ, fmt.Sprintf("(StartIsd=%s AND StartAs=%s)",
+					arg(ia.I), arg(ia.A)))
+			}
+		}
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(subQ, " OR ")))
+	}
+	if len(params.IngressInterfaces) > 0 {
+		subQ := []string{}
+		for _, ifid := range params.IngressInterfaces {
+			subQ = append(subQ, fmt.Sprintf("InIntfID=%s", arg(ifid)))
+		}
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(subQ, " OR ")))
+	}
+	if len(params.Usages) > 0 {
+		subQ := []string{}
+		for _, usage := range params.Usages {
+			p := arg(usage)
+			subQ = append(subQ, fmt.Sprintf("(Usage & %s) = %s", p, p))
+		}
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(subQ, " OR ")))
+	}
+	if params.ExpiresAfter != nil {
+		where = append(where, fmt.Sprintf("(ExpirationTime>%s)",
+			arg(params.ExpiresAfter.Unix())))
+	}
+	if params.ExpiresBefore != nil {
+		where = append(where, fmt.Sprintf("(ExpirationTime<%s)",
+			arg(params.ExpiresBefore.Unix())))
+	}
+	if params.MinLastUpdate != nil {
+		where = append(where, fmt.Sprintf("(LastUpdated>%s)",
+			arg(params.MinLastUpdate.UnixNano())))
+	}
+	if len(where) > 0 {
+		query = append(query, fmt.Sprintf("WHERE %s", strings.Join(where, " AND\n")))
+	}
+	query = append(query, orderBy)
+	return strings.Join(query, "\n"), args
+}
+
+// InsertBeacon inserts the beacon if it is new or updates the changed
+// information.
+func (e *executor) InsertBeacon(ctx context.Context, b beacon.Beacon,
+	usage beacon.Usage) (int, error) {
+
+	// Compute ids outside of the lock.
+	segId, err := b.Segment.ID()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := b.Segment.FullId(); err != nil {
+		return 0, err
+	}
+	info, err := b.Segment.InfoF()
+	if err != nil {
+		return 0, err
+	}
+
+	e.Lock()
+	defer e.Unlock()
+	// The lookup and the modification are done in the same transaction, since
+	// other instances might concurrently modify the database.
+	var inserted int
+	err = db.DoInTx(ctx, e.db, func(ctx context.Context, tx *sql.Tx) error {
+		meta, err := getBeaconMeta(ctx, tx, segId)
+		if err != nil {
+			return err
+		}
+		if meta != nil {
+			// Update the beacon data if it is newer.
+			if !info.Timestamp().After(meta.InfoTime) {
+				return nil
+			}
+			if err := updateExistingBeacon(ctx, tx, b, usage, meta.RowID,
+				time.Now()); err != nil {
+				return err
+			}
+			inserted = 1
+			return nil
+		}
+		// Insert new beacon.
+		n, err := insertNewBeacon(ctx, tx, b, usage, e.ia, time.Now())
+		inserted = n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// getBeaconMeta gets the metadata for existing beacons.
+func getBeaconMeta(ctx context.Context, tx *sql.Tx,
+	segID common.RawBytes) (*beaconMeta, error) {
+
+	var rowId, infoTime, lastUpdated int64
+	query := "SELECT RowID, InfoTime, LastUpdated FROM Beacons WHERE SegID=$1 FOR UPDATE"
+	err := tx.QueryRowContext(ctx, query, segID).Scan(&rowId, &infoTime, &lastUpdated)
+	// New beacons are not in the table.
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, common.NewBasicError("Failed to lookup beacon", err)
+	}
+	meta := &beaconMeta{
+		RowID:       rowId,
+		InfoTime:    time.Unix(infoTime, 0),
+		LastUpdated: time.Unix(0, lastUpdated),
+	}
+	return meta, nil
+}
+
+// updateExistingBeacon updates the changeable data for an existing beacon
+func updateExistingBeacon(ctx context.Context, tx *sql.Tx, b beacon.Beacon,
+	usage beacon.Usage, rowId int64, now time.Time) error {
+
+	fullId, err := b.Segment.FullId()
+	if err != nil {
+		return err
+	}
+	packedSeg, err := b.Segment.Pack()
+	if err != nil {
+		return err
+	}
+	info, err := b.Segment.InfoF()
+	if err != nil {
+		return err
+	}
+	infoTime := info.Timestamp().Unix()
+	lastUpdated := now.UnixNano()
+	expTime := b.Segment.MaxExpiry().Unix()
+	inst := `UPDATE Beacons SET FullID=$1, InIntfID=$2, HopsLength=$3, InfoTime=$4,
+			ExpirationTime=$5, LastUpdated=$6, Usage=$7, Beacon=$8
+			WHERE RowID=$9`
+	_, err = tx.ExecContext(ctx, inst, fullId, b.InIfId, len(b.Segment.ASEntries), infoTime,
+		expTime, lastUpdated, usage, packedSeg, rowId)
+	if err != nil {
+		return common.NewBasicError("Failed to update segment", err)
+	}
+	return nil
+}
+
+// insertNewBeacon inserts the beacon. It returns the number of inserted
+// beacons, which is zero if a concurrent transaction inserted the same beacon.
+func insertNewBeacon(ctx context.Context, tx *sql.Tx, b beacon.Beacon,
+	usage beacon.Usage, localIA addr.IA, now time.Time) (int, error) {
+
+	segId, err := b.Segment.ID()
+	if err != nil {
+		return 0, err
+	}
+	fullId, err := b.Segment.FullId()
+	if err != nil {
+		return 0, err
+	}
+	packed, err := b.Segment.Pack()
+	if err != nil {
+		return 0, err
+	}
+	info, err := b.Segment.InfoF()
+	if err != nil {
+		return 0, err
+	}
+	start := b.Segment.FirstIA()
+	infoTime := info.Timestamp().Unix()
+	lastUpdated := now.UnixNano()
+	expTime := b.Segment.MaxExpiry().Unix()
+
+	// Insert path segment.
+	inst := `INSERT INTO Beacons (SegID, FullID, StartIsd, StartAs, InIntfID, HopsLength,
+			InfoTime, ExpirationTime, LastUpdated, Usage, Beacon)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT DO NOTHING RETURNING RowID`
+	var rowId int64
+	err = tx.QueryRowContext(ctx, inst, segId, fullId, start.I, start.A, b.InIfId,
+		len(b.Segment.ASEntries), infoTime, expTime, lastUpdated, usage, packed).Scan(&rowId)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, common.NewBasicError("Failed to insert path segment", err)
+	}
+	// Insert all interfaces.
+	if err = insertInterfaces(ctx, tx, b, rowId, localIA); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func insertInterfaces(ctx context.Context, tx *sql.Tx, b beacon.Beacon,
+	rowId int64, localIA addr.IA) error {
+
+	stmtStr := `INSERT INTO IntfToBeacon (IsdID, AsID, IntfID, BeaconRowID)
+				VALUES ($1, $2, $3, $4)`
+	stmt, err := tx.PrepareContext(ctx, stmtStr)
+	if err != nil {
+		return common.NewBasicError("Failed to prepare insert into IntfToBeacon", err)
+	}
+	defer stmt.Close()
+	for _, as := range b.Segment.ASEntries {
+		ia := as.IA()
+		// Do not insert peering interfaces.
+		hof, err := as.HopEntries[0].HopField()
+		if err != nil {
+			return common.NewBasicError("Failed to extract hop field", err)
+		}
+		// Ignore the null interface of the first hop.
+		if hof.ConsIngress != 0 {
+			_, err = stmt.ExecContext(ctx, ia.I, ia.A, hof.ConsIngress, rowId)
+			if err != nil {
+				return common.NewBasicError("Failed to insert Ingress into IntfToSeg", err,
+					"ia", ia, "hof", hof)
+			}
+		}
+		// Ignore the null interface of the last hop
+		if hof.ConsEgress != 0 {
+			_, err := stmt.ExecContext(ctx, ia.I, ia.A, hof.ConsEgress, rowId)
+			if err != nil {
+				return common.NewBasicError("Failed to insert Egress into IntfToSeg", err,
+					"ia", ia, "hof", hof)
+			}
+		}
+	}
+	_, err = stmt.ExecContext(ctx, localIA.I, localIA.A, b.InIfId, rowId)
+	if err != nil {
+		return common.NewBasicError("Failed to insert Ingress into IntfToSeg", err,
+			"ia", localIA, "inIfId", b.InIfId)
+	}
+	return nil
+}
+
+func (e *executor) DeleteExpiredBeacons(ctx context.Context, now time.Time) (int, error) {
+	return e.deleteInTx(ctx, func(tx *sql.Tx) (sql.Result, error) {
+		delStmt := `DELETE FROM Beacons WHERE ExpirationTime < $1`
+		return tx.ExecContext(ctx, delStmt, now.Unix())
+	})
+}
+
+func (e *executor) deleteInTx(ctx context.Context,
+	delFunc func(tx *sql.Tx) (sql.Result, error)) (int, error) {
+
+	e.Lock()
+	defer e.Unlock()
+	if e.db == nil {
+		return 0, common.NewBasicError("No database open", nil)
+	}
+	var res sql.Result
+	err := db.DoInTx(ctx, e.db, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		res, err = delFunc(tx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	deleted, _ := res.RowsAffected()
+	return int(deleted), nil
+}
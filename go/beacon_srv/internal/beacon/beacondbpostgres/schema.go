@@ -0,0 +1,59 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacondbpostgres
+
+const (
+	// SchemaName is the name under which the schema version is tracked.
+	SchemaName = "beacondb"
+	// SchemaVersion is the version of the PostgreSQL schema understood by this backend.
+	// Whenever changes to the schema are made, this version number should be increased
+	// to prevent data corruption between incompatible database schemas.
+	SchemaVersion = 1
+	// Schema is the PostgreSQL database layout.
+	Schema = `CREATE TABLE Beacons(
+		RowID BIGSERIAL PRIMARY KEY,
+		SegID BYTEA UNIQUE NOT NULL,
+		FullID BYTEA UNIQUE NOT NULL,
+		StartIsd INTEGER NOT NULL,
+		StartAs BIGINT NOT NULL,
+		InIntfID BIGINT NOT NULL,
+		HopsLength INTEGER NOT NULL,
+		InfoTime BIGINT NOT NULL,
+		ExpirationTime BIGINT NOT NULL,
+		LastUpdated BIGINT NOT NULL,
+		Usage INTEGER NOT NULL,
+		Beacon BYTEA NOT NULL
+	);
+	CREATE TABLE IntfToBeacon(
+		IsdID INTEGER NOT NULL,
+		AsID BIGINT NOT NULL,
+		IntfID BIGINT NOT NULL,
+		BeaconRowID BIGINT NOT NULL REFERENCES Beacons(RowID) ON DELETE CASCADE,
+		PRIMARY KEY (BeaconRowID, IsdID, AsID, IntfID)
+	);
+	CREATE TABLE Revocations(
+		IsdID INTEGER NOT NULL,
+		AsID BIGINT NOT NULL,
+		IntfID BIGINT NOT NULL,
+		LinkType INTEGER NOT NULL,
+		ExpirationTime BIGINT NOT NULL,
+		RawSignedRev BYTEA NOT NULL,
+		PRIMARY KEY (IsdID, AsID, IntfID)
+	);
+	`
+	BeaconsTable      = "Beacons"
+	IntfToBeaconTable = "IntfToBeacon"
+	RevocationsTable  = "Revocations"
+)
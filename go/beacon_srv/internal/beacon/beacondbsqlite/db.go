@@ -17,6 +17,8 @@ package beacondbsqlite
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -168,6 +170,102 @@ func (e *executor) CandidateBeacons(ctx context.Context, setSize int,
 	return results, nil
 }
 
+// GetBeacons returns all beacons that match the query parameters.
+func (e *executor) GetBeacons(ctx context.Context,
+	params *beacon.QueryParams) ([]*beacon.QueryResult, error) {
+
+	e.RLock()
+	defer e.RUnlock()
+	if e.db == nil {
+		return nil, common.NewBasicError("No database open", nil)
+	}
+	stmt, args := buildQuery(params)
+	rows, err := e.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, common.NewBasicError("Error looking up beacons", err, "q", stmt)
+	}
+	defer rows.Close()
+	var res []*beacon.QueryResult
+	for rows.Next() {
+		var rawBeacon []byte
+		var inIntfId common.IFIDType
+		var usage beacon.Usage
+		var lastUpdated int64
+		if err := rows.Scan(&rawBeacon, &inIntfId, &usage, &lastUpdated); err != nil {
+			return nil, common.NewBasicError("Error reading DB response", err)
+		}
+		s, err := seg.NewSegFromRaw(common.RawBytes(rawBeacon))
+		if err != nil {
+			return nil, common.NewBasicError("Unable to parse beacon", err)
+		}
+		res = append(res, &beacon.QueryResult{
+			Beacon:      beacon.Beacon{Segment: s, InIfId: inIntfId},
+			Usage:       usage,
+			LastUpdated: time.Unix(0, lastUpdated),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.NewBasicError("Error reading DB response", err)
+	}
+	return res, nil
+}
+
+func buildQuery(params *beacon.QueryParams) (string, []interface{}) {
+	var args []interface{}
+	query := []string{"SELECT Beacon, InIntfID, Usage, LastUpdated FROM Beacons"}
+	orderBy := "ORDER BY LastUpdated, RowID"
+	if params == nil {
+		return strings.Join(append(query, orderBy), "\n"), args
+	}
+	where := []string{}
+	if len(params.StartsAt) > 0 {
+		subQ := []string{}
+		for _, ia := range params.StartsAt {
+			if ia.A == 0 {
+				subQ = append(subQ, "(StartIsd=?)")
+				args = append(args, ia.I)
+			} else {
+				subQ = append(subQ, "(StartIsd=? AND StartAs=?)")
+				args = append(args, ia.I, ia.A)
+			}
+		}
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(subQ, " OR ")))
+	}
+	if len(params.IngressInterfaces) > 0 {
+		subQ := []string{}
+		for _, ifid := range params.IngressInterfaces {
+			subQ = append(subQ, "InIntfID=?")
+			args = append(args, ifid)
+		}
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(subQ, " OR ")))
+	}
+	if len(params.Usages) > 0 {
+		subQ := []string{}
+		for _, usage := range params.Usages {
+			subQ = append(subQ, "(Usage & ?) == ?")
+			args = append(args, usage, usage)
+		}
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(subQ, " OR ")))
+	}
+	if params.ExpiresAfter != nil {
+		where = append(where, "(ExpirationTime>?)")
+		args = append(args, params.ExpiresAfter.Unix())
+	}
+	if params.ExpiresBefore != nil {
+		where = append(where, "(ExpirationTime<?)")
+		args = append(args, params.ExpiresBefore.Unix())
+	}
+	if params.MinLastUpdate != nil {
+		where = append(where, "(LastUpdated>?)")
+		args = append(args, params.MinLastUpdate.UnixNano())
+	}
+	if len(where) > 0 {
+		query = append(query, fmt.Sprintf("WHERE %s", strings.Join(where, " AND\n")))
+	}
+	query = append(query, orderBy)
+	return strings.Join(query, "\n"), args
+}
+
 // InsertBeacon inserts the beacon if it is new or updates the changed
 // information.
 func (e *executor) InsertBeacon(ctx context.Context, b beacon.Beacon,
@@ -105,6 +105,7 @@ func Test(t *testing.T, db Testable) {
 	Convey("UpdateBeacon", testWrapper(testUpdateExisting))
 	Convey("IgnoreBeaconUpdate", testWrapper(testUpdateOlderIgnored))
 	Convey("CandidateBeacons", testWrapper(testCandidateBeacons))
+	Convey("GetBeacons", testWrapper(testGetBeacons))
 	Convey("DeleteExpiredBeacons", testWrapper(testDeleteExpiredBeacons))
 	txTestWrapper := func(test func(*testing.T, *gomock.Controller, beacon.DBReadWrite)) func() {
 		return func() {
@@ -125,6 +126,7 @@ func Test(t *testing.T, db Testable) {
 		Convey("UpdateBeacon", testWrapper(testUpdateExisting))
 		Convey("IgnoreBeaconUpdate", testWrapper(testUpdateOlderIgnored))
 		Convey("CandidateBeacons", txTestWrapper(testCandidateBeacons))
+		Convey("GetBeacons", txTestWrapper(testGetBeacons))
 		Convey("DeleteExpiredBeacons", txTestWrapper(testDeleteExpiredBeacons))
 		Convey("TestTransactionRollback", func() {
 			ctrl := gomock.NewController(t)
@@ -279,6 +281,111 @@ func testCandidateBeacons(t *testing.T, ctrl *gomock.Controller, db beacon.DBRea
 	})
 }
 
+func testGetBeacons(t *testing.T, ctrl *gomock.Controller, db beacon.DBReadWrite) {
+	Convey("GetBeacons should return the beacons matching the parameters", func() {
+		// defaultExp is the default expiry of the hopfields.
+		defaultExp := spath.DefaultHopFExpiry.ToDuration()
+		b3 := InsertBeacon(t, ctrl, db, Info3, 12, 10, beacon.UsageProp)
+		afterFirst := time.Now()
+		b2 := InsertBeacon(t, ctrl, db, Info2, 13, 20, beacon.UsageProp|beacon.UsageUpReg)
+		b1 := InsertBeacon(t, ctrl, db, Info1, 14, 30, beacon.UsageDownReg)
+		exp15 := time.Unix(15, 0).Add(defaultExp)
+		exp25 := time.Unix(25, 0).Add(defaultExp)
+		testCases := []struct {
+			Name     string
+			Params   *beacon.QueryParams
+			Expected []beacon.Beacon
+		}{
+			{
+				Name:     "nil params",
+				Expected: []beacon.Beacon{b3, b2, b1},
+			},
+			{
+				Name:     "starts at AS",
+				Params:   &beacon.QueryParams{StartsAt: []addr.IA{ia311}},
+				Expected: []beacon.Beacon{b1},
+			},
+			{
+				Name:     "starts at ISD wildcard",
+				Params:   &beacon.QueryParams{StartsAt: []addr.IA{{I: 1}}},
+				Expected: []beacon.Beacon{b3, b2, b1},
+			},
+			{
+				Name: "ingress interfaces",
+				Params: &beacon.QueryParams{
+					IngressInterfaces: []common.IFIDType{12, 14},
+				},
+				Expected: []beacon.Beacon{b3, b1},
+			},
+			{
+				Name:     "usage",
+				Params:   &beacon.QueryParams{Usages: []beacon.Usage{beacon.UsageProp}},
+				Expected: []beacon.Beacon{b3, b2},
+			},
+			{
+				Name: "any of usages",
+				Params: &beacon.QueryParams{
+					Usages: []beacon.Usage{beacon.UsageUpReg, beacon.UsageDownReg},
+				},
+				Expected: []beacon.Beacon{b2, b1},
+			},
+			{
+				Name:     "expiry window",
+				Params:   &beacon.QueryParams{ExpiresAfter: &exp15, ExpiresBefore: &exp25},
+				Expected: []beacon.Beacon{b2},
+			},
+			{
+				Name:     "last updated",
+				Params:   &beacon.QueryParams{MinLastUpdate: &afterFirst},
+				Expected: []beacon.Beacon{b2, b1},
+			},
+			{
+				Name: "combined",
+				Params: &beacon.QueryParams{
+					StartsAt: []addr.IA{ia330},
+					Usages:   []beacon.Usage{beacon.UsageUpReg},
+				},
+				Expected: []beacon.Beacon{b2},
+			},
+			{
+				Name:   "no match",
+				Params: &beacon.QueryParams{StartsAt: []addr.IA{ia332}},
+			},
+		}
+		ctx, cancelF := context.WithTimeout(context.Background(), timeout)
+		defer cancelF()
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				res, err := db.GetBeacons(ctx, tc.Params)
+				SoMsg("err", err, ShouldBeNil)
+				SoMsg("results", len(res), ShouldEqual, len(tc.Expected))
+				for i, expected := range tc.Expected {
+					if i >= len(res) {
+						break
+					}
+					_, err := res[i].Beacon.Segment.ID()
+					xtest.FailOnErr(t, err)
+					_, err = res[i].Beacon.Segment.FullId()
+					xtest.FailOnErr(t, err)
+					SoMsg(fmt.Sprintf("Segment %d should match", i), res[i].Beacon.Segment,
+						ShouldResemble, expected.Segment)
+					SoMsg(fmt.Sprintf("InIfId %d should match", i), res[i].Beacon.InIfId,
+						ShouldEqual, expected.InIfId)
+				}
+			})
+		}
+		Convey("meta data is returned", func() {
+			res, err := db.GetBeacons(ctx, &beacon.QueryParams{
+				IngressInterfaces: []common.IFIDType{13},
+			})
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("results", len(res), ShouldEqual, 1)
+			SoMsg("usage", res[0].Usage, ShouldEqual, beacon.UsageProp|beacon.UsageUpReg)
+			SoMsg("last updated", res[0].LastUpdated, ShouldHappenAfter, afterFirst)
+		})
+	})
+}
+
 func testDeleteExpiredBeacons(t *testing.T, ctrl *gomock.Controller, db beacon.DBReadWrite) {
 	Convey("DeleteExpired should delete expired segments", func() {
 		ts1 := uint32(10)
@@ -22,6 +22,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/infra/modules/db"
 )
 
@@ -34,6 +36,35 @@ type DBRead interface {
 	// fill the channel.
 	CandidateBeacons(ctx context.Context, setSize int, usage Usage) (
 		<-chan BeaconOrErr, error)
+	// GetBeacons returns all beacons that match the query parameters,
+	// ordered by the time they were last updated. A nil params matches all
+	// beacons.
+	GetBeacons(ctx context.Context, params *QueryParams) ([]*QueryResult, error)
+}
+
+// QueryParams contains the parameters to filter beacons in the beacon DB.
+// Unset fields do not filter. A beacon must match all set fields.
+type QueryParams struct {
+	// StartsAt matches beacons that originate in any of the ASes. An AS
+	// number of 0 matches all ASes in the ISD.
+	StartsAt []addr.IA
+	// IngressInterfaces matches beacons received on any of the interfaces.
+	IngressInterfaces []common.IFIDType
+	// Usages matches beacons that are allowed for any of the usages.
+	Usages []Usage
+	// ExpiresAfter matches beacons that expire after the time.
+	ExpiresAfter *time.Time
+	// ExpiresBefore matches beacons that expire before the time.
+	ExpiresBefore *time.Time
+	// MinLastUpdate matches beacons that were last updated after the time.
+	MinLastUpdate *time.Time
+}
+
+// QueryResult is a beacon together with the meta data stored in the beacon DB.
+type QueryResult struct {
+	Beacon      Beacon
+	Usage       Usage
+	LastUpdated time.Time
 }
 
 // DBWrite defines all write operations of the beacon DB.
@@ -99,13 +130,13 @@ func (u Usage) String() string {
 		names = append(names, "UpRegistration")
 	}
 	if u&UsageDownReg != 0 {
-		names = append(names, "UpRegistration")
+		names = append(names, "DownRegistration")
 	}
 	if u&UsageCoreReg != 0 {
-		names = append(names, "UpRegistration")
+		names = append(names, "CoreRegistration")
 	}
 	if u&UsageProp != 0 {
-		names = append(names, "UpRegistration")
+		names = append(names, "Propagation")
 	}
 	return fmt.Sprintf("Usage: [%s]", strings.Join(names, ","))
 }
@@ -37,6 +37,21 @@ const (
 	CoreRegPolicy PolicyType = "CoreSegmentRegistration"
 )
 
+// SelectionAlgorithm is the algorithm used to select the best set of beacons
+// from the candidate set.
+type SelectionAlgorithm string
+
+const (
+	// ShortestFirst selects the beacons with the fewest hops per origin AS.
+	ShortestFirst SelectionAlgorithm = "ShortestFirst"
+	// Diversity selects the beacons per origin AS that share the fewest links
+	// with the already selected beacons. Ties are broken by the number of hops.
+	Diversity SelectionAlgorithm = "Diversity"
+)
+
+// DefaultSelectionAlgorithm is the default SelectionAlgorithm value.
+const DefaultSelectionAlgorithm = ShortestFirst
+
 const (
 	// DefaultBestSetSize is the default BestSetSize value.
 	DefaultBestSetSize = 5
@@ -57,6 +72,9 @@ type Policy struct {
 	Filter Filter `yaml:"Filter"`
 	// Type is the policy type.
 	Type PolicyType `yaml:"Type"`
+	// SelectionAlgorithm is the algorithm used to select the best set from
+	// the candidate set.
+	SelectionAlgorithm SelectionAlgorithm `yaml:"SelectionAlgorithm"`
 }
 
 // InitDefaults initializes the default values for unset fields.
@@ -67,6 +85,9 @@ func (p *Policy) InitDefaults() {
 	if p.CandidateSetSize == 0 {
 		p.CandidateSetSize = DefaultCandidateSetSize
 	}
+	if p.SelectionAlgorithm == "" {
+		p.SelectionAlgorithm = DefaultSelectionAlgorithm
+	}
 	p.Filter.InitDefaults()
 }
 
@@ -84,6 +105,12 @@ func ParseYaml(b common.RawBytes, t PolicyType) (*Policy, error) {
 		return nil, common.NewBasicError("Specified policy type does not match", nil,
 			"expected", t, "actual", p.Type)
 	}
+	switch p.SelectionAlgorithm {
+	case ShortestFirst, Diversity:
+	default:
+		return nil, common.NewBasicError("Unknown selection algorithm", nil,
+			"algorithm", p.SelectionAlgorithm)
+	}
 	return p, nil
 }
 
@@ -67,6 +67,21 @@ func TestLoadFromYaml(t *testing.T) {
 			loadWithType(t)
 		}
 	})
+	Convey("The selection algorithm defaults to shortest first", t, func() {
+		p, err := LoadFromYaml("testdata/policy.yml", PropPolicy)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("SelectionAlgorithm", p.SelectionAlgorithm, ShouldEqual, ShortestFirst)
+	})
+	Convey("Given a policy file with selection algorithm set", t, func() {
+		p, err := LoadFromYaml("testdata/diversityPolicy.yml", UpRegPolicy)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("BestSetSize", p.BestSetSize, ShouldEqual, 3)
+		SoMsg("SelectionAlgorithm", p.SelectionAlgorithm, ShouldEqual, Diversity)
+	})
+	Convey("An unknown selection algorithm is rejected", t, func() {
+		_, err := ParseYaml([]byte("SelectionAlgorithm: Random"), PropPolicy)
+		SoMsg("err", err, ShouldNotBeNil)
+	})
 }
 
 func TestFilterApply(t *testing.T) {
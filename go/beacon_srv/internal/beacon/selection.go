@@ -0,0 +1,117 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacon
+
+import (
+	"sort"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// SelectBeacons selects the best set from the candidate beacons according to
+// the selection algorithm of the policy. Up to BestSetSize beacons are
+// selected per origin AS. The selected beacons are grouped by origin AS in
+// the order the origin ASes first appear in the candidates.
+func (p *Policy) SelectBeacons(candidates []Beacon) []Beacon {
+	var origins []addr.IA
+	groups := make(map[addr.IA][]Beacon)
+	for _, b := range candidates {
+		origin := b.Segment.FirstIA()
+		if _, ok := groups[origin]; !ok {
+			origins = append(origins, origin)
+		}
+		groups[origin] = append(groups[origin], b)
+	}
+	var selected []Beacon
+	for _, origin := range origins {
+		group := groups[origin]
+		sort.SliceStable(group, func(i, j int) bool {
+			return len(group[i].Segment.ASEntries) < len(group[j].Segment.ASEntries)
+		})
+		switch p.SelectionAlgorithm {
+		case Diversity:
+			selected = append(selected, selectDiverse(group, p.BestSetSize)...)
+		default:
+			if len(group) > p.BestSetSize {
+				group = group[:p.BestSetSize]
+			}
+			selected = append(selected, group...)
+		}
+	}
+	return selected
+}
+
+// link identifies an inter-AS link by the egress interface of the upstream AS.
+type link struct {
+	ia   addr.IA
+	ifid common.IFIDType
+}
+
+// selectDiverse greedily selects up to n beacons from the candidates. In each
+// step, the beacon that shares the fewest links with the already selected
+// beacons is chosen. Ties are broken by the order of the candidates. Beacons
+// with malformed hop fields are not selected.
+func selectDiverse(candidates []Beacon, n int) []Beacon {
+	type candidate struct {
+		beacon Beacon
+		links  []link
+	}
+	remaining := make([]candidate, 0, len(candidates))
+	for _, b := range candidates {
+		links, err := beaconLinks(b)
+		if err != nil {
+			continue
+		}
+		remaining = append(remaining, candidate{beacon: b, links: links})
+	}
+	selected := make([]Beacon, 0, n)
+	used := make(map[link]bool)
+	for len(selected) < n && len(remaining) > 0 {
+		best, bestShared := 0, -1
+		for i, c := range remaining {
+			shared := 0
+			for _, l := range c.links {
+				if used[l] {
+					shared++
+				}
+			}
+			if bestShared < 0 || shared < bestShared {
+				best, bestShared = i, shared
+			}
+		}
+		for _, l := range remaining[best].links {
+			used[l] = true
+		}
+		selected = append(selected, remaining[best].beacon)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return selected
+}
+
+// beaconLinks returns all inter-AS links the beacon traversed.
+func beaconLinks(b Beacon) ([]link, error) {
+	links := make([]link, 0, len(b.Segment.ASEntries))
+	for _, entry := range b.Segment.ASEntries {
+		hof, err := entry.HopEntries[0].HopField()
+		if err != nil {
+			return nil, common.NewBasicError("Unable to extract hop field", err)
+		}
+		if hof.ConsEgress != 0 {
+			links = append(links, link{ia: entry.IA(), ifid: hof.ConsEgress})
+		}
+	}
+	return links, nil
+}
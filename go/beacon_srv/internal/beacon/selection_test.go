@@ -0,0 +1,102 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beacon
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest/graph"
+)
+
+func TestPolicySelectBeacons(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	g := graph.NewDefaultGraph(mctrl)
+	// Beacons originating in 1-ff00:0:110 that arrive at 1-ff00:0:112.
+	direct := graphBeacon(g, graph.If_110_X_130_A, graph.If_130_A_112_X)
+	via120And130 := graphBeacon(g, graph.If_110_X_120_A, graph.If_120_A_130_B,
+		graph.If_130_A_112_X)
+	via130And111 := graphBeacon(g, graph.If_110_X_130_A, graph.If_130_B_111_A,
+		graph.If_111_A_112_X)
+	via120And111 := graphBeacon(g, graph.If_110_X_120_A, graph.If_120_X_111_B,
+		graph.If_111_A_112_X)
+	// Beacon originating in 1-ff00:0:120 that arrives at 1-ff00:0:112.
+	from120 := graphBeacon(g, graph.If_120_X_111_B, graph.If_111_A_112_X)
+
+	candidates := []Beacon{via120And130, direct, from120, via130And111, via120And111}
+	testCases := []struct {
+		Name      string
+		Algorithm SelectionAlgorithm
+		SetSize   int
+		Expected  []Beacon
+	}{
+		{
+			Name:      "shortest first",
+			Algorithm: ShortestFirst,
+			SetSize:   2,
+			Expected:  []Beacon{direct, via120And130, from120},
+		},
+		{
+			Name:      "diversity prefers disjoint beacons",
+			Algorithm: Diversity,
+			SetSize:   2,
+			Expected:  []Beacon{direct, via120And111, from120},
+		},
+		{
+			Name:      "diversity breaks ties by hops and order",
+			Algorithm: Diversity,
+			SetSize:   3,
+			Expected:  []Beacon{direct, via120And111, via120And130, from120},
+		},
+		{
+			Name:      "diversity with single beacon per origin",
+			Algorithm: Diversity,
+			SetSize:   1,
+			Expected:  []Beacon{direct, from120},
+		},
+		{
+			Name:      "set size larger than candidates",
+			Algorithm: Diversity,
+			SetSize:   10,
+			Expected:  []Beacon{direct, via120And111, via120And130, via130And111, from120},
+		},
+	}
+	Convey("TestPolicySelectBeacons", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				p := &Policy{BestSetSize: tc.SetSize, SelectionAlgorithm: tc.Algorithm}
+				input := append([]Beacon(nil), candidates...)
+				SoMsg("selected", p.SelectBeacons(input), ShouldResemble, tc.Expected)
+			})
+		}
+		Convey("No candidates", func() {
+			p := &Policy{BestSetSize: 5, SelectionAlgorithm: Diversity}
+			SoMsg("selected", p.SelectBeacons(nil), ShouldBeEmpty)
+		})
+	})
+}
+
+func graphBeacon(g *graph.Graph, ifids ...common.IFIDType) Beacon {
+	segment := g.Beacon(ifids)
+	// Remove the entry of the receiving AS, it is added on propagation.
+	segment.RawASEntries = segment.RawASEntries[:len(segment.RawASEntries)-1]
+	segment.ASEntries = segment.ASEntries[:len(segment.ASEntries)-1]
+	last := segment.ASEntries[len(segment.ASEntries)-1]
+	return Beacon{Segment: segment, InIfId: last.HopEntries[0].RemoteOutIF}
+}
@@ -0,0 +1,222 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package beaconapi exposes the beacons in the beacon DB to operators as an
+// HTTP/JSON endpoint.
+//
+// The handler is registered on the default HTTP mux, which is served by the
+// metrics listener (see env.Metrics.StartPrometheus). It must only be
+// registered if the BS.BeaconAPI option of the beacon server config is set.
+// The beacons are filtered with the following query parameters, which can be
+// repeated to match any of the values:
+//   start=<ISD-AS>           Origin AS of the beacon. AS 0 matches the ISD.
+//   ingress=<IFID>           Interface the beacon was received on.
+//   usage=prop|up|down|core  Usage the beacon is allowed for.
+// The following parameters are RFC 3339 time stamps:
+//   expires_after=<time>     Beacon expires after the time.
+//   expires_before=<time>    Beacon expires before the time.
+//   updated_after=<time>     Beacon was last updated after the time.
+//
+// Example:
+//   curl 'http://127.0.0.1:30453/beacons?start=1-ff00:0:110&ingress=3'
+package beaconapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/scionproto/scion/go/beacon_srv/internal/beacon"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+)
+
+// Path is the path the handler is registered on.
+const Path = "/beacons"
+
+// DefaultTimeout is the timeout for querying the beacon DB.
+const DefaultTimeout = 5 * time.Second
+
+var usages = []struct {
+	name  string
+	usage beacon.Usage
+}{
+	{name: "prop", usage: beacon.UsageProp},
+	{name: "up", usage: beacon.UsageUpReg},
+	{name: "down", usage: beacon.UsageDownReg},
+	{name: "core", usage: beacon.UsageCoreReg},
+}
+
+// Register registers the handler for the beacon DB on the default HTTP mux.
+// It must be called before the prometheus HTTP server is started, and only if
+// BS.BeaconAPI is set in the beacon server config.
+func Register(db beacon.DBRead) {
+	http.Handle(Path, NewHandler(db))
+}
+
+// NewHandler returns a handler that serves the beacons in the beacon DB that
+// match the query parameters.
+func NewHandler(db beacon.DBRead) http.Handler {
+	return &handler{db: db}
+}
+
+type handler struct {
+	db beacon.DBRead
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	params, err := parseParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx, cancelF := context.WithTimeout(r.Context(), DefaultTimeout)
+	defer cancelF()
+	res, err := h.db.GetBeacons(ctx, params)
+	if err != nil {
+		log.Error("[beaconapi] Unable to query beacons", "err", err)
+		http.Error(w, "Unable to query beacons", http.StatusInternalServerError)
+		return
+	}
+	infos := make([]*BeaconInfo, 0, len(res))
+	for _, qr := range res {
+		info, err := newBeaconInfo(qr)
+		if err != nil {
+			log.Error("[beaconapi] Unable to describe beacon", "err", err)
+			http.Error(w, "Unable to describe beacon", http.StatusInternalServerError)
+			return
+		}
+		infos = append(infos, info)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(infos); err != nil {
+		log.Error("[beaconapi] Unable to write response", "err", err)
+	}
+}
+
+// BeaconInfo is the JSON representation of a beacon in the beacon DB.
+type BeaconInfo struct {
+	// ID is the hex encoded segment ID.
+	ID string
+	// StartIA is the origin AS of the beacon.
+	StartIA addr.IA
+	// InIfId is the interface the beacon was received on.
+	InIfId common.IFIDType
+	// Hops contains the ASes on the beacon, starting at the origin.
+	Hops []addr.IA
+	// Usages contains the usages the beacon is allowed for.
+	Usages []string
+	// InfoTime is the time stamp of the info field.
+	InfoTime time.Time
+	// Expiration is the time the last hop field of the beacon expires.
+	Expiration time.Time
+	// LastUpdated is the time the beacon was last updated in the beacon DB.
+	LastUpdated time.Time
+}
+
+func newBeaconInfo(r *beacon.QueryResult) (*BeaconInfo, error) {
+	pseg := r.Beacon.Segment
+	id, err := pseg.ID()
+	if err != nil {
+		return nil, err
+	}
+	info, err := pseg.InfoF()
+	if err != nil {
+		return nil, err
+	}
+	bi := &BeaconInfo{
+		ID:          id.String(),
+		StartIA:     pseg.FirstIA(),
+		InIfId:      r.Beacon.InIfId,
+		InfoTime:    info.Timestamp(),
+		Expiration:  pseg.MaxExpiry(),
+		LastUpdated: r.LastUpdated,
+	}
+	for _, entry := range pseg.ASEntries {
+		bi.Hops = append(bi.Hops, entry.IA())
+	}
+	for _, u := range usages {
+		if r.Usage&u.usage != 0 {
+			bi.Usages = append(bi.Usages, u.name)
+		}
+	}
+	return bi, nil
+}
+
+func parseParams(r *http.Request) (*beacon.QueryParams, error) {
+	values := r.URL.Query()
+	params := &beacon.QueryParams{}
+	for _, raw := range values["start"] {
+		ia, err := addr.IAFromString(raw)
+		if err != nil {
+			return nil, common.NewBasicError("Invalid start", err, "value", raw)
+		}
+		params.StartsAt = append(params.StartsAt, ia)
+	}
+	for _, raw := range values["ingress"] {
+		ifid, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, common.NewBasicError("Invalid ingress", err, "value", raw)
+		}
+		params.IngressInterfaces = append(params.IngressInterfaces, common.IFIDType(ifid))
+	}
+	for _, raw := range values["usage"] {
+		usage, err := parseUsage(raw)
+		if err != nil {
+			return nil, err
+		}
+		params.Usages = append(params.Usages, usage)
+	}
+	var err error
+	if params.ExpiresAfter, err = parseTime(values, "expires_after"); err != nil {
+		return nil, err
+	}
+	if params.ExpiresBefore, err = parseTime(values, "expires_before"); err != nil {
+		return nil, err
+	}
+	if params.MinLastUpdate, err = parseTime(values, "updated_after"); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func parseUsage(raw string) (beacon.Usage, error) {
+	for _, u := range usages {
+		if u.name == raw {
+			return u.usage, nil
+		}
+	}
+	return 0, common.NewBasicError("Invalid usage", nil, "value", raw)
+}
+
+func parseTime(values map[string][]string, key string) (*time.Time, error) {
+	raw, ok := values[key]
+	if !ok || len(raw) == 0 {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw[0])
+	if err != nil {
+		return nil, common.NewBasicError("Invalid time", err, "key", key, "value", raw[0])
+	}
+	return &t, nil
+}
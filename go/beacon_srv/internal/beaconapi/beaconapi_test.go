@@ -0,0 +1,121 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beaconapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/beacon_srv/internal/beacon"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/lib/xtest/graph"
+)
+
+func TestHandler(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	g := graph.NewDefaultGraph(mctrl)
+	pseg := g.Beacon([]common.IFIDType{graph.If_110_X_130_A, graph.If_130_A_112_X})
+	lastUpdated := time.Unix(1000, 0).UTC()
+	db := &testDB{
+		results: []*beacon.QueryResult{
+			{
+				Beacon:      beacon.Beacon{Segment: pseg, InIfId: 3},
+				Usage:       beacon.UsageProp | beacon.UsageUpReg,
+				LastUpdated: lastUpdated,
+			},
+		},
+	}
+	h := NewHandler(db)
+
+	Convey("TestHandler", t, func() {
+		Convey("Valid query", func() {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+
+				"?start=1-ff00:0:110&start=2-0&ingress=3&usage=up"+
+				"&expires_after=2019-01-01T00:00:00Z&updated_after=2019-02-01T00:00:00Z", nil))
+			SoMsg("code", rec.Code, ShouldEqual, http.StatusOK)
+			expAfter := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+			updAfter := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+			SoMsg("params", db.params, ShouldResemble, &beacon.QueryParams{
+				StartsAt: []addr.IA{
+					xtest.MustParseIA("1-ff00:0:110"),
+					xtest.MustParseIA("2-0"),
+				},
+				IngressInterfaces: []common.IFIDType{3},
+				Usages:            []beacon.Usage{beacon.UsageUpReg},
+				ExpiresAfter:      &expAfter,
+				MinLastUpdate:     &updAfter,
+			})
+			var infos []*BeaconInfo
+			xtest.FailOnErr(t, json.Unmarshal(rec.Body.Bytes(), &infos))
+			SoMsg("infos", len(infos), ShouldEqual, 1)
+			id, err := pseg.ID()
+			xtest.FailOnErr(t, err)
+			SoMsg("ID", infos[0].ID, ShouldEqual, id.String())
+			SoMsg("StartIA", infos[0].StartIA, ShouldResemble, xtest.MustParseIA("1-ff00:0:110"))
+			SoMsg("InIfId", infos[0].InIfId, ShouldEqual, 3)
+			SoMsg("Hops", infos[0].Hops, ShouldResemble, []addr.IA{
+				xtest.MustParseIA("1-ff00:0:110"),
+				xtest.MustParseIA("1-ff00:0:130"),
+				xtest.MustParseIA("1-ff00:0:112"),
+			})
+			SoMsg("Usages", infos[0].Usages, ShouldResemble, []string{"prop", "up"})
+			SoMsg("LastUpdated", infos[0].LastUpdated.Equal(lastUpdated), ShouldBeTrue)
+		})
+		invalid := map[string]string{
+			"start":   "?start=1-ff00:0:1:10",
+			"ingress": "?ingress=abc",
+			"usage":   "?usage=all",
+			"time":    "?expires_before=yesterday",
+		}
+		for name, query := range invalid {
+			Convey("Invalid "+name, func() {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+query, nil))
+				SoMsg("code", rec.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		}
+		Convey("Invalid method", func() {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, nil))
+			SoMsg("code", rec.Code, ShouldEqual, http.StatusMethodNotAllowed)
+		})
+	})
+}
+
+// testDB serves the configured results and records the query parameters. All
+// other methods of the beacon DB are not implemented.
+type testDB struct {
+	beacon.DBRead
+	results []*beacon.QueryResult
+	params  *beacon.QueryParams
+}
+
+func (db *testDB) GetBeacons(_ context.Context,
+	params *beacon.QueryParams) ([]*beacon.QueryResult, error) {
+
+	db.params = params
+	return db.results, nil
+}
@@ -39,4 +39,12 @@
 // neighboring ASes. In a core AS, the beacons are propagated to the neighbors
 // on all core link, unless they will create an AS loop. In a non-core AS, the
 // beacons are propagated to the neighbors on all child links.
+//
+// PolicyProvider
+//
+// The policy provider selects the beacons to propagate and the segments to
+// register from the candidate beacons in the beacon DB. The selection
+// algorithm is configured per policy, either shortest first or diversity. The
+// diversity algorithm prefers beacons that share the fewest links with the
+// beacons that are already selected for the same origin AS.
 package beaconing
@@ -37,6 +37,14 @@ type segExtender struct {
 	task   string
 }
 
+// SetMAC sets the MAC that is used to authenticate new hop fields. It is used
+// to switch to the new key after a master key rotation.
+func (s *segExtender) SetMAC(mac hash.Hash) {
+	s.macMtx.Lock()
+	defer s.macMtx.Unlock()
+	s.mac = mac
+}
+
 // extend extends the path segment. Prev should include the full raw hop field,
 // if any, including the flags byte. A zero ingress interface indicates, that
 // the created AS entry is the initial entry. A zero egress interface indicates,
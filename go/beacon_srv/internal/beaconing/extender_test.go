@@ -207,6 +207,37 @@ func TestExtenderExtend(t *testing.T) {
 		SoMsg("exp", hopF.ExpTime, ShouldEqual, 1)
 
 	})
+	Convey("New hop fields are authenticated with the MAC set by SetMAC", t, func() {
+		mctrl := gomock.NewController(t)
+		defer mctrl.Finish()
+		g := graph.NewDefaultGraph(mctrl)
+		intfs := ifstate.NewInterfaces(itopo.Get().IFInfoMap, ifstate.Config{})
+		intfs.Get(graph.If_111_B_120_X).Activate(graph.If_120_X_111_B)
+		ext := segExtender{
+			cfg: Config{
+				MTU:        1337,
+				Signer:     testSigner(t, priv),
+				IfidSize:   DefaultIfidSize,
+				maxExpTime: spath.DefaultHopFExpiry,
+			},
+			mac:   mac,
+			intfs: intfs,
+		}
+		newMac, err := scrypto.InitMac(common.RawBytes("new master key.."))
+		xtest.FailOnErr(t, err)
+		ext.SetMAC(newMac)
+		pseg := testBeacon(g, segDesc).Segment
+		err = ext.extend(pseg, graph.If_111_B_120_X, 0, []common.IFIDType{})
+		SoMsg("err", err, ShouldBeNil)
+		infoF, err := pseg.InfoF()
+		xtest.FailOnErr(t, err)
+		entries := pseg.ASEntries
+		hopF, err := entries[pseg.MaxAEIdx()].HopEntries[0].HopField()
+		xtest.FailOnErr(t, err)
+		prev := entries[pseg.MaxAEIdx()-1].HopEntries[0].RawHopField[1:]
+		SoMsg("new", hopF.Verify(newMac, infoF.TsInt, prev), ShouldBeNil)
+		SoMsg("old", hopF.Verify(mac, infoF.TsInt, prev), ShouldNotBeNil)
+	})
 	Convey("Segment is not extended on error", t, func() {
 		mctrl := gomock.NewController(t)
 		defer mctrl.Finish()
@@ -0,0 +1,105 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beaconing
+
+import (
+	"context"
+
+	"github.com/scionproto/scion/go/beacon_srv/internal/beacon"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ BeaconProvider = (*PolicyProvider)(nil)
+var _ SegmentProvider = (*PolicyProvider)(nil)
+
+// PolicyProvider provides the beacons to propagate and the segments to
+// register. It reads the candidate beacons from the beacon DB and selects the
+// best set according to the selection algorithm of the respective policy.
+type PolicyProvider struct {
+	// DB is the beacon DB the candidate beacons are read from.
+	DB beacon.DBRead
+	// Prop is the propagation policy.
+	Prop beacon.Policy
+	// UpReg is the up segment registration policy.
+	UpReg beacon.Policy
+	// DownReg is the down segment registration policy.
+	DownReg beacon.Policy
+	// CoreReg is the core segment registration policy.
+	CoreReg beacon.Policy
+}
+
+// BeaconsToPropagate returns the beacons selected by the propagation policy.
+func (p *PolicyProvider) BeaconsToPropagate(ctx context.Context) (
+	<-chan beacon.BeaconOrErr, error) {
+
+	return p.selectBeacons(ctx, &p.Prop, beacon.UsageProp)
+}
+
+// SegmentsToRegister returns the beacons selected by the registration policy
+// of the segment type.
+func (p *PolicyProvider) SegmentsToRegister(ctx context.Context, segType proto.PathSegType) (
+	<-chan beacon.BeaconOrErr, error) {
+
+	switch segType {
+	case proto.PathSegType_up:
+		return p.selectBeacons(ctx, &p.UpReg, beacon.UsageUpReg)
+	case proto.PathSegType_down:
+		return p.selectBeacons(ctx, &p.DownReg, beacon.UsageDownReg)
+	case proto.PathSegType_core:
+		return p.selectBeacons(ctx, &p.CoreReg, beacon.UsageCoreReg)
+	default:
+		return nil, common.NewBasicError("Unsupported segment type", nil, "type", segType)
+	}
+}
+
+// selectBeacons reads the candidate set for the usage from the beacon DB,
+// removes the beacons that are filtered by the policy and selects the best set.
+func (p *PolicyProvider) selectBeacons(ctx context.Context, policy *beacon.Policy,
+	usage beacon.Usage) (<-chan beacon.BeaconOrErr, error) {
+
+	candidates, err := p.DB.CandidateBeacons(ctx, policy.CandidateSetSize, usage)
+	if err != nil {
+		return nil, err
+	}
+	var beacons []beacon.Beacon
+	var firstErr error
+	for bOrErr := range candidates {
+		// The candidates are drained completely, such that the DB does not
+		// block on sending. Only the first error is reported.
+		if bOrErr.Err != nil {
+			if firstErr == nil {
+				firstErr = bOrErr.Err
+			}
+			continue
+		}
+		if err := policy.Filter.Apply(bOrErr.Beacon); err != nil {
+			log.Debug("Beacon filtered by policy", "beacon", bOrErr.Beacon, "err", err)
+			continue
+		}
+		beacons = append(beacons, bOrErr.Beacon)
+	}
+	selected := policy.SelectBeacons(beacons)
+	results := make(chan beacon.BeaconOrErr, len(selected)+1)
+	for _, b := range selected {
+		results <- beacon.BeaconOrErr{Beacon: b}
+	}
+	if firstErr != nil {
+		results <- beacon.BeaconOrErr{Err: firstErr}
+	}
+	close(results)
+	return results, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package beaconing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/beacon_srv/internal/beacon"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest/graph"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestPolicyProvider(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	g := graph.NewDefaultGraph(mctrl)
+	// Beacons originating in 1-ff00:0:110 that arrive at 1-ff00:0:112.
+	direct := testBeaconOrErr(g, []common.IFIDType{graph.If_110_X_130_A,
+		graph.If_130_A_112_X})
+	via120And130 := testBeaconOrErr(g, []common.IFIDType{graph.If_110_X_120_A,
+		graph.If_120_A_130_B, graph.If_130_A_112_X})
+	via120And111 := testBeaconOrErr(g, []common.IFIDType{graph.If_110_X_120_A,
+		graph.If_120_X_111_B, graph.If_111_A_112_X})
+	db := &testCandidateDB{
+		candidates: []beacon.BeaconOrErr{via120And130, direct, via120And111},
+	}
+	policy := func(algo beacon.SelectionAlgorithm) beacon.Policy {
+		p := beacon.Policy{BestSetSize: 2, SelectionAlgorithm: algo}
+		p.InitDefaults()
+		return p
+	}
+
+	Convey("TestPolicyProvider", t, func() {
+		Convey("Beacons to propagate are selected by the propagation policy", func() {
+			p := &PolicyProvider{DB: db, Prop: policy(beacon.Diversity)}
+			res, err := p.BeaconsToPropagate(context.Background())
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("beacons", drain(res), ShouldResemble,
+				[]beacon.BeaconOrErr{direct, via120And111})
+			SoMsg("usage", db.usage, ShouldEqual, beacon.UsageProp)
+		})
+		Convey("Segments to register are selected by the registration policy", func() {
+			p := &PolicyProvider{DB: db, UpReg: policy(beacon.ShortestFirst)}
+			res, err := p.SegmentsToRegister(context.Background(), proto.PathSegType_up)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("beacons", drain(res), ShouldResemble,
+				[]beacon.BeaconOrErr{direct, via120And130})
+			SoMsg("usage", db.usage, ShouldEqual, beacon.UsageUpReg)
+		})
+		Convey("Filtered beacons are not selected", func() {
+			prop := policy(beacon.Diversity)
+			prop.Filter.MaxHopsLength = 2
+			p := &PolicyProvider{DB: db, Prop: prop}
+			res, err := p.BeaconsToPropagate(context.Background())
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("beacons", drain(res), ShouldResemble, []beacon.BeaconOrErr{direct})
+		})
+		Convey("Only the first error is returned after the selected beacons", func() {
+			errDB := &testCandidateDB{
+				candidates: []beacon.BeaconOrErr{
+					{Err: errors.New("first")},
+					direct,
+					{Err: errors.New("second")},
+				},
+			}
+			p := &PolicyProvider{DB: errDB, Prop: policy(beacon.Diversity)}
+			res, err := p.BeaconsToPropagate(context.Background())
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("beacons", drain(res), ShouldResemble, []beacon.BeaconOrErr{
+				direct,
+				{Err: errDB.candidates[0].Err},
+			})
+		})
+		Convey("Unsupported segment type returns an error", func() {
+			p := &PolicyProvider{DB: db}
+			_, err := p.SegmentsToRegister(context.Background(), proto.PathSegType_unset)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+// testCandidateDB serves the candidate beacons. All other methods of the
+// beacon DB are not implemented.
+type testCandidateDB struct {
+	beacon.DBRead
+	candidates []beacon.BeaconOrErr
+	usage      beacon.Usage
+}
+
+func (db *testCandidateDB) CandidateBeacons(_ context.Context, setSize int,
+	usage beacon.Usage) (<-chan beacon.BeaconOrErr, error) {
+
+	db.usage = usage
+	res := make(chan beacon.BeaconOrErr, len(db.candidates))
+	for i, c := range db.candidates {
+		if i >= setSize {
+			break
+		}
+		res <- c
+	}
+	close(res)
+	return res, nil
+}
+
+func drain(res <-chan beacon.BeaconOrErr) []beacon.BeaconOrErr {
+	var all []beacon.BeaconOrErr
+	for bOrErr := range res {
+		all = append(all, bOrErr)
+	}
+	return all
+}
@@ -18,6 +18,7 @@ package config
 import (
 	"io"
 
+	"github.com/scionproto/scion/go/beacon_srv/internal/beaconstorage"
 	"github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/infra/modules/idiscovery"
@@ -32,6 +33,7 @@ type Config struct {
 	Logging        env.Logging
 	Metrics        env.Metrics
 	TrustDB        truststorage.TrustDBConf
+	BeaconDB       beaconstorage.BeaconDBConf
 	Discovery      idiscovery.Config
 	BS             BSConfig
 	EnableQUICTest bool
@@ -44,6 +46,7 @@ func (cfg *Config) InitDefaults() {
 		&cfg.Logging,
 		&cfg.Metrics,
 		&cfg.TrustDB,
+		&cfg.BeaconDB,
 		&cfg.Discovery,
 		&cfg.BS,
 	)
@@ -56,6 +59,7 @@ func (cfg *Config) Validate() error {
 		&cfg.Logging,
 		&cfg.Metrics,
 		&cfg.TrustDB,
+		&cfg.BeaconDB,
 		&cfg.Discovery,
 		&cfg.BS,
 	)
@@ -68,6 +72,7 @@ func (cfg *Config) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
 		&cfg.Logging,
 		&cfg.Metrics,
 		&cfg.TrustDB,
+		&cfg.BeaconDB,
 		&cfg.Discovery,
 		&cfg.BS,
 	)
@@ -84,6 +89,9 @@ var _ config.Config = (*BSConfig)(nil)
 type BSConfig struct {
 	config.NoDefaulter
 	config.NoValidator
+	// BeaconAPI enables the beacon API endpoint /beacons on the prometheus
+	// HTTP server, see beaconapi.Register.
+	BeaconAPI bool
 }
 
 // Sample generates a sample for the beacon server specific configuration.
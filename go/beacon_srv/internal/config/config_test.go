@@ -16,14 +16,17 @@ package config
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/BurntSushi/toml"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"github.com/scionproto/scion/go/beacon_srv/internal/beaconstorage"
 	"github.com/scionproto/scion/go/lib/env/envtest"
 	"github.com/scionproto/scion/go/lib/infra/modules/idiscovery/idiscoverytest"
 	"github.com/scionproto/scion/go/lib/truststorage/truststoragetest"
+	"github.com/scionproto/scion/go/lib/util"
 )
 
 func TestConfigSample(t *testing.T) {
@@ -43,17 +46,41 @@ func TestConfigSample(t *testing.T) {
 func InitTestConfig(cfg *Config) {
 	envtest.InitTest(&cfg.General, &cfg.Logging, &cfg.Metrics, nil)
 	truststoragetest.InitTestConfig(&cfg.TrustDB)
+	InitTestBeaconDBConf(&cfg.BeaconDB)
 	idiscoverytest.InitTestConfig(&cfg.Discovery)
 	InitTestBSConfig(&cfg.BS)
 }
 
-func InitTestBSConfig(cfg *BSConfig) {}
+func InitTestBeaconDBConf(cfg *beaconstorage.BeaconDBConf) {
+	*cfg = beaconstorage.BeaconDBConf{
+		beaconstorage.MaxOpenConnsKey: "maxOpenConns",
+		beaconstorage.MaxIdleConnsKey: "maxIdleConns",
+	}
+}
+
+func InitTestBSConfig(cfg *BSConfig) {
+	cfg.BeaconAPI = true
+}
 
 func CheckTestConfig(cfg *Config, id string) {
 	envtest.CheckTest(&cfg.General, &cfg.Logging, &cfg.Metrics, nil, id)
 	truststoragetest.CheckTestConfig(&cfg.TrustDB, id)
+	CheckTestBeaconDBConf(&cfg.BeaconDB, id)
 	idiscoverytest.CheckTestConfig(&cfg.Discovery)
 	CheckTestBSConfig(&cfg.BS)
 }
 
-func CheckTestBSConfig(cfg *BSConfig) {}
+func CheckTestBeaconDBConf(cfg *beaconstorage.BeaconDBConf, id string) {
+	util.LowerKeys(*cfg)
+	_, maxOpenSet := cfg.MaxOpenConns()
+	_, maxIdleSet := cfg.MaxIdleConns()
+	SoMsg("MaxOpenConns", maxOpenSet, ShouldBeFalse)
+	SoMsg("MaxIdleConns", maxIdleSet, ShouldBeFalse)
+	SoMsg("Backend", cfg.Backend(), ShouldEqual, beaconstorage.BackendSqlite)
+	SoMsg("Connection", cfg.Connection(), ShouldEqual,
+		fmt.Sprintf("/var/lib/scion/beacondb/%s.beacon.db", id))
+}
+
+func CheckTestBSConfig(cfg *BSConfig) {
+	SoMsg("BeaconAPI correct", cfg.BeaconAPI, ShouldBeFalse)
+}
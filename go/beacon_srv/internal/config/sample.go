@@ -17,5 +17,7 @@ package config
 const idSample = "bs-1"
 
 const bsconfigSample = `
-# No config supported yet
+# Enable the beacon API endpoint /beacons on the prometheus HTTP server. The
+# endpoint exposes the beacons in the beacon DB to operators. (default false)
+BeaconAPI = false
 `
@@ -92,6 +92,14 @@ func (s *Sender) CreatePkt(msg *Msg) (*snet.SCIONPacket, error) {
 	return pkt, nil
 }
 
+// SetMAC sets the MAC that is used to issue hop fields. It is used to switch
+// to the new key after a master key rotation.
+func (s *Sender) SetMAC(mac hash.Hash) {
+	s.macMtx.Lock()
+	defer s.macMtx.Unlock()
+	s.MAC = mac
+}
+
 // CreatePath creates the one-hop path and initializes it.
 func (s *Sender) CreatePath(ifid common.IFIDType, now time.Time) (*Path, error) {
 	s.macMtx.Lock()
@@ -18,18 +18,17 @@
 package brconf
 
 import (
-	"crypto/sha256"
 	"path/filepath"
 	"sync"
 
-	"golang.org/x/crypto/pbkdf2"
-
 	"github.com/scionproto/scion/go/border/netconf"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/as_conf"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/keyconf"
 	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
 	"github.com/scionproto/scion/go/lib/topology"
 )
 
@@ -47,8 +46,22 @@ type BRConf struct {
 	ASConf *as_conf.ASConf
 	// MasterKeys holds the local AS master keys.
 	MasterKeys keyconf.Master
-	// HFMacPool is the pool of Hop Field MAC generation instances.
+	// HFMacPool is the pool of Hop Field MAC generation instances. It uses the
+	// active master key (Key0).
 	HFMacPool *sync.Pool
+	// SecondaryHFMacPool is the pool of Hop Field MAC instances for the
+	// secondary master key (Key1). Hop fields are also accepted if they are
+	// authenticated with the secondary key, such that the master key can be
+	// rotated without invalidating the existing paths.
+	SecondaryHFMacPool *sync.Pool
+	// DRKeys provides the keys to authenticate SCMP messages. If it is nil,
+	// the MACs of authenticated SCMP messages are neither verified nor
+	// generated.
+	DRKeys drkey.Lvl2Source
+	// HashTreeKeys provides the keys to verify the signatures of SCMP messages
+	// that are authenticated with a hash tree. If it is nil, the signatures
+	// are not verified.
+	HashTreeKeys scmp_auth.HashTreeKeys
 	// Net is the network configuration of this router.
 	Net *netconf.NetConf
 	// Dir is the configuration directory.
@@ -82,10 +95,13 @@ func Load(id, confDir string) (*BRConf, error) {
 // to topology with the oldConf.
 func WithNewTopo(id string, topo *topology.Topo, oldConf *BRConf) (*BRConf, error) {
 	conf := &BRConf{
-		Dir:        oldConf.Dir,
-		ASConf:     oldConf.ASConf,
-		MasterKeys: oldConf.MasterKeys,
-		HFMacPool:  oldConf.HFMacPool,
+		Dir:                oldConf.Dir,
+		ASConf:             oldConf.ASConf,
+		MasterKeys:         oldConf.MasterKeys,
+		HFMacPool:          oldConf.HFMacPool,
+		SecondaryHFMacPool: oldConf.SecondaryHFMacPool,
+		DRKeys:             oldConf.DRKeys,
+		HashTreeKeys:       oldConf.HashTreeKeys,
 	}
 	if err := conf.initTopo(id, topo); err != nil {
 		return nil, common.NewBasicError("Unable to initialize topo", err)
@@ -134,7 +150,8 @@ func (cfg *BRConf) loadAsConf() error {
 	return nil
 }
 
-// loadMasterKeys loads the master keys from the config directory.
+// loadMasterKeys loads the master keys from the key provider configured in the
+// keys directory, see keyconf.NewProvider.
 func (cfg *BRConf) loadMasterKeys() error {
 	var err error
 	cfg.MasterKeys, err = keyconf.LoadMaster(filepath.Join(cfg.Dir, "keys"))
@@ -144,27 +161,33 @@ func (cfg *BRConf) loadMasterKeys() error {
 	return nil
 }
 
-// initMacPool initializes the hop field mac pool.
+// initMacPool initializes the hop field mac pools for the active and the
+// secondary master key.
 func (cfg *BRConf) initMacPool() error {
-	// Generate keys
-	// This uses 16B keys with 1000 hash iterations, which is the same as the
-	// defaults used by pycrypto.
-	hfGenKey := pbkdf2.Key(cfg.MasterKeys.Key0, []byte("Derive OF Key"), 1000, 16, sha256.New)
-
-	// First check for MAC creation errors.
-	if _, err := scrypto.InitMac(hfGenKey); err != nil {
+	var err error
+	if cfg.HFMacPool, err = newMacPool(cfg.MasterKeys.Key0); err != nil {
 		return err
 	}
-	// Create a pool of MAC instances.
-	cfg.HFMacPool = &sync.Pool{
-		New: func() interface{} {
-			mac, _ := scrypto.InitMac(hfGenKey)
-			return mac
-		},
+	if cfg.SecondaryHFMacPool, err = newMacPool(cfg.MasterKeys.Key1); err != nil {
+		return err
 	}
 	return nil
 }
 
+// newMacPool creates a pool of MAC instances for the hop field MAC key
+// derived from the master key.
+func newMacPool(masterKey common.RawBytes) (*sync.Pool, error) {
+	newMac, err := scrypto.HFMacFactory(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &sync.Pool{
+		New: func() interface{} {
+			return newMac()
+		},
+	}, nil
+}
+
 // initNet initializes the network configuration.
 func (cfg *BRConf) initNet() error {
 	var err error
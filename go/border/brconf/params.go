@@ -77,6 +77,19 @@ type BR struct {
 	// RollbackFailAction indicates the action that should be taken
 	// if the rollback fails.
 	RollbackFailAction FailAction
+	// DRKeyStaticFile is the file with the secret from which the keys to
+	// authenticate SCMP messages are derived. The secret is used as the master
+	// key of all ASes, it must thus only be used for testing. SCMP
+	// authentication is disabled if it is not set.
+	DRKeyStaticFile string
+	// SCMPHashTreeKeysFile is the JSON file that maps ISD-AS strings to the
+	// base64 encoded Ed25519 keys that verify the signatures of SCMP messages
+	// authenticated with a hash tree, see scmp_auth.LoadStaticHashTreeKeys.
+	// The signatures are not verified if it is not set.
+	SCMPHashTreeKeysFile string
+	// Capture enables the packet capture endpoint /capture on the prometheus
+	// HTTP server.
+	Capture bool
 }
 
 func (cfg *BR) InitDefaults() {
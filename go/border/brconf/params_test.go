@@ -51,6 +51,9 @@ func InitTestDiscoveryConfig(cfg *Discovery) {
 
 func InitTestBRConfig(cfg *BR) {
 	cfg.Profile = true
+	cfg.DRKeyStaticFile = "test"
+	cfg.SCMPHashTreeKeysFile = "test"
+	cfg.Capture = true
 }
 
 func CheckTestConfig(cfg *Config, id string) {
@@ -67,4 +70,7 @@ func CheckTestDiscoveryConfig(cfg *Discovery) {
 func CheckTestBRConfig(cfg *BR) {
 	SoMsg("Profile correct", cfg.Profile, ShouldBeFalse)
 	SoMsg("RollbackFailAction correct", cfg.RollbackFailAction, ShouldEqual, FailActionFatal)
+	SoMsg("DRKeyStaticFile correct", cfg.DRKeyStaticFile, ShouldBeEmpty)
+	SoMsg("SCMPHashTreeKeysFile correct", cfg.SCMPHashTreeKeysFile, ShouldBeEmpty)
+	SoMsg("Capture correct", cfg.Capture, ShouldBeFalse)
 }
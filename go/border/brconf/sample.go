@@ -23,6 +23,22 @@ Profile = false
 # Action that should be taken when an error occurs during a context rollback.
 # (Fatal | Continue) (default Fatal)
 RollbackFailAction = "Fatal"
+
+# File with the base64 encoded secret from which the keys to authenticate SCMP
+# messages are derived. The secret is used as the master key of all ASes, it
+# must thus only be used for testing. SCMP authentication is disabled if it is
+# not set. (default "")
+DRKeyStaticFile = ""
+
+# JSON file that maps ISD-AS strings to the base64 encoded Ed25519 keys that
+# verify the signatures of SCMP messages authenticated with a hash tree. The
+# signatures are not verified if it is not set. (default "")
+SCMPHashTreeKeysFile = ""
+
+# Enable the packet capture endpoint /capture on the prometheus HTTP server.
+# The captured packets can contain sensitive data, the endpoint must only be
+# reachable by administrators. (default false)
+Capture = false
 `
 
 const discoverySample = `
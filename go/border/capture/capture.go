@@ -0,0 +1,283 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capture implements the on-demand packet capture of the border
+// router.
+//
+// A capture session records copies of the packets that match its filter in a
+// ring buffer of bounded size. The buffer is allocated when the session starts
+// and is at most MaxBufferSize bytes large. When the buffer is full, the oldest records are
+// overwritten, such that capturing never blocks packet forwarding. At most one
+// session is active at a time. If no session is active, the cost of a capture
+// point is a single atomic load.
+//
+// The records of a session are written as pcap or pcapng file, see WritePcap
+// and WritePcapng. The capture is controlled over HTTP, see NewHandler.
+package capture
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spkt"
+)
+
+// MaxBufferSize is the maximum size of the ring buffer of a session in bytes.
+const MaxBufferSize = 32 << 20
+
+// Point is the location in the router where a packet is captured.
+type Point uint8
+
+const (
+	// Input captures the packets read from a socket.
+	Input Point = iota
+	// Output captures the packets written to a socket.
+	Output
+	// Drop captures the packets that are dropped because of an error.
+	Drop
+)
+
+func (p Point) String() string {
+	switch p {
+	case Input:
+		return "in"
+	case Output:
+		return "out"
+	case Drop:
+		return "drop"
+	default:
+		return "unknown"
+	}
+}
+
+// PointFromString parses the string representation of a capture point.
+func PointFromString(s string) (Point, error) {
+	for _, p := range []Point{Input, Output, Drop} {
+		if strings.ToLower(s) == p.String() {
+			return p, nil
+		}
+	}
+	return 0, common.NewBasicError("Unknown capture point", nil, "point", s)
+}
+
+// Filter selects the packets that are captured. Empty fields match all
+// packets.
+type Filter struct {
+	// IfIDs are the interfaces on which packets are captured. Interface 0 is
+	// the socket to the local AS.
+	IfIDs []common.IFIDType
+	// Points are the capture points.
+	Points []Point
+	// SrcIA and DstIA are the source and destination ISD-AS of the packets.
+	// The ISD or the AS can be 0 to match all ISDs or ASes.
+	SrcIA addr.IA
+	DstIA addr.IA
+	// Reason is the drop reason. If it is set, only dropped packets match.
+	Reason string
+}
+
+// Match returns whether the packet raw captured at p on interface ifid with
+// the drop reason matches the filter.
+func (f *Filter) Match(p Point, ifid common.IFIDType, reason string, raw common.RawBytes) bool {
+	if len(f.IfIDs) > 0 && !containsIfID(f.IfIDs, ifid) {
+		return false
+	}
+	if len(f.Points) > 0 && !containsPoint(f.Points, p) {
+		return false
+	}
+	if f.Reason != "" && (p != Drop || f.Reason != reason) {
+		return false
+	}
+	if f.SrcIA.IsZero() && f.DstIA.IsZero() {
+		return true
+	}
+	// The ISD-ASes directly follow the common header.
+	if len(raw) < spkt.CmnHdrLen+2*addr.IABytes {
+		return false
+	}
+	dstIA := addr.IAFromRaw(raw[spkt.CmnHdrLen:])
+	srcIA := addr.IAFromRaw(raw[spkt.CmnHdrLen+addr.IABytes:])
+	return matchIA(f.SrcIA, srcIA) && matchIA(f.DstIA, dstIA)
+}
+
+func containsIfID(ifids []common.IFIDType, ifid common.IFIDType) bool {
+	for _, i := range ifids {
+		if i == ifid {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPoint(points []Point, p Point) bool {
+	for _, c := range points {
+		if c == p {
+			return true
+		}
+	}
+	return false
+}
+
+func matchIA(filter, ia addr.IA) bool {
+	return (filter.I == 0 || filter.I == ia.I) && (filter.A == 0 || filter.A == ia.A)
+}
+
+// Record is a captured packet.
+type Record struct {
+	Time   time.Time
+	Point  Point
+	IfID   common.IFIDType
+	Reason string
+	// Len is the length of the packet.
+	Len int
+	// Raw is the packet, truncated to the snap length of the session.
+	Raw common.RawBytes
+}
+
+// Session is a capture session.
+type Session struct {
+	filter  Filter
+	snapLen int
+
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+	lost    uint64
+}
+
+var (
+	// enabled is 1 if a session is active. It is accessed atomically.
+	enabled int32
+	// activeMu protects active.
+	activeMu sync.RWMutex
+	active   *Session
+)
+
+// Start starts a session that captures the packets matching f. The session
+// keeps the last size packets, truncated to snapLen bytes. An error is
+// returned if the buffer exceeds MaxBufferSize or if another session is
+// active.
+func Start(f Filter, size, snapLen int) (*Session, error) {
+	if err := CheckBufferSize(size, snapLen); err != nil {
+		return nil, err
+	}
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	if active != nil {
+		return nil, common.NewBasicError("Capture already active", nil)
+	}
+	// Preallocate the buffer, such that capturing does not allocate.
+	buf := make(common.RawBytes, size*snapLen)
+	records := make([]Record, size)
+	for i := range records {
+		records[i].Raw = buf[i*snapLen : i*snapLen : (i+1)*snapLen]
+	}
+	active = &Session{filter: f, snapLen: snapLen, records: records}
+	atomic.StoreInt32(&enabled, 1)
+	return active, nil
+}
+
+// CheckBufferSize returns an error if a session that keeps size packets of
+// snapLen bytes exceeds MaxBufferSize.
+func CheckBufferSize(size, snapLen int) error {
+	if size <= 0 || snapLen <= 0 {
+		return common.NewBasicError("Size and snap length must be positive", nil,
+			"size", size, "snapLen", snapLen)
+	}
+	if size > MaxBufferSize/snapLen {
+		return common.NewBasicError("Capture exceeds buffer size", nil,
+			"size", size, "snapLen", snapLen, "max", MaxBufferSize)
+	}
+	return nil
+}
+
+// Stop stops the session. It returns the captured records, oldest first, and
+// the number of records that were overwritten because the ring buffer was
+// full.
+func (s *Session) Stop() ([]Record, uint64) {
+	activeMu.Lock()
+	if active == s {
+		atomic.StoreInt32(&enabled, 0)
+		active = nil
+	}
+	activeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return s.records[:s.next], s.lost
+	}
+	return append(s.records[s.next:], s.records[:s.next]...), s.lost
+}
+
+// SnapLen returns the snap length of the session.
+func (s *Session) SnapLen() int {
+	return s.snapLen
+}
+
+func (s *Session) add(p Point, ifid common.IFIDType, reason string, raw common.RawBytes) {
+	if !s.filter.Match(p, ifid, reason, raw) {
+		return
+	}
+	n := len(raw)
+	if n > s.snapLen {
+		n = s.snapLen
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := &s.records[s.next]
+	if s.full {
+		s.lost++
+	}
+	// The preallocated buffer has a capacity of snapLen.
+	r.Raw = append(r.Raw[:0], raw[:n]...)
+	r.Time, r.Point, r.IfID, r.Reason, r.Len = now, p, ifid, reason, len(raw)
+	s.next++
+	if s.next == len(s.records) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+// Packet captures the packet raw at p on interface ifid, if a session is
+// active and the packet matches its filter.
+func Packet(p Point, ifid common.IFIDType, raw common.RawBytes) {
+	if atomic.LoadInt32(&enabled) == 0 {
+		return
+	}
+	add(p, ifid, "", raw)
+}
+
+// Dropped captures the packet raw that was received on interface ifid and
+// dropped for the given reason, if a session is active and the packet
+// matches its filter.
+func Dropped(ifid common.IFIDType, reason string, raw common.RawBytes) {
+	if atomic.LoadInt32(&enabled) == 0 {
+		return
+	}
+	add(Drop, ifid, reason, raw)
+}
+
+func add(p Point, ifid common.IFIDType, reason string, raw common.RawBytes) {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	if active != nil {
+		active.add(p, ifid, reason, raw)
+	}
+}
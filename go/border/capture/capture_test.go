@@ -0,0 +1,189 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+// testPkt returns a packet from srcIA to dstIA with an SCMP hop-by-hop
+// extension.
+func testPkt(srcIA, dstIA string) common.RawBytes {
+	raw := make(common.RawBytes, spkt.CmnHdrLen+2*addr.IABytes+8+common.LineLen)
+	cmnHdr := spkt.CmnHdr{
+		DstType:  addr.HostTypeIPv4,
+		SrcType:  addr.HostTypeIPv4,
+		TotalLen: uint16(len(raw)),
+		HdrLen:   uint8((len(raw) - common.LineLen) / common.LineLen),
+		NextHdr:  common.HopByHopClass,
+	}
+	cmnHdr.Write(raw)
+	xtest.MustParseIA(dstIA).Write(raw[spkt.CmnHdrLen:])
+	xtest.MustParseIA(srcIA).Write(raw[spkt.CmnHdrLen+addr.IABytes:])
+	extn := raw[len(raw)-common.LineLen:]
+	extn[0], extn[1], extn[2] = uint8(common.L4UDP), 1, common.ExtnSCMPType.Type
+	return raw
+}
+
+func TestFilter(t *testing.T) {
+	Convey("Filter", t, func() {
+		raw := testPkt("1-ff00:0:110", "2-ff00:0:220")
+		tests := []struct {
+			desc   string
+			f      Filter
+			p      Point
+			reason string
+			match  bool
+		}{
+			{"empty", Filter{}, Input, "", true},
+			{"ifid", Filter{IfIDs: []common.IFIDType{2, 3}}, Input, "", false},
+			{"point", Filter{Points: []Point{Output, Drop}}, Drop, "parse", true},
+			{"reason", Filter{Reason: "parse"}, Input, "", false},
+			{"wrong reason", Filter{Reason: "parse"}, Drop, "route", false},
+			{"src", Filter{SrcIA: xtest.MustParseIA("1-0")}, Input, "", true},
+			{"dst", Filter{DstIA: xtest.MustParseIA("1-ff00:0:220")}, Input, "", false},
+		}
+		for _, test := range tests {
+			SoMsg(test.desc, test.f.Match(test.p, 1, test.reason, raw), ShouldEqual, test.match)
+		}
+	})
+}
+
+func TestSession(t *testing.T) {
+	Convey("Session", t, func() {
+		s, err := Start(Filter{Points: []Point{Input}}, 2, 10)
+		SoMsg("err", err, ShouldBeNil)
+		_, err = Start(Filter{}, 2, 10)
+		SoMsg("second session", err, ShouldNotBeNil)
+		raw := testPkt("1-ff00:0:110", "2-ff00:0:220")
+		for i := 1; i <= 3; i++ {
+			Packet(Input, common.IFIDType(i), raw)
+		}
+		Packet(Output, 4, raw)
+		records, lost := s.Stop()
+		SoMsg("lost", lost, ShouldEqual, 1)
+		SoMsg("records", len(records), ShouldEqual, 2)
+		SoMsg("oldest", records[0].IfID, ShouldEqual, 2)
+		SoMsg("newest", records[1].IfID, ShouldEqual, 3)
+		SoMsg("snap", records[1].Raw, ShouldResemble, raw[:10])
+		SoMsg("len", records[1].Len, ShouldEqual, len(raw))
+		Packet(Input, 5, raw)
+		records, _ = s.Stop()
+		SoMsg("stopped", len(records), ShouldEqual, 2)
+	})
+	Convey("Start rejects sessions that exceed the buffer size", t, func() {
+		_, err := Start(Filter{}, MaxBufferSize/MaxSnapLen+1, MaxSnapLen)
+		SoMsg("err", err, ShouldNotBeNil)
+		_, err = Start(Filter{}, 0, 10)
+		SoMsg("zero size", err, ShouldNotBeNil)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	mtu := func(ifids []common.IFIDType) int {
+		if len(ifids) == 0 {
+			return 1400
+		}
+		return 0
+	}
+	Convey("Handler", t, func() {
+		tests := []struct {
+			desc    string
+			query   string
+			code    int
+			snapLen uint32
+		}{
+			{"default snaplen is the mtu", "", http.StatusOK, 1400},
+			{"unknown mtu", "ifid=1", http.StatusOK, DefaultSnapLen},
+			{"explicit snaplen", "snaplen=100", http.StatusOK, 100},
+			{"snaplen too large", "snaplen=65536", http.StatusBadRequest, 0},
+			{"buffer too large", "count=100000&snaplen=1500", http.StatusBadRequest, 0},
+		}
+		for _, test := range tests {
+			req := httptest.NewRequest(http.MethodGet,
+				"/capture?format=pcap&duration=1ms&"+test.query, nil)
+			w := httptest.NewRecorder()
+			NewHandler(mtu).ServeHTTP(w, req)
+			SoMsg(test.desc+" code", w.Code, ShouldEqual, test.code)
+			if test.code == http.StatusOK {
+				SoMsg(test.desc+" snaplen", order.Uint32(w.Body.Bytes()[16:]),
+					ShouldEqual, test.snapLen)
+			}
+		}
+	})
+}
+
+func TestWritePcap(t *testing.T) {
+	Convey("WritePcap writes the header and the records", t, func() {
+		raw := testPkt("1-ff00:0:110", "2-ff00:0:220")
+		records := []Record{
+			{Point: Input, IfID: 1, Len: len(raw), Raw: raw},
+			{Point: Drop, IfID: 1, Reason: "parse", Len: len(raw), Raw: raw[:5]},
+		}
+		buf := &bytes.Buffer{}
+		SoMsg("err", WritePcap(buf, records, 100), ShouldBeNil)
+		b := buf.Bytes()
+		SoMsg("magic", order.Uint32(b), ShouldEqual, pcapMagic)
+		SoMsg("snaplen", order.Uint32(b[16:]), ShouldEqual, 100)
+		SoMsg("link type", order.Uint32(b[20:]), ShouldEqual, LinkType)
+		b = b[24:]
+		for _, r := range records {
+			SoMsg("caplen", order.Uint32(b[8:]), ShouldEqual, len(r.Raw))
+			SoMsg("len", order.Uint32(b[12:]), ShouldEqual, r.Len)
+			SoMsg("raw", common.RawBytes(b[16:16+len(r.Raw)]), ShouldResemble, r.Raw)
+			b = b[16+len(r.Raw):]
+		}
+		SoMsg("end", len(b), ShouldEqual, 0)
+	})
+}
+
+func TestWritePcapng(t *testing.T) {
+	Convey("WritePcapng writes well-formed blocks", t, func() {
+		raw := testPkt("1-ff00:0:110", "2-ff00:0:220")
+		records := []Record{
+			{Point: Input, IfID: 1, Len: len(raw), Raw: raw},
+			{Point: Drop, IfID: 1, Reason: "parse", Len: len(raw), Raw: raw[:5]},
+			{Point: Output, IfID: 2, Len: len(raw), Raw: raw},
+		}
+		buf := &bytes.Buffer{}
+		SoMsg("err", WritePcapng(buf, records, DefaultSnapLen), ShouldBeNil)
+		var types []uint32
+		for b := buf.Bytes(); len(b) > 0; {
+			l := order.Uint32(b[4:])
+			SoMsg("aligned", l%4, ShouldEqual, 0)
+			SoMsg("trailer", order.Uint32(b[l-4:]), ShouldEqual, l)
+			types = append(types, order.Uint32(b))
+			b = b[l:]
+		}
+		SoMsg("types", types, ShouldResemble, []uint32{pcapngSHB, pcapngIDB, pcapngEPB,
+			pcapngEPB, pcapngIDB, pcapngEPB})
+	})
+	Convey("Annotate describes the extensions", t, func() {
+		raw := testPkt("1-ff00:0:110", "2-ff00:0:220")
+		desc := Annotate(Record{Point: Drop, Reason: "parse", Raw: raw})
+		SoMsg("desc", desc, ShouldStartWith, "point: drop, reason: parse, extn: SCMP")
+		SoMsg("l4", desc, ShouldEndWith, "l4: UDP")
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+)
+
+const (
+	// DefaultDuration is the default duration of a capture.
+	DefaultDuration = 10 * time.Second
+	// MaxDuration is the maximum duration of a capture.
+	MaxDuration = 5 * time.Minute
+	// DefaultSize is the default number of packets kept by a capture.
+	DefaultSize = 1000
+	// MaxSize is the maximum number of packets kept by a capture.
+	MaxSize = 100000
+	// DefaultSnapLen is the number of bytes captured per packet if the MTU
+	// of the captured interfaces is unknown.
+	DefaultSnapLen = 1500
+	// MaxSnapLen is the maximum number of bytes captured per packet.
+	MaxSnapLen = 65535
+)
+
+// MTUFunc returns the largest MTU of the interfaces ifids, or of all
+// interfaces if ifids is empty. Interface 0 is the socket to the local AS. It
+// returns 0 if the MTU is unknown.
+type MTUFunc func(ifids []common.IFIDType) int
+
+// NewHandler returns the HTTP handler that runs a capture and responds with
+// the captured packets. The capture is configured with the following query
+// parameters, all of them are optional:
+//  ifid:     comma separated list of interface IDs, 0 is the local socket
+//  point:    comma separated list of capture points (in, out, drop)
+//  src, dst: source and destination ISD-AS, e.g., 1-ff00:0:110 or 1-0
+//  reason:   drop reason, e.g., path_expired_hopf, see rcmn.DropReason. If it
+//            is set, only dropped packets are captured
+//  duration: duration of the capture, e.g., 30s (default 10s, max 5m)
+//  count:    number of packets that are kept, older packets are overwritten
+//            (default 1000, max 100000)
+//  snaplen:  number of bytes captured per packet (default the MTU of the
+//            captured interfaces as returned by mtu, max 65535)
+//  format:   pcapng or pcap (default pcapng)
+// Requests for which count times snaplen exceeds MaxBufferSize are rejected.
+// The capture stops early if the client cancels the request.
+func NewHandler(mtu MTUFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, mtu)
+	})
+}
+
+func handle(w http.ResponseWriter, r *http.Request, mtu MTUFunc) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	f, err := parseFilter(q.Get("ifid"), q.Get("point"), q.Get("src"), q.Get("dst"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.Reason = q.Get("reason")
+	duration, err := parseDuration(q.Get("duration"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	size, err := parseInt(q.Get("count"), "count", DefaultSize, MaxSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	snapLen, err := parseInt(q.Get("snaplen"), "snaplen", defaultSnapLen(mtu, f.IfIDs),
+		MaxSnapLen)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := CheckBufferSize(size, snapLen); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	write := WritePcapng
+	switch q.Get("format") {
+	case "", "pcapng":
+	case "pcap":
+		write = WritePcap
+	default:
+		http.Error(w, "Unknown format", http.StatusBadRequest)
+		return
+	}
+	s, err := Start(f, size, snapLen)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	log.Info("[capture] Started", "filter", f, "duration", duration, "count", size,
+		"snapLen", snapLen)
+	select {
+	case <-time.After(duration):
+	case <-r.Context().Done():
+	}
+	records, lost := s.Stop()
+	log.Info("[capture] Stopped", "records", len(records), "overwritten", lost)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := write(w, records, snapLen); err != nil {
+		log.Error("[capture] Unable to write capture", "err", err)
+	}
+}
+
+func defaultSnapLen(mtu MTUFunc, ifids []common.IFIDType) int {
+	if mtu == nil {
+		return DefaultSnapLen
+	}
+	if l := mtu(ifids); l > 0 && l <= MaxSnapLen {
+		return l
+	}
+	return DefaultSnapLen
+}
+
+func parseFilter(ifids, points, src, dst string) (Filter, error) {
+	var f Filter
+	for _, s := range splitList(ifids) {
+		ifid, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return f, common.NewBasicError("Invalid interface ID", err, "ifid", s)
+		}
+		f.IfIDs = append(f.IfIDs, common.IFIDType(ifid))
+	}
+	for _, s := range splitList(points) {
+		p, err := PointFromString(s)
+		if err != nil {
+			return f, err
+		}
+		f.Points = append(f.Points, p)
+	}
+	var err error
+	if src != "" {
+		if f.SrcIA, err = addr.IAFromString(src); err != nil {
+			return f, err
+		}
+	}
+	if dst != "" {
+		if f.DstIA, err = addr.IAFromString(dst); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return DefaultDuration, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, common.NewBasicError("Invalid duration", err)
+	}
+	if d <= 0 || d > MaxDuration {
+		return 0, common.NewBasicError("Duration out of range", nil,
+			"duration", d, "max", MaxDuration)
+	}
+	return d, nil
+}
+
+func parseInt(s, name string, def, max int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, common.NewBasicError("Invalid parameter", err, "param", name)
+	}
+	if v <= 0 || v > max {
+		return 0, common.NewBasicError("Parameter out of range", nil,
+			"param", name, "value", v, "max", max)
+	}
+	return v, nil
+}
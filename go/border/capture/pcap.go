@@ -0,0 +1,209 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/gopacket"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/layers"
+	"github.com/scionproto/scion/go/lib/spkt"
+)
+
+// LinkType is the pcap link type of the captured packets. The packets start
+// with the SCION common header, there is no registered link type for SCION,
+// so the first user link type is used. The SCION dissector of wireshark can
+// be assigned to it in the DLT_USER preferences.
+const LinkType = 147
+
+const (
+	pcapMagic        = 0xa1b2c3d4
+	pcapngSHB        = 0x0a0d0d0a
+	pcapngIDB        = 0x00000001
+	pcapngEPB        = 0x00000006
+	pcapngByteOrder  = 0x1a2b3c4d
+	pcapngOptEnd     = 0
+	pcapngOptComment = 1
+	pcapngOptName    = 2
+	pcapngOptFlags   = 2
+	// Direction bits of the epb_flags option.
+	pcapngInbound  = 1
+	pcapngOutbound = 2
+)
+
+var order = binary.LittleEndian
+
+// WritePcap writes the records as pcap file.
+func WritePcap(w io.Writer, records []Record, snapLen int) error {
+	bw := bufio.NewWriter(w)
+	hdr := make([]byte, 24)
+	order.PutUint32(hdr[0:], pcapMagic)
+	order.PutUint16(hdr[4:], 2)
+	order.PutUint16(hdr[6:], 4)
+	order.PutUint32(hdr[16:], uint32(snapLen))
+	order.PutUint32(hdr[20:], LinkType)
+	bw.Write(hdr)
+	recHdr := make([]byte, 16)
+	for _, r := range records {
+		order.PutUint32(recHdr[0:], uint32(r.Time.Unix()))
+		order.PutUint32(recHdr[4:], uint32(r.Time.Nanosecond()/1000))
+		order.PutUint32(recHdr[8:], uint32(len(r.Raw)))
+		order.PutUint32(recHdr[12:], uint32(r.Len))
+		bw.Write(recHdr)
+		bw.Write(r.Raw)
+	}
+	return bw.Flush()
+}
+
+// WritePcapng writes the records as pcapng file. Each interface of the router
+// is written as separate interface of the file. The packets are annotated
+// with the capture point, the drop reason and the SCION extensions.
+func WritePcapng(w io.Writer, records []Record, snapLen int) error {
+	bw := bufio.NewWriter(w)
+	shb := make([]byte, 16)
+	order.PutUint32(shb[0:], pcapngByteOrder)
+	order.PutUint16(shb[4:], 1)
+	order.PutUint16(shb[6:], 0)
+	// The section length is not specified.
+	order.PutUint64(shb[8:], ^uint64(0))
+	writeBlock(bw, pcapngSHB, shb, nil)
+	idbs := make(map[common.IFIDType]uint32)
+	for _, r := range records {
+		id, ok := idbs[r.IfID]
+		if !ok {
+			id = uint32(len(idbs))
+			idbs[r.IfID] = id
+			idb := make([]byte, 8)
+			order.PutUint16(idb[0:], LinkType)
+			order.PutUint32(idb[4:], uint32(snapLen))
+			name := fmt.Sprintf("ifid %d", r.IfID)
+			if r.IfID == 0 {
+				name = "local"
+			}
+			writeBlock(bw, pcapngIDB, idb, []option{{pcapngOptName, []byte(name)}})
+		}
+		// Timestamps are in microseconds, the default resolution.
+		ts := uint64(r.Time.UnixNano() / 1000)
+		epb := make([]byte, 20, 20+len(r.Raw)+3)
+		order.PutUint32(epb[0:], id)
+		order.PutUint32(epb[4:], uint32(ts>>32))
+		order.PutUint32(epb[8:], uint32(ts))
+		order.PutUint32(epb[12:], uint32(len(r.Raw)))
+		order.PutUint32(epb[16:], uint32(r.Len))
+		epb = append(epb, r.Raw...)
+		epb = append(epb, make([]byte, pad(len(r.Raw)))...)
+		flags := make([]byte, 4)
+		if r.Point == Output {
+			order.PutUint32(flags, pcapngOutbound)
+		} else {
+			order.PutUint32(flags, pcapngInbound)
+		}
+		opts := []option{
+			{pcapngOptFlags, flags},
+			{pcapngOptComment, []byte(Annotate(r))},
+		}
+		writeBlock(bw, pcapngEPB, epb, opts)
+	}
+	return bw.Flush()
+}
+
+type option struct {
+	code  uint16
+	value []byte
+}
+
+// writeBlock writes a pcapng block with the given type, body and options.
+// The body must be padded to 32 bits.
+func writeBlock(w io.Writer, blockType uint32, body []byte, opts []option) {
+	optsLen := 0
+	if len(opts) > 0 {
+		for _, o := range opts {
+			optsLen += 4 + len(o.value) + pad(len(o.value))
+		}
+		optsLen += 4
+	}
+	totalLen := uint32(12 + len(body) + optsLen)
+	b := make([]byte, 8, totalLen)
+	order.PutUint32(b[0:], blockType)
+	order.PutUint32(b[4:], totalLen)
+	b = append(b, body...)
+	if len(opts) > 0 {
+		for _, o := range opts {
+			b = appendOption(b, o.code, o.value)
+		}
+		b = appendOption(b, pcapngOptEnd, nil)
+	}
+	b = b[:len(b)+4]
+	order.PutUint32(b[len(b)-4:], totalLen)
+	w.Write(b)
+}
+
+func appendOption(b []byte, code uint16, value []byte) []byte {
+	hdr := make([]byte, 4)
+	order.PutUint16(hdr[0:], code)
+	order.PutUint16(hdr[2:], uint16(len(value)))
+	b = append(b, hdr...)
+	b = append(b, value...)
+	return append(b, make([]byte, pad(len(value)))...)
+}
+
+func pad(l int) int {
+	return (4 - l%4) % 4
+}
+
+// Annotate describes the capture point, the drop reason, and the extensions
+// and the L4 protocol of the captured packet.
+func Annotate(r Record) string {
+	desc := []string{fmt.Sprintf("point: %s", r.Point)}
+	if r.Reason != "" {
+		desc = append(desc, fmt.Sprintf("reason: %s", r.Reason))
+	}
+	cmnHdr, err := spkt.CmnHdrFromRaw(r.Raw)
+	if err != nil {
+		return strings.Join(desc, ", ")
+	}
+	off := cmnHdr.HdrLenBytes()
+	class := cmnHdr.NextHdr
+	for (class == common.HopByHopClass || class == common.End2EndClass) && off < len(r.Raw) {
+		extn := &layers.Extension{}
+		if err := extn.DecodeFromBytes(r.Raw[off:], gopacket.NilDecodeFeedback); err != nil {
+			desc = append(desc, fmt.Sprintf("extn: truncated %s", class))
+			return strings.Join(desc, ", ")
+		}
+		desc = append(desc, fmt.Sprintf("extn: %s", describeExtn(class, extn)))
+		off += len(extn.Contents)
+		class = extn.NextHeader
+	}
+	desc = append(desc, fmt.Sprintf("l4: %s", class))
+	return strings.Join(desc, ", ")
+}
+
+func describeExtn(class common.L4ProtocolType, extn *layers.Extension) string {
+	e, err := layers.ExtensionFactory(class, extn)
+	if err != nil {
+		return fmt.Sprintf("invalid %s", common.ExtnType{Class: class, Type: extn.Type})
+	}
+	if _, ok := e.(*layers.ExtnUnknown); ok {
+		// Only the type of the extensions that layers does not decode is known.
+		return e.Type().String()
+	}
+	return e.String()
+}
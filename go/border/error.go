@@ -19,12 +19,17 @@
 package main
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/border/capture"
+	"github.com/scionproto/scion/go/border/metrics"
 	"github.com/scionproto/scion/go/border/rcmn"
 	"github.com/scionproto/scion/go/border/rpkt"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/layers"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
 )
 
 type pktErrorArgs struct {
@@ -33,11 +38,15 @@ type pktErrorArgs struct {
 }
 
 // handlePktError is called to enqueue packets with protocol-level errors
-// for handling by the PacketError goroutine.
-func (r *Router) handlePktError(rp *rpkt.RtrPkt, perr error, desc string) {
+// for handling by the PacketError goroutine. The packet is accounted as
+// dropped in stage, unless perr carries a more specific reason.
+func (r *Router) handlePktError(rp *rpkt.RtrPkt, perr error, stage rcmn.DropReason,
+	desc string) {
+
 	// XXX(kormat): uncomment for debugging:
 	// perr = common.NewBasicError("Raw packet", perr, "raw", rp.Raw)
 	rp.Error(desc, "err", perr)
+	r.dropPkt(rp, rcmn.DropReasonFromError(stage, perr))
 	rp.RefInc(1)
 	args := pktErrorArgs{rp: rp, perr: perr}
 	select {
@@ -100,7 +109,19 @@ func (r *Router) doPktError(rp *rpkt.RtrPkt, perr error) {
 		rp.Error("Error creating SCMP response", "err", err)
 		return
 	}
-	reply.Route()
+	if err := reply.Route(); err != nil {
+		rp.Error("Error routing SCMP response", "err", err)
+		return
+	}
+	metrics.SCMPGenerated.With(metrics.SCMPLabels(serr.CT)).Inc()
+}
+
+// dropPkt accounts for the packet being dropped for the given reason, and
+// captures it if a capture session is active.
+func (r *Router) dropPkt(rp *rpkt.RtrPkt, reason rcmn.DropReason) {
+	metrics.Drops.With(prometheus.Labels{"sock": rp.Ingress.Sock,
+		"reason": string(reason)}).Inc()
+	capture.Dropped(rp.Ingress.IfID, string(reason), rp.Raw)
 }
 
 // createSCMPErrorReply generates an SCMP error reply to the supplied packet.
@@ -134,7 +155,29 @@ func (r *Router) createSCMPErrorReply(rp *rpkt.RtrPkt, ct scmp.ClassType,
 			sp.HBHExt = append(sp.HBHExt, e)
 		}
 	}
+	if rp.Ctx.Conf.DRKeys != nil {
+		// Authenticate the error with the key between the local AS and the
+		// destination host. SCMPAuthDRKey extensions of the original packet
+		// are dropped, they do not apply to the reply.
+		oldE2E := sp.E2EExt
+		sp.E2EExt = nil
+		for _, e := range oldE2E {
+			if _, ok := e.(*scmp_auth.DRKeyExtn); !ok {
+				sp.E2EExt = append(sp.E2EExt, e)
+			}
+		}
+		authExt := scmp_auth.NewDRKeyExtn()
+		authExt.Direction = scmp_auth.AsToHost
+		sp.E2EExt = append(sp.E2EExt, authExt)
+	}
 	sp.Pld = scmp.PldFromQuotes(ct, info, rp.L4Type, rp.GetRaw)
 	sp.L4 = scmp.NewHdr(ct, sp.Pld.Len())
-	return rp.CreateReply(sp)
+	reply, err := rp.CreateReply(sp)
+	if err != nil {
+		return nil, err
+	}
+	if err := reply.AuthSCMP(); err != nil {
+		return nil, err
+	}
+	return reply, nil
 }
@@ -23,9 +23,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/ipv4"
 
+	"github.com/scionproto/scion/go/border/capture"
 	"github.com/scionproto/scion/go/border/metrics"
+	"github.com/scionproto/scion/go/border/rcmn"
 	"github.com/scionproto/scion/go/border/rctx"
 	"github.com/scionproto/scion/go/border/rpkt"
 	"github.com/scionproto/scion/go/lib/assert"
@@ -131,6 +134,7 @@ Top:
 			rp.Ingress.Sock = sock
 			inputBytes.Add(float64(msg.N))
 			inputPktSize.Observe(float64(msg.N))
+			capture.Packet(capture.Input, s.Ifid, rp.Raw)
 		}
 		for written := 0; written < pktsRead; {
 			wn, _ := s.Ring.Write(pkts[written:pktsRead], true)
@@ -207,6 +211,8 @@ func (r *Router) posixOutput(s *rctx.Sock, _, stopped chan struct{}) {
 	outputWrites := metrics.OutputWrites.With(s.Labels)
 	outputWriteErrs := metrics.OutputWriteErrors.With(s.Labels)
 	outputWriteLatency := metrics.OutputWriteLatency.With(s.Labels)
+	outputDrops := metrics.Drops.With(prometheus.Labels{"sock": s.Labels["sock"],
+		"reason": string(rcmn.DropOutput)})
 
 	for {
 		var bytes int // Needs to be declared before goto
@@ -229,6 +235,7 @@ func (r *Router) posixOutput(s *rctx.Sock, _, stopped chan struct{}) {
 				if common.IsTemporaryErr(err) {
 					continue
 				}
+				outputDrops.Add(float64(toWrite))
 				pktsWritten = toWrite
 				goto End
 			}
@@ -243,6 +250,7 @@ func (r *Router) posixOutput(s *rctx.Sock, _, stopped chan struct{}) {
 			}
 			bytes += msg.N
 			outputPktSize.Observe(float64(msg.N))
+			capture.Packet(capture.Output, s.Ifid, rp.Raw)
 			rp.Release()   // Release inner RtrPkt entry
 			epkts[i] = nil // Clear EgressRtrPkt reference
 		}
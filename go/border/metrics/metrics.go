@@ -22,6 +22,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/scmp"
 )
 
 // Declare prometheus metrics to export.
@@ -51,10 +52,29 @@ var (
 	ProcessPktTime    *prometheus.CounterVec
 	ProcessSockSrcDst *prometheus.CounterVec
 
+	// Hop field verification metrics
+	HFMacVerify *prometheus.CounterVec
+
+	// Drop and SCMP metrics
+	Drops         *prometheus.CounterVec
+	SCMPGenerated *prometheus.CounterVec
+
 	// Misc
 	IFState *prometheus.GaugeVec
 )
 
+// Values of the key label of HFMacVerify.
+const (
+	// MasterKeyActive indicates that the hop field was verified with the
+	// active master key.
+	MasterKeyActive = "active"
+	// MasterKeySecondary indicates that the hop field was verified with the
+	// secondary master key.
+	MasterKeySecondary = "secondary"
+	// MasterKeyNone indicates that the hop field could not be verified.
+	MasterKeyNone = "none"
+)
+
 // Init ensures all metrics are registered.
 func Init(elem string) {
 	namespace := "border"
@@ -109,6 +129,18 @@ func Init(elem string) {
 	ProcessSockSrcDst = newCVec("process_pkts_src_dst_total",
 		"Total number of packets from one sock to another.", []string{"inSock", "outSock"})
 
+	HFMacVerify = newCVec("hopf_mac_verify_total",
+		"Total number of hop field MAC verifications, by master key that verified the MAC.",
+		[]string{"key"})
+
+	Drops = newCVec("drops_total",
+		"Total number of dropped packets, by drop reason and input sock, or output sock "+
+			"for packets that could not be written.",
+		[]string{"sock", "reason"})
+	SCMPGenerated = newCVec("scmp_generated_total",
+		"Total number of SCMP messages generated by the router, by class and type.",
+		[]string{"class", "type"})
+
 	// border_base_labels is a special metric that always has the value `1`,
 	// that is used to add labels to non-br metrics.
 	BRLabels := newG("base_labels", "Border base labels.")
@@ -118,3 +150,8 @@ func Init(elem string) {
 	// Initialize ringbuf metrics.
 	ringbuf.InitMetrics("border", []string{"ringId"})
 }
+
+// SCMPLabels returns the labels of SCMPGenerated for ct.
+func SCMPLabels(ct scmp.ClassType) prometheus.Labels {
+	return prometheus.Labels{"class": ct.Class.Label(), "type": ct.Type.Label(ct.Class)}
+}
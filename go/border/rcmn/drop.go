@@ -0,0 +1,86 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rcmn
+
+import (
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scmp"
+)
+
+// DropReason classifies why a packet was dropped. It is used as label of the
+// drop metrics and to filter packet captures.
+//
+// Packets dropped because of an error with SCMP metadata are classified by
+// the SCMP class and type of the error, e.g., path_expired_hopf,
+// path_bad_mac or path_bad_if, see scmp.ClassType.Label. Packets rejected by
+// the validation hook of an extension are classified as DropExtension, SCMP
+// messages with an invalid authenticator as DropSCMPAuth. All other drops are
+// classified by the processing stage in which they happened.
+type DropReason string
+
+// Processing stages in which packets are dropped.
+const (
+	// DropParse indicates that the packet could not be parsed.
+	DropParse DropReason = "parse"
+	// DropValidate indicates that the packet failed validation.
+	DropValidate DropReason = "validate"
+	// DropExtension indicates that the validation hook of an extension
+	// rejected the packet.
+	DropExtension DropReason = "extension"
+	// DropSCMPAuth indicates that the authenticator of an SCMP message, i.e.,
+	// its DRKey MAC or hash tree signature, could not be verified.
+	DropSCMPAuth DropReason = "scmp_auth"
+	// DropLocalProcessing indicates that it could not be determined whether
+	// the packet must be processed by the router.
+	DropLocalProcessing DropReason = "local_processing"
+	// DropPayload indicates that the payload of a packet destined to the
+	// router could not be parsed.
+	DropPayload DropReason = "payload"
+	// DropProcess indicates that the processing hooks failed.
+	DropProcess DropReason = "process"
+	// DropRoute indicates that the packet could not be forwarded.
+	DropRoute DropReason = "route"
+	// DropOutput indicates that the packet could not be written to the
+	// socket.
+	DropOutput DropReason = "output"
+)
+
+const (
+	// ErrExtnHook is the message of the errors that wrap the errors of
+	// extension hooks, such that the resulting drops are classified as
+	// DropExtension.
+	ErrExtnHook = "Extension hook failed"
+	// ErrSCMPAuth is the message of the errors that wrap the errors of the
+	// SCMP authentication, such that the resulting drops are classified as
+	// DropSCMPAuth.
+	ErrSCMPAuth = "SCMP authentication failed"
+)
+
+// DropReasonFromError returns the reason for dropping a packet because of err
+// in stage. If err carries SCMP metadata, the SCMP class and type are
+// returned. If err is an ErrExtnHook or ErrSCMPAuth error, DropExtension or
+// DropSCMPAuth is returned, otherwise stage.
+func DropReasonFromError(stage DropReason, err error) DropReason {
+	if serr := scmp.ToError(err); serr != nil {
+		return DropReason(serr.CT.Label())
+	}
+	switch common.GetErrorMsg(err) {
+	case ErrExtnHook:
+		return DropExtension
+	case ErrSCMPAuth:
+		return DropSCMPAuth
+	}
+	return stage
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rcmn
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scmp"
+)
+
+func TestDropReasonFromError(t *testing.T) {
+	scmpErr := scmp.NewError(scmp.C_Path, scmp.T_P_ExpiredHopF, nil, nil)
+	tests := []struct {
+		name     string
+		err      error
+		expected DropReason
+	}{
+		{
+			name:     "SCMP errors are classified by class and type",
+			err:      scmpErr,
+			expected: "path_expired_hopf",
+		},
+		{
+			name:     "Nested SCMP errors are classified by class and type",
+			err:      common.NewBasicError("Outer", scmpErr),
+			expected: "path_expired_hopf",
+		},
+		{
+			name:     "Other errors are classified by stage",
+			err:      common.NewBasicError("Plain error", nil),
+			expected: DropProcess,
+		},
+		{
+			name:     "Extension hook errors are classified as extension",
+			err:      common.NewBasicError(ErrExtnHook, common.NewBasicError("Hook", nil)),
+			expected: DropExtension,
+		},
+		{
+			name:     "SCMP authentication errors are classified as scmp_auth",
+			err:      common.NewBasicError(ErrSCMPAuth, common.NewBasicError("Bad MAC", nil)),
+			expected: DropSCMPAuth,
+		},
+		{
+			name:     "Extension hook errors with SCMP metadata are classified by SCMP",
+			err:      common.NewBasicError(ErrExtnHook, scmpErr),
+			expected: "path_expired_hopf",
+		},
+	}
+	Convey("DropReasonFromError", t, func() {
+		for _, test := range tests {
+			Convey(test.name, func() {
+				SoMsg("reason", DropReasonFromError(DropProcess, test.err), ShouldEqual,
+					test.expected)
+			})
+		}
+	})
+}
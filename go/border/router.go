@@ -132,23 +132,27 @@ func (r *Router) processPacket(rp *rpkt.RtrPkt) {
 	// XXX(kormat): uncomment for debugging:
 	//rp.Debug("processPacket", "raw", rp.Raw)
 	if err := rp.Parse(); err != nil {
-		r.handlePktError(rp, err, "Error parsing packet")
+		r.handlePktError(rp, err, rcmn.DropParse, "Error parsing packet")
 		return
 	}
 	// Validation looks for errors in the packet that didn't break basic
 	// parsing.
 	valid, err := rp.Validate()
 	if err != nil {
-		r.handlePktError(rp, err, "Error validating packet")
+		r.handlePktError(rp, err, rcmn.DropValidate, "Error validating packet")
 		return
 	}
 	if !valid {
+		// Only the validation hooks of the extensions reject packets
+		// without error.
+		r.dropPkt(rp, rcmn.DropExtension)
 		return
 	}
 	// Check if the packet needs to be processed locally, and if so register
 	// hooks for doing so.
 	if err := rp.NeedsLocalProcessing(); err != nil {
 		rp.Error("Error checking for local processing", "err", err)
+		r.dropPkt(rp, rcmn.DropLocalProcessing)
 		return
 	}
 	// Parse the packet payload, if a previous step has registered a relevant
@@ -157,15 +161,16 @@ func (r *Router) processPacket(rp *rpkt.RtrPkt) {
 		// Any errors at this point are application-level, and hence not
 		// calling handlePktError, as no SCMP errors will be sent.
 		rp.Error("Error parsing payload", "err", err)
+		r.dropPkt(rp, rcmn.DropPayload)
 		return
 	}
 	// Process the packet, if a previous step has registered a relevant hook for doing so.
 	if err := rp.Process(); err != nil {
-		r.handlePktError(rp, err, "Error processing packet")
+		r.handlePktError(rp, err, rcmn.DropProcess, "Error processing packet")
 		return
 	}
 	// Forward the packet. Packets destined to self are forwarded to the local dispatcher.
 	if err := rp.Route(); err != nil {
-		r.handlePktError(rp, err, "Error routing packet")
+		r.handlePktError(rp, err, rcmn.DropRoute, "Error routing packet")
 	}
 }
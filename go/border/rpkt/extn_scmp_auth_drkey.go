@@ -18,9 +18,12 @@
 package rpkt
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/spse"
 	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
 )
@@ -101,3 +104,89 @@ func (s *rSCMPAuthDRKeyExtn) ResetMac() {
 		s.MAC()[i] = 0
 	}
 }
+
+// VerifyMAC verifies the MAC of the packet with the key indicated by the
+// direction, as provided by keys for time t. Within scmpAuthEpochGrace after
+// the start of an epoch, the key of the previous epoch is accepted as well,
+// as the packet may have been authenticated before the epoch changed.
+func (s *rSCMPAuthDRKeyExtn) VerifyMAC(keys drkey.Lvl2Source, t time.Time) error {
+	key, err := s.key(keys, t)
+	if err != nil {
+		return err
+	}
+	expected := append(common.RawBytes(nil), s.MAC()...)
+	s.ResetMac()
+	defer s.SetMAC(expected)
+	ok, err := s.verify(key.Key, expected)
+	if !ok && err == nil && t.Sub(key.Epoch.Begin) < scmpAuthEpochGrace {
+		var prev drkey.Lvl2Key
+		if prev, err = s.key(keys, key.Epoch.Begin.Add(-time.Second)); err != nil {
+			return err
+		}
+		ok, err = s.verify(prev.Key, expected)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return common.NewBasicError("Invalid SCMP authentication MAC", nil,
+			"dir", s.Direction())
+	}
+	return nil
+}
+
+// verify computes the MAC of the packet with key and compares it to expected.
+// The MAC field of the extension must be zero.
+func (s *rSCMPAuthDRKeyExtn) verify(key drkey.DRKey, expected common.RawBytes) (bool, error) {
+	mac, err := scmp_auth.ComputeMAC(key, s.rp.Raw)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(mac, expected) == 1, nil
+}
+
+// Authenticate sets the MAC of the packet, using the key indicated by the
+// direction, as provided by keys for time t.
+func (s *rSCMPAuthDRKeyExtn) Authenticate(keys drkey.Lvl2Source, t time.Time) error {
+	key, err := s.key(keys, t)
+	if err != nil {
+		return err
+	}
+	s.ResetMac()
+	mac, err := scmp_auth.ComputeMAC(key.Key, s.rp.Raw)
+	if err != nil {
+		return err
+	}
+	return s.SetMAC(mac)
+}
+
+// key returns the key indicated by the direction, as provided by keys for
+// time t.
+func (s *rSCMPAuthDRKeyExtn) key(keys drkey.Lvl2Source, t time.Time) (drkey.Lvl2Key, error) {
+	srcIA, err := s.rp.SrcIA()
+	if err != nil {
+		return drkey.Lvl2Key{}, err
+	}
+	dstIA, err := s.rp.DstIA()
+	if err != nil {
+		return drkey.Lvl2Key{}, err
+	}
+	srcHost, err := s.rp.SrcHost()
+	if err != nil {
+		return drkey.Lvl2Key{}, err
+	}
+	dstHost, err := s.rp.DstHost()
+	if err != nil {
+		return drkey.Lvl2Key{}, err
+	}
+	meta, err := s.Direction().Lvl2Meta(srcIA, dstIA, srcHost, dstHost)
+	if err != nil {
+		return drkey.Lvl2Key{}, err
+	}
+	key, err := keys.Lvl2(meta, t)
+	if err != nil {
+		return drkey.Lvl2Key{}, common.NewBasicError("Unable to get SCMP authentication key",
+			err, "meta", meta)
+	}
+	return key, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpkt
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestSCMPAuthDRKeyExtnMAC(t *testing.T) {
+	keys, err := drkey.NewStaticSource(common.RawBytes("static drkey secret"), time.Hour)
+	xtest.FailOnErr(t, err)
+	now := time.Now()
+	for dir := scmp_auth.AsToAs; dir <= scmp_auth.HostToHostReversed; dir++ {
+		Convey("Authenticate and verify with direction "+dir.String(), t, func() {
+			r, extn := prepareSCMPAuthSample(t, dir)
+			SoMsg("authenticate", extn.Authenticate(keys, now), ShouldBeNil)
+			SoMsg("mac", extn.MAC(), ShouldNotResemble,
+				make(common.RawBytes, scmp_auth.MACLength))
+			SoMsg("verify", extn.VerifyMAC(keys, now), ShouldBeNil)
+			Convey("The MAC survives path offset updates", func() {
+				r.CmnHdr.UpdatePathOffsets(r.Raw, r.CmnHdr.CurrInfoF, r.CmnHdr.CurrHopF+1)
+				SoMsg("verify", extn.VerifyMAC(keys, now), ShouldBeNil)
+			})
+			Convey("A tampered packet is rejected", func() {
+				r.Raw[len(r.Raw)-scmp_auth.DRKeyTotalLength-1] ^= 0xff
+				SoMsg("verify", extn.VerifyMAC(keys, now), ShouldNotBeNil)
+			})
+			Convey("A tampered MAC is rejected", func() {
+				extn.MAC()[0] ^= 0xff
+				SoMsg("verify", extn.VerifyMAC(keys, now), ShouldNotBeNil)
+			})
+			Convey("A different direction is rejected", func() {
+				extn.SetDirection((dir + 1) % (scmp_auth.HostToHostReversed + 1))
+				SoMsg("verify", extn.VerifyMAC(keys, now), ShouldNotBeNil)
+			})
+		})
+	}
+}
+
+func TestSCMPAuthDRKeyExtnEpochChange(t *testing.T) {
+	keys, err := drkey.NewStaticSource(common.RawBytes("static drkey secret"), time.Hour)
+	xtest.FailOnErr(t, err)
+	begin := drkey.EpochAt(time.Now(), time.Hour).Begin
+	Convey("MACs of the previous epoch", t, func() {
+		_, extn := prepareSCMPAuthSample(t, scmp_auth.AsToHost)
+		xtest.FailOnErr(t, extn.Authenticate(keys, begin.Add(-time.Second)))
+		Convey("are accepted shortly after the epoch change", func() {
+			err := extn.VerifyMAC(keys, begin.Add(scmpAuthEpochGrace-time.Second))
+			SoMsg("verify", err, ShouldBeNil)
+		})
+		Convey("are rejected after the grace period", func() {
+			SoMsg("verify", extn.VerifyMAC(keys, begin.Add(scmpAuthEpochGrace)), ShouldNotBeNil)
+		})
+	})
+	Convey("MACs of the next epoch are rejected before the epoch change", t, func() {
+		_, extn := prepareSCMPAuthSample(t, scmp_auth.AsToHost)
+		xtest.FailOnErr(t, extn.Authenticate(keys, begin))
+		SoMsg("verify", extn.VerifyMAC(keys, begin.Add(-time.Second)), ShouldNotBeNil)
+	})
+}
+
+// prepareSCMPAuthSample returns the sample packet, with the last bytes of the
+// payload replaced by an SCMPAuthDRKey extension with direction dir.
+func prepareSCMPAuthSample(t *testing.T, dir scmp_auth.Dir) (*RtrPkt, *rSCMPAuthDRKeyExtn) {
+	r := prepareRtrPacketSample()
+	r.Logger = log.Root()
+	xtest.FailOnErr(t, r.parseBasic())
+	e := scmp_auth.NewDRKeyExtn()
+	xtest.FailOnErr(t, e.SetDirection(dir))
+	start := len(r.Raw) - e.Len()
+	xtest.FailOnErr(t, e.Write(r.Raw[start:]))
+	extn, err := rSCMPAuthDRKeyExtnFromRaw(r, start, len(r.Raw))
+	xtest.FailOnErr(t, err)
+	return r, extn
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
 	"github.com/scionproto/scion/go/lib/spse"
 	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
 )
@@ -68,6 +69,41 @@ func (s *rSCMPAuthHashTreeExtn) Validate() (HookResult, error) {
 	return HookContinue, nil
 }
 
+// VerifySignature verifies that the hashes of the extension prove that the
+// packet is a leaf of a hash tree, and that the root of the tree is signed
+// with the key of the source AS, as provided by keys.
+func (s *rSCMPAuthHashTreeExtn) VerifySignature(keys scmp_auth.HashTreeKeys) error {
+	srcIA, err := s.rp.SrcIA()
+	if err != nil {
+		return err
+	}
+	key, err := keys.VerifyKey(srcIA)
+	if err != nil {
+		return common.NewBasicError("Unable to get SCMP authentication key", err,
+			"ia", srcIA)
+	}
+	// The leaf is computed with order, signature and hashes set to zero.
+	proof := s.raw[scmp_auth.OrderOffset:s.TotalLength()]
+	saved := append(common.RawBytes(nil), proof...)
+	for i := range proof {
+		proof[i] = 0
+	}
+	leaf, err := scmp_auth.ComputeLeaf(s.rp.Raw)
+	copy(proof, saved)
+	if err != nil {
+		return err
+	}
+	root, err := scmp_auth.ComputeRoot(leaf, s.Order(), s.Hashes())
+	if err != nil {
+		return err
+	}
+	if err := scrypto.Verify(root, s.Signature(), key, scrypto.Ed25519); err != nil {
+		return common.NewBasicError("Invalid SCMP authentication signature", err,
+			"ia", srcIA)
+	}
+	return nil
+}
+
 // GetExtn returns the scmp_auth.HashTreeExtn representation,
 // which does not have direct access to the underlying buffer.
 func (s *rSCMPAuthHashTreeExtn) GetExtn() (common.Extension, error) {
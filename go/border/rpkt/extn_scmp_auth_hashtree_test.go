@@ -0,0 +1,101 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpkt
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestSCMPAuthHashTreeExtnVerify(t *testing.T) {
+	pub, priv, err := scrypto.GenKeyPair(scrypto.Ed25519)
+	xtest.FailOnErr(t, err)
+	otherPub, _, err := scrypto.GenKeyPair(scrypto.Ed25519)
+	xtest.FailOnErr(t, err)
+	Convey("VerifySignature", t, func() {
+		r, extn := prepareSCMPAuthHashTreeSample(t, priv)
+		srcIA, err := r.SrcIA()
+		xtest.FailOnErr(t, err)
+		keys := scmp_auth.StaticHashTreeKeys{srcIA: pub}
+		raw := append(common.RawBytes(nil), r.Raw...)
+		SoMsg("verify", extn.VerifySignature(keys), ShouldBeNil)
+		SoMsg("raw", r.Raw, ShouldResemble, raw)
+		Convey("The proof survives path offset updates", func() {
+			r.CmnHdr.UpdatePathOffsets(r.Raw, r.CmnHdr.CurrInfoF, r.CmnHdr.CurrHopF+1)
+			SoMsg("verify", extn.VerifySignature(keys), ShouldBeNil)
+		})
+		Convey("A tampered packet is rejected", func() {
+			r.Raw[len(r.Raw)-extn.TotalLength()-1] ^= 0xff
+			SoMsg("verify", extn.VerifySignature(keys), ShouldNotBeNil)
+		})
+		Convey("A tampered hash is rejected", func() {
+			extn.Hashes()[0] ^= 0xff
+			SoMsg("verify", extn.VerifySignature(keys), ShouldNotBeNil)
+		})
+		Convey("A different order is rejected", func() {
+			extn.Order()[1] ^= 0x01
+			SoMsg("verify", extn.VerifySignature(keys), ShouldNotBeNil)
+		})
+		Convey("A tampered signature is rejected", func() {
+			extn.Signature()[0] ^= 0xff
+			SoMsg("verify", extn.VerifySignature(keys), ShouldNotBeNil)
+		})
+		Convey("A signature with the key of another AS is rejected", func() {
+			keys := scmp_auth.StaticHashTreeKeys{srcIA: otherPub}
+			SoMsg("verify", extn.VerifySignature(keys), ShouldNotBeNil)
+		})
+		Convey("Messages from ASes without key are rejected", func() {
+			SoMsg("verify", extn.VerifySignature(scmp_auth.StaticHashTreeKeys{}),
+				ShouldNotBeNil)
+		})
+	})
+}
+
+// prepareSCMPAuthHashTreeSample returns the sample packet, with the last bytes
+// of the payload replaced by an SCMPAuthHashTree extension of height 2, whose
+// root is signed with signKey.
+func prepareSCMPAuthHashTreeSample(t *testing.T,
+	signKey common.RawBytes) (*RtrPkt, *rSCMPAuthHashTreeExtn) {
+
+	r := prepareRtrPacketSample()
+	r.Logger = log.Root()
+	xtest.FailOnErr(t, r.parseBasic())
+	e, err := scmp_auth.NewHashTreeExtn(2)
+	xtest.FailOnErr(t, err)
+	start := len(r.Raw) - e.Len()
+	xtest.FailOnErr(t, e.Write(r.Raw[start:]))
+	leaf, err := scmp_auth.ComputeLeaf(r.Raw)
+	xtest.FailOnErr(t, err)
+	order := common.RawBytes{0x00, 0x02}
+	hashes := common.RawBytes("0123456789abcdef0123456789ABCDEF")
+	root, err := scmp_auth.ComputeRoot(leaf, order, hashes)
+	xtest.FailOnErr(t, err)
+	sig, err := scrypto.Sign(root, signKey, scrypto.Ed25519)
+	xtest.FailOnErr(t, err)
+	xtest.FailOnErr(t, e.SetOrder(order))
+	xtest.FailOnErr(t, e.SetSignature(sig))
+	xtest.FailOnErr(t, e.SetHashes(hashes))
+	xtest.FailOnErr(t, e.Write(r.Raw[start:]))
+	extn, err := rSCMPAuthHashTreeExtnFromRaw(r, start, len(r.Raw))
+	xtest.FailOnErr(t, err)
+	return r, extn
+}
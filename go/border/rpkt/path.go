@@ -19,9 +19,11 @@ package rpkt
 
 import (
 	"hash"
+	"sync"
 	"time"
 
 	"github.com/scionproto/scion/go/border/ifstate"
+	"github.com/scionproto/scion/go/border/metrics"
 	"github.com/scionproto/scion/go/border/rcmn"
 	"github.com/scionproto/scion/go/lib/assert"
 	"github.com/scionproto/scion/go/lib/common"
@@ -65,9 +67,7 @@ func (rp *RtrPkt) validatePath(dirFrom rcmn.Dir) error {
 		)
 	}
 	// Verify the Hop Field MAC.
-	hfmac := rp.Ctx.Conf.HFMacPool.Get().(hash.Hash)
-	err := rp.hopF.Verify(hfmac, rp.infoF.TsInt, rp.getHopFVer(dirFrom))
-	rp.Ctx.Conf.HFMacPool.Put(hfmac)
+	err := rp.verifyHopF(dirFrom)
 	if err != nil && common.GetErrorMsg(err) == spath.ErrorHopFBadMac {
 		err = scmp.NewError(scmp.C_Path, scmp.T_P_BadMac,
 			rp.mkInfoPathOffsets(rp.CmnHdr.CurrInfoF, rp.CmnHdr.CurrHopF), err)
@@ -75,6 +75,38 @@ func (rp *RtrPkt) validatePath(dirFrom rcmn.Dir) error {
 	return err
 }
 
+// verifyHopF verifies the Hop Field MAC with the active master key. If the MAC
+// is invalid, it is verified with the secondary master key, such that hop
+// fields created before a master key rotation stay valid.
+func (rp *RtrPkt) verifyHopF(dirFrom rcmn.Dir) error {
+	prevHopF := rp.getHopFVer(dirFrom)
+	err := verifyHopFWithPool(rp.Ctx.Conf.HFMacPool, rp.hopF, rp.infoF.TsInt, prevHopF)
+	if err == nil {
+		metrics.HFMacVerify.WithLabelValues(metrics.MasterKeyActive).Inc()
+		return nil
+	}
+	pool := rp.Ctx.Conf.SecondaryHFMacPool
+	if pool == nil || common.GetErrorMsg(err) != spath.ErrorHopFBadMac {
+		metrics.HFMacVerify.WithLabelValues(metrics.MasterKeyNone).Inc()
+		return err
+	}
+	if secErr := verifyHopFWithPool(pool, rp.hopF, rp.infoF.TsInt, prevHopF); secErr != nil {
+		metrics.HFMacVerify.WithLabelValues(metrics.MasterKeyNone).Inc()
+		return err
+	}
+	metrics.HFMacVerify.WithLabelValues(metrics.MasterKeySecondary).Inc()
+	return nil
+}
+
+func verifyHopFWithPool(pool *sync.Pool, hopF *spath.HopField, tsInt uint32,
+	prev common.RawBytes) error {
+
+	hfmac := pool.Get().(hash.Hash)
+	err := hopF.Verify(hfmac, tsInt, prev)
+	pool.Put(hfmac)
+	return err
+}
+
 // validateLocalIF makes sure a given interface ID exists in the local AS, and
 // that it isn't revoked. Note that revocations are ignored if the packet's
 // destination is this router.
@@ -0,0 +1,117 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpkt
+
+import (
+	"hash"
+	"os"
+	"sync"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/border/metrics"
+	"github.com/scionproto/scion/go/border/rcmn"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Init("br1-ff00_0_111-1")
+	os.Exit(m.Run())
+}
+
+func TestVerifyHopF(t *testing.T) {
+	active, err := scrypto.HFMacFactory(common.RawBytes("active master key"))
+	xtest.FailOnErr(t, err)
+	secondary, err := scrypto.HFMacFactory(common.RawBytes("secondary master key"))
+	xtest.FailOnErr(t, err)
+	other, err := scrypto.HFMacFactory(common.RawBytes("other master key"))
+	xtest.FailOnErr(t, err)
+
+	tests := []struct {
+		name        string
+		issuer      func() hash.Hash
+		noSecondary bool
+		key         string
+		err         bool
+	}{
+		{
+			name:   "Hop field issued with the active key is accepted",
+			issuer: active,
+			key:    metrics.MasterKeyActive,
+		},
+		{
+			name:   "Hop field issued with the secondary key is accepted",
+			issuer: secondary,
+			key:    metrics.MasterKeySecondary,
+		},
+		{
+			name:   "Hop field issued with an unknown key is rejected",
+			issuer: other,
+			key:    metrics.MasterKeyNone,
+			err:    true,
+		},
+		{
+			name:        "Hop field issued with the secondary key is rejected without pool",
+			issuer:      secondary,
+			noSecondary: true,
+			key:         metrics.MasterKeyNone,
+			err:         true,
+		},
+	}
+	for _, test := range tests {
+		Convey(test.name, t, func() {
+			r := prepareRtrPacketSample()
+			xtest.FailOnErr(t, r.parseBasic())
+			infoF, err := r.InfoF()
+			xtest.FailOnErr(t, err)
+			hopF, err := r.HopF()
+			xtest.FailOnErr(t, err)
+			r.Ctx.Conf.HFMacPool = newTestMacPool(active)
+			if !test.noSecondary {
+				r.Ctx.Conf.SecondaryHFMacPool = newTestMacPool(secondary)
+			}
+			hopF.Mac = hopF.CalcMac(test.issuer(), infoF.TsInt,
+				r.getHopFVer(rcmn.DirExternal))
+			before := hfMacVerifyCount(t, test.key)
+
+			err = r.verifyHopF(rcmn.DirExternal)
+			if test.err {
+				SoMsg("err", common.GetErrorMsg(err), ShouldEqual, spath.ErrorHopFBadMac)
+			} else {
+				SoMsg("err", err, ShouldBeNil)
+			}
+			SoMsg("metric", hfMacVerifyCount(t, test.key)-before, ShouldEqual, 1)
+		})
+	}
+}
+
+func newTestMacPool(newMac func() hash.Hash) *sync.Pool {
+	return &sync.Pool{
+		New: func() interface{} {
+			return newMac()
+		},
+	}
+}
+
+func hfMacVerifyCount(t *testing.T, key string) float64 {
+	m := &dto.Metric{}
+	xtest.FailOnErr(t, metrics.HFMacVerify.WithLabelValues(key).Write(m))
+	return m.GetCounter().GetValue()
+}
@@ -19,6 +19,7 @@ package rpkt
 import (
 	"time"
 
+	"github.com/scionproto/scion/go/border/metrics"
 	"github.com/scionproto/scion/go/border/rcmn"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
@@ -123,7 +124,12 @@ func (rp *RtrPkt) processSCMPTraceRoute() error {
 		return err
 	}
 	// Forward reply
-	reply.Route()
+	if err := reply.Route(); err != nil {
+		rp.Error("Error routing traceroute reply", "err", err)
+	} else {
+		ct := scmp.ClassType{Class: scmpHdr.Class, Type: scmpHdr.Type}
+		metrics.SCMPGenerated.With(metrics.SCMPLabels(ct)).Inc()
+	}
 	// Drop original packet prepending drop hook so it is the first one to run.
 	rp.hooks.Route = append([]hookRoute{rp.drop}, rp.hooks.Route...)
 	return nil
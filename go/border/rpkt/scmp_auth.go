@@ -0,0 +1,107 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file handles the authentication of SCMP messages with the
+// SCMPAuthDRKey and SCMPAuthHashTree extensions.
+
+package rpkt
+
+import (
+	"time"
+
+	"github.com/scionproto/scion/go/border/rcmn"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spse"
+)
+
+// scmpAuthEpochGrace is the time after the start of a DRKey epoch during which
+// SCMP messages authenticated with the key of the previous epoch are still
+// accepted. It covers the propagation delay and small clock offsets.
+const scmpAuthEpochGrace = 5 * time.Second
+
+// validateSCMPAuth verifies the authenticator of authenticated SCMP messages
+// that enter the local AS, such that forged messages (e.g., revocations) do
+// not reach the end hosts. SCMPAuthDRKey extensions are verified with the
+// DRKey source, SCMPAuthHashTree extensions with the hash tree keys. SCMP
+// messages without these extensions are not affected, the end hosts decide
+// whether to accept them. Shortly after an epoch change, MACs computed with
+// the DRKey of the previous epoch are accepted. Extensions are not verified
+// if the respective keys are not configured.
+func (rp *RtrPkt) validateSCMPAuth() error {
+	conf := rp.Ctx.Conf
+	if (conf.DRKeys == nil && conf.HashTreeKeys == nil) ||
+		rp.DirFrom != rcmn.DirExternal || !rp.dstIA.Equal(conf.IA) {
+		return nil
+	}
+	if found, err := rp.findL4(); !found || err != nil {
+		return err
+	}
+	if rp.L4Type != common.L4SCMP {
+		return nil
+	}
+	for i, eIdx := range rp.idxs.e2eExt {
+		start := eIdx.Index + common.ExtnSubHdrLen
+		if eIdx.Type != common.ExtnSCIONPacketSecurityType {
+			continue
+		}
+		switch spse.SecMode(rp.Raw[start]) {
+		case spse.ScmpAuthDRKey:
+			if conf.DRKeys == nil {
+				continue
+			}
+		case spse.ScmpAuthHashTree:
+			if conf.HashTreeKeys == nil {
+				continue
+			}
+		default:
+			continue
+		}
+		end := eIdx.Index + int(rp.Raw[eIdx.Index+1])*common.LineLen
+		e, err := rp.extnParseE2E(eIdx.Type, start, end, len(rp.idxs.hbhExt)+i)
+		if err != nil {
+			return err
+		}
+		switch extn := e.(type) {
+		case *rSCMPAuthDRKeyExtn:
+			if _, err := extn.Validate(); err != nil {
+				return err
+			}
+			return extn.VerifyMAC(conf.DRKeys, rp.TimeIn)
+		case *rSCMPAuthHashTreeExtn:
+			if _, err := extn.Validate(); err != nil {
+				return err
+			}
+			return extn.VerifySignature(conf.HashTreeKeys)
+		}
+	}
+	return nil
+}
+
+// AuthSCMP sets the MACs of the SCMPAuthDRKey extensions of a packet created
+// by the router. It is a no-op if no DRKey source is configured.
+func (rp *RtrPkt) AuthSCMP() error {
+	keys := rp.Ctx.Conf.DRKeys
+	if keys == nil {
+		return nil
+	}
+	now := time.Now()
+	for _, e := range rp.E2EExt {
+		if extn, ok := e.(*rSCMPAuthDRKeyExtn); ok {
+			if err := extn.Authenticate(keys, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
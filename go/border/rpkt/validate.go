@@ -17,6 +17,7 @@
 package rpkt
 
 import (
+	"github.com/scionproto/scion/go/border/rcmn"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/scmp"
@@ -70,11 +71,15 @@ func (rp *RtrPkt) Validate() (bool, error) {
 	if err := rp.validateExtns(); err != nil {
 		return false, err
 	}
+	if err := rp.validateSCMPAuth(); err != nil {
+		return false, common.NewBasicError(rcmn.ErrSCMPAuth, err)
+	}
 	for i, f := range rp.hooks.Validate {
 		ret, err := f()
 		switch {
 		case err != nil:
-			return false, err
+			return false, common.NewBasicError(rcmn.ErrExtnHook, err,
+				"hook", "Validate", "idx", i)
 		case ret == HookContinue:
 			continue
 		case ret == HookFinish:
@@ -29,16 +29,19 @@ import (
 	"github.com/syndtr/gocapability/capability"
 
 	"github.com/scionproto/scion/go/border/brconf"
+	"github.com/scionproto/scion/go/border/capture"
 	"github.com/scionproto/scion/go/border/netconf"
 	"github.com/scionproto/scion/go/border/rctx"
 	"github.com/scionproto/scion/go/border/rpkt"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/fatal"
 	"github.com/scionproto/scion/go/lib/infra/modules/idiscovery"
 	"github.com/scionproto/scion/go/lib/infra/modules/itopo"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/proto"
 )
@@ -56,6 +59,9 @@ type extSockOps interface {
 	Teardown(r *Router, ctx *rctx.Ctx, intfs *netconf.Interface, oldCtx *rctx.Ctx)
 }
 
+// drkeyEpochDuration is the duration of the epochs of the static DRKey source.
+const drkeyEpochDuration = 24 * time.Hour
+
 // SockOps enable the network stack to be modular. Any network stack that wants
 // to be included defines its own init function which adds SockOps to these maps.
 var registeredLocSockOps = map[brconf.SockType]locSockOps{}
@@ -92,10 +98,43 @@ func (r *Router) setup() error {
 	if err = r.clearCapabilities(); err != nil {
 		return err
 	}
+	if cfg.BR.Capture {
+		// The capture endpoint is served by the prometheus HTTP server.
+		http.Handle("/capture", capture.NewHandler(captureMTU))
+		log.Info("Packet capture enabled", "addr", cfg.Metrics.Prometheus)
+	}
 	cfg.Metrics.StartPrometheus()
 	return nil
 }
 
+// captureMTU returns the largest MTU of the interfaces ifids, or of all
+// interfaces if ifids is empty. Interface 0 uses the MTU of the local AS.
+func captureMTU(ifids []common.IFIDType) int {
+	ctx := rctx.Get()
+	if ctx == nil {
+		return 0
+	}
+	mtu := 0
+	for _, ifid := range ifids {
+		if ifid == 0 && ctx.Conf.Topo.MTU > mtu {
+			mtu = ctx.Conf.Topo.MTU
+		}
+		if intf, ok := ctx.Conf.Net.IFs[ifid]; ok && intf.MTU > mtu {
+			mtu = intf.MTU
+		}
+	}
+	if len(ifids) > 0 {
+		return mtu
+	}
+	mtu = ctx.Conf.Topo.MTU
+	for _, intf := range ctx.Conf.Net.IFs {
+		if intf.MTU > mtu {
+			mtu = intf.MTU
+		}
+	}
+	return mtu
+}
+
 // clearCapabilities drops unnecessary capabilities after startup
 func (r *Router) clearCapabilities() error {
 	caps, err := capability.NewPid(0)
@@ -117,6 +156,20 @@ func (r *Router) loadNewConfig() (*brconf.BRConf, error) {
 	if config, err = brconf.Load(r.Id, r.confDir); err != nil {
 		return nil, common.NewBasicError("Failed to load topology config", err, "dir", r.confDir)
 	}
+	if cfg.BR.DRKeyStaticFile != "" {
+		config.DRKeys, err = drkey.LoadStaticSource(cfg.BR.DRKeyStaticFile, drkeyEpochDuration)
+		if err != nil {
+			return nil, err
+		}
+		log.Warn("SCMP authentication uses static DRKeys, do not use in production",
+			"file", cfg.BR.DRKeyStaticFile)
+	}
+	if cfg.BR.SCMPHashTreeKeysFile != "" {
+		config.HashTreeKeys, err = scmp_auth.LoadStaticHashTreeKeys(cfg.BR.SCMPHashTreeKeysFile)
+		if err != nil {
+			return nil, err
+		}
+	}
 	log.Debug("Topology and AS config loaded", "IA", config.IA, "IfIDs", config.BR,
 		"dir", r.confDir)
 	return config, nil
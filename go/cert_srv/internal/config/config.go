@@ -38,6 +38,8 @@ const (
 	ReissReqRate = 10 * time.Second
 	// ReissueReqTimeout is the default timeout of a reissue request.
 	ReissueReqTimeout = 5 * time.Second
+	// DRKeyEpochDuration is the default duration of the DRKey epochs.
+	DRKeyEpochDuration = 24 * time.Hour
 
 	ErrorKeyConf   = "Unable to load KeyConf"
 	ErrorCustomers = "Unable to load Customers"
@@ -111,6 +113,15 @@ type CSConfig struct {
 	ReissueTimeout util.DurWrap
 	// AutomaticRenewal whether automatic reissuing is enabled.
 	AutomaticRenewal bool
+	// TransparencyLog is the path to the transparency log that records all
+	// certificate chains issued by this AS. If empty, no log is kept. Only
+	// applies to core ASes.
+	TransparencyLog string
+	// DRKeyEnabled indicates whether the CS derives and serves DRKeys.
+	DRKeyEnabled bool
+	// DRKeyEpochDuration is the duration of the DRKey epochs. It must be the
+	// same in all ASes.
+	DRKeyEpochDuration util.DurWrap
 }
 
 func (cfg *CSConfig) InitDefaults() {
@@ -126,6 +137,9 @@ func (cfg *CSConfig) InitDefaults() {
 	if cfg.ReissueTimeout.Duration == 0 {
 		cfg.ReissueTimeout.Duration = ReissueReqTimeout
 	}
+	if cfg.DRKeyEpochDuration.Duration == 0 {
+		cfg.DRKeyEpochDuration.Duration = DRKeyEpochDuration
+	}
 }
 
 func (cfg *CSConfig) Validate() error {
@@ -141,6 +155,9 @@ func (cfg *CSConfig) Validate() error {
 	if cfg.ReissueTimeout.Duration == 0 {
 		return common.NewBasicError("ReissueTimeout must not be zero", nil)
 	}
+	if cfg.DRKeyEpochDuration.Duration < time.Second {
+		return common.NewBasicError("DRKeyEpochDuration must be at least 1s", nil)
+	}
 	return nil
 }
 
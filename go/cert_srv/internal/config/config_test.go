@@ -100,4 +100,9 @@ func CheckTestCSConfig(cfg *CSConfig) {
 		LeafReissTime)
 	SoMsg("IssuerReissLeadTime correct", cfg.IssuerReissueLeadTime.Duration, ShouldEqual,
 		IssuerReissTime)
+	SoMsg("TransparencyLog correct", cfg.TransparencyLog, ShouldEqual,
+		"/var/lib/scion/spki/cs-1.translog")
+	SoMsg("DRKeyEnabled correct", cfg.DRKeyEnabled, ShouldBeFalse)
+	SoMsg("DRKeyEpochDuration correct", cfg.DRKeyEpochDuration.Duration, ShouldEqual,
+		DRKeyEpochDuration)
 }
@@ -34,4 +34,17 @@ ReissueTimeout = "5s"
 
 # Whether automatic reissuing is enabled. (default false)
 AutomaticRenewal = false
+
+# Path to the transparency log that records all certificate chains issued by
+# this AS. Only applies to core ASes. The log is served on the metrics
+# listener under /translog/ for auditing. If empty, no log is kept.
+# (default "")
+TransparencyLog = "/var/lib/scion/spki/cs-1.translog"
+
+# Whether the CS derives DRKeys from the AS master key and serves them to
+# other CSes and local end hosts. (default false)
+DRKeyEnabled = false
+
+# Duration of the DRKey epochs. It must be the same in all ASes. (default 24h)
+DRKeyEpochDuration = "24h"
 `
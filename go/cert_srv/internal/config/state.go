@@ -18,11 +18,16 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/modules/trust"
 	"github.com/scionproto/scion/go/lib/infra/modules/trust/trustdb"
 	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/scrypto/translog"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
 )
 
 type State struct {
@@ -30,6 +35,9 @@ type State struct {
 	Store *trust.Store
 	// TrustDB is the trust DB.
 	TrustDB trustdb.TrustDB
+	// TransLog is the transparency log of the issued certificate chains. It
+	// is nil if the log is disabled.
+	TransLog *translog.Log
 	// keyConf contains the AS level keys.
 	keyConf *keyconf.Conf
 	// keyConfLock guards KeyConf.
@@ -44,29 +52,66 @@ type State struct {
 	verifierLock sync.RWMutex
 }
 
-func LoadState(confDir string, isCore bool, trustDB trustdb.TrustDB,
+func LoadState(confDir string, ia addr.IA, isCore bool, trustDB trustdb.TrustDB,
 	trustStore *trust.Store) (*State, error) {
 
 	s := &State{
 		Store:   trustStore,
 		TrustDB: trustDB,
 	}
-	if err := s.loadKeyConf(confDir, isCore); err != nil {
+	if err := s.loadKeyConf(confDir, ia, isCore); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
-// loadKeyConf loads the key configuration.
-func (s *State) loadKeyConf(confDir string, isCore bool) error {
-	var err error
-	s.keyConf, err = keyconf.Load(filepath.Join(confDir, "keys"), isCore, isCore, false, true)
+// loadKeyConf loads the key configuration from the key provider configured in
+// the keys directory, see keyconf.NewProvider. The signing algorithms of the
+// keys are determined from the local certificate chain and TRC.
+func (s *State) loadKeyConf(confDir string, ia addr.IA, isCore bool) error {
+	algos, err := loadKeyAlgorithms(filepath.Join(confDir, "certs"), ia)
+	if err != nil {
+		return common.NewBasicError(ErrorKeyConf, err)
+	}
+	s.keyConf, err = keyconf.LoadWithAlgorithms(filepath.Join(confDir, "keys"), algos,
+		isCore, isCore, false, true)
 	if err != nil {
 		return common.NewBasicError(ErrorKeyConf, err)
 	}
 	return nil
 }
 
+// loadKeyAlgorithms determines the signing algorithms of the AS keys from the
+// newest certificate chain and TRC in dir. Algorithms that cannot be
+// determined are left unset, i.e., default to ed25519.
+func loadKeyAlgorithms(dir string, ia addr.IA) (keyconf.Algorithms, error) {
+	var algos keyconf.Algorithms
+	logErr := func(err error) {
+		log.Warn("Error reading crypto material", "err", err)
+	}
+	chain, err := cert.ChainFromDir(dir, ia, logErr)
+	if err != nil {
+		return algos, err
+	}
+	if chain != nil {
+		algos.Sign = chain.Leaf.SignAlgorithm
+		if chain.Issuer.Subject.Equal(ia) {
+			algos.IssSig = chain.Issuer.SignAlgorithm
+		}
+	}
+	t, err := trc.TRCFromDir(dir, ia.I, logErr)
+	if err != nil {
+		return algos, err
+	}
+	if t != nil {
+		if coreAS, ok := t.CoreASes[ia]; ok {
+			algos.OnRoot = coreAS.OnlineKeyAlg
+			algos.OffRoot = coreAS.OfflineKeyAlg
+		}
+	}
+	return algos, nil
+}
+
 // GetSigningKey returns the signing key of the current key configuration.
 func (s *State) GetSigningKey() common.RawBytes {
 	s.keyConfLock.RLock()
@@ -88,6 +133,14 @@ func (s *State) GetDecryptKey() common.RawBytes {
 	return s.keyConf.DecryptKey
 }
 
+// GetMasterKey returns the active AS master key of the current key
+// configuration.
+func (s *State) GetMasterKey() common.RawBytes {
+	s.keyConfLock.RLock()
+	defer s.keyConfLock.RUnlock()
+	return s.keyConf.Master.Key0
+}
+
 // GetOnRootKey returns the online root key of the current key configuration.
 func (s *State) GetOnRootKey() common.RawBytes {
 	s.keyConfLock.RLock()
@@ -21,9 +21,11 @@ import (
 
 	"github.com/scionproto/scion/go/lib/keyconf"
 	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/xtest"
 )
 
 func TestLoadState(t *testing.T) {
+	ia := xtest.MustParseIA("1-ff00:0:311")
 	mstr0, _ := keyconf.LoadKey("testdata/keys/master0.key", keyconf.RawKey)
 	mstr1, _ := keyconf.LoadKey("testdata/keys/master1.key", keyconf.RawKey)
 	dcrpt, _ := keyconf.LoadKey("testdata/keys/as-decrypt.key",
@@ -32,7 +34,7 @@ func TestLoadState(t *testing.T) {
 	issSig, _ := keyconf.LoadKey("testdata/keys/core-sig.seed", scrypto.Ed25519)
 	online, _ := keyconf.LoadKey("testdata/keys/online-root.seed", scrypto.Ed25519)
 	Convey("Load core state", t, func() {
-		state, err := LoadState("testdata", true, nil, nil)
+		state, err := LoadState("testdata", ia, true, nil, nil)
 		SoMsg("err", err, ShouldBeNil)
 		SoMsg("Master0", state.keyConf.Master.Key0, ShouldResemble, mstr0)
 		SoMsg("Master1", state.keyConf.Master.Key1, ShouldResemble, mstr1)
@@ -44,7 +46,7 @@ func TestLoadState(t *testing.T) {
 	})
 
 	Convey("Load non-core state", t, func() {
-		state, err := LoadState("testdata", false, nil, nil)
+		state, err := LoadState("testdata", ia, false, nil, nil)
 		SoMsg("err", err, ShouldBeNil)
 		SoMsg("Master0", state.keyConf.Master.Key0, ShouldResemble, mstr0)
 		SoMsg("Master1", state.keyConf.Master.Key1, ShouldResemble, mstr1)
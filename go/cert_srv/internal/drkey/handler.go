@@ -0,0 +1,222 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"context"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/proto"
+)
+
+const HandlerTimeout = 5 * time.Second
+
+// Lvl1ReqHandler handles first-level key requests of remote CSes. The
+// requested key is derived for the AS of the requester, which must have
+// signed the request, and is encrypted with its public encryption key. Keys
+// are only served for the current and the next epoch.
+type Lvl1ReqHandler struct {
+	Keeper *Keeper
+}
+
+func (h *Lvl1ReqHandler) Handle(r *infra.Request) *infra.HandlerResult {
+	ctx, cancelF := context.WithTimeout(r.Context(), HandlerTimeout)
+	defer cancelF()
+	logger := log.FromCtx(ctx)
+	rw, ok := infra.ResponseWriterFromContext(ctx)
+	if !ok {
+		logger.Error("[DRKeyLvl1Handler] Unable to service request, no response writer found")
+		return infra.MetricsErrInternal
+	}
+	sendAck := messenger.SendAckHelper(ctx, rw)
+	req := r.Message.(*drkey_mgmt.Lvl1Req)
+	peer, ok := r.Peer.(*snet.Addr)
+	if !ok {
+		logger.Error("[DRKeyLvl1Handler] Invalid peer address type", "peer", r.Peer)
+		return infra.MetricsErrInvalid
+	}
+	if err := h.validateSign(r, req); err != nil {
+		logger.Warn("[DRKeyLvl1Handler] Invalid request", "peer", peer, "req", req, "err", err)
+		sendAck(proto.Ack_ErrCode_reject, messenger.AckRejectFailedToVerify)
+		return infra.MetricsErrInvalid
+	}
+	if err := h.Keeper.checkValTime(req.ValTime(), time.Now()); err != nil {
+		logger.Warn("[DRKeyLvl1Handler] Invalid validity time", "peer", peer, "req", req,
+			"err", err)
+		sendAck(proto.Ack_ErrCode_reject, err.Error())
+		return infra.MetricsErrInvalid
+	}
+	rep, err := h.reply(ctx, req)
+	if err != nil {
+		logger.Error("[DRKeyLvl1Handler] Unable to create reply", "peer", peer, "req", req,
+			"err", err)
+		sendAck(proto.Ack_ErrCode_retry, err.Error())
+		return infra.MetricsErrInternal
+	}
+	if err := rw.SendDRKeyLvl1Reply(ctx, rep); err != nil {
+		logger.Error("[DRKeyLvl1Handler] Unable to send reply", "peer", peer, "err", err)
+		return infra.MetricsErrInternal
+	}
+	return infra.MetricsResultOk
+}
+
+// validateSign checks that the request was signed by the AS the key is
+// requested for.
+func (h *Lvl1ReqHandler) validateSign(r *infra.Request, req *drkey_mgmt.Lvl1Req) error {
+	signed, ok := r.FullMessage.(*ctrl.SignedPld)
+	if !ok || signed.Sign == nil || signed.Sign.Type == proto.SignType_none {
+		return common.NewBasicError("Request is not signed", nil)
+	}
+	src, err := ctrl.NewSignSrcDefFromRaw(signed.Sign.Src)
+	if err != nil {
+		return err
+	}
+	if !src.IA.Equal(req.DstIA()) {
+		return common.NewBasicError("Signer does not match requested AS", nil,
+			"signer", src.IA, "dst", req.DstIA())
+	}
+	return nil
+}
+
+func (h *Lvl1ReqHandler) reply(ctx context.Context,
+	req *drkey_mgmt.Lvl1Req) (*drkey_mgmt.Lvl1Rep, error) {
+
+	key, err := h.Keeper.DeriveLvl1(req.DstIA(), req.ValTime())
+	if err != nil {
+		return nil, err
+	}
+	chain, err := h.Keeper.State.Store.GetValidChain(ctx, req.DstIA(), scrypto.LatestVer, nil)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to get certificate chain", err,
+			"ia", req.DstIA())
+	}
+	nonce, err := scrypto.Nonce(scrypto.NaClBoxNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := scrypto.Encrypt(common.RawBytes(key.Key), nonce, chain.Leaf.SubjectEncKey,
+		h.Keeper.State.GetDecryptKey(), chain.Leaf.EncAlgorithm)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to encrypt first-level key", err)
+	}
+	return &drkey_mgmt.Lvl1Rep{
+		RawSrcIA:      key.SrcIA.IAInt(),
+		RawDstIA:      key.DstIA.IAInt(),
+		RawEpochBegin: util.TimeToSecs(key.Epoch.Begin),
+		RawEpochEnd:   util.TimeToSecs(key.Epoch.End),
+		Cipher:        cipher,
+		Nonce:         nonce,
+		CertVerDst:    chain.Leaf.Version,
+		RawTimestamp:  util.TimeToSecs(time.Now()),
+	}, nil
+}
+
+// Lvl2ReqHandler handles second-level key requests of local end hosts, which
+// are forwarded by sciond. Only keys for which the local AS is the source or
+// the destination are served. Keys bound to a host in the local AS are only
+// served to that host, i.e., to the sciond running on it.
+type Lvl2ReqHandler struct {
+	Keeper *Keeper
+}
+
+func (h *Lvl2ReqHandler) Handle(r *infra.Request) *infra.HandlerResult {
+	ctx, cancelF := context.WithTimeout(r.Context(), HandlerTimeout)
+	defer cancelF()
+	logger := log.FromCtx(ctx)
+	rw, ok := infra.ResponseWriterFromContext(ctx)
+	if !ok {
+		logger.Error("[DRKeyLvl2Handler] Unable to service request, no response writer found")
+		return infra.MetricsErrInternal
+	}
+	sendAck := messenger.SendAckHelper(ctx, rw)
+	req := r.Message.(*drkey_mgmt.Lvl2Req)
+	peer, ok := r.Peer.(*snet.Addr)
+	if !ok || peer.Host == nil || !peer.IA.Equal(h.Keeper.IA) {
+		logger.Warn("[DRKeyLvl2Handler] Dropping request from non-local peer", "peer", r.Peer)
+		sendAck(proto.Ack_ErrCode_reject, "Only local hosts can request second-level keys")
+		return infra.MetricsErrInvalid
+	}
+	meta, err := req.Meta(drkey.Epoch{})
+	if err != nil {
+		logger.Warn("[DRKeyLvl2Handler] Invalid request", "req", req, "err", err)
+		sendAck(proto.Ack_ErrCode_reject, messenger.AckRejectFailedToParse)
+		return infra.MetricsErrInvalid
+	}
+	if err := h.validateHost(meta, peer.Host.L3); err != nil {
+		logger.Warn("[DRKeyLvl2Handler] Host not allowed to fetch key", "peer", peer,
+			"req", req, "err", err)
+		sendAck(proto.Ack_ErrCode_reject, err.Error())
+		return infra.MetricsErrInvalid
+	}
+	if err := h.Keeper.checkValTime(req.ValTime(), time.Now()); err != nil {
+		logger.Warn("[DRKeyLvl2Handler] Invalid validity time", "req", req, "err", err)
+		sendAck(proto.Ack_ErrCode_reject, err.Error())
+		return infra.MetricsErrInvalid
+	}
+	lvl1, err := h.Keeper.Lvl1(ctx, req.SrcIA(), req.DstIA(), req.ValTime())
+	if err != nil {
+		logger.Error("[DRKeyLvl2Handler] Unable to get first-level key", "req", req, "err", err)
+		sendAck(proto.Ack_ErrCode_retry, err.Error())
+		return infra.MetricsErrInternal
+	}
+	meta.Epoch = lvl1.Epoch
+	key, err := drkey.DeriveLvl2(meta, lvl1)
+	if err != nil {
+		logger.Warn("[DRKeyLvl2Handler] Unable to derive key", "req", req, "err", err)
+		sendAck(proto.Ack_ErrCode_reject, err.Error())
+		return infra.MetricsErrInvalid
+	}
+	if err := rw.SendDRKeyLvl2Reply(ctx, drkey_mgmt.NewLvl2Rep(key, time.Now())); err != nil {
+		logger.Error("[DRKeyLvl2Handler] Unable to send reply", "peer", peer, "err", err)
+		return infra.MetricsErrInternal
+	}
+	return infra.MetricsResultOk
+}
+
+// validateHost checks that the requesting host is allowed to fetch the key
+// described by meta. AS2AS keys are not bound to a host. AS2Host keys are only
+// served to the destination host, and Host2Host keys to either of the two
+// hosts, if they are in the local AS.
+func (h *Lvl2ReqHandler) validateHost(meta drkey.Lvl2Meta, host addr.HostAddr) error {
+	isSrc := meta.SrcIA.Equal(h.Keeper.IA) && meta.SrcHost != nil && meta.SrcHost.Equal(host)
+	isDst := meta.DstIA.Equal(h.Keeper.IA) && meta.DstHost != nil && meta.DstHost.Equal(host)
+	switch meta.KeyType {
+	case drkey.AS2AS:
+		return nil
+	case drkey.AS2Host:
+		if isDst {
+			return nil
+		}
+	case drkey.Host2Host:
+		if isSrc || isDst {
+			return nil
+		}
+	default:
+		return common.NewBasicError("Unknown key type", nil, "type", meta.KeyType)
+	}
+	return common.NewBasicError("Key not bound to requesting host", nil,
+		"type", meta.KeyType, "host", host)
+}
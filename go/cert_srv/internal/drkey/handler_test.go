@@ -0,0 +1,202 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/ctrl/ack"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/mock_infra"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var (
+	hostA = addr.HostFromIP(net.IP{127, 0, 0, 1})
+	hostB = addr.HostFromIP(net.IP{127, 0, 0, 2})
+)
+
+func TestLvl2ReqHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		peer    *snet.Addr
+		meta    drkey.Lvl2Meta
+		valTime time.Duration
+		ok      bool
+	}{
+		{
+			name: "AS2AS key",
+			peer: hostAddr(localIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.AS2AS, SrcIA: localIA, DstIA: remoteIA},
+			ok:   true,
+		},
+		{
+			name: "AS2Host key for the requesting host",
+			peer: hostAddr(localIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.AS2Host, SrcIA: localIA, DstIA: localIA,
+				DstHost: hostA},
+			ok: true,
+		},
+		{
+			name: "AS2Host key for another host",
+			peer: hostAddr(localIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.AS2Host, SrcIA: localIA, DstIA: localIA,
+				DstHost: hostB},
+		},
+		{
+			name: "AS2Host key for a host in a remote AS",
+			peer: hostAddr(localIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.AS2Host, SrcIA: localIA, DstIA: remoteIA,
+				DstHost: hostA},
+		},
+		{
+			name: "Host2Host key from the requesting host",
+			peer: hostAddr(localIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.Host2Host, SrcIA: localIA, DstIA: remoteIA,
+				SrcHost: hostA, DstHost: hostB},
+			ok: true,
+		},
+		{
+			name: "Host2Host key between other hosts",
+			peer: hostAddr(localIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.Host2Host, SrcIA: localIA, DstIA: remoteIA,
+				SrcHost: hostB, DstHost: hostA},
+		},
+		{
+			name:    "Request for a past epoch",
+			peer:    hostAddr(localIA, hostA),
+			meta:    drkey.Lvl2Meta{KeyType: drkey.AS2AS, SrcIA: localIA, DstIA: remoteIA},
+			valTime: -time.Hour,
+		},
+		{
+			name: "Request from a remote AS",
+			peer: hostAddr(remoteIA, hostA),
+			meta: drkey.Lvl2Meta{KeyType: drkey.AS2AS, SrcIA: localIA, DstIA: remoteIA},
+		},
+	}
+	Convey("Lvl2ReqHandler serves keys only to the hosts they are bound to", t, func() {
+		for _, test := range tests {
+			Convey(test.name, func() {
+				mctrl := gomock.NewController(t)
+				defer mctrl.Finish()
+				k := newTestKeeper(t)
+				now := time.Now()
+				test.meta.Protocol = "test"
+				rw := mock_infra.NewMockResponseWriter(mctrl)
+				var rep *drkey_mgmt.Lvl2Rep
+				var a *ack.Ack
+				if test.ok {
+					rw.EXPECT().SendDRKeyLvl2Reply(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, msg *drkey_mgmt.Lvl2Rep) error {
+							rep = msg
+							return nil
+						})
+				} else {
+					rw.EXPECT().SendAckReply(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, msg *ack.Ack) error {
+							a = msg
+							return nil
+						})
+				}
+				h := &Lvl2ReqHandler{Keeper: k}
+				ctx := infra.NewContextWithResponseWriter(context.Background(), rw)
+				msg := drkey_mgmt.NewLvl2Req(test.meta, now.Add(test.valTime))
+				req := infra.NewRequest(ctx, msg, nil, test.peer, 0)
+				res := h.Handle(req)
+				if !test.ok {
+					SoMsg("res", res, ShouldEqual, infra.MetricsErrInvalid)
+					SoMsg("ack", a.Err, ShouldEqual, proto.Ack_ErrCode_reject)
+					return
+				}
+				SoMsg("res", res, ShouldEqual, infra.MetricsResultOk)
+				lvl1, err := k.DeriveLvl1(test.meta.DstIA, now)
+				xtest.FailOnErr(t, err)
+				test.meta.Epoch = lvl1.Epoch
+				expected, err := drkey.DeriveLvl2(test.meta, lvl1)
+				xtest.FailOnErr(t, err)
+				SoMsg("key", drkey.DRKey(rep.DRKey), ShouldResemble, expected.Key)
+				SoMsg("epoch", rep.Epoch(), ShouldResemble, lvl1.Epoch)
+			})
+		}
+	})
+}
+
+func TestLvl1ReqHandler(t *testing.T) {
+	signed := func(signer addr.IA) *ctrl.SignedPld {
+		src := &ctrl.SignSrcDef{IA: signer, ChainVer: 1, TRCVer: 1}
+		return &ctrl.SignedPld{Sign: proto.NewSignS(proto.SignType_ed25519, src.Pack())}
+	}
+	tests := []struct {
+		name    string
+		signed  *ctrl.SignedPld
+		valTime time.Duration
+	}{
+		{
+			name:   "Unsigned request",
+			signed: &ctrl.SignedPld{},
+		},
+		{
+			name:   "Request signed by another AS",
+			signed: signed(otherIA),
+		},
+		{
+			name:    "Request for a past epoch",
+			signed:  signed(remoteIA),
+			valTime: -time.Hour,
+		},
+		{
+			name:    "Request for the epoch after the next",
+			signed:  signed(remoteIA),
+			valTime: 2 * time.Hour,
+		},
+	}
+	Convey("Lvl1ReqHandler rejects invalid requests", t, func() {
+		for _, test := range tests {
+			Convey(test.name, func() {
+				mctrl := gomock.NewController(t)
+				defer mctrl.Finish()
+				rw := mock_infra.NewMockResponseWriter(mctrl)
+				var a *ack.Ack
+				rw.EXPECT().SendAckReply(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, msg *ack.Ack) error {
+						a = msg
+						return nil
+					})
+				h := &Lvl1ReqHandler{Keeper: newTestKeeper(t)}
+				ctx := infra.NewContextWithResponseWriter(context.Background(), rw)
+				msg := drkey_mgmt.NewLvl1Req(remoteIA, time.Now().Add(test.valTime))
+				req := infra.NewRequest(ctx, msg, test.signed, hostAddr(remoteIA, hostA), 0)
+				SoMsg("res", h.Handle(req), ShouldEqual, infra.MetricsErrInvalid)
+				SoMsg("ack", a.Err, ShouldEqual, proto.Ack_ErrCode_reject)
+			})
+		}
+	})
+}
+
+func hostAddr(ia addr.IA, host addr.HostAddr) *snet.Addr {
+	return &snet.Addr{IA: ia, Host: &addr.AppAddr{L3: host}}
+}
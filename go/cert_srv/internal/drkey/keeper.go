@@ -0,0 +1,209 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drkey implements the DRKey service of the certificate server.
+//
+// The CS derives the secret values of the local AS from the AS master key,
+// and from them the first-level keys from the local AS to remote ASes. The
+// first-level keys from remote ASes to the local AS are fetched from the CSes
+// of the remote ASes and cached until they expire. Local end hosts fetch
+// second-level keys, which the CS derives from the first-level keys.
+package drkey
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/cert_srv/internal/config"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// Keeper provides the first-level keys of the local AS.
+type Keeper struct {
+	State *config.State
+	IA    addr.IA
+	Msgr  infra.Messenger
+	// EpochDuration is the duration of the DRKey epochs.
+	EpochDuration time.Duration
+
+	// remote contains the first-level keys fetched from remote ASes.
+	remote *drkey.Lvl1Store
+	svMu   sync.Mutex
+	svs    []drkey.SV
+}
+
+func NewKeeper(state *config.State, ia addr.IA, msgr infra.Messenger,
+	epochDuration time.Duration) *Keeper {
+
+	return &Keeper{
+		State:         state,
+		IA:            ia,
+		Msgr:          msgr,
+		EpochDuration: epochDuration,
+		remote:        drkey.NewLvl1Store(),
+	}
+}
+
+// SV returns the secret value of the local AS for the epoch that contains t.
+// Only the current and the next epoch are served.
+func (k *Keeper) SV(t time.Time) (drkey.SV, error) {
+	if err := k.checkValTime(t, time.Now()); err != nil {
+		return drkey.SV{}, err
+	}
+	epoch := drkey.EpochAt(t, k.EpochDuration)
+	k.svMu.Lock()
+	defer k.svMu.Unlock()
+	for _, sv := range k.svs {
+		if sv.Epoch.Equal(epoch) {
+			return sv, nil
+		}
+	}
+	sv, err := drkey.DeriveSV(epoch, k.State.GetMasterKey())
+	if err != nil {
+		return drkey.SV{}, err
+	}
+	k.svs = append(k.svs, sv)
+	return sv, nil
+}
+
+// DeriveLvl1 derives the first-level key from the local AS to dst that is
+// valid at t.
+func (k *Keeper) DeriveLvl1(dst addr.IA, t time.Time) (drkey.Lvl1Key, error) {
+	sv, err := k.SV(t)
+	if err != nil {
+		return drkey.Lvl1Key{}, err
+	}
+	meta := drkey.Lvl1Meta{Epoch: sv.Epoch, SrcIA: k.IA, DstIA: dst}
+	return drkey.DeriveLvl1(meta, sv)
+}
+
+// Lvl1 returns the first-level key from src to dst that is valid at t. One of
+// them must be the local AS, and t must be within the current or the next
+// epoch. Keys from remote ASes are fetched from the CS of the remote AS, if
+// they are not cached.
+func (k *Keeper) Lvl1(ctx context.Context, src, dst addr.IA,
+	t time.Time) (drkey.Lvl1Key, error) {
+
+	switch {
+	case src.Equal(k.IA):
+		return k.DeriveLvl1(dst, t)
+	case !dst.Equal(k.IA):
+		return drkey.Lvl1Key{}, common.NewBasicError("Key does not involve the local AS", nil,
+			"src", src, "dst", dst)
+	}
+	if err := k.checkValTime(t, time.Now()); err != nil {
+		return drkey.Lvl1Key{}, err
+	}
+	if key, ok := k.remote.Get(src, dst, t); ok {
+		return key, nil
+	}
+	key, err := k.fetchLvl1(ctx, src, t)
+	if err != nil {
+		return drkey.Lvl1Key{}, err
+	}
+	k.remote.Add(key)
+	return key, nil
+}
+
+// checkValTime checks that t is within the current or the next epoch at now.
+// Keys for past epochs are no longer handed out, and keys for later epochs are
+// not derived ahead of time.
+func (k *Keeper) checkValTime(t, now time.Time) error {
+	current := drkey.EpochAt(now, k.EpochDuration)
+	epoch := drkey.EpochAt(t, k.EpochDuration)
+	if epoch.Begin.Before(current.Begin) || epoch.Begin.After(current.End) {
+		return common.NewBasicError("Validity time outside current and next epoch", nil,
+			"valTime", t, "current", current)
+	}
+	return nil
+}
+
+// fetchLvl1 requests the first-level key from src to the local AS from the
+// CS of src, and decrypts it.
+func (k *Keeper) fetchLvl1(ctx context.Context, src addr.IA,
+	t time.Time) (drkey.Lvl1Key, error) {
+
+	a := &snet.Addr{IA: src, Host: addr.NewSVCUDPAppAddr(addr.SvcCS)}
+	req := drkey_mgmt.NewLvl1Req(k.IA, t)
+	rep, err := k.Msgr.RequestDRKeyLvl1(ctx, req, a, messenger.NextId())
+	if err != nil {
+		return drkey.Lvl1Key{}, common.NewBasicError("Unable to fetch first-level key", err,
+			"src", src)
+	}
+	if !rep.SrcIA().Equal(src) || !rep.DstIA().Equal(k.IA) {
+		return drkey.Lvl1Key{}, common.NewBasicError("Reply for wrong ASes", nil,
+			"src", rep.SrcIA(), "dst", rep.DstIA())
+	}
+	epoch := rep.Epoch()
+	if !epoch.Contains(t) {
+		return drkey.Lvl1Key{}, common.NewBasicError("Reply for wrong epoch", nil,
+			"epoch", epoch, "valTime", t)
+	}
+	chain, err := k.State.Store.GetValidChain(ctx, src, scrypto.LatestVer, nil)
+	if err != nil {
+		return drkey.Lvl1Key{}, common.NewBasicError("Unable to get certificate chain", err,
+			"ia", src)
+	}
+	raw, err := scrypto.Decrypt(rep.Cipher, rep.Nonce, chain.Leaf.SubjectEncKey,
+		k.State.GetDecryptKey(), chain.Leaf.EncAlgorithm)
+	if err != nil {
+		return drkey.Lvl1Key{}, common.NewBasicError("Unable to decrypt first-level key", err,
+			"src", src, "certVerDst", rep.CertVerDst)
+	}
+	if len(raw) != drkey.KeyLen {
+		return drkey.Lvl1Key{}, common.NewBasicError("Invalid key length", nil,
+			"expected", drkey.KeyLen, "actual", len(raw))
+	}
+	meta := drkey.Lvl1Meta{Epoch: epoch, SrcIA: src, DstIA: k.IA}
+	log.Debug("[DRKey] Fetched first-level key", "src", src, "epoch", epoch)
+	return drkey.Lvl1Key{Lvl1Meta: meta, Key: drkey.DRKey(raw)}, nil
+}
+
+// RemoveExpired removes the secret values and first-level keys that expired
+// before now.
+func (k *Keeper) RemoveExpired(now time.Time) {
+	k.svMu.Lock()
+	svs := k.svs[:0]
+	for _, sv := range k.svs {
+		if now.Before(sv.Epoch.End) {
+			svs = append(svs, sv)
+		}
+	}
+	k.svs = svs
+	k.svMu.Unlock()
+	if n := k.remote.RemoveExpired(now); n > 0 {
+		log.Debug("[DRKey] Removed expired first-level keys", "count", n)
+	}
+}
+
+var _ periodic.Task = (*Cleaner)(nil)
+
+// Cleaner is a periodic.Task that removes the expired keys of a Keeper.
+type Cleaner struct {
+	Keeper *Keeper
+}
+
+func (c *Cleaner) Run(_ context.Context) {
+	c.Keeper.RemoveExpired(time.Now())
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/cert_srv/internal/config"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	localIA  = xtest.MustParseIA("1-ff00:0:311")
+	remoteIA = xtest.MustParseIA("1-ff00:0:110")
+	otherIA  = xtest.MustParseIA("1-ff00:0:120")
+)
+
+func TestKeeperCheckValTime(t *testing.T) {
+	k := &Keeper{EpochDuration: time.Hour}
+	now := time.Unix(9000, 0)
+	tests := []struct {
+		name    string
+		valTime time.Time
+		ok      bool
+	}{
+		{"Start of current epoch", time.Unix(7200, 0), true},
+		{"Now", now, true},
+		{"Start of next epoch", time.Unix(10800, 0), true},
+		{"End of next epoch", time.Unix(14399, 0), true},
+		{"End of previous epoch", time.Unix(7199, 0), false},
+		{"Epoch after the next", time.Unix(14400, 0), false},
+	}
+	Convey("checkValTime accepts only the current and the next epoch", t, func() {
+		for _, test := range tests {
+			Convey(test.name, func() {
+				err := k.checkValTime(test.valTime, now)
+				SoMsg("err", err == nil, ShouldEqual, test.ok)
+			})
+		}
+	})
+}
+
+func TestKeeperSV(t *testing.T) {
+	Convey("SV", t, func() {
+		k := newTestKeeper(t)
+		now := time.Now()
+		Convey("Current and next epoch are derived and cached", func() {
+			cur, err := k.SV(now)
+			SoMsg("err cur", err, ShouldBeNil)
+			SoMsg("epoch cur", cur.Epoch, ShouldResemble, drkey.EpochAt(now, time.Hour))
+			next, err := k.SV(now.Add(time.Hour))
+			SoMsg("err next", err, ShouldBeNil)
+			SoMsg("epoch next", next.Epoch.Begin, ShouldResemble, cur.Epoch.End)
+			SoMsg("keys differ", next.Key.Equal(cur.Key), ShouldBeFalse)
+			again, err := k.SV(now)
+			SoMsg("err again", err, ShouldBeNil)
+			SoMsg("cached", again, ShouldResemble, cur)
+			SoMsg("svs", len(k.svs), ShouldEqual, 2)
+		})
+		Convey("Past epochs are rejected", func() {
+			_, err := k.SV(now.Add(-time.Hour))
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("Epochs after the next are rejected", func() {
+			_, err := k.SV(now.Add(2 * time.Hour))
+			SoMsg("err", err, ShouldNotBeNil)
+			SoMsg("svs", k.svs, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestKeeperLvl1(t *testing.T) {
+	Convey("Lvl1", t, func() {
+		k := newTestKeeper(t)
+		now := time.Now()
+		Convey("Keys from the local AS are derived from the SV", func() {
+			key, err := k.Lvl1(context.Background(), localIA, remoteIA, now)
+			SoMsg("err", err, ShouldBeNil)
+			sv, err := k.SV(now)
+			xtest.FailOnErr(t, err)
+			meta := drkey.Lvl1Meta{Epoch: sv.Epoch, SrcIA: localIA, DstIA: remoteIA}
+			expected, err := drkey.DeriveLvl1(meta, sv)
+			xtest.FailOnErr(t, err)
+			SoMsg("key", key, ShouldResemble, expected)
+		})
+		Convey("Keys not involving the local AS are rejected", func() {
+			_, err := k.Lvl1(context.Background(), remoteIA, otherIA, now)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("Keys from remote ASes outside the epoch bounds are rejected", func() {
+			_, err := k.Lvl1(context.Background(), remoteIA, localIA, now.Add(-time.Hour))
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestKeeperRemoveExpired(t *testing.T) {
+	Convey("RemoveExpired removes the SVs of expired epochs", t, func() {
+		k := newTestKeeper(t)
+		now := time.Now()
+		cur, err := k.SV(now)
+		xtest.FailOnErr(t, err)
+		next, err := k.SV(now.Add(time.Hour))
+		xtest.FailOnErr(t, err)
+		k.RemoveExpired(cur.Epoch.End.Add(-time.Second))
+		SoMsg("before end", k.svs, ShouldResemble, []drkey.SV{cur, next})
+		k.RemoveExpired(cur.Epoch.End)
+		SoMsg("at end", k.svs, ShouldResemble, []drkey.SV{next})
+	})
+}
+
+func newTestKeeper(t *testing.T) *Keeper {
+	state, err := config.LoadState("testdata", localIA, false, nil, nil)
+	xtest.FailOnErr(t, err)
+	return NewKeeper(state, localIA, nil, time.Hour)
+}
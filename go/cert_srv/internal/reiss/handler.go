@@ -167,7 +167,7 @@ func (h *Handler) validateReq(c *cert.Certificate, vKey common.RawBytes,
 }
 
 // issueChain creates a certificate chain for the certificate and adds it to the
-// trust store.
+// trust store and the transparency log.
 func (h *Handler) issueChain(ctx context.Context, c *cert.Certificate,
 	vKey common.RawBytes, verVersion uint64) (*cert.Chain, error) {
 
@@ -212,6 +212,12 @@ func (h *Handler) issueChain(ctx context.Context, c *cert.Certificate,
 		tx.Rollback()
 		return nil, common.NewBasicError("Chain already in DB", nil, "chain", chain)
 	}
+	// Record the chain before it becomes visible, such that no chain is issued
+	// without being logged.
+	if err = logChain(h.State.TransLog, chain); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 	if err = tx.Commit(); err != nil {
 		return nil, common.NewBasicError("Failed to commit transaction", err)
 	}
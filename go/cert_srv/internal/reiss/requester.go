@@ -19,8 +19,6 @@ import (
 	"context"
 	"time"
 
-	"golang.org/x/crypto/ed25519"
-
 	"github.com/scionproto/scion/go/cert_srv/internal/config"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
@@ -132,18 +130,20 @@ func (r *Requester) handleRep(ctx context.Context, rep *cert_mgmt.ChainIssRep) (
 
 // validateRep validates that the received certificate chain can be added to the trust store.
 func (r *Requester) validateRep(ctx context.Context, chain *cert.Chain) error {
-	verKey := common.RawBytes(ed25519.PrivateKey(
-		r.State.GetSigningKey()).Public().(ed25519.PublicKey))
+	verKey, err := scrypto.PubKey(r.State.GetSigningKey(), chain.Leaf.SignAlgorithm)
+	if err != nil {
+		return common.NewBasicError("Unable to derive verification key", err)
+	}
 	if !bytes.Equal(chain.Leaf.SubjectSignKey, verKey) {
 		return common.NewBasicError("Invalid SubjectSignKey", nil, "expected",
 			verKey, "actual", chain.Leaf.SubjectSignKey)
 	}
 	// FIXME(roosd): validate SubjectEncKey
-	chain, err := r.State.Store.GetChain(ctx, r.IA, scrypto.LatestVer)
+	local, err := r.State.Store.GetChain(ctx, r.IA, scrypto.LatestVer)
 	if err != nil {
 		return err
 	}
-	issuer := chain.Leaf.Issuer
+	issuer := local.Leaf.Issuer
 	if !chain.Leaf.Issuer.Equal(issuer) {
 		return common.NewBasicError("Invalid Issuer", nil, "expected",
 			issuer, "actual", chain.Leaf.Issuer)
@@ -102,6 +102,9 @@ func (s *Self) createLeafCert(ctx context.Context, leaf *cert.Certificate) error
 	if err := trust.VerifyChain(ctx, s.IA, chain, s.State.Store); err != nil {
 		return common.NewBasicError("Unable to verify chain", err, "chain", chain)
 	}
+	if err := logChain(s.State.TransLog, chain); err != nil {
+		return err
+	}
 	if _, err := s.State.TrustDB.InsertChain(ctx, chain); err != nil {
 		return common.NewBasicError("Unable to write certificate chain", err, "chain", chain)
 	}
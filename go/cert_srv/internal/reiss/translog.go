@@ -0,0 +1,80 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reiss
+
+import (
+	"context"
+	"time"
+
+	"github.com/scionproto/scion/go/cert_srv/internal/config"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/scrypto/translog"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+// logChain appends the issued certificate chain to the transparency log. It
+// is a no-op if the log is disabled.
+func logChain(l *translog.Log, chain *cert.Chain) error {
+	if l == nil {
+		return nil
+	}
+	raw, err := chain.JSON(false)
+	if err != nil {
+		return common.NewBasicError("Unable to encode certificate chain", err)
+	}
+	index, err := l.Append(raw)
+	if err != nil {
+		return common.NewBasicError("Unable to add certificate chain to transparency log", err)
+	}
+	log.Info("[reiss] Added certificate chain to transparency log", "chain", chain.Key(),
+		"index", index)
+	return nil
+}
+
+// TransLogSTH returns a function that creates signed tree heads for the
+// transparency log in state. The tree heads are signed with the issuer signing
+// key and are verifiable with the newest issuer certificate of ia.
+func TransLogSTH(state *config.State, ia addr.IA) translog.STHFunc {
+	return func() (*translog.SignedTreeHead, error) {
+		ctx, cancelF := context.WithTimeout(context.Background(), HandlerTimeout)
+		defer cancelF()
+		issCrt, err := state.TrustDB.GetIssCertMaxVersion(ctx, ia)
+		if err != nil {
+			return nil, err
+		}
+		if issCrt == nil {
+			return nil, common.NewBasicError("Issuer certificate not found", nil, "ia", ia)
+		}
+		size := state.TransLog.Size()
+		root, err := state.TransLog.RootHash(size)
+		if err != nil {
+			return nil, err
+		}
+		sth := &translog.SignedTreeHead{
+			IA:            ia,
+			IssuerVersion: issCrt.Version,
+			TreeSize:      size,
+			Timestamp:     util.TimeToSecs(time.Now()),
+			RootHash:      root,
+		}
+		if err := sth.Sign(state.GetIssSigningKey(), issCrt.SignAlgorithm); err != nil {
+			return nil, common.NewBasicError("Unable to sign tree head", err)
+		}
+		return sth, nil
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/BurntSushi/toml"
 
 	"github.com/scionproto/scion/go/cert_srv/internal/config"
+	"github.com/scionproto/scion/go/cert_srv/internal/drkey"
 	"github.com/scionproto/scion/go/cert_srv/internal/reiss"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/discovery"
@@ -46,6 +47,8 @@ var (
 	reissRunner *periodic.Runner
 	discRunners idiscovery.Runners
 	corePusher  *periodic.Runner
+	drkeyKeeper *drkey.Keeper
+	drkeyRunner *periodic.Runner
 	msgr        infra.Messenger
 	trustDB     trustdb.TrustDB
 )
@@ -82,6 +85,8 @@ func realMain() int {
 	startReissRunner()
 	// Start the periodic fetching from discovery service.
 	startDiscovery()
+	// Start the periodic removal of expired DRKeys.
+	startDRKeyCleaner()
 	// Start the messenger.
 	go func() {
 		defer log.LogPanicAndExit()
@@ -181,6 +186,17 @@ func startDiscovery() {
 	}
 }
 
+func startDRKeyCleaner() {
+	if drkeyKeeper == nil {
+		return
+	}
+	drkeyRunner = periodic.StartPeriodicTask(
+		&drkey.Cleaner{Keeper: drkeyKeeper},
+		periodic.NewTicker(time.Minute),
+		time.Minute,
+	)
+}
+
 func stopReissRunner() {
 	if corePusher != nil {
 		corePusher.Stop()
@@ -193,6 +209,12 @@ func stopReissRunner() {
 func stop() {
 	stopReissRunner()
 	discRunners.Stop()
+	if drkeyRunner != nil {
+		drkeyRunner.Stop()
+	}
 	msgr.CloseServer()
 	trustDB.Close()
+	if state.TransLog != nil {
+		state.TransLog.Close()
+	}
 }
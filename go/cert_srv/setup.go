@@ -16,12 +16,14 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"path/filepath"
 	"time"
 
 	"github.com/BurntSushi/toml"
 
 	"github.com/scionproto/scion/go/cert_srv/internal/config"
+	"github.com/scionproto/scion/go/cert_srv/internal/drkey"
 	"github.com/scionproto/scion/go/cert_srv/internal/metrics"
 	"github.com/scionproto/scion/go/cert_srv/internal/reiss"
 	"github.com/scionproto/scion/go/lib/addr"
@@ -33,6 +35,7 @@ import (
 	"github.com/scionproto/scion/go/lib/infra/modules/trust"
 	"github.com/scionproto/scion/go/lib/infra/modules/trust/trustdb"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scrypto/translog"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/sock/reliable"
 	"github.com/scionproto/scion/go/lib/topology"
@@ -106,7 +109,7 @@ func initState(cfg *config.Config, router snet.Router) error {
 	if err != nil {
 		return common.NewBasicError("Unable to initialize trust store", err)
 	}
-	state, err = config.LoadState(cfg.General.ConfigDir, topo.Core,
+	state, err = config.LoadState(cfg.General.ConfigDir, topo.ISD_AS, topo.Core,
 		trustDB, trustStore)
 	if err != nil {
 		return common.NewBasicError("Unable to load CS state", err)
@@ -123,6 +126,13 @@ func initState(cfg *config.Config, router snet.Router) error {
 	if err = setDefaultSignerVerifier(state, topo.ISD_AS); err != nil {
 		return common.NewBasicError("Unable to set default signer and verifier", err)
 	}
+	if topo.Core && cfg.CS.TransparencyLog != "" {
+		if state.TransLog, err = translog.Open(cfg.CS.TransparencyLog); err != nil {
+			return common.NewBasicError("Unable to open transparency log", err)
+		}
+		http.Handle(translog.PathPrefix,
+			translog.NewHandler(state.TransLog, reiss.TransLogSTH(state, topo.ISD_AS)))
+	}
 	return nil
 }
 
@@ -177,7 +187,8 @@ func setMessenger(cfg *config.Config, router snet.Router) error {
 	msgr.AddHandler(infra.TRCRequest, state.Store.NewTRCReqHandler(true))
 	msgr.AddHandler(infra.Chain, state.Store.NewChainPushHandler())
 	msgr.AddHandler(infra.TRC, state.Store.NewTRCPushHandler())
-	msgr.UpdateSigner(state.GetSigner(), []infra.MessageType{infra.ChainIssueRequest})
+	msgr.UpdateSigner(state.GetSigner(), []infra.MessageType{infra.ChainIssueRequest,
+		infra.DRKeyLvl1Request, infra.DRKeyLvl1Reply})
 	msgr.UpdateVerifier(state.GetVerifier())
 	// Only core CS handles certificate reissuance requests.
 	if topo.Core {
@@ -186,5 +197,11 @@ func setMessenger(cfg *config.Config, router snet.Router) error {
 			IA:    topo.ISD_AS,
 		})
 	}
+	if cfg.CS.DRKeyEnabled {
+		drkeyKeeper = drkey.NewKeeper(state, topo.ISD_AS, msgr,
+			cfg.CS.DRKeyEpochDuration.Duration)
+		msgr.AddHandler(infra.DRKeyLvl1Request, &drkey.Lvl1ReqHandler{Keeper: drkeyKeeper})
+		msgr.AddHandler(infra.DRKeyLvl2Request, &drkey.Lvl2ReqHandler{Keeper: drkeyKeeper})
+	}
 	return nil
 }
@@ -0,0 +1,116 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config contains the configuration of the discovery service.
+package config
+
+import (
+	"io"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/config"
+	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+var (
+	// DefaultDynamicTTL is the default TTL of the dynamic topology.
+	DefaultDynamicTTL = 30 * time.Second
+	// DefaultRegistrationTTL is the default time a service registration is
+	// valid without being renewed.
+	DefaultRegistrationTTL = 30 * time.Second
+)
+
+var _ config.Config = (*Config)(nil)
+
+type Config struct {
+	General env.General
+	Logging env.Logging
+	Metrics env.Metrics
+	DS      DSConfig
+}
+
+func (cfg *Config) InitDefaults() {
+	config.InitAll(
+		&cfg.General,
+		&cfg.Logging,
+		&cfg.Metrics,
+		&cfg.DS,
+	)
+}
+
+func (cfg *Config) Validate() error {
+	return config.ValidateAll(
+		&cfg.General,
+		&cfg.Logging,
+		&cfg.Metrics,
+		&cfg.DS,
+	)
+}
+
+func (cfg *Config) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
+	config.WriteSample(dst, path, config.CtxMap{config.ID: idSample},
+		&cfg.General,
+		&cfg.Logging,
+		&cfg.Metrics,
+		&cfg.DS,
+	)
+}
+
+func (cfg *Config) ConfigName() string {
+	return "ds_config"
+}
+
+var _ config.Config = (*DSConfig)(nil)
+
+type DSConfig struct {
+	// ACL is the file that lists the networks of privileged requesters, one
+	// CIDR per line. Only privileged requesters get the full topology and can
+	// register service instances. If it is not set, no requester is
+	// privileged.
+	ACL string
+	// DynamicTTL is the TTL of the dynamic topology.
+	DynamicTTL util.DurWrap
+	// RegistrationTTL is the time a service registration is valid without
+	// being renewed.
+	RegistrationTTL util.DurWrap
+}
+
+func (cfg *DSConfig) InitDefaults() {
+	if cfg.DynamicTTL.Duration == 0 {
+		cfg.DynamicTTL.Duration = DefaultDynamicTTL
+	}
+	if cfg.RegistrationTTL.Duration == 0 {
+		cfg.RegistrationTTL.Duration = DefaultRegistrationTTL
+	}
+}
+
+func (cfg *DSConfig) Validate() error {
+	if cfg.DynamicTTL.Duration < time.Second {
+		return common.NewBasicError("DynamicTTL must be at least 1s", nil)
+	}
+	if cfg.RegistrationTTL.Duration < time.Second {
+		return common.NewBasicError("RegistrationTTL must be at least 1s", nil)
+	}
+	return nil
+}
+
+func (cfg *DSConfig) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
+	config.WriteString(dst, dsSample)
+}
+
+func (cfg *DSConfig) ConfigName() string {
+	return "ds"
+}
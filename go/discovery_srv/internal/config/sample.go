@@ -0,0 +1,31 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+const idSample = "ds-1"
+
+const dsSample = `
+# File that lists the networks of privileged requesters, one CIDR per line.
+# Only privileged requesters get the full topology and can register service
+# instances. If it is not set, no requester is privileged. (default "")
+ACL = "/etc/scion/ds_acl"
+
+# The TTL of the dynamic topology. (default 30s)
+DynamicTTL = "30s"
+
+# The time a service registration is valid without being renewed.
+# (default 30s)
+RegistrationTTL = "30s"
+`
@@ -0,0 +1,71 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// ACL contains the networks of the privileged requesters.
+type ACL struct {
+	nets []*net.IPNet
+}
+
+// LoadACL loads the ACL from file. The file lists one network in CIDR
+// notation per line. Empty lines and lines starting with '#' are ignored. If
+// file is empty, the ACL does not contain any network.
+func LoadACL(file string) (*ACL, error) {
+	acl := &ACL{}
+	if file == "" {
+		return acl, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to open ACL", err, "file", file)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, common.NewBasicError("Invalid ACL entry", err,
+				"file", file, "line", line)
+		}
+		acl.nets = append(acl.nets, ipNet)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, common.NewBasicError("Unable to read ACL", err, "file", file)
+	}
+	return acl, nil
+}
+
+// Privileged returns whether ip is in one of the networks of the ACL.
+func (a *ACL) Privileged(ip net.IP) bool {
+	for _, ipNet := range a.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
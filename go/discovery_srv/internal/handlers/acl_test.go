@@ -0,0 +1,52 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoadACL(t *testing.T) {
+	Convey("LoadACL", t, func() {
+		f, err := ioutil.TempFile("", "ds_acl")
+		SoMsg("err", err, ShouldBeNil)
+		defer os.Remove(f.Name())
+		Convey("loads the networks of the file", func() {
+			f.WriteString("# infrastructure\n192.0.2.0/24\n\n  2001:db8::/32\n")
+			f.Close()
+			acl, err := LoadACL(f.Name())
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("v4", acl.Privileged(net.ParseIP("192.0.2.42")), ShouldBeTrue)
+			SoMsg("v6", acl.Privileged(net.ParseIP("2001:db8::1")), ShouldBeTrue)
+			SoMsg("other", acl.Privileged(net.ParseIP("198.51.100.1")), ShouldBeFalse)
+		})
+		Convey("rejects invalid entries", func() {
+			f.WriteString("192.0.2.1\n")
+			f.Close()
+			_, err := LoadACL(f.Name())
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("does not privilege anybody without a file", func() {
+			acl, err := LoadACL("")
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("privileged", acl.Privileged(net.ParseIP("127.0.0.1")), ShouldBeFalse)
+		})
+	})
+}
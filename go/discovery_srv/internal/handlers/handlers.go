@@ -0,0 +1,169 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package handlers contains the HTTP handlers of the discovery service.
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/discovery_srv/internal/topo"
+	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// maxRegistrationSize is the maximum size of a registration request body.
+const maxRegistrationSize = 1 << 16
+
+var registerPrefix = "/" + discovery.Base + "/register/"
+
+// Handler serves the topology files and handles the registrations of service
+// instances.
+type Handler struct {
+	state  *topo.State
+	regTTL time.Duration
+	mux    *http.ServeMux
+
+	mu  sync.RWMutex
+	acl *ACL
+}
+
+// NewHandler returns the handler that serves the topology files of state.
+// Registrations are valid for regTTL.
+func NewHandler(state *topo.State, acl *ACL, regTTL time.Duration) *Handler {
+	h := &Handler{state: state, regTTL: regTTL, acl: acl, mux: http.NewServeMux()}
+	for _, mode := range []discovery.Mode{discovery.Static, discovery.Dynamic} {
+		for _, file := range []discovery.File{discovery.Full, discovery.Reduced,
+			discovery.Endhost, discovery.Default} {
+
+			h.mux.HandleFunc("/"+discovery.Path(mode, file), h.topoHandler(mode, file))
+		}
+	}
+	h.mux.HandleFunc(registerPrefix, h.handleRegistration)
+	return h
+}
+
+// SetACL replaces the ACL, e.g., after it has been reloaded from disk.
+func (h *Handler) SetACL(acl *ACL) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.acl = acl
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) topoHandler(mode discovery.Mode, file discovery.File) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		privileged := h.privileged(r)
+		f := file
+		if f == discovery.Default {
+			f = discovery.Endhost
+			if privileged {
+				f = discovery.Full
+			}
+		}
+		if f == discovery.Full && !privileged {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		files, err := h.state.Files(mode)
+		if err != nil {
+			log.Error("[discovery] Unable to get topology", "mode", mode, "err", err)
+			http.Error(w, "Unable to get topology", http.StatusInternalServerError)
+			return
+		}
+		raw, err := files.Get(f)
+		if err != nil {
+			log.Error("[discovery] Unable to get topology", "file", f, "err", err)
+			http.Error(w, "Unable to get topology", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(raw)
+	}
+}
+
+func (h *Handler) handleRegistration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.privileged(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, registerPrefix), "/")
+	if len(parts) != 2 || parts[1] == "" {
+		http.Error(w, "Invalid path", http.StatusNotFound)
+		return
+	}
+	svc, name := proto.ServiceTypeFromString(parts[0]), parts[1]
+	if r.Method == http.MethodDelete {
+		ok, err := h.state.Deregister(svc, name)
+		if err != nil {
+			log.Error("[discovery] Unable to update dynamic topology", "err", err)
+		}
+		if !ok {
+			http.Error(w, "Registration not found", http.StatusNotFound)
+			return
+		}
+		log.Info("[discovery] Removed registration", "svc", svc, "name", name)
+		return
+	}
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRegistrationSize))
+	if err != nil {
+		http.Error(w, "Unable to read body", http.StatusBadRequest)
+		return
+	}
+	info := &topology.RawSrvInfo{}
+	if err := json.Unmarshal(raw, info); err != nil {
+		http.Error(w, "Unable to parse body", http.StatusBadRequest)
+		return
+	}
+	if err := h.state.Register(svc, name, info, time.Now().Add(h.regTTL)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Debug("[discovery] Registered service instance", "svc", svc, "name", name)
+}
+
+// privileged returns whether the requester is privileged according to the
+// ACL.
+func (h *Handler) privileged(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.acl.Privileged(ip)
+}
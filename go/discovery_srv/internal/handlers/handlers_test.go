@@ -0,0 +1,198 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/discovery_srv/internal/topo"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/proto"
+)
+
+const (
+	privilegedAddr   = "192.0.2.1:40000"
+	unprivilegedAddr = "198.51.100.1:40000"
+)
+
+func TestTopoHandler(t *testing.T) {
+	h, state := newTestHandler(t)
+	static, err := state.Files(discovery.Static)
+	xtest.FailOnErr(t, err)
+	dynamic, err := state.Files(discovery.Dynamic)
+	xtest.FailOnErr(t, err)
+	tests := []struct {
+		name     string
+		mode     discovery.Mode
+		file     discovery.File
+		remote   string
+		code     int
+		expected common.RawBytes
+	}{
+		{
+			name:     "Default resolves to full for privileged requesters",
+			mode:     discovery.Static,
+			file:     discovery.Default,
+			remote:   privilegedAddr,
+			code:     http.StatusOK,
+			expected: static.Full,
+		},
+		{
+			name:     "Default resolves to endhost for unprivileged requesters",
+			mode:     discovery.Static,
+			file:     discovery.Default,
+			remote:   unprivilegedAddr,
+			code:     http.StatusOK,
+			expected: static.Endhost,
+		},
+		{
+			name:     "Full is served to privileged requesters",
+			mode:     discovery.Static,
+			file:     discovery.Full,
+			remote:   privilegedAddr,
+			code:     http.StatusOK,
+			expected: static.Full,
+		},
+		{
+			name:   "Full is forbidden for unprivileged requesters",
+			mode:   discovery.Static,
+			file:   discovery.Full,
+			remote: unprivilegedAddr,
+			code:   http.StatusForbidden,
+		},
+		{
+			name:     "Reduced is served to unprivileged requesters",
+			mode:     discovery.Static,
+			file:     discovery.Reduced,
+			remote:   unprivilegedAddr,
+			code:     http.StatusOK,
+			expected: static.Reduced,
+		},
+		{
+			name:     "Endhost is served to unprivileged requesters",
+			mode:     discovery.Static,
+			file:     discovery.Endhost,
+			remote:   unprivilegedAddr,
+			code:     http.StatusOK,
+			expected: static.Endhost,
+		},
+		{
+			name:     "Dynamic default resolves to full for privileged requesters",
+			mode:     discovery.Dynamic,
+			file:     discovery.Default,
+			remote:   privilegedAddr,
+			code:     http.StatusOK,
+			expected: dynamic.Full,
+		},
+		{
+			name:   "Dynamic full is forbidden for unprivileged requesters",
+			mode:   discovery.Dynamic,
+			file:   discovery.Full,
+			remote: unprivilegedAddr,
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "Requesters with invalid addresses are not privileged",
+			mode:   discovery.Static,
+			file:   discovery.Full,
+			remote: "invalid",
+			code:   http.StatusForbidden,
+		},
+	}
+	for _, test := range tests {
+		Convey(test.name, t, func() {
+			r := httptest.NewRequest(http.MethodGet, "/"+discovery.Path(test.mode, test.file),
+				nil)
+			r.RemoteAddr = test.remote
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			SoMsg("code", w.Code, ShouldEqual, test.code)
+			if test.code == http.StatusOK {
+				SoMsg("body", common.RawBytes(w.Body.Bytes()), ShouldResemble, test.expected)
+			}
+		})
+	}
+	Convey("Only GET is allowed", t, func() {
+		r := httptest.NewRequest(http.MethodPost,
+			"/"+discovery.Path(discovery.Static, discovery.Endhost), nil)
+		r.RemoteAddr = privilegedAddr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		SoMsg("code", w.Code, ShouldEqual, http.StatusMethodNotAllowed)
+	})
+}
+
+func TestHandleRegistration(t *testing.T) {
+	body := `{"Addrs": {"IPv4": {"Public": {"Addr": "127.0.0.100", "L4Port": 30000}}}}`
+	path := "/" + discovery.RegisterPath(proto.ServiceType_ps, "ps-new")
+	Convey("Registrations", t, func() {
+		h, state := newTestHandler(t)
+		Convey("are forbidden for unprivileged requesters", func() {
+			w := serve(h, http.MethodPut, path, body, unprivilegedAddr)
+			SoMsg("code", w.Code, ShouldEqual, http.StatusForbidden)
+			SoMsg("dynamic", pathServices(t, state), ShouldNotContainKey, "ps-new")
+		})
+		Convey("are added and removed for privileged requesters", func() {
+			w := serve(h, http.MethodPut, path, body, privilegedAddr)
+			SoMsg("put", w.Code, ShouldEqual, http.StatusOK)
+			SoMsg("registered", pathServices(t, state), ShouldContainKey, "ps-new")
+			w = serve(h, http.MethodDelete, path, "", privilegedAddr)
+			SoMsg("delete", w.Code, ShouldEqual, http.StatusOK)
+			SoMsg("removed", pathServices(t, state), ShouldNotContainKey, "ps-new")
+			w = serve(h, http.MethodDelete, path, "", privilegedAddr)
+			SoMsg("delete again", w.Code, ShouldEqual, http.StatusNotFound)
+		})
+		Convey("with invalid bodies are rejected", func() {
+			w := serve(h, http.MethodPut, path, "{", privilegedAddr)
+			SoMsg("code", w.Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func newTestHandler(t *testing.T) (*Handler, *topo.State) {
+	rt, err := topology.LoadRawFromFile("testdata/topology.json")
+	xtest.FailOnErr(t, err)
+	state, err := topo.NewState(rt, time.Minute)
+	xtest.FailOnErr(t, err)
+	_, ipNet, err := net.ParseCIDR("192.0.2.0/24")
+	xtest.FailOnErr(t, err)
+	return NewHandler(state, &ACL{nets: []*net.IPNet{ipNet}}, time.Hour), state
+}
+
+func serve(h *Handler, method, path, body, remote string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.RemoteAddr = remote
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func pathServices(t *testing.T, state *topo.State) map[string]*topology.RawSrvInfo {
+	files, err := state.Files(discovery.Dynamic)
+	xtest.FailOnErr(t, err)
+	rt, err := topology.LoadRaw(files.Full)
+	xtest.FailOnErr(t, err)
+	return rt.PathService
+}
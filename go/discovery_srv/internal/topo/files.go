@@ -0,0 +1,84 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package topo creates the topology files served by the discovery service.
+// The static topology is loaded from disk. The dynamic topology is the static
+// topology with the registered service instances added to it.
+package topo
+
+import (
+	"encoding/json"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/topology"
+)
+
+// Files contains the versions of a topology that are served.
+type Files struct {
+	// Full is the unmodified topology.
+	Full common.RawBytes
+	// Reduced is the topology without bind and border router interface
+	// addresses, see topology.StripBind.
+	Reduced common.RawBytes
+	// Endhost is the reduced topology without the services that are not
+	// relevant to end hosts, see topology.StripServices.
+	Endhost common.RawBytes
+}
+
+// NewFiles creates the versions of rt. rt is not modified.
+func NewFiles(rt *topology.RawTopo) (*Files, error) {
+	f := &Files{}
+	var err error
+	if f.Full, err = marshal(rt); err != nil {
+		return nil, err
+	}
+	// The topology is stripped in place, so a copy is stripped instead.
+	stripped, err := topology.LoadRaw(f.Full)
+	if err != nil {
+		return nil, err
+	}
+	topology.StripBind(stripped)
+	if f.Reduced, err = marshal(stripped); err != nil {
+		return nil, err
+	}
+	topology.StripServices(stripped)
+	if f.Endhost, err = marshal(stripped); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Get returns the requested version. The default version depends on the
+// privilege of the requester, it must be resolved by the caller.
+func (f *Files) Get(file discovery.File) (common.RawBytes, error) {
+	switch file {
+	case discovery.Full:
+		return f.Full, nil
+	case discovery.Reduced:
+		return f.Reduced, nil
+	case discovery.Endhost:
+		return f.Endhost, nil
+	default:
+		return nil, common.NewBasicError("Unsupported file", nil, "file", file)
+	}
+}
+
+func marshal(rt *topology.RawTopo) (common.RawBytes, error) {
+	b, err := json.MarshalIndent(rt, "", "    ")
+	if err != nil {
+		return nil, common.NewBasicError("Unable to marshal topology", err)
+	}
+	return b, nil
+}
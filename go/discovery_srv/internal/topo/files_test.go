@@ -0,0 +1,97 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+const testTopo = "testdata/topology.json"
+
+func TestNewFiles(t *testing.T) {
+	Convey("NewFiles", t, func() {
+		rt := loadTopo(t)
+		files, err := NewFiles(rt)
+		SoMsg("err", err, ShouldBeNil)
+		Convey("does not modify the topology", func() {
+			SoMsg("rt", rt, ShouldResemble, loadTopo(t))
+		})
+		Convey("keeps all information in the full topology", func() {
+			SoMsg("full", parse(t, files.Full), ShouldResemble, rt)
+		})
+		Convey("strips the bind addresses in the reduced topology", func() {
+			reduced := parse(t, files.Reduced)
+			SoMsg("cs bind", reduced.CertificateService["cs1-ff00:0:311-1"].Addrs["IPv4"].Bind,
+				ShouldBeNil)
+			for name, br := range reduced.BorderRouters {
+				for ifid, intf := range br.Interfaces {
+					SoMsg(name+" bind overlay", intf.BindOverlay, ShouldBeNil)
+					SoMsg(name+" public overlay", intf.PublicOverlay, ShouldBeNil)
+					SoMsg(name+" remote overlay", intf.RemoteOverlay, ShouldBeNil)
+					SoMsg(name+" ISD_AS", intf.ISD_AS, ShouldEqual,
+						rt.BorderRouters[name].Interfaces[ifid].ISD_AS)
+				}
+			}
+			SoMsg("bs", reduced.BeaconService, ShouldResemble, rt.BeaconService)
+			SoMsg("ps", reduced.PathService, ShouldResemble, rt.PathService)
+		})
+		Convey("strips the infrastructure services in the endhost topology", func() {
+			endhost := parse(t, files.Endhost)
+			SoMsg("bs", endhost.BeaconService, ShouldBeEmpty)
+			SoMsg("sb", endhost.SibraService, ShouldBeEmpty)
+			SoMsg("zk", endhost.ZookeeperService, ShouldBeEmpty)
+			SoMsg("ps", endhost.PathService, ShouldResemble, rt.PathService)
+			SoMsg("cs bind", endhost.CertificateService["cs1-ff00:0:311-1"].Addrs["IPv4"].Bind,
+				ShouldBeNil)
+		})
+	})
+}
+
+func TestFilesGet(t *testing.T) {
+	Convey("Get returns the requested version", t, func() {
+		files, err := NewFiles(loadTopo(t))
+		xtest.FailOnErr(t, err)
+		tests := map[discovery.File][]byte{
+			discovery.Full:    files.Full,
+			discovery.Reduced: files.Reduced,
+			discovery.Endhost: files.Endhost,
+		}
+		for file, expected := range tests {
+			raw, err := files.Get(file)
+			SoMsg(string(file)+" err", err, ShouldBeNil)
+			SoMsg(string(file), []byte(raw), ShouldResemble, expected)
+		}
+		_, err = files.Get(discovery.Default)
+		SoMsg("default", err, ShouldNotBeNil)
+	})
+}
+
+func loadTopo(t *testing.T) *topology.RawTopo {
+	rt, err := topology.LoadRawFromFile(testTopo)
+	xtest.FailOnErr(t, err)
+	return rt
+}
+
+func parse(t *testing.T, raw []byte) *topology.RawTopo {
+	rt, err := topology.LoadRaw(raw)
+	xtest.FailOnErr(t, err)
+	return rt
+}
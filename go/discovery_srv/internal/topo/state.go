@@ -0,0 +1,217 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// State holds the static topology, the registered service instances, and the
+// files of the static and the dynamic topology. It is safe for concurrent use.
+type State struct {
+	dynamicTTL time.Duration
+
+	mu           sync.RWMutex
+	staticFiles  *Files
+	dynamicFiles *Files
+	regs         map[regKey]registration
+}
+
+type regKey struct {
+	svc  proto.ServiceType
+	name string
+}
+
+type registration struct {
+	info   *topology.RawSrvInfo
+	expiry time.Time
+}
+
+// NewState creates the state for the static topology. The dynamic topology is
+// served with the given TTL.
+func NewState(static *topology.RawTopo, dynamicTTL time.Duration) (*State, error) {
+	s := &State{
+		dynamicTTL: dynamicTTL,
+		regs:       make(map[regKey]registration),
+	}
+	if err := s.SetStatic(static); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetStatic replaces the static topology, e.g., after it has been reloaded
+// from disk. The dynamic topology is updated accordingly.
+func (s *State) SetStatic(static *topology.RawTopo) error {
+	if _, err := topology.TopoFromRaw(static); err != nil {
+		return common.NewBasicError("Invalid static topology", err)
+	}
+	files, err := NewFiles(static)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oldFiles := s.staticFiles
+	s.staticFiles = files
+	if err := s.updateDynamic(time.Now()); err != nil {
+		s.staticFiles = oldFiles
+		return err
+	}
+	return nil
+}
+
+// Files returns the files of the topology in the given mode.
+func (s *State) Files(mode discovery.Mode) (*Files, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	switch mode {
+	case discovery.Static:
+		return s.staticFiles, nil
+	case discovery.Dynamic:
+		return s.dynamicFiles, nil
+	default:
+		return nil, common.NewBasicError("Unsupported mode", nil, "mode", mode)
+	}
+}
+
+// Register adds or renews the registration of the service instance name of
+// type svc. The registration expires at expiry. An error is returned if the
+// instance cannot be added to the dynamic topology.
+func (s *State) Register(svc proto.ServiceType, name string, info *topology.RawSrvInfo,
+	expiry time.Time) error {
+
+	if name == "" {
+		return common.NewBasicError("Instance name must not be empty", nil)
+	}
+	if _, err := svcMap(&topology.RawTopo{}, svc); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := regKey{svc: svc, name: name}
+	old, ok := s.regs[k]
+	s.regs[k] = registration{info: info, expiry: expiry}
+	if err := s.updateDynamic(time.Now()); err != nil {
+		if ok {
+			s.regs[k] = old
+		} else {
+			delete(s.regs, k)
+		}
+		return common.NewBasicError("Invalid registration", err, "svc", svc, "name", name)
+	}
+	return nil
+}
+
+// Deregister removes the registration of the service instance name of type
+// svc. It returns false if there is no such registration.
+func (s *State) Deregister(svc proto.ServiceType, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := regKey{svc: svc, name: name}
+	if _, ok := s.regs[k]; !ok {
+		return false, nil
+	}
+	delete(s.regs, k)
+	return true, s.updateDynamic(time.Now())
+}
+
+// UpdateDynamic removes the expired registrations and recreates the dynamic
+// topology with a fresh timestamp.
+func (s *State) UpdateDynamic() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateDynamic(time.Now())
+}
+
+// updateDynamic recreates the dynamic topology from the static topology and
+// the registrations that are valid at now. The caller must hold the lock.
+func (s *State) updateDynamic(now time.Time) error {
+	rt, err := topology.LoadRaw(s.staticFiles.Full)
+	if err != nil {
+		return err
+	}
+	rt.Timestamp = now.Unix()
+	rt.TimestampHuman = util.TimeToString(now)
+	rt.TTL = uint32(s.dynamicTTL / time.Second)
+	for k, reg := range s.regs {
+		if !now.Before(reg.expiry) {
+			delete(s.regs, k)
+			continue
+		}
+		// Registered instances replace static instances with the same name.
+		m, _ := svcMap(rt, k.svc)
+		if *m == nil {
+			*m = make(map[string]*topology.RawSrvInfo)
+		}
+		(*m)[k.name] = reg.info
+	}
+	if _, err := topology.TopoFromRaw(rt); err != nil {
+		return common.NewBasicError("Invalid dynamic topology", err)
+	}
+	files, err := NewFiles(rt)
+	if err != nil {
+		return err
+	}
+	s.dynamicFiles = files
+	return nil
+}
+
+// svcMap returns a pointer to the map of instances of type svc in rt.
+func svcMap(rt *topology.RawTopo, svc proto.ServiceType) (*map[string]*topology.RawSrvInfo,
+	error) {
+
+	switch svc {
+	case proto.ServiceType_bs:
+		return &rt.BeaconService, nil
+	case proto.ServiceType_ps:
+		return &rt.PathService, nil
+	case proto.ServiceType_cs:
+		return &rt.CertificateService, nil
+	case proto.ServiceType_sb:
+		return &rt.SibraService, nil
+	case proto.ServiceType_ds:
+		return &rt.DiscoveryService, nil
+	case proto.ServiceType_sig:
+		return &rt.SIG, nil
+	default:
+		return nil, common.NewBasicError("Unsupported service type", nil, "svc", svc)
+	}
+}
+
+var _ periodic.Task = (*Updater)(nil)
+
+// Updater periodically updates the dynamic topology, such that its timestamp
+// is refreshed before the TTL expires and that expired registrations are
+// removed.
+type Updater struct {
+	State *State
+}
+
+func (u *Updater) Run(_ context.Context) {
+	if err := u.State.UpdateDynamic(); err != nil {
+		log.Error("[topo.Updater] Unable to update dynamic topology", "err", err)
+	}
+}
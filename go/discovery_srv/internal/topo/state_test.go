@@ -0,0 +1,146 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/discovery"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestStateRegister(t *testing.T) {
+	Convey("Register", t, func() {
+		s, err := NewState(loadTopo(t), time.Minute)
+		xtest.FailOnErr(t, err)
+		expiry := time.Now().Add(time.Hour)
+		Convey("adds the instance to the dynamic topology only", func() {
+			err := s.Register(proto.ServiceType_ps, "ps-new", srvInfo("127.0.0.100"), expiry)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("dynamic", dynamic(t, s).PathService["ps-new"], ShouldResemble,
+				srvInfo("127.0.0.100"))
+			SoMsg("static", static(t, s).PathService, ShouldNotContainKey, "ps-new")
+			SoMsg("ttl", dynamic(t, s).TTL, ShouldEqual, 60)
+		})
+		Convey("replaces static instances with the same name", func() {
+			err := s.Register(proto.ServiceType_ps, "ps1-ff00:0:311-1",
+				srvInfo("127.0.0.100"), expiry)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("dynamic", dynamic(t, s).PathService["ps1-ff00:0:311-1"], ShouldResemble,
+				srvInfo("127.0.0.100"))
+		})
+		Convey("renews existing registrations", func() {
+			xtest.FailOnErr(t, s.Register(proto.ServiceType_sig, "sig-new",
+				srvInfo("127.0.0.100"), expiry))
+			err := s.Register(proto.ServiceType_sig, "sig-new", srvInfo("127.0.0.101"),
+				expiry.Add(time.Hour))
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("dynamic", dynamic(t, s).SIG["sig-new"], ShouldResemble,
+				srvInfo("127.0.0.101"))
+		})
+		Convey("rejects invalid registrations", func() {
+			xtest.FailOnErr(t, s.Register(proto.ServiceType_ps, "ps-new",
+				srvInfo("127.0.0.100"), expiry))
+			before := dynamic(t, s)
+			err := s.Register(proto.ServiceType_ps, "ps-new", srvInfo("invalid"), expiry)
+			SoMsg("invalid address", err, ShouldNotBeNil)
+			err = s.Register(proto.ServiceType_ps, "ps-other", srvInfo("invalid"), expiry)
+			SoMsg("invalid new address", err, ShouldNotBeNil)
+			err = s.Register(proto.ServiceType_ps, "", srvInfo("127.0.0.100"), expiry)
+			SoMsg("empty name", err, ShouldNotBeNil)
+			err = s.Register(proto.ServiceType_unset, "x", srvInfo("127.0.0.100"), expiry)
+			SoMsg("unsupported type", err, ShouldNotBeNil)
+			after := dynamic(t, s)
+			SoMsg("ps", after.PathService, ShouldResemble, before.PathService)
+		})
+	})
+}
+
+func TestStateDeregister(t *testing.T) {
+	Convey("Deregister", t, func() {
+		s, err := NewState(loadTopo(t), time.Minute)
+		xtest.FailOnErr(t, err)
+		xtest.FailOnErr(t, s.Register(proto.ServiceType_ps, "ps-new", srvInfo("127.0.0.100"),
+			time.Now().Add(time.Hour)))
+		Convey("removes the instance from the dynamic topology", func() {
+			ok, err := s.Deregister(proto.ServiceType_ps, "ps-new")
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("ok", ok, ShouldBeTrue)
+			SoMsg("dynamic", dynamic(t, s).PathService, ShouldNotContainKey, "ps-new")
+		})
+		Convey("reports unknown registrations", func() {
+			ok, err := s.Deregister(proto.ServiceType_bs, "ps-new")
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("ok", ok, ShouldBeFalse)
+			SoMsg("dynamic", dynamic(t, s).PathService, ShouldContainKey, "ps-new")
+		})
+		Convey("does not remove static instances", func() {
+			ok, err := s.Deregister(proto.ServiceType_ps, "ps1-ff00:0:311-1")
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("ok", ok, ShouldBeFalse)
+			SoMsg("dynamic", dynamic(t, s).PathService, ShouldContainKey, "ps1-ff00:0:311-1")
+		})
+	})
+}
+
+func TestStateExpiry(t *testing.T) {
+	Convey("Expired registrations are removed from the dynamic topology", t, func() {
+		s, err := NewState(loadTopo(t), time.Minute)
+		xtest.FailOnErr(t, err)
+		now := time.Now()
+		xtest.FailOnErr(t, s.Register(proto.ServiceType_ps, "ps-short", srvInfo("127.0.0.100"),
+			now.Add(time.Minute)))
+		xtest.FailOnErr(t, s.Register(proto.ServiceType_ps, "ps-long", srvInfo("127.0.0.101"),
+			now.Add(time.Hour)))
+		s.mu.Lock()
+		err = s.updateDynamic(now.Add(time.Minute))
+		s.mu.Unlock()
+		SoMsg("err", err, ShouldBeNil)
+		rt := dynamic(t, s)
+		SoMsg("expired", rt.PathService, ShouldNotContainKey, "ps-short")
+		SoMsg("valid", rt.PathService, ShouldContainKey, "ps-long")
+		SoMsg("regs", len(s.regs), ShouldEqual, 1)
+		SoMsg("timestamp", rt.Timestamp, ShouldEqual, now.Add(time.Minute).Unix())
+	})
+}
+
+func srvInfo(ip string) *topology.RawSrvInfo {
+	return &topology.RawSrvInfo{
+		Addrs: topology.RawAddrMap{
+			"IPv4": &topology.RawPubBindOverlay{
+				Public: topology.RawAddrPortOverlay{
+					RawAddrPort: topology.RawAddrPort{Addr: ip, L4Port: 30000},
+				},
+			},
+		},
+	}
+}
+
+func static(t *testing.T, s *State) *topology.RawTopo {
+	files, err := s.Files(discovery.Static)
+	xtest.FailOnErr(t, err)
+	return parse(t, files.Full)
+}
+
+func dynamic(t *testing.T, s *State) *topology.RawTopo {
+	files, err := s.Files(discovery.Dynamic)
+	xtest.FailOnErr(t, err)
+	return parse(t, files.Full)
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/scionproto/scion/go/discovery_srv/internal/config"
+	"github.com/scionproto/scion/go/discovery_srv/internal/handlers"
+	"github.com/scionproto/scion/go/discovery_srv/internal/topo"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/fatal"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/topology"
+)
+
+var (
+	cfg         config.Config
+	environment *env.Env
+
+	state   *topo.State
+	handler *handlers.Handler
+)
+
+func init() {
+	flag.Usage = env.Usage
+}
+
+// main initializes the discovery service and starts serving the topologies.
+func main() {
+	os.Exit(realMain())
+}
+
+func realMain() int {
+	fatal.Init()
+	env.AddFlags()
+	flag.Parse()
+	if v, ok := env.CheckFlags(&cfg); !ok {
+		return v
+	}
+	if err := setupBasic(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer log.Flush()
+	defer env.LogAppStopped(common.DS, cfg.General.ID)
+	defer log.LogPanicAndExit()
+	if err := setup(); err != nil {
+		log.Crit("Setup failed", "err", err)
+		return 1
+	}
+	rt, err := topology.LoadRawFromFile(cfg.General.Topology)
+	if err != nil {
+		log.Crit("Unable to load topology", "err", err)
+		return 1
+	}
+	listen, err := listenAddr(rt)
+	if err != nil {
+		log.Crit("Unable to determine listen address", "err", err)
+		return 1
+	}
+	if state, err = topo.NewState(rt, cfg.DS.DynamicTTL.Duration); err != nil {
+		log.Crit("Unable to initialize topology state", "err", err)
+		return 1
+	}
+	acl, err := handlers.LoadACL(cfg.DS.ACL)
+	if err != nil {
+		log.Crit("Unable to load ACL", "err", err)
+		return 1
+	}
+	handler = handlers.NewHandler(state, acl, cfg.DS.RegistrationTTL.Duration)
+	cfg.Metrics.StartPrometheus()
+	go func() {
+		defer log.LogPanicAndExit()
+		log.Info("Serving topologies", "addr", listen)
+		if err := http.ListenAndServe(listen, handler); err != nil {
+			fatal.Fatal(common.NewBasicError("HTTP ListenAndServe error", err))
+		}
+	}()
+	// Refresh the dynamic topology well before its TTL expires.
+	updater := periodic.StartPeriodicTask(&topo.Updater{State: state},
+		periodic.NewTicker(cfg.DS.DynamicTTL.Duration/2), cfg.DS.DynamicTTL.Duration/2)
+	defer updater.Kill()
+	select {
+	case <-environment.AppShutdownSignal:
+		// Whenever we receive a SIGINT or SIGTERM we exit without an error.
+		return 0
+	case <-fatal.Chan():
+		return 1
+	}
+}
+
+// listenAddr returns the address of the discovery service instance in the
+// topology.
+func listenAddr(rt *topology.RawTopo) (string, error) {
+	t, err := topology.TopoFromRaw(rt)
+	if err != nil {
+		return "", err
+	}
+	topoAddr := t.DS.GetById(cfg.General.ID)
+	if topoAddr == nil {
+		return "", common.NewBasicError("Unable to find topo address", nil,
+			"id", cfg.General.ID)
+	}
+	a := topoAddr.BindOrPublic(t.Overlay)
+	return net.JoinHostPort(a.L3.IP().String(), strconv.Itoa(int(a.L4.Port()))), nil
+}
+
+// reload reloads the static topology and the ACL. The listen address is not
+// changed.
+func reload() {
+	if state == nil {
+		return
+	}
+	rt, err := topology.LoadRawFromFile(cfg.General.Topology)
+	if err != nil {
+		log.Error("Unable to reload topology", "err", err)
+		return
+	}
+	if err := state.SetStatic(rt); err != nil {
+		log.Error("Unable to set reloaded topology", "err", err)
+		return
+	}
+	acl, err := handlers.LoadACL(cfg.DS.ACL)
+	if err != nil {
+		log.Error("Unable to reload ACL", "err", err)
+		return
+	}
+	handler.SetACL(acl)
+	log.Info("Reloaded topology and ACL")
+}
+
+func setupBasic() error {
+	if _, err := toml.DecodeFile(env.ConfigFile(), &cfg); err != nil {
+		return err
+	}
+	cfg.InitDefaults()
+	if err := env.InitLogging(&cfg.Logging); err != nil {
+		return err
+	}
+	return env.LogAppStarted(common.DS, cfg.General.ID)
+}
+
+func setup() error {
+	if err := cfg.Validate(); err != nil {
+		return common.NewBasicError("Unable to validate config", err)
+	}
+	environment = env.SetupEnv(reload)
+	return nil
+}
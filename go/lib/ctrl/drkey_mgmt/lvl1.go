@@ -0,0 +1,109 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the Go representation of first-level DRKey requests and
+// replies, which are exchanged between certificate servers.
+
+package drkey_mgmt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ proto.Cerealizable = (*Lvl1Req)(nil)
+
+// Lvl1Req requests the first-level key from the AS of the receiving
+// certificate server to DstIA, which is valid at ValTime.
+type Lvl1Req struct {
+	RawDstIA     addr.IAInt `capnp:"dstIA"`
+	RawValTime   uint32     `capnp:"valTime"`
+	RawTimestamp uint32     `capnp:"timestamp"`
+}
+
+func NewLvl1Req(dstIA addr.IA, valTime time.Time) *Lvl1Req {
+	return &Lvl1Req{
+		RawDstIA:     dstIA.IAInt(),
+		RawValTime:   util.TimeToSecs(valTime),
+		RawTimestamp: util.TimeToSecs(time.Now()),
+	}
+}
+
+func (c *Lvl1Req) DstIA() addr.IA {
+	return c.RawDstIA.IA()
+}
+
+func (c *Lvl1Req) ValTime() time.Time {
+	return util.SecsToTime(c.RawValTime)
+}
+
+func (c *Lvl1Req) Timestamp() time.Time {
+	return util.SecsToTime(c.RawTimestamp)
+}
+
+func (c *Lvl1Req) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyLvl1Req_TypeID
+}
+
+func (c *Lvl1Req) String() string {
+	return fmt.Sprintf("DstIA: %s ValTime: %s Timestamp: %s", c.DstIA(),
+		util.TimeToString(c.ValTime()), util.TimeToString(c.Timestamp()))
+}
+
+var _ proto.Cerealizable = (*Lvl1Rep)(nil)
+
+// Lvl1Rep contains a first-level key. The key is encrypted with the public
+// encryption key of the destination AS, in the certificate with version
+// CertVerDst.
+type Lvl1Rep struct {
+	RawSrcIA      addr.IAInt `capnp:"srcIA"`
+	RawDstIA      addr.IAInt `capnp:"dstIA"`
+	RawEpochBegin uint32     `capnp:"epochBegin"`
+	RawEpochEnd   uint32     `capnp:"epochEnd"`
+	Cipher        common.RawBytes
+	Nonce         common.RawBytes
+	CertVerDst    uint64
+	RawTimestamp  uint32 `capnp:"timestamp"`
+}
+
+func (c *Lvl1Rep) SrcIA() addr.IA {
+	return c.RawSrcIA.IA()
+}
+
+func (c *Lvl1Rep) DstIA() addr.IA {
+	return c.RawDstIA.IA()
+}
+
+func (c *Lvl1Rep) Epoch() drkey.Epoch {
+	return drkey.NewEpoch(c.RawEpochBegin, c.RawEpochEnd)
+}
+
+func (c *Lvl1Rep) Timestamp() time.Time {
+	return util.SecsToTime(c.RawTimestamp)
+}
+
+func (c *Lvl1Rep) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyLvl1Rep_TypeID
+}
+
+func (c *Lvl1Rep) String() string {
+	return fmt.Sprintf("SrcIA: %s DstIA: %s Epoch: %s CertVerDst: %d Timestamp: %s",
+		c.SrcIA(), c.DstIA(), c.Epoch(), c.CertVerDst, util.TimeToString(c.Timestamp()))
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the Go representation of second-level DRKey requests and
+// replies, which end hosts send to the certificate server via sciond.
+
+package drkey_mgmt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// Host is the representation of an optional host address.
+type Host struct {
+	Type uint8
+	Host common.RawBytes
+}
+
+// NewHost returns the representation of h. A nil address is represented as
+// host address of type none.
+func NewHost(h addr.HostAddr) *Host {
+	if h == nil {
+		return &Host{Type: uint8(addr.HostTypeNone)}
+	}
+	return &Host{Type: uint8(h.Type()), Host: h.Pack()}
+}
+
+// ToHostAddr returns the host address, or nil if the host is not set.
+func (h *Host) ToHostAddr() (addr.HostAddr, error) {
+	if h == nil || addr.HostAddrType(h.Type) == addr.HostTypeNone {
+		return nil, nil
+	}
+	return addr.HostFromRaw(h.Host, addr.HostAddrType(h.Type))
+}
+
+var _ proto.Cerealizable = (*Lvl2Req)(nil)
+
+// Lvl2Req requests a second-level key that is valid at ValTime.
+type Lvl2Req struct {
+	Protocol   string
+	ReqType    uint8
+	RawValTime uint32     `capnp:"valTime"`
+	RawSrcIA   addr.IAInt `capnp:"srcIA"`
+	RawDstIA   addr.IAInt `capnp:"dstIA"`
+	SrcHost    *Host
+	DstHost    *Host
+}
+
+// NewLvl2Req creates a request for the second-level key described by meta.
+// The epoch of meta is ignored.
+func NewLvl2Req(meta drkey.Lvl2Meta, valTime time.Time) *Lvl2Req {
+	return &Lvl2Req{
+		Protocol:   meta.Protocol,
+		ReqType:    uint8(meta.KeyType),
+		RawValTime: util.TimeToSecs(valTime),
+		RawSrcIA:   meta.SrcIA.IAInt(),
+		RawDstIA:   meta.DstIA.IAInt(),
+		SrcHost:    NewHost(meta.SrcHost),
+		DstHost:    NewHost(meta.DstHost),
+	}
+}
+
+func (c *Lvl2Req) SrcIA() addr.IA {
+	return c.RawSrcIA.IA()
+}
+
+func (c *Lvl2Req) DstIA() addr.IA {
+	return c.RawDstIA.IA()
+}
+
+func (c *Lvl2Req) ValTime() time.Time {
+	return util.SecsToTime(c.RawValTime)
+}
+
+// Meta returns the description of the requested key for the given epoch.
+func (c *Lvl2Req) Meta(epoch drkey.Epoch) (drkey.Lvl2Meta, error) {
+	srcHost, err := c.SrcHost.ToHostAddr()
+	if err != nil {
+		return drkey.Lvl2Meta{}, common.NewBasicError("Invalid src host", err)
+	}
+	dstHost, err := c.DstHost.ToHostAddr()
+	if err != nil {
+		return drkey.Lvl2Meta{}, common.NewBasicError("Invalid dst host", err)
+	}
+	return drkey.Lvl2Meta{
+		KeyType:  drkey.Lvl2KeyType(c.ReqType),
+		Protocol: c.Protocol,
+		Epoch:    epoch,
+		SrcIA:    c.SrcIA(),
+		DstIA:    c.DstIA(),
+		SrcHost:  srcHost,
+		DstHost:  dstHost,
+	}, nil
+}
+
+func (c *Lvl2Req) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyLvl2Req_TypeID
+}
+
+func (c *Lvl2Req) String() string {
+	return fmt.Sprintf("Protocol: %q Type: %s SrcIA: %s DstIA: %s ValTime: %s",
+		c.Protocol, drkey.Lvl2KeyType(c.ReqType), c.SrcIA(), c.DstIA(),
+		util.TimeToString(c.ValTime()))
+}
+
+var _ proto.Cerealizable = (*Lvl2Rep)(nil)
+
+// Lvl2Rep contains a second-level key.
+type Lvl2Rep struct {
+	RawTimestamp  uint32          `capnp:"timestamp"`
+	DRKey         common.RawBytes `capnp:"drkey"`
+	RawEpochBegin uint32          `capnp:"epochBegin"`
+	RawEpochEnd   uint32          `capnp:"epochEnd"`
+}
+
+func NewLvl2Rep(key drkey.Lvl2Key, now time.Time) *Lvl2Rep {
+	return &Lvl2Rep{
+		RawTimestamp:  util.TimeToSecs(now),
+		DRKey:         common.RawBytes(key.Key),
+		RawEpochBegin: util.TimeToSecs(key.Epoch.Begin),
+		RawEpochEnd:   util.TimeToSecs(key.Epoch.End),
+	}
+}
+
+func (c *Lvl2Rep) Epoch() drkey.Epoch {
+	return drkey.NewEpoch(c.RawEpochBegin, c.RawEpochEnd)
+}
+
+func (c *Lvl2Rep) Timestamp() time.Time {
+	return util.SecsToTime(c.RawTimestamp)
+}
+
+func (c *Lvl2Rep) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyLvl2Rep_TypeID
+}
+
+func (c *Lvl2Rep) String() string {
+	return fmt.Sprintf("Epoch: %s Timestamp: %s", c.Epoch(), util.TimeToString(c.Timestamp()))
+}
@@ -33,15 +33,20 @@
 //
 // Files
 //
-// There are two privilege versions of the topology file. The endhost version
-// is intended for end hosts and non-privileged entities. The full version is
-// only intended for privileged entities that need all topology information.
+// There are three privilege versions of the topology file. The endhost
+// version is intended for end hosts and non-privileged entities. The full
+// version is only intended for privileged entities that need all topology
+// information. The reduced version lies in between.
 //
 // Endhost: The endhost version of the topology file contains all the
 // information necessary for end hosts. Unnecessary information is stripped
 // from the file (e.g. border router interface addresses or beacon service
 // addresses).
 //
+// Reduced: The reduced version of the topology file contains all services,
+// but the bind addresses and the border router interface addresses are
+// stripped from the file.
+//
 // Full: The full version of the topology file contains all the information.
 // This file is only accessible by privileged entities (e.g infrastructure
 // elements).
@@ -56,14 +61,27 @@
 // is dependent on the mode and file version:
 //  static  && default:  /discovery/v1/static/default.json
 //  static  && endhost:  /discovery/v1/static/endhost.json
+//  static  && reduced:  /discovery/v1/static/reduced.json
 //  static  && full:     /discovery/v1/static/full.json
 //  dynamic && default:  /discovery/v1/dynamic/default.json
 //  dynamic && endhost:  /discovery/v1/dynamic/endhost.json
+//  dynamic && reduced:  /discovery/v1/dynamic/reduced.json
 //  dynamic && full:     /discovery/v1/dynamic/full.json
+//
+// Registrations
+//
+// Service instances register with the discovery service to be added to the
+// dynamic topology. A registration is a http put request of the JSON encoded
+// topology.RawSrvInfo of the instance, to the path
+//  /discovery/v1/register/<service type>/<instance name>
+// Registrations expire unless they are renewed. A http delete request to the
+// same path removes the registration.
 package discovery
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -73,6 +91,7 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/proto"
 )
 
 // FetchParams contains the parameters for fetching the topology from
@@ -80,7 +99,8 @@ import (
 type FetchParams struct {
 	// Mode indicates whether the static or the dynamic topology is requested.
 	Mode Mode
-	// File indicates whether the full, endhost or default topology is requested.
+	// File indicates whether the full, reduced, endhost or default topology
+	// is requested.
 	File File
 	// Https indicates whether https should be used.
 	Https bool
@@ -100,6 +120,9 @@ type File string
 const (
 	// Full is the full topology file, including all service information.
 	Full File = "full.json"
+	// Reduced is the full topology file without bind and border router
+	// interface addresses.
+	Reduced File = "reduced.json"
 	// Endhost is a stripped down topology file for non-privileged entities.
 	Endhost File = "endhost.json"
 	// Default is a topology file whose content is based on the privilege of
@@ -152,20 +175,60 @@ func FetchTopoRaw(ctx context.Context, params FetchParams, ds *addr.AppAddr,
 	return topo, raw, nil
 }
 
+// Register registers the service instance name of type svc with the
+// discovery service, such that it is added to the dynamic topology. The
+// registration must be renewed before it expires. If client is nil, the
+// default http client is used.
+func Register(ctx context.Context, svc proto.ServiceType, name string,
+	info *topology.RawSrvInfo, https bool, ds *addr.AppAddr, client *http.Client) error {
+
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return common.NewBasicError("Unable to marshal service info", err)
+	}
+	url, err := createURLWithPath(RegisterPath(svc, name), https, ds)
+	if err != nil {
+		return common.NewBasicError("Unable to create URL", err)
+	}
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(raw))
+	if err != nil {
+		return common.NewBasicError("Unable to create request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rep, err := ctxhttp.Do(ctx, client, req)
+	if err != nil {
+		return common.NewBasicError("HTTP request failed", err)
+	}
+	defer rep.Body.Close()
+	if rep.StatusCode != http.StatusOK {
+		return common.NewBasicError("Status not OK", nil, "status", rep.Status)
+	}
+	return nil
+}
+
 // createURL builds the url to the topology file.
 func createURL(params FetchParams, ds *addr.AppAddr) (string, error) {
+	return createURLWithPath(Path(params.Mode, params.File), params.Https, ds)
+}
+
+func createURLWithPath(path string, https bool, ds *addr.AppAddr) (string, error) {
 	if ds == nil {
 		return "", common.NewBasicError("Addr not set", nil)
 	}
 	protocol := "http"
-	if params.Https {
+	if https {
 		protocol = "https"
 	}
-	return fmt.Sprintf("%s://%s:%d/%s", protocol, ds.L3.IP(), ds.L4.Port(),
-		Path(params.Mode, params.File)), nil
+	return fmt.Sprintf("%s://%s:%d/%s", protocol, ds.L3.IP(), ds.L4.Port(), path), nil
 }
 
 // Path creates the route to the topology file based on the mode and file.
 func Path(mode Mode, file File) string {
 	return fmt.Sprintf("%s/%s/%s", Base, mode, file)
 }
+
+// RegisterPath creates the route to the registration of the service instance
+// name of type svc.
+func RegisterPath(svc proto.ServiceType, name string) string {
+	return fmt.Sprintf("%s/register/%s/%s", Base, svc, name)
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+const svSalt = "Derive DRKey Key"
+
+// DeriveSV derives the secret value of the epoch from the AS master key.
+func DeriveSV(epoch Epoch, masterKey common.RawBytes) (SV, error) {
+	if len(masterKey) == 0 {
+		return SV{}, common.NewBasicError("Master key must not be empty", nil)
+	}
+	in := make(common.RawBytes, len(masterKey)+8)
+	off := copy(in, masterKey)
+	common.Order.PutUint32(in[off:], util.TimeToSecs(epoch.Begin))
+	common.Order.PutUint32(in[off+4:], util.TimeToSecs(epoch.End))
+	// This uses the same parameters as the derivation of the hop field MAC key.
+	key := pbkdf2.Key(in, []byte(svSalt), 1000, KeyLen, sha256.New)
+	return SV{Epoch: epoch, Key: key}, nil
+}
+
+// DeriveLvl1 derives the level 1 key described by meta from the secret value
+// of the source AS.
+func DeriveLvl1(meta Lvl1Meta, sv SV) (Lvl1Key, error) {
+	if !meta.Epoch.Equal(sv.Epoch) {
+		return Lvl1Key{}, common.NewBasicError("Epoch mismatch", nil,
+			"expected", sv.Epoch, "actual", meta.Epoch)
+	}
+	// The input is padded to the block size of the MAC.
+	in := make(common.RawBytes, 2*addr.IABytes)
+	meta.DstIA.Write(in)
+	key, err := mac(sv.Key, in)
+	if err != nil {
+		return Lvl1Key{}, err
+	}
+	return Lvl1Key{Lvl1Meta: meta, Key: key}, nil
+}
+
+// DeriveLvl2 derives the level 2 key described by meta from the level 1 key
+// between the source and destination AS.
+func DeriveLvl2(meta Lvl2Meta, key Lvl1Key) (Lvl2Key, error) {
+	if !meta.SrcIA.Equal(key.SrcIA) || !meta.DstIA.Equal(key.DstIA) {
+		return Lvl2Key{}, common.NewBasicError("ISD-AS mismatch", nil,
+			"expected", key.Lvl1Meta, "actual", meta)
+	}
+	if !meta.Epoch.Equal(key.Epoch) {
+		return Lvl2Key{}, common.NewBasicError("Epoch mismatch", nil,
+			"expected", key.Epoch, "actual", meta.Epoch)
+	}
+	if len(meta.Protocol) > 255 {
+		return Lvl2Key{}, common.NewBasicError("Protocol too long", nil,
+			"len", len(meta.Protocol))
+	}
+	in := common.RawBytes{byte(meta.KeyType), byte(len(meta.Protocol))}
+	in = append(in, meta.Protocol...)
+	var err error
+	switch meta.KeyType {
+	case AS2AS:
+	case AS2Host:
+		in, err = appendHost(in, meta.DstHost)
+	case Host2Host:
+		if in, err = appendHost(in, meta.SrcHost); err == nil {
+			in, err = appendHost(in, meta.DstHost)
+		}
+	default:
+		return Lvl2Key{}, common.NewBasicError("Unknown key type", nil, "type", meta.KeyType)
+	}
+	if err != nil {
+		return Lvl2Key{}, err
+	}
+	raw, err := mac(key.Key, in)
+	if err != nil {
+		return Lvl2Key{}, err
+	}
+	return Lvl2Key{Lvl2Meta: meta, Key: raw}, nil
+}
+
+func appendHost(b common.RawBytes, host addr.HostAddr) (common.RawBytes, error) {
+	if host == nil {
+		return nil, common.NewBasicError("Host must not be nil", nil)
+	}
+	b = append(b, byte(host.Type()))
+	return append(b, host.Pack()...), nil
+}
+
+func mac(key DRKey, in common.RawBytes) (DRKey, error) {
+	m, err := scrypto.InitMac(common.RawBytes(key))
+	if err != nil {
+		return nil, err
+	}
+	m.Write(in)
+	return m.Sum(nil), nil
+}
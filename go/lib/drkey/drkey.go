@@ -0,0 +1,166 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drkey implements the dynamically recreatable key (DRKey) hierarchy.
+//
+// Every AS derives a secret value (SV) per epoch from its master key. The
+// level 1 key of AS A for AS B is derived from the SV of A and the address of
+// B:
+//
+//	K_{A->B} = MAC_{SV_A}(B)
+//
+// A derives level 1 keys on the fly. B fetches K_{A->B} from the certificate
+// server of A, encrypted with the public key of B. Level 2 keys are derived
+// from level 1 keys for a specific protocol and, optionally, specific end
+// hosts:
+//
+//	K_{A->B:H_B}^p     = MAC_{K_{A->B}}(type || p || H_B)
+//	K_{A:H_A->B:H_B}^p = MAC_{K_{A->B}}(type || p || H_A || H_B)
+//
+// The certificate servers of A and B can thus both derive the level 2 keys of
+// all hosts in their AS, and end hosts fetch them from their local certificate
+// server via sciond.
+package drkey
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+// KeyLen is the length of all keys in the hierarchy.
+const KeyLen = 16
+
+// DRKey is a key of the hierarchy.
+type DRKey common.RawBytes
+
+func (k DRKey) Equal(other DRKey) bool {
+	return bytes.Equal(k, other)
+}
+
+func (k DRKey) String() string {
+	return "[redacted key]"
+}
+
+// Epoch is the validity period of a key. Begin is inclusive, End exclusive.
+type Epoch struct {
+	Begin time.Time
+	End   time.Time
+}
+
+// NewEpoch returns the epoch between begin and end, in seconds since the Unix
+// epoch.
+func NewEpoch(begin, end uint32) Epoch {
+	return Epoch{Begin: util.SecsToTime(begin), End: util.SecsToTime(end)}
+}
+
+// EpochAt returns the epoch of the given duration that contains t. Epochs are
+// aligned to the Unix epoch, such that all ASes agree on them.
+func EpochAt(t time.Time, duration time.Duration) Epoch {
+	secs := int64(duration / time.Second)
+	begin := t.Unix() - t.Unix()%secs
+	return Epoch{
+		Begin: time.Unix(begin, 0).UTC(),
+		End:   time.Unix(begin+secs, 0).UTC(),
+	}
+}
+
+// Contains indicates whether t is within the epoch.
+func (e Epoch) Contains(t time.Time) bool {
+	return !t.Before(e.Begin) && t.Before(e.End)
+}
+
+func (e Epoch) Equal(other Epoch) bool {
+	return e.Begin.Equal(other.Begin) && e.End.Equal(other.End)
+}
+
+func (e Epoch) String() string {
+	return fmt.Sprintf("[%s, %s)", util.TimeToString(e.Begin), util.TimeToString(e.End))
+}
+
+// SV is the secret value of the local AS for an epoch.
+type SV struct {
+	Epoch Epoch
+	Key   DRKey
+}
+
+// Lvl1Meta identifies a level 1 key.
+type Lvl1Meta struct {
+	Epoch Epoch
+	SrcIA addr.IA
+	DstIA addr.IA
+}
+
+// Lvl1Key is a level 1 key. It is derived by the source AS and fetched by the
+// destination AS.
+type Lvl1Key struct {
+	Lvl1Meta
+	Key DRKey
+}
+
+func (k Lvl1Key) String() string {
+	return fmt.Sprintf("Lvl1Key: %s->%s Epoch: %s", k.SrcIA, k.DstIA, k.Epoch)
+}
+
+// Lvl2KeyType indicates which hosts a level 2 key is bound to.
+type Lvl2KeyType uint8
+
+const (
+	// AS2AS keys are bound to the source and destination AS only.
+	AS2AS Lvl2KeyType = iota
+	// AS2Host keys are bound to a host in the destination AS.
+	AS2Host
+	// Host2Host keys are bound to a host in both the source and the
+	// destination AS.
+	Host2Host
+)
+
+func (t Lvl2KeyType) String() string {
+	switch t {
+	case AS2AS:
+		return "AS2AS"
+	case AS2Host:
+		return "AS2Host"
+	case Host2Host:
+		return "Host2Host"
+	}
+	return fmt.Sprintf("UNKNOWN (%d)", t)
+}
+
+// Lvl2Meta identifies a level 2 key. The hosts that are not part of the key
+// type are ignored.
+type Lvl2Meta struct {
+	KeyType  Lvl2KeyType
+	Protocol string
+	Epoch    Epoch
+	SrcIA    addr.IA
+	DstIA    addr.IA
+	SrcHost  addr.HostAddr
+	DstHost  addr.HostAddr
+}
+
+// Lvl2Key is a level 2 key.
+type Lvl2Key struct {
+	Lvl2Meta
+	Key DRKey
+}
+
+func (k Lvl2Key) String() string {
+	return fmt.Sprintf("Lvl2Key: %s %q %s,%s->%s,%s Epoch: %s", k.KeyType, k.Protocol,
+		k.SrcIA, k.SrcHost, k.DstIA, k.DstHost, k.Epoch)
+}
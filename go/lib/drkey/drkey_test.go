@@ -0,0 +1,145 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	srcIA      = xtest.MustParseIA("1-ff00:0:110")
+	dstIA      = xtest.MustParseIA("1-ff00:0:111")
+	testEpoch  = NewEpoch(86400, 2*86400)
+	testMaster = common.RawBytes("0123456789abcdef")
+)
+
+func testLvl1(t *testing.T) Lvl1Key {
+	sv, err := DeriveSV(testEpoch, testMaster)
+	xtest.FailOnErr(t, err)
+	k, err := DeriveLvl1(Lvl1Meta{Epoch: testEpoch, SrcIA: srcIA, DstIA: dstIA}, sv)
+	xtest.FailOnErr(t, err)
+	return k
+}
+
+func TestEpochAt(t *testing.T) {
+	Convey("EpochAt aligns epochs to the Unix epoch", t, func() {
+		e := EpochAt(time.Unix(86400+3600, 0), 24*time.Hour)
+		SoMsg("epoch", e.Equal(testEpoch), ShouldBeTrue)
+		SoMsg("begin", e.Contains(time.Unix(86400, 0)), ShouldBeTrue)
+		SoMsg("end", e.Contains(time.Unix(2*86400, 0)), ShouldBeFalse)
+	})
+}
+
+func TestDeriveLvl1(t *testing.T) {
+	Convey("DeriveLvl1", t, func() {
+		k := testLvl1(t)
+		SoMsg("len", len(k.Key), ShouldEqual, KeyLen)
+		SoMsg("deterministic", k.Key.Equal(testLvl1(t).Key), ShouldBeTrue)
+		sv, _ := DeriveSV(testEpoch, testMaster)
+		other, err := DeriveLvl1(Lvl1Meta{Epoch: testEpoch, SrcIA: srcIA, DstIA: srcIA}, sv)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("dst bound", other.Key.Equal(k.Key), ShouldBeFalse)
+		nextEpoch := NewEpoch(2*86400, 3*86400)
+		nextSV, _ := DeriveSV(nextEpoch, testMaster)
+		SoMsg("epoch bound", nextSV.Key.Equal(sv.Key), ShouldBeFalse)
+		_, err = DeriveLvl1(Lvl1Meta{Epoch: nextEpoch, SrcIA: srcIA, DstIA: dstIA}, sv)
+		SoMsg("epoch mismatch", err, ShouldNotBeNil)
+	})
+}
+
+func TestDeriveLvl2(t *testing.T) {
+	Convey("DeriveLvl2", t, func() {
+		lvl1 := testLvl1(t)
+		meta := Lvl2Meta{
+			Protocol: "scmp",
+			Epoch:    testEpoch,
+			SrcIA:    srcIA,
+			DstIA:    dstIA,
+			SrcHost:  addr.HostFromIPStr("192.0.2.1"),
+			DstHost:  addr.HostFromIPStr("192.0.2.2"),
+		}
+		keys := make(map[string]Lvl2KeyType)
+		for _, kt := range []Lvl2KeyType{AS2AS, AS2Host, Host2Host} {
+			meta.KeyType = kt
+			k, err := DeriveLvl2(meta, lvl1)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("len", len(k.Key), ShouldEqual, KeyLen)
+			keys[string(k.Key)] = kt
+		}
+		SoMsg("distinct", len(keys), ShouldEqual, 3)
+		Convey("is bound to the protocol", func() {
+			meta.Protocol = "other"
+			k, _ := DeriveLvl2(meta, lvl1)
+			_, ok := keys[string(k.Key)]
+			SoMsg("bound", ok, ShouldBeFalse)
+		})
+		Convey("requires the hosts of the key type", func() {
+			meta.KeyType = AS2Host
+			meta.DstHost = nil
+			_, err := DeriveLvl2(meta, lvl1)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("requires matching ASes", func() {
+			meta.DstIA = srcIA
+			_, err := DeriveLvl2(meta, lvl1)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestStaticSource(t *testing.T) {
+	Convey("StaticSource derives the keys from the secret", t, func() {
+		s, err := NewStaticSource(testMaster, 24*time.Hour)
+		SoMsg("err", err, ShouldBeNil)
+		meta := Lvl2Meta{KeyType: AS2AS, Protocol: "scmp", SrcIA: srcIA, DstIA: dstIA}
+		k, err := s.Lvl2(meta, time.Unix(86400+3600, 0))
+		SoMsg("lvl2 err", err, ShouldBeNil)
+		SoMsg("epoch", k.Epoch.Equal(testEpoch), ShouldBeTrue)
+		meta.Epoch = testEpoch
+		expected, _ := DeriveLvl2(meta, testLvl1(t))
+		SoMsg("key", k.Key.Equal(expected.Key), ShouldBeTrue)
+		next, _ := s.Lvl2(meta, time.Unix(2*86400, 0))
+		SoMsg("next epoch", next.Key.Equal(k.Key), ShouldBeFalse)
+	})
+}
+
+func TestLvl1Store(t *testing.T) {
+	Convey("Lvl1Store", t, func() {
+		s := NewLvl1Store()
+		k := testLvl1(t)
+		s.Add(k)
+		next := k
+		next.Epoch = NewEpoch(2*86400, 3*86400)
+		s.Add(next)
+		got, ok := s.Get(srcIA, dstIA, time.Unix(86400+1, 0))
+		SoMsg("found", ok, ShouldBeTrue)
+		SoMsg("key", got.Epoch.Equal(k.Epoch), ShouldBeTrue)
+		got, ok = s.Get(srcIA, dstIA, time.Unix(2*86400, 0))
+		SoMsg("found next", ok, ShouldBeTrue)
+		SoMsg("next", got.Epoch.Equal(next.Epoch), ShouldBeTrue)
+		_, ok = s.Get(dstIA, srcIA, time.Unix(86400+1, 0))
+		SoMsg("reverse", ok, ShouldBeFalse)
+		SoMsg("removed", s.RemoveExpired(time.Unix(2*86400, 0)), ShouldEqual, 1)
+		_, ok = s.Get(srcIA, dstIA, time.Unix(86400+1, 0))
+		SoMsg("expired", ok, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
+)
+
+// Lvl2Source provides level 2 keys. It decouples the components that
+// authenticate traffic with DRKeys from the way the keys are obtained.
+type Lvl2Source interface {
+	// Lvl2 returns the level 2 key described by meta. The epoch of meta is
+	// ignored, the key of the epoch that contains t is returned.
+	Lvl2(meta Lvl2Meta, t time.Time) (Lvl2Key, error)
+}
+
+var _ Lvl2Source = (*StaticSource)(nil)
+
+// StaticSource derives the keys of all ASes from a single secret, which is
+// used as the master key of every AS. Everybody that holds the secret can
+// derive the keys of all ASes, it must thus only be used for testing.
+type StaticSource struct {
+	secret        common.RawBytes
+	epochDuration time.Duration
+	// sv caches the secret value of the last requested epoch, such that it is
+	// not derived for every key.
+	mu sync.Mutex
+	sv SV
+}
+
+// NewStaticSource creates a source that derives all keys from secret, with
+// epochs of the given duration.
+func NewStaticSource(secret common.RawBytes, epochDuration time.Duration) (*StaticSource,
+	error) {
+
+	if len(secret) == 0 {
+		return nil, common.NewBasicError("Secret must not be empty", nil)
+	}
+	if epochDuration < time.Second {
+		return nil, common.NewBasicError("Epoch duration must be at least 1s", nil,
+			"duration", epochDuration)
+	}
+	return &StaticSource{secret: secret, epochDuration: epochDuration}, nil
+}
+
+// LoadStaticSource creates a static source from the base64 encoded secret
+// stored in file.
+func LoadStaticSource(file string, epochDuration time.Duration) (*StaticSource, error) {
+	secret, err := keyconf.LoadKey(file, keyconf.RawKey)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to load static DRKey secret", err,
+			"file", file)
+	}
+	return NewStaticSource(secret, epochDuration)
+}
+
+func (s *StaticSource) Lvl2(meta Lvl2Meta, t time.Time) (Lvl2Key, error) {
+	sv, err := s.svAt(t)
+	if err != nil {
+		return Lvl2Key{}, err
+	}
+	meta.Epoch = sv.Epoch
+	lvl1Meta := Lvl1Meta{Epoch: meta.Epoch, SrcIA: meta.SrcIA, DstIA: meta.DstIA}
+	lvl1, err := DeriveLvl1(lvl1Meta, sv)
+	if err != nil {
+		return Lvl2Key{}, err
+	}
+	return DeriveLvl2(meta, lvl1)
+}
+
+func (s *StaticSource) svAt(t time.Time) (SV, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sv.Key != nil && s.sv.Epoch.Contains(t) {
+		return s.sv, nil
+	}
+	sv, err := DeriveSV(EpochAt(t, s.epochDuration), s.secret)
+	if err != nil {
+		return SV{}, err
+	}
+	s.sv = sv
+	return sv, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+)
+
+// Lvl1Store holds level 1 keys, indexed by source and destination AS. Multiple
+// keys of the same ASes are kept for different epochs, such that keys can be
+// fetched before their epoch starts. It is safe for concurrent use.
+type Lvl1Store struct {
+	mu   sync.RWMutex
+	keys map[lvl1Idx][]Lvl1Key
+}
+
+type lvl1Idx struct {
+	src, dst addr.IA
+}
+
+func NewLvl1Store() *Lvl1Store {
+	return &Lvl1Store{keys: make(map[lvl1Idx][]Lvl1Key)}
+}
+
+// Add adds the key to the store. It replaces the key of the same ASes and
+// epoch, if there is one.
+func (s *Lvl1Store) Add(k Lvl1Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := lvl1Idx{src: k.SrcIA, dst: k.DstIA}
+	keys := s.keys[idx]
+	for i := range keys {
+		if keys[i].Epoch.Equal(k.Epoch) {
+			keys[i] = k
+			return
+		}
+	}
+	s.keys[idx] = append(keys, k)
+}
+
+// Get returns the key from src to dst whose epoch contains t.
+func (s *Lvl1Store) Get(src, dst addr.IA, t time.Time) (Lvl1Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, k := range s.keys[lvl1Idx{src: src, dst: dst}] {
+		if k.Epoch.Contains(t) {
+			return k, true
+		}
+	}
+	return Lvl1Key{}, false
+}
+
+// RemoveExpired removes the keys whose epoch has ended before t. It returns
+// the number of removed keys.
+func (s *Lvl1Store) RemoveExpired(t time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed int
+	for idx, keys := range s.keys {
+		valid := keys[:0]
+		for _, k := range keys {
+			if t.Before(k.Epoch.End) {
+				valid = append(valid, k)
+			}
+		}
+		removed += len(keys) - len(valid)
+		if len(valid) == 0 {
+			delete(s.keys, idx)
+			continue
+		}
+		s.keys[idx] = valid
+	}
+	return removed
+}
@@ -34,6 +34,10 @@
 //  infra.SegSync             -> ctrl.SignedPld/ctrl.Pld/path_mgmt.SegSync
 //  infra.ChainIssueRequest   -> ctrl.SignedPld/ctrl.Pld/cert_mgmt.ChainIssReq
 //  infra.ChainIssueReply     -> ctrl.SignedPld/ctrl.Pld/cert_mgmt.ChainIssRep
+//  infra.DRKeyLvl1Request    -> ctrl.SignedPld/ctrl.Pld/drkey_mgmt.Lvl1Req
+//  infra.DRKeyLvl1Reply      -> ctrl.SignedPld/ctrl.Pld/drkey_mgmt.Lvl1Rep
+//  infra.DRKeyLvl2Request    -> ctrl.SignedPld/ctrl.Pld/drkey_mgmt.Lvl2Req
+//  infra.DRKeyLvl2Reply      -> ctrl.SignedPld/ctrl.Pld/drkey_mgmt.Lvl2Rep
 //
 // To start processing messages received via the Messenger, call
 // ListenAndServe. The method runs in the current goroutine, and spawns new
@@ -83,6 +87,7 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/ctrl_msg"
 	"github.com/scionproto/scion/go/lib/ctrl/ifid"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
@@ -536,6 +541,90 @@ func (m *Messenger) SendChainIssueReply(ctx context.Context, msg *cert_mgmt.Chai
 	return m.getRequester(infra.ChainIssueReply).Notify(ctx, pld, a)
 }
 
+func (m *Messenger) RequestDRKeyLvl1(ctx context.Context, msg *drkey_mgmt.Lvl1Req, a net.Addr,
+	id uint64) (*drkey_mgmt.Lvl1Rep, error) {
+
+	logger := log.FromCtx(ctx)
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: id})
+	if err != nil {
+		return nil, err
+	}
+	logger.Trace("[Messenger] Sending request", "req_type", infra.DRKeyLvl1Request,
+		"msg_id", id, "request", msg, "peer", a)
+	replyCtrlPld, _, err := m.getRequester(infra.DRKeyLvl1Request).Request(ctx, pld, a)
+	if err != nil {
+		return nil, common.NewBasicError("[Messenger] Request error", err)
+	}
+	_, replyMsg, err := validate(replyCtrlPld)
+	if err != nil {
+		return nil, common.NewBasicError("[Messenger] Reply validation failed", err)
+	}
+	switch reply := replyMsg.(type) {
+	case *drkey_mgmt.Lvl1Rep:
+		logger.Trace("[Messenger] Received reply")
+		return reply, nil
+	case *ack.Ack:
+		return nil, &infra.Error{Message: reply}
+	default:
+		err := newTypeAssertErr("*drkey_mgmt.Lvl1Rep", replyMsg)
+		return nil, common.NewBasicError("[Messenger] Type assertion failed", err)
+	}
+}
+
+func (m *Messenger) SendDRKeyLvl1Reply(ctx context.Context, msg *drkey_mgmt.Lvl1Rep,
+	a net.Addr, id uint64) error {
+
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: id})
+	if err != nil {
+		return err
+	}
+	logger := log.FromCtx(ctx)
+	logger.Trace("[Messenger] Sending Notify", "type", infra.DRKeyLvl1Reply, "to", a, "id", id)
+	return m.getRequester(infra.DRKeyLvl1Reply).Notify(ctx, pld, a)
+}
+
+func (m *Messenger) RequestDRKeyLvl2(ctx context.Context, msg *drkey_mgmt.Lvl2Req, a net.Addr,
+	id uint64) (*drkey_mgmt.Lvl2Rep, error) {
+
+	logger := log.FromCtx(ctx)
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: id})
+	if err != nil {
+		return nil, err
+	}
+	logger.Trace("[Messenger] Sending request", "req_type", infra.DRKeyLvl2Request,
+		"msg_id", id, "request", msg, "peer", a)
+	replyCtrlPld, _, err := m.getRequester(infra.DRKeyLvl2Request).Request(ctx, pld, a)
+	if err != nil {
+		return nil, common.NewBasicError("[Messenger] Request error", err)
+	}
+	_, replyMsg, err := validate(replyCtrlPld)
+	if err != nil {
+		return nil, common.NewBasicError("[Messenger] Reply validation failed", err)
+	}
+	switch reply := replyMsg.(type) {
+	case *drkey_mgmt.Lvl2Rep:
+		logger.Trace("[Messenger] Received reply")
+		return reply, nil
+	case *ack.Ack:
+		return nil, &infra.Error{Message: reply}
+	default:
+		err := newTypeAssertErr("*drkey_mgmt.Lvl2Rep", replyMsg)
+		return nil, common.NewBasicError("[Messenger] Type assertion failed", err)
+	}
+}
+
+func (m *Messenger) SendDRKeyLvl2Reply(ctx context.Context, msg *drkey_mgmt.Lvl2Rep,
+	a net.Addr, id uint64) error {
+
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: id})
+	if err != nil {
+		return err
+	}
+	logger := log.FromCtx(ctx)
+	logger.Trace("[Messenger] Sending Notify", "type", infra.DRKeyLvl2Reply, "to", a, "id", id)
+	return m.getRequester(infra.DRKeyLvl2Reply).Notify(ctx, pld, a)
+}
+
 // sendMessage sends payload msg of type expectedType to address a, using id.
 // If waiting for Acks is disabled, sendMessage returns immediately after
 // sending the message on the network. If waiting for Acks is enabled,
@@ -929,6 +1018,21 @@ func validate(pld *ctrl.Pld) (infra.MessageType, proto.Cerealizable, error) {
 				common.NewBasicError("Unsupported SignedPld.CtrlPld.PathMgmt.Xxx message type",
 					nil, "capnp_which", pld.PathMgmt.Which)
 		}
+	case proto.CtrlPld_Which_drkeyMgmt:
+		switch pld.DRKeyMgmt.Which {
+		case proto.DRKeyMgmt_Which_drkeyLvl1Req:
+			return infra.DRKeyLvl1Request, pld.DRKeyMgmt.Lvl1Req, nil
+		case proto.DRKeyMgmt_Which_drkeyLvl1Rep:
+			return infra.DRKeyLvl1Reply, pld.DRKeyMgmt.Lvl1Rep, nil
+		case proto.DRKeyMgmt_Which_drkeyLvl2Req:
+			return infra.DRKeyLvl2Request, pld.DRKeyMgmt.Lvl2Req, nil
+		case proto.DRKeyMgmt_Which_drkeyLvl2Rep:
+			return infra.DRKeyLvl2Reply, pld.DRKeyMgmt.Lvl2Rep, nil
+		default:
+			return infra.None, nil,
+				common.NewBasicError("Unsupported SignedPld.CtrlPld.DRKeyMgmt.Xxx message type",
+					nil, "capnp_which", pld.DRKeyMgmt.Which)
+		}
 	case proto.CtrlPld_Which_ack:
 		return infra.Ack, pld.Ack, nil
 	default:
@@ -24,6 +24,7 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/ifid"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
@@ -208,6 +209,42 @@ func (m *MessengerWithMetrics) SendChainIssueReply(ctx context.Context, msg *cer
 	return err
 }
 
+func (m *MessengerWithMetrics) RequestDRKeyLvl1(ctx context.Context, msg *drkey_mgmt.Lvl1Req,
+	a net.Addr, id uint64) (*drkey_mgmt.Lvl1Rep, error) {
+
+	opMetrics := metricStartOp(infra.DRKeyLvl1Request)
+	reply, err := m.messenger.RequestDRKeyLvl1(ctx, msg, a, id)
+	opMetrics.publishResult(err)
+	return reply, err
+}
+
+func (m *MessengerWithMetrics) SendDRKeyLvl1Reply(ctx context.Context, msg *drkey_mgmt.Lvl1Rep,
+	a net.Addr, id uint64) error {
+
+	opMetrics := metricStartOp(infra.DRKeyLvl1Reply)
+	err := m.messenger.SendDRKeyLvl1Reply(ctx, msg, a, id)
+	opMetrics.publishResult(err)
+	return err
+}
+
+func (m *MessengerWithMetrics) RequestDRKeyLvl2(ctx context.Context, msg *drkey_mgmt.Lvl2Req,
+	a net.Addr, id uint64) (*drkey_mgmt.Lvl2Rep, error) {
+
+	opMetrics := metricStartOp(infra.DRKeyLvl2Request)
+	reply, err := m.messenger.RequestDRKeyLvl2(ctx, msg, a, id)
+	opMetrics.publishResult(err)
+	return reply, err
+}
+
+func (m *MessengerWithMetrics) SendDRKeyLvl2Reply(ctx context.Context, msg *drkey_mgmt.Lvl2Rep,
+	a net.Addr, id uint64) error {
+
+	opMetrics := metricStartOp(infra.DRKeyLvl2Reply)
+	err := m.messenger.SendDRKeyLvl2Reply(ctx, msg, a, id)
+	opMetrics.publishResult(err)
+	return err
+}
+
 func (m *MessengerWithMetrics) AddHandler(msgType infra.MessageType, handler infra.Handler) {
 	handlerWithMetrics := func(request *infra.Request) *infra.HandlerResult {
 		inCallsTotal.With(prometheus.Labels{
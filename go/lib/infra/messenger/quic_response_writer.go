@@ -20,6 +20,7 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/rpc"
@@ -139,3 +140,41 @@ func (rw *QUICResponseWriter) SendIfStateInfoReply(ctx context.Context,
 	}
 	return rw.ReplyWriter.WriteReply(&rpc.Reply{SignedPld: signedCtrlPld})
 }
+
+func (rw *QUICResponseWriter) SendDRKeyLvl1Reply(ctx context.Context,
+	msg *drkey_mgmt.Lvl1Rep) error {
+
+	go func() {
+		defer log.LogPanicAndExit()
+		<-ctx.Done()
+		rw.ReplyWriter.Close()
+	}()
+	ctrlPld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: rw.ID})
+	if err != nil {
+		return err
+	}
+	signedCtrlPld, err := ctrlPld.SignedPld(infra.NullSigner)
+	if err != nil {
+		return err
+	}
+	return rw.ReplyWriter.WriteReply(&rpc.Reply{SignedPld: signedCtrlPld})
+}
+
+func (rw *QUICResponseWriter) SendDRKeyLvl2Reply(ctx context.Context,
+	msg *drkey_mgmt.Lvl2Rep) error {
+
+	go func() {
+		defer log.LogPanicAndExit()
+		<-ctx.Done()
+		rw.ReplyWriter.Close()
+	}()
+	ctrlPld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: rw.ID})
+	if err != nil {
+		return err
+	}
+	signedCtrlPld, err := ctrlPld.SignedPld(infra.NullSigner)
+	if err != nil {
+		return err
+	}
+	return rw.ReplyWriter.WriteReply(&rpc.Reply{SignedPld: signedCtrlPld})
+}
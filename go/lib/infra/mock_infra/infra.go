@@ -12,6 +12,7 @@ import (
 	ctrl "github.com/scionproto/scion/go/lib/ctrl"
 	ack "github.com/scionproto/scion/go/lib/ctrl/ack"
 	cert_mgmt "github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	drkey_mgmt "github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	ifid "github.com/scionproto/scion/go/lib/ctrl/ifid"
 	path_mgmt "github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	infra "github.com/scionproto/scion/go/lib/infra"
@@ -340,6 +341,36 @@ func (mr *MockMessengerMockRecorder) RequestChainIssue(arg0, arg1, arg2, arg3 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestChainIssue", reflect.TypeOf((*MockMessenger)(nil).RequestChainIssue), arg0, arg1, arg2, arg3)
 }
 
+// RequestDRKeyLvl1 mocks base method
+func (m *MockMessenger) RequestDRKeyLvl1(arg0 context.Context, arg1 *drkey_mgmt.Lvl1Req, arg2 net.Addr, arg3 uint64) (*drkey_mgmt.Lvl1Rep, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDRKeyLvl1", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*drkey_mgmt.Lvl1Rep)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDRKeyLvl1 indicates an expected call of RequestDRKeyLvl1
+func (mr *MockMessengerMockRecorder) RequestDRKeyLvl1(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDRKeyLvl1", reflect.TypeOf((*MockMessenger)(nil).RequestDRKeyLvl1), arg0, arg1, arg2, arg3)
+}
+
+// RequestDRKeyLvl2 mocks base method
+func (m *MockMessenger) RequestDRKeyLvl2(arg0 context.Context, arg1 *drkey_mgmt.Lvl2Req, arg2 net.Addr, arg3 uint64) (*drkey_mgmt.Lvl2Rep, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestDRKeyLvl2", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*drkey_mgmt.Lvl2Rep)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestDRKeyLvl2 indicates an expected call of RequestDRKeyLvl2
+func (mr *MockMessengerMockRecorder) RequestDRKeyLvl2(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestDRKeyLvl2", reflect.TypeOf((*MockMessenger)(nil).RequestDRKeyLvl2), arg0, arg1, arg2, arg3)
+}
+
 // SendAck mocks base method
 func (m *MockMessenger) SendAck(arg0 context.Context, arg1 *ack.Ack, arg2 net.Addr, arg3 uint64) error {
 	m.ctrl.T.Helper()
@@ -382,6 +413,34 @@ func (mr *MockMessengerMockRecorder) SendChainIssueReply(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChainIssueReply", reflect.TypeOf((*MockMessenger)(nil).SendChainIssueReply), arg0, arg1, arg2, arg3)
 }
 
+// SendDRKeyLvl1Reply mocks base method
+func (m *MockMessenger) SendDRKeyLvl1Reply(arg0 context.Context, arg1 *drkey_mgmt.Lvl1Rep, arg2 net.Addr, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDRKeyLvl1Reply", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDRKeyLvl1Reply indicates an expected call of SendDRKeyLvl1Reply
+func (mr *MockMessengerMockRecorder) SendDRKeyLvl1Reply(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDRKeyLvl1Reply", reflect.TypeOf((*MockMessenger)(nil).SendDRKeyLvl1Reply), arg0, arg1, arg2, arg3)
+}
+
+// SendDRKeyLvl2Reply mocks base method
+func (m *MockMessenger) SendDRKeyLvl2Reply(arg0 context.Context, arg1 *drkey_mgmt.Lvl2Rep, arg2 net.Addr, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDRKeyLvl2Reply", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDRKeyLvl2Reply indicates an expected call of SendDRKeyLvl2Reply
+func (mr *MockMessengerMockRecorder) SendDRKeyLvl2Reply(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDRKeyLvl2Reply", reflect.TypeOf((*MockMessenger)(nil).SendDRKeyLvl2Reply), arg0, arg1, arg2, arg3)
+}
+
 // SendIfId mocks base method
 func (m *MockMessenger) SendIfId(arg0 context.Context, arg1 *ifid.IFID, arg2 net.Addr, arg3 uint64) error {
 	m.ctrl.T.Helper()
@@ -597,6 +656,34 @@ func (mr *MockResponseWriterMockRecorder) SendChainIssueReply(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChainIssueReply", reflect.TypeOf((*MockResponseWriter)(nil).SendChainIssueReply), arg0, arg1)
 }
 
+// SendDRKeyLvl1Reply mocks base method
+func (m *MockResponseWriter) SendDRKeyLvl1Reply(arg0 context.Context, arg1 *drkey_mgmt.Lvl1Rep) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDRKeyLvl1Reply", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDRKeyLvl1Reply indicates an expected call of SendDRKeyLvl1Reply
+func (mr *MockResponseWriterMockRecorder) SendDRKeyLvl1Reply(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDRKeyLvl1Reply", reflect.TypeOf((*MockResponseWriter)(nil).SendDRKeyLvl1Reply), arg0, arg1)
+}
+
+// SendDRKeyLvl2Reply mocks base method
+func (m *MockResponseWriter) SendDRKeyLvl2Reply(arg0 context.Context, arg1 *drkey_mgmt.Lvl2Rep) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDRKeyLvl2Reply", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDRKeyLvl2Reply indicates an expected call of SendDRKeyLvl2Reply
+func (mr *MockResponseWriterMockRecorder) SendDRKeyLvl2Reply(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDRKeyLvl2Reply", reflect.TypeOf((*MockResponseWriter)(nil).SendDRKeyLvl2Reply), arg0, arg1)
+}
+
 // SendIfStateInfoReply mocks base method
 func (m *MockResponseWriter) SendIfStateInfoReply(arg0 context.Context, arg1 *path_mgmt.IFStateInfos) error {
 	m.ctrl.T.Helper()
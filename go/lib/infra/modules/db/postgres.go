@@ -0,0 +1,106 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+const (
+	// schemaVersionsTable keeps track of the schema versions of all the
+	// databases that share a PostgreSQL database.
+	schemaVersionsTable = `CREATE TABLE IF NOT EXISTS SchemaVersions(
+		Name TEXT PRIMARY KEY,
+		Version INTEGER NOT NULL
+	);`
+)
+
+// NewPostgres returns a new PostgreSQL backend connecting to the database
+// described by the connection string. The name identifies the schema, such that
+// multiple backends can share the same PostgreSQL database. If the schema does
+// not exist yet, it is created. If the schema version of the stored database is
+// different from schemaVersion, an error is returned.
+//
+// The PostgreSQL driver has to be registered by the caller, e.g. by importing
+// github.com/lib/pq.
+func NewPostgres(connection, name, schema string, schemaVersion int) (*sql.DB, error) {
+	var err error
+	if connection == "" {
+		return nil, common.NewBasicError("Empty connection not allowed for postgres", nil)
+	}
+	db, err := sql.Open("postgres", connection)
+	if err != nil {
+		return nil, common.NewBasicError("Couldn't open PostgreSQL database", err,
+			"name", name)
+	}
+	// On future errors, close the sql database before exiting
+	defer func() {
+		if err != nil {
+			db.Close()
+		}
+	}()
+	// Make sure DB is reachable
+	if err = db.Ping(); err != nil {
+		return nil, common.NewBasicError("Initial DB ping failed, connection broken?", err,
+			"name", name)
+	}
+	if _, err = db.Exec(schemaVersionsTable); err != nil {
+		return nil, common.NewBasicError("Failed to set up schema versions table", err,
+			"name", name)
+	}
+	// Setting up the schema is done in a transaction that locks the version
+	// table, such that concurrently starting instances do not race.
+	err = DoInTx(context.Background(), db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "LOCK TABLE SchemaVersions IN EXCLUSIVE MODE")
+		if err != nil {
+			return common.NewBasicError("Failed to lock schema versions table", err)
+		}
+		var existingVersion int
+		err = tx.QueryRowContext(ctx, "SELECT Version FROM SchemaVersions WHERE Name=$1",
+			name).Scan(&existingVersion)
+		switch {
+		case err == sql.ErrNoRows:
+			return setupPostgres(ctx, tx, name, schema, schemaVersion)
+		case err != nil:
+			return common.NewBasicError("Failed to check schema version", err)
+		case existingVersion != schemaVersion:
+			return common.NewBasicError("Database schema version mismatch", nil,
+				"expected", schemaVersion, "have", existingVersion)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, common.NewBasicError("Failed to initialize PostgreSQL database", err,
+			"name", name)
+	}
+	return db, nil
+}
+
+func setupPostgres(ctx context.Context, tx *sql.Tx, name, schema string,
+	schemaVersion int) error {
+
+	if _, err := tx.ExecContext(ctx, schema); err != nil {
+		return common.NewBasicError("Failed to set up PostgreSQL database", err)
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO SchemaVersions (Name, Version) VALUES ($1, $2)",
+		name, schemaVersion)
+	if err != nil {
+		return common.NewBasicError("Failed to write schema version", err)
+	}
+	return nil
+}
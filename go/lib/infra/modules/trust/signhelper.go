@@ -24,6 +24,7 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/scrypto"
 	"github.com/scionproto/scion/go/lib/util"
@@ -64,6 +65,10 @@ func NewBasicSigner(key common.RawBytes, meta infra.SignerMeta) (*BasicSigner, e
 	switch meta.Algo {
 	case scrypto.Ed25519:
 		signer.signType = proto.SignType_ed25519
+	case scrypto.ECDSAP256:
+		signer.signType = proto.SignType_ecdsap256
+	case scrypto.ECDSAP384:
+		signer.signType = proto.SignType_ecdsap384
 	default:
 		return nil, common.NewBasicError("Unsupported signing algorithm", nil, "algo", meta.Algo)
 	}
@@ -154,17 +159,23 @@ func (v *BasicVerifier) VerifyPld(ctx context.Context, spld *ctrl.SignedPld) (*c
 
 func (v *BasicVerifier) ignoreSign(p *ctrl.Pld, sign *proto.SignS) bool {
 	u0, _ := p.Union()
-	outer, ok := u0.(*cert_mgmt.Pld)
-	if !ok {
-		return false
-	}
-	u1, _ := outer.Union()
-	switch u1.(type) {
-	case *cert_mgmt.Chain, *cert_mgmt.TRC:
-		return true
-	case *cert_mgmt.ChainReq, *cert_mgmt.TRCReq:
-		if sign == nil || sign.Type == proto.SignType_none {
+	unsigned := sign == nil || sign.Type == proto.SignType_none
+	switch outer := u0.(type) {
+	case *cert_mgmt.Pld:
+		u1, _ := outer.Union()
+		switch u1.(type) {
+		case *cert_mgmt.Chain, *cert_mgmt.TRC:
 			return true
+		case *cert_mgmt.ChainReq, *cert_mgmt.TRCReq:
+			return unsigned
+		}
+	case *drkey_mgmt.Pld:
+		// Second-level keys are only exchanged between end hosts and the
+		// certificate server of their own AS.
+		u1, _ := outer.Union()
+		switch u1.(type) {
+		case *drkey_mgmt.Lvl2Req, *drkey_mgmt.Lvl2Rep:
+			return unsigned
 		}
 	}
 	return false
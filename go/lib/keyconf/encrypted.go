@@ -0,0 +1,182 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"io/ioutil"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+)
+
+const (
+	// EncKeysFile is the default name of the encrypted key file.
+	EncKeysFile = "keys.enc"
+
+	ErrorDecrypt = "Unable to decrypt key file"
+
+	encVersion = 1
+	// The scrypt parameters recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	// The largest scrypt parameters accepted when decrypting. They bound the
+	// memory (128*N*R bytes) and time required to derive the key, such that a
+	// crafted key file cannot exhaust the resources of the service.
+	maxScryptN = 1 << 20
+	maxScryptR = 8
+	maxScryptP = 16
+	// aesKeyLen selects AES-256.
+	aesKeyLen = 32
+	saltLen   = 16
+)
+
+// encFile is the format of the encrypted key file. The ciphertext is the
+// AES-GCM encrypted JSON map from key names to raw keys. The AES key is
+// derived from the passphrase with scrypt.
+type encFile struct {
+	Version    int
+	N          int
+	R          int
+	P          int
+	Salt       []byte
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+var _ Provider = (*EncryptedProvider)(nil)
+
+// EncryptedProvider provides the keys stored in a passphrase-encrypted key
+// file. The file is decrypted when the provider is created, the keys are
+// kept in memory.
+type EncryptedProvider struct {
+	keys map[string]common.RawBytes
+}
+
+// NewEncryptedProvider decrypts the key file with the passphrase.
+func NewEncryptedProvider(file string, passphrase []byte) (*EncryptedProvider, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to read encrypted key file", err,
+			"file", file)
+	}
+	keys, err := DecryptKeys(raw, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedProvider{keys: keys}, nil
+}
+
+func (p *EncryptedProvider) Key(name string) (common.RawBytes, error) {
+	key, ok := p.keys[name]
+	if !ok {
+		return nil, common.NewBasicError(ErrorNotFound, nil, "name", name)
+	}
+	return key, nil
+}
+
+// EncryptKeys encrypts the raw keys with the passphrase. The result is the
+// content of an encrypted key file.
+func EncryptKeys(keys map[string]common.RawBytes, passphrase []byte) ([]byte, error) {
+	plain, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	f := &encFile{
+		Version: encVersion,
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+	}
+	if f.Salt, err = scrypto.Nonce(saltLen); err != nil {
+		return nil, err
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if f.Nonce, err = scrypto.Nonce(aead.NonceSize()); err != nil {
+		return nil, err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plain, nil)
+	return json.MarshalIndent(f, "", "    ")
+}
+
+// DecryptKeys decrypts the content of an encrypted key file with the
+// passphrase.
+func DecryptKeys(raw []byte, passphrase []byte) (map[string]common.RawBytes, error) {
+	f := &encFile{}
+	if err := json.Unmarshal(raw, f); err != nil {
+		return nil, common.NewBasicError(ErrorParse, err)
+	}
+	if f.Version != encVersion {
+		return nil, common.NewBasicError("Unsupported encrypted key file version", nil,
+			"expected", encVersion, "actual", f.Version)
+	}
+	if err := f.checkParams(); err != nil {
+		return nil, err
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, common.NewBasicError(ErrorDecrypt, nil, "err", "invalid nonce size")
+	}
+	plain, err := aead.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return nil, common.NewBasicError(ErrorDecrypt, err)
+	}
+	keys := make(map[string]common.RawBytes)
+	if err := json.Unmarshal(plain, &keys); err != nil {
+		return nil, common.NewBasicError(ErrorParse, err)
+	}
+	return keys, nil
+}
+
+// checkParams checks that the scrypt parameters are within the accepted
+// limits. N must be a power of two larger than 1.
+func (f *encFile) checkParams() error {
+	if f.N <= 1 || f.N > maxScryptN || f.N&(f.N-1) != 0 {
+		return common.NewBasicError("Invalid scrypt parameter", nil, "N", f.N,
+			"max", maxScryptN)
+	}
+	if f.R <= 0 || f.R > maxScryptR {
+		return common.NewBasicError("Invalid scrypt parameter", nil, "R", f.R,
+			"max", maxScryptR)
+	}
+	if f.P <= 0 || f.P > maxScryptP {
+		return common.NewBasicError("Invalid scrypt parameter", nil, "P", f.P,
+			"max", maxScryptP)
+	}
+	return nil
+}
+
+func (f *encFile) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, f.Salt, f.N, f.R, f.P, aesKeyLen)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to derive key", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -18,7 +18,6 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
-	"path/filepath"
 	"strings"
 
 	"golang.org/x/crypto/ed25519"
@@ -53,60 +52,122 @@ const (
 
 	RawKey = "raw"
 
-	ErrorOpen    = "Unable to load key"
-	ErrorParse   = "Unable to parse key file"
-	ErrorUnknown = "Unknown algorithm"
+	ErrorOpen     = "Unable to load key"
+	ErrorParse    = "Unable to parse key file"
+	ErrorUnknown  = "Unknown algorithm"
+	ErrorNotFound = "Key not found"
 )
 
-// Load loads key configuration from specified path.
+// Load loads key configuration from specified path. The keys are read from
+// the key provider configured in the directory, see NewProvider. The provider
+// is closed once the keys are read, e.g., the token session is logged out.
+// The signing keys are parsed as ed25519 keys, use LoadWithAlgorithms for
+// other algorithms.
 // issSigKey, onKey, offKey, master can be set true, to load the respective keys.
 func Load(path string, issSigKey, onKey, offKey, master bool) (*Conf, error) {
+	return LoadWithAlgorithms(path, Algorithms{}, issSigKey, onKey, offKey, master)
+}
+
+// LoadWithAlgorithms behaves like Load, but parses the signing keys according
+// to the provided algorithms.
+func LoadWithAlgorithms(path string, algos Algorithms,
+	issSigKey, onKey, offKey, master bool) (*Conf, error) {
+
+	p, err := NewProvider(path)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := LoadFromProvider(p, algos, issSigKey, onKey, offKey, master)
+	if cerr := CloseProvider(p); cerr != nil {
+		return nil, common.NewBasicError("Unable to close key provider", cerr)
+	}
+	return conf, err
+}
+
+// LoadFromProvider loads the key configuration from the key provider. The
+// signing keys are parsed according to algos. issSigKey, onKey, offKey, master
+// can be set true, to load the respective keys.
+func LoadFromProvider(p Provider, algos Algorithms,
+	issSigKey, onKey, offKey, master bool) (*Conf, error) {
+
 	conf := &Conf{}
 	var err error
-	conf.DecryptKey, err = loadKeyCond(filepath.Join(path, DecKeyFile),
-		scrypto.Curve25519xSalsa20Poly1305, true)
+	conf.DecryptKey, err = loadKeyCond(p, DecKeyFile, scrypto.Curve25519xSalsa20Poly1305, true)
 	if err != nil {
 		return nil, err
 	}
-	conf.SignKey, err = loadKeyCond(filepath.Join(path, SigKeyFile), scrypto.Ed25519, true)
+	conf.SignKey, err = loadKeyCond(p, SigKeyFile, signAlgo(algos.Sign), true)
 	if err != nil {
 		return nil, err
 	}
-	conf.IssSigKey, err = loadKeyCond(filepath.Join(path, IssSigKeyFile),
-		scrypto.Ed25519, issSigKey)
+	conf.IssSigKey, err = loadKeyCond(p, IssSigKeyFile, signAlgo(algos.IssSig), issSigKey)
 	if err != nil {
 		return nil, err
 	}
-	conf.OffRootKey, err = loadKeyCond(filepath.Join(path, OffKeyFile), scrypto.Ed25519, offKey)
+	conf.OffRootKey, err = loadKeyCond(p, OffKeyFile, signAlgo(algos.OffRoot), offKey)
 	if err != nil {
 		return nil, err
 	}
-	conf.OnRootKey, err = loadKeyCond(filepath.Join(path, OnKeyFile), scrypto.Ed25519, onKey)
+	conf.OnRootKey, err = loadKeyCond(p, OnKeyFile, signAlgo(algos.OnRoot), onKey)
 	if err != nil {
 		return nil, err
 	}
-	if conf.Master, err = loadMasterCond(path, master); err != nil {
+	if conf.Master, err = loadMasterCond(p, master); err != nil {
 		return nil, err
 	}
 	return conf, nil
 }
 
-func loadKeyCond(file string, algo string, load bool) (common.RawBytes, error) {
+// Algorithms contains the signing algorithms of the keys in the key
+// configuration. Unset algorithms default to ed25519.
+type Algorithms struct {
+	Sign    string
+	IssSig  string
+	OffRoot string
+	OnRoot  string
+}
+
+func signAlgo(algo string) string {
+	if algo == "" {
+		return scrypto.Ed25519
+	}
+	return algo
+}
+
+func loadKeyCond(p Provider, name string, algo string, load bool) (common.RawBytes, error) {
 	if !load {
 		return nil, nil
 	}
-	return LoadKey(file, algo)
+	return LoadKeyFromProvider(p, name, algo)
 }
 
-func loadMasterCond(path string, load bool) (Master, error) {
+func loadMasterCond(p Provider, load bool) (Master, error) {
 	if !load {
 		return Master{}, nil
 	}
-	return LoadMaster(path)
+	return LoadMasterFromProvider(p)
 }
 
 // LoadKey decodes a base64 encoded key stored in file and returns the raw bytes.
 func LoadKey(file string, algo string) (common.RawBytes, error) {
+	raw, err := readKeyFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseKey(raw, algo)
+}
+
+// LoadKeyFromProvider loads the key stored under name in the key provider and
+// returns the raw bytes.
+func LoadKeyFromProvider(p Provider, name string, algo string) (common.RawBytes, error) {
+	raw, err := p.Key(name)
+	if err != nil {
+		return nil, common.NewBasicError(ErrorOpen, err, "key", name)
+	}
+	return parseKey(raw, algo)
+}
+
+func readKeyFile(file string) (common.RawBytes, error) {
 	b, err := ioutil.ReadFile(file)
 	if err != nil {
 		return nil, common.NewBasicError(ErrorOpen, err)
@@ -116,12 +177,22 @@ func LoadKey(file string, algo string) (common.RawBytes, error) {
 	if err != nil {
 		return nil, common.NewBasicError(ErrorParse, err)
 	}
-	dbuf = dbuf[:n]
+	return dbuf[:n], nil
+}
+
+func parseKey(raw common.RawBytes, algo string) (common.RawBytes, error) {
 	switch strings.ToLower(algo) {
 	case RawKey, scrypto.Curve25519xSalsa20Poly1305:
-		return dbuf, nil
+		return raw, nil
 	case scrypto.Ed25519:
-		return common.RawBytes(ed25519.NewKeyFromSeed(dbuf)), nil
+		if len(raw) != ed25519.SeedSize {
+			return nil, common.NewBasicError(ErrorParse, nil, "algo", algo,
+				"expected", ed25519.SeedSize, "actual", len(raw))
+		}
+		return common.RawBytes(ed25519.NewKeyFromSeed(raw)), nil
+	case scrypto.ECDSAP256, scrypto.ECDSAP384:
+		// ECDSA keys are stored as the raw private scalar.
+		return raw, nil
 	default:
 		return nil, common.NewBasicError(ErrorUnknown, nil, "algo", algo)
 	}
@@ -135,18 +206,39 @@ func (c *Conf) String() string {
 		"<redacted>", "<redacted>", "<redacted>", "<redacted>", "<redacted>", "<redacted>")
 }
 
+// Master contains the AS master keys. Key0 is the active key that is used to
+// create new hop fields. Key1 is the secondary key. Hop fields authenticated
+// with the secondary key are still accepted. This allows rotating the master
+// key without invalidating the existing paths: The new key is first installed
+// as secondary key, and then swapped with the active key once all border
+// routers accept it.
 type Master struct {
 	Key0 common.RawBytes
 	Key1 common.RawBytes
 }
 
+// LoadMaster loads the master keys from the key provider configured in path.
+// The provider is closed once the keys are read.
 func LoadMaster(path string) (Master, error) {
+	p, err := NewProvider(path)
+	if err != nil {
+		return Master{}, err
+	}
+	m, err := LoadMasterFromProvider(p)
+	if cerr := CloseProvider(p); cerr != nil {
+		return Master{}, common.NewBasicError("Unable to close key provider", cerr)
+	}
+	return m, err
+}
+
+// LoadMasterFromProvider loads the master keys from the key provider.
+func LoadMasterFromProvider(p Provider) (Master, error) {
 	var err error
 	m := Master{}
-	if m.Key0, err = LoadKey(filepath.Join(path, MasterKey0), RawKey); err != nil {
+	if m.Key0, err = LoadKeyFromProvider(p, MasterKey0, RawKey); err != nil {
 		return m, err
 	}
-	if m.Key1, err = LoadKey(filepath.Join(path, MasterKey1), RawKey); err != nil {
+	if m.Key1, err = LoadKeyFromProvider(p, MasterKey1, RawKey); err != nil {
 		return m, err
 	}
 	return m, nil
@@ -0,0 +1,150 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+const (
+	// ProviderConfFile is the file in the keys directory that configures the
+	// key provider. If the file does not exist, the keys are read from the
+	// plain key files in the directory.
+	ProviderConfFile = "keystore.json"
+
+	// ProviderFile reads the keys from the base64 encoded key files.
+	ProviderFile = "file"
+	// ProviderEncrypted reads the keys from a passphrase-encrypted key file.
+	ProviderEncrypted = "encrypted"
+	// ProviderToken reads the keys from a PKCS#11-style token.
+	ProviderToken = "pkcs11"
+)
+
+// Provider provides access to the keys of an AS. The keys are identified by
+// the names of their key files, e.g., SigKeyFile. Providers that hold a
+// session, e.g., TokenProvider, implement io.Closer, see CloseProvider.
+type Provider interface {
+	// Key returns the raw key material stored under name. For ed25519 keys,
+	// this is the seed.
+	Key(name string) (common.RawBytes, error)
+}
+
+// ProviderConf is the configuration of the key provider stored in
+// ProviderConfFile. Relative paths are resolved against the keys directory.
+type ProviderConf struct {
+	// Type is the type of the key provider.
+	Type string
+	// File is the encrypted key file. Only used by ProviderEncrypted.
+	File string `json:",omitempty"`
+	// PassphraseFile contains the passphrase of the encrypted key file, or
+	// the PIN of the token.
+	PassphraseFile string `json:",omitempty"`
+	// Module is the name of the registered token module. Only used by
+	// ProviderToken.
+	Module string `json:",omitempty"`
+	// ModuleConf is passed to the token module when the token is opened.
+	// Only used by ProviderToken.
+	ModuleConf string `json:",omitempty"`
+}
+
+// NewProvider creates the key provider for the keys directory dir according
+// to the ProviderConfFile in dir.
+func NewProvider(dir string) (Provider, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(dir, ProviderConfFile))
+	if os.IsNotExist(err) {
+		return &FileProvider{Dir: dir}, nil
+	}
+	if err != nil {
+		return nil, common.NewBasicError("Unable to read key provider config", err)
+	}
+	cfg := &ProviderConf{}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return nil, common.NewBasicError("Unable to parse key provider config", err)
+	}
+	return cfg.New(dir)
+}
+
+// New creates the key provider for the keys directory dir.
+func (cfg *ProviderConf) New(dir string) (Provider, error) {
+	switch cfg.Type {
+	case ProviderFile, "":
+		return &FileProvider{Dir: dir}, nil
+	case ProviderEncrypted:
+		pass, err := readSecret(dir, cfg.PassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		file := cfg.File
+		if file == "" {
+			file = EncKeysFile
+		}
+		return NewEncryptedProvider(resolvePath(dir, file), []byte(pass))
+	case ProviderToken:
+		pin, err := readSecret(dir, cfg.PassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewTokenProvider(cfg.Module, cfg.ModuleConf, pin)
+	default:
+		return nil, common.NewBasicError("Unknown key provider type", nil, "type", cfg.Type)
+	}
+}
+
+// CloseProvider closes p, if it implements io.Closer. Otherwise, it is a
+// no-op.
+func CloseProvider(p Provider) error {
+	if c, ok := p.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// readSecret reads the passphrase or PIN stored in file. Trailing whitespace
+// is removed.
+func readSecret(dir, file string) (string, error) {
+	if file == "" {
+		return "", common.NewBasicError("No passphrase file configured", nil)
+	}
+	raw, err := ioutil.ReadFile(resolvePath(dir, file))
+	if err != nil {
+		return "", common.NewBasicError("Unable to read passphrase file", err)
+	}
+	return strings.TrimRight(string(raw), " \t\r\n"), nil
+}
+
+func resolvePath(dir, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+var _ Provider = (*FileProvider)(nil)
+
+// FileProvider reads the base64 encoded keys from the key files in Dir.
+type FileProvider struct {
+	Dir string
+}
+
+func (p *FileProvider) Key(name string) (common.RawBytes, error) {
+	return readKeyFile(filepath.Join(p.Dir, name))
+}
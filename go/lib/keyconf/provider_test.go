@@ -0,0 +1,163 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var keyNames = []string{DecKeyFile, SigKeyFile, IssSigKeyFile, OffKeyFile, OnKeyFile,
+	MasterKey0, MasterKey1}
+
+// lastToken is the token most recently opened by the soft token module.
+var lastToken *SoftToken
+
+func init() {
+	RegisterTokenModule("soft", func(conf string) (Token, error) {
+		keys, err := rawKeys()
+		if err != nil {
+			return nil, err
+		}
+		lastToken = NewSoftToken(conf, keys)
+		return lastToken, nil
+	})
+}
+
+func rawKeys() (map[string]common.RawBytes, error) {
+	p := &FileProvider{Dir: "testdata"}
+	keys := make(map[string]common.RawBytes)
+	for _, name := range keyNames {
+		key, err := p.Key(name)
+		if err != nil {
+			return nil, err
+		}
+		keys[name] = key
+	}
+	return keys, nil
+}
+
+func loadRawKeys(t *testing.T) map[string]common.RawBytes {
+	keys, err := rawKeys()
+	xtest.FailOnErr(t, err)
+	return keys
+}
+
+func writeProviderConf(t *testing.T, dir string, cfg *ProviderConf, secret string) {
+	raw, err := json.Marshal(cfg)
+	xtest.FailOnErr(t, err)
+	xtest.FailOnErr(t, ioutil.WriteFile(filepath.Join(dir, ProviderConfFile), raw, 0600))
+	xtest.FailOnErr(t, ioutil.WriteFile(filepath.Join(dir, "secret"), []byte(secret), 0600))
+}
+
+func Test_EncryptKeys(t *testing.T) {
+	Convey("Encrypted keys can only be decrypted with the passphrase", t, func() {
+		keys := loadRawKeys(t)
+		raw, err := EncryptKeys(keys, []byte("passphrase"))
+		SoMsg("err", err, ShouldBeNil)
+		Convey("Correct passphrase", func() {
+			dec, err := DecryptKeys(raw, []byte("passphrase"))
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("keys", dec, ShouldResemble, keys)
+		})
+		Convey("Wrong passphrase", func() {
+			_, err := DecryptKeys(raw, []byte("wrong"))
+			SoMsg("err", common.GetErrorMsg(err), ShouldEqual, ErrorDecrypt)
+		})
+	})
+	Convey("Files with excessive scrypt parameters are rejected", t, func() {
+		tests := []struct {
+			desc    string
+			n, r, p int
+		}{
+			{"N not a power of two", scryptN + 1, scryptR, scryptP},
+			{"N too large", maxScryptN << 1, scryptR, scryptP},
+			{"N too small", 1, scryptR, scryptP},
+			{"R too large", scryptN, maxScryptR + 1, scryptP},
+			{"P too large", scryptN, scryptR, maxScryptP + 1},
+			{"P zero", scryptN, scryptR, 0},
+		}
+		raw, err := EncryptKeys(loadRawKeys(t), []byte("passphrase"))
+		xtest.FailOnErr(t, err)
+		for _, test := range tests {
+			f := &encFile{}
+			xtest.FailOnErr(t, json.Unmarshal(raw, f))
+			f.N, f.R, f.P = test.n, test.r, test.p
+			mod, err := json.Marshal(f)
+			xtest.FailOnErr(t, err)
+			_, err = DecryptKeys(mod, []byte("passphrase"))
+			SoMsg(test.desc, err, ShouldNotBeNil)
+		}
+	})
+}
+
+func Test_NewProvider(t *testing.T) {
+	Convey("Keys are loaded from the configured provider", t, func() {
+		expected, err := Load("testdata", true, true, true, true)
+		xtest.FailOnErr(t, err)
+		dir, cleanF := xtest.MustTempDir("", "keyconf")
+		defer cleanF()
+
+		Convey("Encrypted key file", func() {
+			raw, err := EncryptKeys(loadRawKeys(t), []byte("passphrase"))
+			xtest.FailOnErr(t, err)
+			xtest.FailOnErr(t, ioutil.WriteFile(filepath.Join(dir, EncKeysFile), raw, 0600))
+			cfg := &ProviderConf{Type: ProviderEncrypted, PassphraseFile: "secret"}
+			Convey("Correct passphrase", func() {
+				writeProviderConf(t, dir, cfg, "passphrase\n")
+				c, err := Load(dir, true, true, true, true)
+				SoMsg("err", err, ShouldBeNil)
+				SoMsg("conf", c, ShouldResemble, expected)
+			})
+			Convey("Wrong passphrase", func() {
+				writeProviderConf(t, dir, cfg, "wrong")
+				_, err := Load(dir, true, true, true, true)
+				SoMsg("err", err, ShouldNotBeNil)
+			})
+		})
+		Convey("Token", func() {
+			cfg := &ProviderConf{Type: ProviderToken, Module: "soft", ModuleConf: "1234",
+				PassphraseFile: "secret"}
+			Convey("Correct PIN", func() {
+				writeProviderConf(t, dir, cfg, "1234")
+				c, err := Load(dir, true, true, true, true)
+				SoMsg("err", err, ShouldBeNil)
+				SoMsg("conf", c, ShouldResemble, expected)
+				SoMsg("logged out", lastToken.loggedIn, ShouldBeFalse)
+				_, err = LoadMaster(dir)
+				SoMsg("master err", err, ShouldBeNil)
+				SoMsg("master logged out", lastToken.loggedIn, ShouldBeFalse)
+			})
+			Convey("Wrong PIN", func() {
+				writeProviderConf(t, dir, cfg, "0000")
+				_, err := Load(dir, true, true, true, true)
+				SoMsg("err", err, ShouldNotBeNil)
+			})
+			Convey("Unknown module", func() {
+				cfg.Module = "unknown"
+				writeProviderConf(t, dir, cfg, "1234")
+				_, err := Load(dir, true, true, true, true)
+				SoMsg("err", err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"sync"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Token is a PKCS#11-style token that stores the keys as objects. The objects
+// are identified by their labels, which are the names of the key files. Since
+// the keys are used by the SCION crypto library directly, the key objects must
+// be extractable.
+type Token interface {
+	// Login authenticates to the token with the PIN.
+	Login(pin string) error
+	// FindObject returns the value of the object with the label.
+	FindObject(label string) (common.RawBytes, error)
+	// Logout ends the session with the token.
+	Logout() error
+}
+
+// TokenModule opens the token described by the module specific
+// configuration.
+type TokenModule func(conf string) (Token, error)
+
+var (
+	tokenModulesMtx sync.RWMutex
+	tokenModules    = make(map[string]TokenModule)
+)
+
+// RegisterTokenModule makes the token module available under name. It is
+// intended to be called from the init function of the package implementing
+// the module. Registering a module twice panics.
+func RegisterTokenModule(name string, module TokenModule) {
+	tokenModulesMtx.Lock()
+	defer tokenModulesMtx.Unlock()
+	if _, ok := tokenModules[name]; ok {
+		panic("Token module registered twice: " + name)
+	}
+	tokenModules[name] = module
+}
+
+var _ Provider = (*TokenProvider)(nil)
+
+// TokenProvider provides the keys stored on a token.
+type TokenProvider struct {
+	token Token
+}
+
+// NewTokenProvider opens the token with the registered module and logs in
+// with the PIN.
+func NewTokenProvider(module, conf, pin string) (*TokenProvider, error) {
+	tokenModulesMtx.RLock()
+	open, ok := tokenModules[module]
+	tokenModulesMtx.RUnlock()
+	if !ok {
+		return nil, common.NewBasicError("Unknown token module", nil, "module", module)
+	}
+	token, err := open(conf)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to open token", err, "module", module)
+	}
+	if err := token.Login(pin); err != nil {
+		return nil, common.NewBasicError("Unable to login to token", err, "module", module)
+	}
+	return &TokenProvider{token: token}, nil
+}
+
+func (p *TokenProvider) Key(name string) (common.RawBytes, error) {
+	return p.token.FindObject(name)
+}
+
+// Close logs out of the token.
+func (p *TokenProvider) Close() error {
+	return p.token.Logout()
+}
+
+var _ Token = (*SoftToken)(nil)
+
+// SoftToken is a software token that keeps the objects in memory. It can be
+// used to test token modules.
+type SoftToken struct {
+	mtx      sync.Mutex
+	pin      string
+	objects  map[string]common.RawBytes
+	loggedIn bool
+}
+
+// NewSoftToken creates a software token protected by pin that contains the
+// objects.
+func NewSoftToken(pin string, objects map[string]common.RawBytes) *SoftToken {
+	return &SoftToken{pin: pin, objects: objects}
+}
+
+func (t *SoftToken) Login(pin string) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if pin != t.pin {
+		return common.NewBasicError("Incorrect PIN", nil)
+	}
+	t.loggedIn = true
+	return nil
+}
+
+func (t *SoftToken) FindObject(label string) (common.RawBytes, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if !t.loggedIn {
+		return nil, common.NewBasicError("Not logged in", nil)
+	}
+	obj, ok := t.objects[label]
+	if !ok {
+		return nil, common.NewBasicError(ErrorNotFound, nil, "label", label)
+	}
+	return obj, nil
+}
+
+func (t *SoftToken) Logout() error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.loggedIn = false
+	return nil
+}
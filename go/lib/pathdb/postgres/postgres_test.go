@@ -0,0 +1,60 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/pathdb/pathdbtest"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+// connectionEnv is the environment variable containing the connection string
+// of the PostgreSQL database used for testing. If it is not set, the tests are
+// skipped.
+const connectionEnv = "SCION_TEST_POSTGRES"
+
+var _ pathdbtest.TestablePathDB = (*TestPathDB)(nil)
+
+type TestPathDB struct {
+	*Backend
+	connection string
+}
+
+func (b *TestPathDB) Prepare(t *testing.T, ctx context.Context) {
+	if b.Backend != nil {
+		b.Backend.Close()
+	}
+	db, err := New(b.connection)
+	xtest.FailOnErr(t, err)
+	_, err = db.db.ExecContext(ctx, "TRUNCATE Segments, IntfToSeg, SegTypes, HpCfgIds, NextQuery")
+	xtest.FailOnErr(t, err)
+	b.Backend = db
+}
+
+func TestPathDBSuite(t *testing.T) {
+	connection := os.Getenv(connectionEnv)
+	if connection == "" {
+		t.Skipf("%s not set", connectionEnv)
+	}
+	tdb := &TestPathDB{connection: connection}
+	Convey("PathDBSuite", t, func() {
+		pathdbtest.TestPathDB(t, tdb)
+	})
+}
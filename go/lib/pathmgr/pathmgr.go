@@ -101,9 +101,10 @@ type Resolver interface {
 	Watch(ctx context.Context, src, dst addr.IA) (*SyncPaths, error)
 	// WatchFilter returns a pointer to a SyncPaths object that contains paths from
 	// src to dst that adhere to the specified filter. On path changes the list is
-	// refreshed automatically.
+	// refreshed automatically. The paths are additionally provided in the order
+	// defined by the filter's ordering.
 	//
-	// A nil filter will not delete any paths.
+	// A nil filter will not delete any paths and uses the default ordering.
 	WatchFilter(ctx context.Context, src, dst addr.IA, filter *pathpol.Policy) (*SyncPaths, error)
 	// WatchCount returns the number of active watchers.
 	WatchCount() int
@@ -173,6 +174,7 @@ func (r *resolver) WatchFilter(ctx context.Context, src, dst addr.IA,
 		aps = filter.Act(aps).(spathmeta.AppPathSet)
 	}
 	sp := NewSyncPaths()
+	sp.policy = filter
 	sp.update(aps)
 
 	query := &queryConfig{
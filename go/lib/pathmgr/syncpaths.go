@@ -19,6 +19,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/spath/spathmeta"
 )
 
@@ -37,12 +38,18 @@ type SyncPaths struct {
 	mutex sync.Mutex
 	// Destructor is called to destroy the object
 	destructor func()
+	// policy is used to order the paths. If it is nil, the default ordering
+	// is used.
+	policy *pathpol.Policy
 }
 
 // SyncPathsData is the atomic value inside a SyncPaths object. It provides a
-// snapshot of a SyncPaths object. Callers must not change APS.
+// snapshot of a SyncPaths object. Callers must not change APS or Ordered.
 type SyncPathsData struct {
-	APS         spathmeta.AppPathSet
+	APS spathmeta.AppPathSet
+	// Ordered contains the paths in APS in order of preference, as defined by
+	// the ordering of the policy the paths are filtered with.
+	Ordered     []*spathmeta.AppPath
 	ModifyTime  time.Time
 	RefreshTime time.Time
 }
@@ -78,6 +85,7 @@ func (sp *SyncPaths) update(newAPS spathmeta.AppPathSet) {
 		value.ModifyTime = value.RefreshTime
 	}
 	value.APS = newAPS
+	value.Ordered = sp.policy.Order(newAPS)
 	sp.value.Store(value)
 }
 
@@ -29,22 +29,32 @@ type ACL struct {
 
 // NewACL creates a new entry and checks for the presence of a default action
 func NewACL(entries ...*ACLEntry) (*ACL, error) {
-	lastRule := entries[len(entries)-1].Rule
-	if lastRule.IfIDs[0] != 0 || lastRule.ISD != 0 || lastRule.AS != 0 {
+	lastEntry := entries[len(entries)-1]
+	lastRule := lastEntry.Rule
+	if lastEntry.Attr != nil || (lastRule != nil &&
+		(lastRule.IfIDs[0] != 0 || lastRule.ISD != 0 || lastRule.AS != 0)) {
 		return nil, common.NewBasicError("ACL does not have a default", nil)
 	}
 	return &ACL{Entries: entries}, nil
 }
 
-// Eval returns the set of paths that match the ACL.
+// Eval returns the set of paths that match the ACL. Attribute predicates never
+// match, use Policy.Act to evaluate them against the attribute database of the
+// policy.
 func (a *ACL) Eval(inputSet spathmeta.AppPathSet) spathmeta.AppPathSet {
+	return a.eval(inputSet, nil)
+}
+
+// eval returns the set of paths that match the ACL, the attribute predicates
+// are evaluated against db.
+func (a *ACL) eval(inputSet spathmeta.AppPathSet, db AttributeDB) spathmeta.AppPathSet {
 	resultSet := make(spathmeta.AppPathSet)
 	if a == nil || len(a.Entries) == 0 {
 		return inputSet
 	}
 	for key, path := range inputSet {
 		// Check ACL
-		if a.evalPath(path) {
+		if a.evalPath(path, db) {
 			resultSet[key] = path
 		}
 	}
@@ -59,27 +69,32 @@ func (a *ACL) UnmarshalJSON(b []byte) error {
 	return json.Unmarshal(b, &a.Entries)
 }
 
-func (a *ACL) evalPath(path *spathmeta.AppPath) ACLAction {
+func (a *ACL) evalPath(path *spathmeta.AppPath, db AttributeDB) ACLAction {
 	for i, iface := range path.Entry.Path.Interfaces {
-		if a.evalInterface(iface, i%2 != 0) == Deny {
+		if a.evalInterface(iface, i%2 != 0, db) == Deny {
 			return Deny
 		}
 	}
 	return Allow
 }
 
-func (a *ACL) evalInterface(iface sciond.PathInterface, ingress bool) ACLAction {
+func (a *ACL) evalInterface(iface sciond.PathInterface, ingress bool,
+	db AttributeDB) ACLAction {
+
 	for _, aclEntry := range a.Entries {
-		if aclEntry.Rule.pathIFMatch(iface, ingress) {
+		if aclEntry.match(iface, ingress, db) {
 			return aclEntry.Action
 		}
 	}
 	panic("Default ACL action missing")
 }
 
+// ACLEntry is an entry of an ACL. It either matches interfaces by a hop
+// predicate (Rule) or by the labels of the AS in the attribute database (Attr).
 type ACLEntry struct {
 	Action ACLAction
 	Rule   *HopPredicate
+	Attr   *AttributePredicate
 }
 
 func (ae *ACLEntry) LoadFromString(str string) error {
@@ -93,6 +108,10 @@ func (ae *ACLEntry) LoadFromString(str string) error {
 		if err != nil {
 			return err
 		}
+		if isAttributePredicate(parts[1]) {
+			ae.Attr, err = AttributePredicateFromString(parts[1])
+			return err
+		}
 		ae.Rule, err = HopPredicateFromString(parts[1])
 		return err
 	}
@@ -104,12 +123,27 @@ func (ae *ACLEntry) String() string {
 	if ae.Action == Allow {
 		str = allowSymbol
 	}
-	if ae.Rule != nil {
+	if ae.Attr != nil {
+		str = str + " " + ae.Attr.String()
+	} else if ae.Rule != nil {
 		str = str + " " + ae.Rule.String()
 	}
 	return str
 }
 
+// match returns true if the entry matches the PathInterface. Attribute
+// predicates are evaluated against db.
+func (ae *ACLEntry) match(iface sciond.PathInterface, ingress bool, db AttributeDB) bool {
+	if ae.Attr != nil {
+		return ae.Attr.match(iface.ISD_AS(), db)
+	}
+	// An entry without a rule (e.g. "+") matches everything.
+	if ae.Rule == nil {
+		return true
+	}
+	return ae.Rule.pathIFMatch(iface, ingress)
+}
+
 func (ae *ACLEntry) MarshalJSON() ([]byte, error) {
 	return json.Marshal(ae.String())
 }
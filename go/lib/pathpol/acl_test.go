@@ -46,6 +46,20 @@ func TestACLEntryLoadFromString(t *testing.T) {
 			String:   "+",
 			ACLEntry: ACLEntry{Action: Allow},
 		},
+		{
+			Name:   "Allow country=CH",
+			String: "+ country=CH",
+			ACLEntry: ACLEntry{
+				Action: Allow,
+				Attr:   &AttributePredicate{Key: "country", Value: "CH"},
+			},
+		},
+		{
+			Name:     "Bad attribute predicate",
+			String:   "- =CH",
+			ACLEntry: ACLEntry{Action: Deny},
+			Error:    true,
+		},
 		{
 			Name:     "Allow none",
 			String:   "- 0",
@@ -83,4 +97,8 @@ func TestACLEntryString(t *testing.T) {
 		aclEntry := &ACLEntry{Action: true, Rule: &HopPredicate{IfIDs: []common.IFIDType{0}}}
 		SoMsg("aclEntry", aclEntryString, ShouldResemble, aclEntry.String())
 	})
+	Convey("TestACLEntryString attribute", t, func() {
+		aclEntry := &ACLEntry{Action: Deny, Attr: &AttributePredicate{Key: "tier", Value: "3"}}
+		SoMsg("aclEntry", aclEntry.String(), ShouldEqual, "- tier=3")
+	})
 }
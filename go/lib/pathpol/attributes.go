@@ -0,0 +1,148 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Labels are the attributes of an AS, e.g. its country, operator or trust
+// tier. Keys and values are arbitrary strings.
+type Labels map[string]string
+
+// attrPredRE matches attribute predicates. Keys start with a letter, such that
+// they cannot be confused with the ISD of a hop predicate.
+var attrPredRE = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9_.:-]*=[a-zA-Z0-9_.:-]+`)
+
+// AttributeDB maps ASes to their labels. It is used to evaluate attribute
+// predicates in path policies, see Policy.Attributes. In a nil database no AS
+// has labels, i.e. attribute predicates never match.
+type AttributeDB map[addr.IA]Labels
+
+// LoadAttributeDB parses the attribute database in the file. The file is a
+// YAML (or JSON) mapping from ISD-AS strings to labels, e.g.:
+//
+//   1-ff00:0:110:
+//     country: CH
+//     operator: example
+//     tier: 1
+func LoadAttributeDB(fileName string) (AttributeDB, error) {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to read from file", err, "name", fileName)
+	}
+	return ParseAttributeDB(b)
+}
+
+// ParseAttributeDB parses the attribute database in YAML (or JSON) format.
+func ParseAttributeDB(b common.RawBytes) (AttributeDB, error) {
+	var raw map[string]Labels
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, common.NewBasicError("Unable to parse attribute database", err)
+	}
+	db := make(AttributeDB, len(raw))
+	for rawIA, labels := range raw {
+		ia, err := addr.IAFromString(rawIA)
+		if err != nil {
+			return nil, common.NewBasicError("Unable to parse ISD-AS", err, "ISDAS", rawIA)
+		}
+		db[ia] = labels
+	}
+	return db, nil
+}
+
+// labelString returns the labels of the AS in the form {key1=value1,key2=value2}
+// sorted by key. It is used to match attribute predicates in sequences. Labels
+// that cannot be expressed as an attribute predicate are omitted.
+func (db AttributeDB) labelString(ia addr.IA) string {
+	var labels []string
+	for key, value := range db[ia] {
+		label := key + "=" + value
+		if attrPredRE.FindString(label) == label {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// An AttributePredicate matches all ASes that have a label with the given key
+// and value in the attribute database, see docs/PathPolicy.md.
+type AttributePredicate struct {
+	Key   string
+	Value string
+}
+
+// AttributePredicateFromString parses an attribute predicate of the form
+// key=value.
+func AttributePredicateFromString(str string) (*AttributePredicate, error) {
+	if attrPredRE.FindString(str) != str {
+		return nil, common.NewBasicError("Failed to parse attribute predicate", nil,
+			"value", str)
+	}
+	parts := strings.Split(str, "=")
+	return &AttributePredicate{Key: parts[0], Value: parts[1]}, nil
+}
+
+// isAttributePredicate returns whether the string is an attribute predicate
+// rather than a hop predicate.
+func isAttributePredicate(str string) bool {
+	return strings.Contains(str, "=")
+}
+
+// match returns true if the AS has the label of the predicate in the database.
+func (ap *AttributePredicate) match(ia addr.IA, db AttributeDB) bool {
+	value, ok := db[ia][ap.Key]
+	return ok && value == ap.Value
+}
+
+// labelRegexp returns the regular expression that matches the labels of an AS in
+// the form produced by AttributeDB.labelString if the AS has the label of the
+// predicate.
+func (ap *AttributePredicate) labelRegexp() string {
+	return fmt.Sprintf(`\{([^ ,}]+,)*%s(,[^ ,}]+)*\}`, regexp.QuoteMeta(ap.String()))
+}
+
+func (ap AttributePredicate) String() string {
+	return ap.Key + "=" + ap.Value
+}
+
+func (ap *AttributePredicate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ap.String())
+}
+
+func (ap *AttributePredicate) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	nap, err := AttributePredicateFromString(str)
+	if err != nil {
+		return err
+	}
+	*ap = *nap
+	return nil
+}
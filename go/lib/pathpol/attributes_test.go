@@ -0,0 +1,194 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+const testAttributes = `
+1-ff00:0:110:
+  country: XX
+  tier: 1
+1-ff00:0:111:
+  country: CH
+  tier: 2
+1-ff00:0:133:
+  country: CH
+2-ff00:0:222:
+  country: CH
+`
+
+func TestParseAttributeDB(t *testing.T) {
+	Convey("TestParseAttributeDB", t, func() {
+		Convey("Valid database", func() {
+			db, err := ParseAttributeDB([]byte(testAttributes))
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("len", len(db), ShouldEqual, 4)
+			SoMsg("labels", db[xtest.MustParseIA("1-ff00:0:110")], ShouldResemble,
+				Labels{"country": "XX", "tier": "1"})
+		})
+		Convey("Invalid ISD-AS", func() {
+			_, err := ParseAttributeDB([]byte("1-ff00:0:1:10:\n  country: CH\n"))
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestAttributeACLEval(t *testing.T) {
+	db, err := ParseAttributeDB([]byte(testAttributes))
+	xtest.FailOnErr(t, err)
+	testCases := []struct {
+		Name       string
+		ACL        string
+		ExpPathNum int
+	}{
+		{
+			Name:       "deny country=XX, allow rest",
+			ACL:        `["- country=XX", "+"]`,
+			ExpPathNum: 2,
+		},
+		{
+			Name:       "deny tier=2, allow rest",
+			ACL:        `["- tier=2", "+"]`,
+			ExpPathNum: 0,
+		},
+		{
+			Name:       "allow country=CH, deny rest",
+			ACL:        `["+ country=CH", "-"]`,
+			ExpPathNum: 0,
+		},
+		{
+			Name:       "deny unknown label, allow rest",
+			ACL:        `["- operator=unknown", "+"]`,
+			ExpPathNum: 2,
+		},
+	}
+
+	Convey("TestAttributeACLEval", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		conn := testGetSCIONDConn(t, ctrl)
+		src, dst := xtest.MustParseIA("1-ff00:0:133"), xtest.MustParseIA("2-ff00:0:222")
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				var acl ACL
+				xtest.FailOnErr(t, json.Unmarshal([]byte(tc.ACL), &acl))
+				paths, err := conn.Paths(context.Background(), dst, src, 5,
+					sciond.PathReqFlags{})
+				SoMsg("sciond err", err, ShouldBeNil)
+
+				inAPS := spathmeta.NewAppPathSet(paths)
+				policy := &Policy{ACL: &acl, Attributes: db}
+				outAPS := policy.Act(inAPS).(spathmeta.AppPathSet)
+				SoMsg("paths", len(outAPS), ShouldEqual, tc.ExpPathNum)
+			})
+		}
+	})
+}
+
+func TestAttributeSequenceEval(t *testing.T) {
+	db, err := ParseAttributeDB([]byte(testAttributes))
+	xtest.FailOnErr(t, err)
+	direct := newOrderTestPath(t, 1400, 100, "1-ff00:0:110#1", "1-ff00:0:111#2")
+	viaCH := newOrderTestPath(t, 1400, 100, "1-ff00:0:110#2", "2-ff00:0:222#3",
+		"2-ff00:0:222#4", "1-ff00:0:111#5")
+	viaUnknown := newOrderTestPath(t, 1400, 100, "1-ff00:0:110#3", "1-ff00:0:112#6",
+		"1-ff00:0:112#7", "1-ff00:0:111#8")
+	aps := spathmeta.AppPathSet{}
+	for _, p := range []*spathmeta.AppPath{direct, viaCH, viaUnknown} {
+		aps[p.Key()] = p
+	}
+	testCases := []struct {
+		Name     string
+		Seq      string
+		Expected []*spathmeta.AppPath
+	}{
+		{
+			Name:     "attributes with wildcard",
+			Seq:      "country=XX 0* country=CH",
+			Expected: []*spathmeta.AppPath{direct, viaCH, viaUnknown},
+		},
+		{
+			Name:     "attribute with operator",
+			Seq:      "country=XX country=CH+",
+			Expected: []*spathmeta.AppPath{direct, viaCH},
+		},
+		{
+			Name:     "attribute with other labels",
+			Seq:      "tier=1 tier=2",
+			Expected: []*spathmeta.AppPath{direct},
+		},
+		{
+			Name:     "attribute or hop predicate",
+			Seq:      "1-ff00:0:110#0 (country=CH|1-ff00:0:112) 1-ff00:0:111",
+			Expected: []*spathmeta.AppPath{viaCH, viaUnknown},
+		},
+		{
+			Name:     "unknown label",
+			Seq:      "0+ country=DE 0+",
+			Expected: []*spathmeta.AppPath{},
+		},
+	}
+	Convey("TestAttributeSequenceEval", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				seq, err := NewSequence(tc.Seq)
+				SoMsg("err", err, ShouldBeNil)
+				policy := &Policy{Sequence: seq, Attributes: db}
+				outAPS := policy.Act(aps).(spathmeta.AppPathSet)
+				expected := spathmeta.AppPathSet{}
+				for _, p := range tc.Expected {
+					expected[p.Key()] = p
+				}
+				SoMsg("paths", outAPS, ShouldResemble, expected)
+			})
+		}
+		Convey("Attributes never match without database", func() {
+			seq, err := NewSequence("country=XX 0* country=CH")
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("paths", len(seq.Eval(aps)), ShouldEqual, 0)
+		})
+		Convey("Options use the database of the enclosing policy", func() {
+			seq, err := NewSequence("country=XX country=CH+")
+			SoMsg("err", err, ShouldBeNil)
+			policy := &Policy{
+				Options:    []Option{{Weight: 1, Policy: &Policy{Sequence: seq}}},
+				Attributes: db,
+			}
+			SoMsg("paths", len(policy.Act(aps).(spathmeta.AppPathSet)), ShouldEqual, 2)
+		})
+		Convey("Sequence with attributes is marshaled unchanged", func() {
+			seq, err := NewSequence("country=XX 0*")
+			SoMsg("err", err, ShouldBeNil)
+			b, err := json.Marshal(seq)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("json", string(b), ShouldEqual, `"country=XX 0*"`)
+		})
+		Convey("Invalid attribute predicate", func() {
+			_, err := NewSequence("country= 0*")
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
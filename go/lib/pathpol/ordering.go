@@ -0,0 +1,153 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+const (
+	ascendingSymbol  = "+"
+	descendingSymbol = "-"
+)
+
+// OrderAttribute is a path attribute by which paths can be ordered.
+type OrderAttribute string
+
+const (
+	// OrderHops orders paths by the number of interfaces on the path.
+	OrderHops OrderAttribute = "hops"
+	// OrderMTU orders paths by their MTU.
+	OrderMTU OrderAttribute = "mtu"
+	// OrderExp orders paths by their expiration time.
+	OrderExp OrderAttribute = "exp"
+	// OrderISD orders paths by the number of distinct ISDs they traverse.
+	OrderISD OrderAttribute = "isd"
+)
+
+// DefaultOrdering is used to order paths if a policy does not specify an
+// ordering. It prefers the paths with the fewest hops, then the ones that
+// expire last.
+var DefaultOrdering = Ordering{
+	{Attribute: OrderHops},
+	{Attribute: OrderExp, Descending: true},
+}
+
+// Ordering is a list of order entries. Paths are sorted by the first entry,
+// ties are broken by the subsequent entries. Paths that are equal according to
+// all entries are sorted by their key, such that the resulting order is
+// always deterministic.
+type Ordering []*OrderEntry
+
+// Sort returns the paths in the set sorted according to the ordering.
+func (o Ordering) Sort(inputSet spathmeta.AppPathSet) []*spathmeta.AppPath {
+	paths := make([]*spathmeta.AppPath, 0, len(inputSet))
+	keys := make(map[*spathmeta.AppPath]spathmeta.PathKey, len(inputSet))
+	for key, path := range inputSet {
+		paths = append(paths, path)
+		keys[path] = key
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		for _, entry := range o {
+			if c := entry.compare(paths[i], paths[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return keys[paths[i]] < keys[paths[j]]
+	})
+	return paths
+}
+
+// OrderEntry orders paths by an attribute, either ascending or descending.
+type OrderEntry struct {
+	Attribute  OrderAttribute
+	Descending bool
+}
+
+// LoadFromString parses an order entry of the form "+attr" or "-attr". If the
+// direction symbol is omitted, ascending order is used.
+func (oe *OrderEntry) LoadFromString(str string) error {
+	oe.Descending = false
+	if len(str) > 0 {
+		switch str[:1] {
+		case ascendingSymbol:
+			str = str[1:]
+		case descendingSymbol:
+			oe.Descending = true
+			str = str[1:]
+		}
+	}
+	attr := OrderAttribute(str)
+	switch attr {
+	case OrderHops, OrderMTU, OrderExp, OrderISD:
+		oe.Attribute = attr
+		return nil
+	}
+	return common.NewBasicError("Unknown order attribute", nil, "attribute", str)
+}
+
+func (oe *OrderEntry) String() string {
+	if oe.Descending {
+		return descendingSymbol + string(oe.Attribute)
+	}
+	return ascendingSymbol + string(oe.Attribute)
+}
+
+func (oe *OrderEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(oe.String())
+}
+
+func (oe *OrderEntry) UnmarshalJSON(b []byte) error {
+	var str string
+	err := json.Unmarshal(b, &str)
+	if err != nil {
+		return err
+	}
+	return oe.LoadFromString(str)
+}
+
+// compare returns a negative number if path a is preferred over path b, a
+// positive number if b is preferred over a, and 0 if they are equal.
+func (oe *OrderEntry) compare(a, b *spathmeta.AppPath) int64 {
+	c := oe.value(a) - oe.value(b)
+	if oe.Descending {
+		return -c
+	}
+	return c
+}
+
+func (oe *OrderEntry) value(path *spathmeta.AppPath) int64 {
+	fwdPath := path.Entry.Path
+	switch oe.Attribute {
+	case OrderHops:
+		return int64(len(fwdPath.Interfaces))
+	case OrderMTU:
+		return int64(fwdPath.Mtu)
+	case OrderExp:
+		return int64(fwdPath.ExpTime)
+	case OrderISD:
+		isds := make(map[addr.ISD]struct{})
+		for _, iface := range fwdPath.Interfaces {
+			isds[iface.ISD_AS().I] = struct{}{}
+		}
+		return int64(len(isds))
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestOrderingSort(t *testing.T) {
+	short := newOrderTestPath(t, 1400, 100, "1-ff00:0:110#1", "1-ff00:0:111#2")
+	longMultiISD := newOrderTestPath(t, 1500, 300, "1-ff00:0:110#2", "2-ff00:0:210#3",
+		"2-ff00:0:210#4", "1-ff00:0:111#5")
+	longSingleISD := newOrderTestPath(t, 1300, 200, "1-ff00:0:110#3", "1-ff00:0:112#6",
+		"1-ff00:0:112#7", "1-ff00:0:111#8")
+	aps := spathmeta.AppPathSet{}
+	for _, p := range []*spathmeta.AppPath{short, longMultiISD, longSingleISD} {
+		aps[p.Key()] = p
+	}
+
+	testCases := []struct {
+		Name     string
+		Ordering Ordering
+		Expected []*spathmeta.AppPath
+	}{
+		{
+			Name:     "default ordering",
+			Ordering: DefaultOrdering,
+			Expected: []*spathmeta.AppPath{short, longMultiISD, longSingleISD},
+		},
+		{
+			Name:     "descending mtu",
+			Ordering: Ordering{{Attribute: OrderMTU, Descending: true}},
+			Expected: []*spathmeta.AppPath{longMultiISD, short, longSingleISD},
+		},
+		{
+			Name:     "ascending mtu",
+			Ordering: Ordering{{Attribute: OrderMTU}},
+			Expected: []*spathmeta.AppPath{longSingleISD, short, longMultiISD},
+		},
+		{
+			Name:     "ascending isd, descending exp",
+			Ordering: Ordering{{Attribute: OrderISD}, {Attribute: OrderExp, Descending: true}},
+			Expected: []*spathmeta.AppPath{longSingleISD, short, longMultiISD},
+		},
+		{
+			Name:     "descending hops, ascending exp",
+			Ordering: Ordering{{Attribute: OrderHops, Descending: true}, {Attribute: OrderExp}},
+			Expected: []*spathmeta.AppPath{longSingleISD, longMultiISD, short},
+		},
+	}
+	Convey("TestOrderingSort", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				SoMsg("order", tc.Ordering.Sort(aps), ShouldResemble, tc.Expected)
+			})
+		}
+		Convey("equal paths are sorted deterministically", func() {
+			other := newOrderTestPath(t, 1400, 100, "1-ff00:0:110#9", "1-ff00:0:111#9")
+			set := spathmeta.AppPathSet{short.Key(): short, other.Key(): other}
+			first := DefaultOrdering.Sort(set)
+			for i := 0; i < 10; i++ {
+				SoMsg("order", DefaultOrdering.Sort(set), ShouldResemble, first)
+			}
+		})
+		Convey("nil policy uses default ordering", func() {
+			var policy *Policy
+			SoMsg("order", policy.Order(aps), ShouldResemble, DefaultOrdering.Sort(aps))
+		})
+	})
+}
+
+func TestOrderingJSON(t *testing.T) {
+	Convey("TestOrderingJSON", t, func() {
+		Convey("Unmarshal valid ordering", func() {
+			var o Ordering
+			err := json.Unmarshal([]byte(`["hops", "-exp", "+mtu"]`), &o)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("ordering", o, ShouldResemble, Ordering{
+				{Attribute: OrderHops},
+				{Attribute: OrderExp, Descending: true},
+				{Attribute: OrderMTU},
+			})
+			b, err := json.Marshal(o)
+			SoMsg("marshal err", err, ShouldBeNil)
+			SoMsg("marshal", string(b), ShouldEqual, `["+hops","-exp","+mtu"]`)
+		})
+		Convey("Unmarshal unknown attribute", func() {
+			var o Ordering
+			err := json.Unmarshal([]byte(`["-bw"]`), &o)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func newOrderTestPath(t *testing.T, mtu uint16, expTime uint32,
+	ifaces ...string) *spathmeta.AppPath {
+
+	entry := &sciond.PathReplyEntry{
+		Path: &sciond.FwdPathMeta{
+			Mtu:     mtu,
+			ExpTime: expTime,
+		},
+	}
+	for _, str := range ifaces {
+		iface, err := sciond.NewPathInterface(str)
+		xtest.FailOnErr(t, err)
+		entry.Path.Interfaces = append(entry.Path.Interfaces, iface)
+	}
+	return &spathmeta.AppPath{Entry: entry}
+}
@@ -13,9 +13,10 @@
 // limitations under the License.
 
 // Package pathpol implements path policies, documentation in doc/PathPolicy.md
-// Currently implemented: ACL, Sequence, Extends and Options.
+// Currently implemented: ACL, Sequence, Extends, Options and Ordering.
 //
-// A policy has an Act() method that takes an AppPathSet and returns a filtered AppPathSet
+// A policy has an Act() method that takes an AppPathSet and returns a filtered AppPathSet and
+// an Order() method that returns the paths of an AppPathSet in order of preference.
 package pathpol
 
 import (
@@ -43,6 +44,12 @@ type Policy struct {
 	ACL      *ACL      `json:",omitempty"`
 	Sequence *Sequence `json:",omitempty"`
 	Options  []Option  `json:",omitempty"`
+	Ordering Ordering  `json:",omitempty"`
+	// Attributes is the attribute database that the attribute predicates of
+	// the ACL and the sequence are evaluated against. Options without an
+	// attribute database use the one of the enclosing policy. It is not part
+	// of the JSON representation, applications load it with LoadAttributeDB.
+	Attributes AttributeDB `json:"-"`
 }
 
 // NewPolicy creates a Policy and sorts its Options
@@ -57,20 +64,37 @@ func NewPolicy(name string, acl *ACL, sequence *Sequence, options []Option) *Pol
 
 // Act filters the path set according the policy
 func (p *Policy) Act(values interface{}) interface{} {
-	inputSet := values.(spathmeta.AppPathSet)
+	return p.act(values.(spathmeta.AppPathSet), nil)
+}
+
+// act filters the path set according to the policy. If the policy has no
+// attribute database, db is used.
+func (p *Policy) act(inputSet spathmeta.AppPathSet, db AttributeDB) spathmeta.AppPathSet {
+	if p.Attributes != nil {
+		db = p.Attributes
+	}
 	// Filter on ACL
-	resultSet := p.ACL.Eval(inputSet)
+	resultSet := p.ACL.eval(inputSet, db)
 	// Filter on Sequence
 	if p.Sequence != nil {
-		resultSet = p.Sequence.Eval(resultSet)
+		resultSet = p.Sequence.eval(resultSet, db)
 	}
 	// Filter on sub policies
 	if len(p.Options) > 0 {
-		resultSet = p.evalOptions(resultSet)
+		resultSet = p.evalOptions(resultSet, db)
 	}
 	return resultSet
 }
 
+// Order returns the paths in the set sorted according to the ordering of the policy. If the policy
+// is nil or does not specify an ordering, DefaultOrdering is used.
+func (p *Policy) Order(inputSet spathmeta.AppPathSet) []*spathmeta.AppPath {
+	if p == nil || len(p.Ordering) == 0 {
+		return DefaultOrdering.Sort(inputSet)
+	}
+	return p.Ordering.Sort(inputSet)
+}
+
 // PolicyFromExtPolicy creates a Policy from an extending Policy and the extended policies
 func PolicyFromExtPolicy(extPolicy *ExtPolicy, extended []*ExtPolicy) (*Policy, error) {
 	policy := extPolicy.Policy
@@ -116,13 +140,19 @@ func (p *Policy) applyExtended(extends []string, exPolicies []*ExtPolicy) error
 		if p.Sequence == nil {
 			p.Sequence = policy.Sequence
 		}
+		// Replace Ordering
+		if len(p.Ordering) == 0 {
+			p.Ordering = policy.Ordering
+		}
 	}
 	return nil
 }
 
 // evalOptions evaluates the options of a policy and returns the pathSet that matches the option
-// with the highest weight
-func (p *Policy) evalOptions(inputSet spathmeta.AppPathSet) spathmeta.AppPathSet {
+// with the highest weight. The options are evaluated with the attribute database db.
+func (p *Policy) evalOptions(inputSet spathmeta.AppPathSet,
+	db AttributeDB) spathmeta.AppPathSet {
+
 	subPolicySet := make(spathmeta.AppPathSet)
 	currWeight := p.Options[0].Weight
 	// Go through sub policies
@@ -131,7 +161,7 @@ func (p *Policy) evalOptions(inputSet spathmeta.AppPathSet) spathmeta.AppPathSet
 			break
 		}
 		currWeight = option.Weight
-		subPaths := option.Policy.Act(inputSet).(spathmeta.AppPathSet)
+		subPaths := option.Policy.act(inputSet, db)
 		for key, path := range subPaths {
 			subPolicySet[key] = path
 		}
@@ -350,8 +350,8 @@ func TestSequenceEval(t *testing.T) {
 	})
 }
 
-var allowEntry = &ACLEntry{ACLAction(true), NewHopPredicate()}
-var denyEntry = &ACLEntry{ACLAction(false), NewHopPredicate()}
+var allowEntry = &ACLEntry{Action: Allow, Rule: NewHopPredicate()}
+var denyEntry = &ACLEntry{Action: Deny, Rule: NewHopPredicate()}
 
 func TestACLEval(t *testing.T) {
 	testCases := []struct {
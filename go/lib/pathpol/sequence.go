@@ -24,6 +24,7 @@ import (
 
 	"github.com/antlr/antlr4/runtime/Go/antlr"
 
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathpol/sequence"
@@ -34,12 +35,22 @@ const (
 	isdWildcard = "([0-9]+)"
 	asWildcard  = "(([0-9]+)|([0-9a-fA-F]+:[0-9a-fA-F]+:[0-9a-fA-F]+))"
 	ifWildcard  = "([0-9]+)"
+	// labelsWildcard matches the labels of an AS, see AttributeDB.labelString.
+	labelsWildcard = "(\\{[^ ]*\\})?"
+	// attrPlaceholderBase is the first ISD number that is used as a placeholder
+	// for an attribute predicate while parsing a sequence. The sequence grammar
+	// only knows hop predicates, thus, every attribute predicate is substituted
+	// by an ISD-level hop predicate with a number that does not fit into the
+	// 16 bits of an ISD.
+	attrPlaceholderBase = 1 << 16
 )
 
 type Sequence struct {
 	re     *regexp.Regexp
 	srcstr string
 	restr  string
+	// attrs indicates whether the sequence contains attribute predicates.
+	attrs bool
 }
 
 // NewSequence creates a new sequence from a string
@@ -48,7 +59,8 @@ func NewSequence(s string) (*Sequence, error) {
 	if s == "" {
 		return &Sequence{}, nil
 	}
-	istream := antlr.NewInputStream(s)
+	src, attrs := substituteAttributes(s)
+	istream := antlr.NewInputStream(src)
 	lexer := sequence.NewSequenceLexer(istream)
 	lexer.RemoveErrorListeners()
 	errListener := &errorListener{}
@@ -57,7 +69,7 @@ func NewSequence(s string) (*Sequence, error) {
 	parser := sequence.NewSequenceParser(tstream)
 	parser.RemoveErrorListeners()
 	parser.AddErrorListener(errListener)
-	listener := sequenceListener{}
+	listener := sequenceListener{attrs: attrs}
 	antlr.ParseTreeWalkerDefault.Walk(&listener, parser.Start())
 	if errListener.msg != "" {
 		return nil, common.NewBasicError("Failed to parse a sequence", nil,
@@ -70,11 +82,34 @@ func NewSequence(s string) (*Sequence, error) {
 		return nil, common.NewBasicError("Error while parsing sequence regexp", err,
 			"regexp", restr)
 	}
-	return &Sequence{re: re, srcstr: s, restr: restr}, nil
+	return &Sequence{re: re, srcstr: s, restr: restr, attrs: len(attrs) > 0}, nil
 }
 
-// Eval evaluates the interface sequence list and returns the set of paths that match the list
+// substituteAttributes replaces the attribute predicates in the sequence by
+// placeholder hop predicates. It returns the resulting sequence and the
+// attribute predicates keyed by their placeholder.
+func substituteAttributes(s string) (string, map[string]*AttributePredicate) {
+	attrs := make(map[string]*AttributePredicate)
+	src := attrPredRE.ReplaceAllStringFunc(s, func(str string) string {
+		// The regexp only matches valid attribute predicates.
+		ap, _ := AttributePredicateFromString(str)
+		placeholder := fmt.Sprint(attrPlaceholderBase + len(attrs))
+		attrs[placeholder] = ap
+		return placeholder
+	})
+	return src, attrs
+}
+
+// Eval evaluates the interface sequence list and returns the set of paths that match the list.
+// Attribute predicates never match, use Policy.Act to evaluate them against the attribute
+// database of the policy.
 func (s *Sequence) Eval(inputSet spathmeta.AppPathSet) spathmeta.AppPathSet {
+	return s.eval(inputSet, nil)
+}
+
+// eval evaluates the interface sequence list and returns the set of paths that match the list,
+// the attribute predicates are evaluated against db.
+func (s *Sequence) eval(inputSet spathmeta.AppPathSet, db AttributeDB) spathmeta.AppPathSet {
 	if s == nil || s.srcstr == "" {
 		return inputSet
 	}
@@ -91,14 +126,17 @@ func (s *Sequence) Eval(inputSet spathmeta.AppPathSet) spathmeta.AppPathSet {
 		// Turn the path into a string. For each AS on the path there will be
 		// one element in form <IA>#<inbound-interface>,<outbound-interface>,
 		// e.g. 64-ff00:0:112#3,5. For the source AS, the inbound interface will be
-		// zero. For destination AS, outbound interface will be zero.
-		p := fmt.Sprintf("%s#0,%d ", ifaces[0].ISD_AS(), ifaces[0].IfID)
+		// zero. For destination AS, outbound interface will be zero. If the
+		// sequence contains attribute predicates, the labels of the AS are
+		// appended, e.g. 64-ff00:0:112#3,5{country=CH}.
+		p := fmt.Sprintf("%s#0,%d%s ", ifaces[0].ISD_AS(), ifaces[0].IfID,
+			s.labels(ifaces[0].ISD_AS(), db))
 		for i := 1; i < len(ifaces)-1; i += 2 {
-			p += fmt.Sprintf("%s#%d,%d ", ifaces[i].ISD_AS(),
-				ifaces[i].IfID, ifaces[i+1].IfID)
+			p += fmt.Sprintf("%s#%d,%d%s ", ifaces[i].ISD_AS(),
+				ifaces[i].IfID, ifaces[i+1].IfID, s.labels(ifaces[i].ISD_AS(), db))
 		}
-		p += fmt.Sprintf("%s#%d,0 ", ifaces[len(ifaces)-1].ISD_AS(),
-			ifaces[len(ifaces)-1].IfID)
+		p += fmt.Sprintf("%s#%d,0%s ", ifaces[len(ifaces)-1].ISD_AS(),
+			ifaces[len(ifaces)-1].IfID, s.labels(ifaces[len(ifaces)-1].ISD_AS(), db))
 		// Check whether the string matches the sequence regexp.
 		//fmt.Printf("EVAL: %s\n", p)
 		if s.re.MatchString(p) {
@@ -108,6 +146,15 @@ func (s *Sequence) Eval(inputSet spathmeta.AppPathSet) spathmeta.AppPathSet {
 	return resultSet
 }
 
+// labels returns the labels of the AS in db if the sequence contains attribute
+// predicates.
+func (s *Sequence) labels(ia addr.IA, db AttributeDB) string {
+	if !s.attrs {
+		return ""
+	}
+	return db.labelString(ia)
+}
+
 func (s *Sequence) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.srcstr)
 }
@@ -141,6 +188,8 @@ func (l *errorListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol
 type sequenceListener struct {
 	*sequence.BaseSequenceListener
 	stack []string
+	// attrs contains the attribute predicates keyed by their placeholder ISD.
+	attrs map[string]*AttributePredicate
 }
 
 func (l *sequenceListener) push(s string) {
@@ -204,7 +253,7 @@ func (l *sequenceListener) ExitParentheses(c *sequence.ParenthesesContext) {
 }
 
 func (l *sequenceListener) ExitHop(c *sequence.HopContext) {
-	re := fmt.Sprintf("(%s +)", l.pop())
+	re := fmt.Sprintf("(%s%s +)", l.pop(), labelsWildcard)
 	//fmt.Printf("Hop: %s RE: %s\n", c.GetText(), re)
 	l.push(re)
 }
@@ -212,6 +261,10 @@ func (l *sequenceListener) ExitHop(c *sequence.HopContext) {
 func (l *sequenceListener) ExitISDHop(c *sequence.ISDHopContext) {
 	isd := l.pop()
 	re := fmt.Sprintf("(%s-%s#%s,%s)", isd, asWildcard, ifWildcard, ifWildcard)
+	if ap, ok := l.attrs[isd]; ok {
+		re = fmt.Sprintf("(%s-%s#%s,%s%s)", isdWildcard, asWildcard, ifWildcard, ifWildcard,
+			ap.labelRegexp())
+	}
 	//fmt.Printf("ISDHop: %s RE: %s\n", c.GetText(), re)
 	l.push(re)
 }
@@ -0,0 +1,170 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pathsel selects sets of maximally link-disjoint paths for
+// applications that use multiple paths to the same destination.
+//
+// The selection is computed greedily: the most preferred path is selected
+// first, then the path that shares the fewest interfaces with the already
+// selected paths is added until the requested number of paths is reached.
+// Ties are broken by the preference order of the paths.
+//
+// Usage:
+//   sel, err := pathsel.Watch(ctx, resolver, src, dst, policy, 3)
+//   if err != nil {
+//       // handle error
+//   }
+//   defer sel.Destroy()
+//   selection := sel.Get()
+//   // use selection.Paths, selection.Score
+package pathsel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+// Selection is a set of paths together with its disjointness.
+type Selection struct {
+	// Paths contains the selected paths, in the order they were selected.
+	Paths []*spathmeta.AppPath
+	// SharedInterfaces is the number of distinct interfaces that are used by
+	// more than one path of the selection.
+	SharedInterfaces int
+	// Score is the disjointness score of the selection. It is the number of
+	// distinct interfaces divided by the total number of interfaces on all
+	// selected paths. A score of 1 means the paths are fully disjoint. The
+	// score of a selection without interfaces is 1.
+	Score float64
+}
+
+// Select selects up to n paths from the given paths, such that the number of
+// shared interfaces is minimized. The paths must be in order of preference.
+// If fewer than n paths are given, all of them are selected. An error is
+// returned if n is negative.
+func Select(paths []*spathmeta.AppPath, n int) (*Selection, error) {
+	if n < 0 {
+		return nil, common.NewBasicError("Number of paths must not be negative", nil, "n", n)
+	}
+	selected := make([]*spathmeta.AppPath, 0, n)
+	used := make(map[sciond.PathInterface]int)
+	remaining := append([]*spathmeta.AppPath(nil), paths...)
+	for len(selected) < n && len(remaining) > 0 {
+		best, bestShared := 0, -1
+		for i, path := range remaining {
+			shared := 0
+			for _, iface := range path.Entry.Path.Interfaces {
+				if used[iface] > 0 {
+					shared++
+				}
+			}
+			if bestShared < 0 || shared < bestShared {
+				best, bestShared = i, shared
+			}
+		}
+		for _, iface := range remaining[best].Entry.Path.Interfaces {
+			used[iface]++
+		}
+		selected = append(selected, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return newSelection(selected, used), nil
+}
+
+func newSelection(paths []*spathmeta.AppPath, used map[sciond.PathInterface]int) *Selection {
+	sel := &Selection{
+		Paths: paths,
+		Score: 1,
+	}
+	total := 0
+	for _, cnt := range used {
+		total += cnt
+		if cnt > 1 {
+			sel.SharedInterfaces++
+		}
+	}
+	if total > 0 {
+		sel.Score = float64(len(used)) / float64(total)
+	}
+	return sel
+}
+
+// Selector keeps a selection of disjoint paths up to date with the paths in a
+// SyncPaths. Selectors are safe for concurrent use by multiple goroutines.
+type Selector struct {
+	sp *pathmgr.SyncPaths
+	n  int
+
+	mtx         sync.Mutex
+	refreshTime time.Time
+	selection   *Selection
+}
+
+// NewSelector creates a selector that selects n paths from the paths in sp.
+// An error is returned if n is not positive.
+func NewSelector(sp *pathmgr.SyncPaths, n int) (*Selector, error) {
+	if n <= 0 {
+		return nil, common.NewBasicError("Number of paths must be positive", nil, "n", n)
+	}
+	return &Selector{
+		sp: sp,
+		n:  n,
+	}, nil
+}
+
+// Watch creates a selector that selects n paths from src to dst that adhere
+// to the policy. The paths are refreshed automatically by the resolver. A nil
+// policy does not filter any paths. Callers must call Destroy on the selector
+// once it is no longer needed.
+func Watch(ctx context.Context, resolver pathmgr.Resolver, src, dst addr.IA,
+	policy *pathpol.Policy, n int) (*Selector, error) {
+
+	if n <= 0 {
+		return nil, common.NewBasicError("Number of paths must be positive", nil, "n", n)
+	}
+	sp, err := resolver.WatchFilter(ctx, src, dst, policy)
+	if err != nil {
+		return nil, err
+	}
+	return NewSelector(sp, n)
+}
+
+// Get returns the current selection. The selection is recomputed if the
+// paths in the underlying SyncPaths were refreshed since the last call. Paths
+// are also refreshed if the set of paths does not change, e.g., to update
+// expired hop fields, so the refresh time is used instead of the modify time.
+func (s *Selector) Get() *Selection {
+	data := s.sp.Load()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.selection == nil || !data.RefreshTime.Equal(s.refreshTime) {
+		// n is positive, so the selection cannot fail.
+		s.selection, _ = Select(data.Ordered, s.n)
+		s.refreshTime = data.RefreshTime
+	}
+	return s.selection
+}
+
+// Destroy stops the path updates of the underlying SyncPaths.
+func (s *Selector) Destroy() {
+	s.sp.Destroy()
+}
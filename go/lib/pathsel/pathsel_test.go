@@ -0,0 +1,107 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathsel
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestSelect(t *testing.T) {
+	// p1 and p2 share the link between 1-ff00:0:110#1 and 1-ff00:0:120#1, p3
+	// is disjoint from both.
+	p1 := newTestPath(t, "1-ff00:0:110#1", "1-ff00:0:120#1", "1-ff00:0:120#2",
+		"1-ff00:0:111#1")
+	p2 := newTestPath(t, "1-ff00:0:110#1", "1-ff00:0:120#1", "1-ff00:0:120#3",
+		"1-ff00:0:111#2")
+	p3 := newTestPath(t, "1-ff00:0:110#2", "1-ff00:0:130#1", "1-ff00:0:130#2",
+		"1-ff00:0:111#3")
+
+	Convey("TestSelect", t, func() {
+		Convey("Disjoint paths are preferred", func() {
+			sel, err := Select([]*spathmeta.AppPath{p1, p2, p3}, 2)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("paths", sel.Paths, ShouldResemble, []*spathmeta.AppPath{p1, p3})
+			SoMsg("shared", sel.SharedInterfaces, ShouldEqual, 0)
+			SoMsg("score", sel.Score, ShouldEqual, 1)
+		})
+		Convey("Preference order is respected", func() {
+			sel, err := Select([]*spathmeta.AppPath{p2, p1, p3}, 2)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("paths", sel.Paths, ShouldResemble, []*spathmeta.AppPath{p2, p3})
+		})
+		Convey("Shared interfaces are reported", func() {
+			sel, err := Select([]*spathmeta.AppPath{p1, p2, p3}, 3)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("paths", sel.Paths, ShouldResemble, []*spathmeta.AppPath{p1, p3, p2})
+			SoMsg("shared", sel.SharedInterfaces, ShouldEqual, 2)
+			SoMsg("score", sel.Score, ShouldAlmostEqual, 10.0/12.0)
+		})
+		Convey("Fewer paths than requested", func() {
+			sel, err := Select([]*spathmeta.AppPath{p1}, 3)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("paths", sel.Paths, ShouldResemble, []*spathmeta.AppPath{p1})
+			SoMsg("score", sel.Score, ShouldEqual, 1)
+		})
+		Convey("No paths", func() {
+			sel, err := Select(nil, 3)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("paths", sel.Paths, ShouldBeEmpty)
+			SoMsg("score", sel.Score, ShouldEqual, 1)
+		})
+		Convey("Negative number of paths", func() {
+			_, err := Select([]*spathmeta.AppPath{p1}, -1)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestSelector(t *testing.T) {
+	Convey("Selector on empty SyncPaths returns empty selection", t, func() {
+		s, err := NewSelector(pathmgr.NewSyncPaths(), 2)
+		SoMsg("err", err, ShouldBeNil)
+		sel := s.Get()
+		SoMsg("paths", sel.Paths, ShouldBeEmpty)
+		SoMsg("cached", s.Get(), ShouldEqual, sel)
+		Convey("and recomputes the selection after a refresh", func() {
+			// Simulate a refresh of the paths since the last call.
+			s.refreshTime = time.Time{}
+			SoMsg("recomputed", s.Get(), ShouldNotEqual, sel)
+		})
+	})
+	Convey("Selector rejects non-positive number of paths", t, func() {
+		_, err := NewSelector(pathmgr.NewSyncPaths(), 0)
+		SoMsg("err", err, ShouldNotBeNil)
+	})
+}
+
+func newTestPath(t *testing.T, ifaces ...string) *spathmeta.AppPath {
+	entry := &sciond.PathReplyEntry{
+		Path: &sciond.FwdPathMeta{},
+	}
+	for _, str := range ifaces {
+		iface, err := sciond.NewPathInterface(str)
+		xtest.FailOnErr(t, err)
+		entry.Path.Interfaces = append(entry.Path.Interfaces, iface)
+	}
+	return &spathmeta.AppPath{Entry: entry}
+}
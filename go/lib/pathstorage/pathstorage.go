@@ -24,18 +24,21 @@ import (
 	"github.com/scionproto/scion/go/lib/infra/modules/db"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathdb"
+	postgrespathdb "github.com/scionproto/scion/go/lib/pathdb/postgres"
 	sqlitepathdb "github.com/scionproto/scion/go/lib/pathdb/sqlite"
 	"github.com/scionproto/scion/go/lib/revcache"
 	"github.com/scionproto/scion/go/lib/revcache/memrevcache"
+	sqliterevcache "github.com/scionproto/scion/go/lib/revcache/sqlite"
 	"github.com/scionproto/scion/go/lib/util"
 )
 
 type Backend string
 
 const (
-	BackendNone   Backend = ""
-	BackendSqlite Backend = "sqlite"
-	BackendMem    Backend = "mem"
+	BackendNone     Backend = ""
+	BackendSqlite   Backend = "sqlite"
+	BackendPostgres Backend = "postgres"
+	BackendMem      Backend = "mem"
 )
 
 const (
@@ -86,7 +89,7 @@ func (cfg *PathDBConf) Validate() error {
 		return err
 	}
 	switch cfg.Backend() {
-	case BackendSqlite:
+	case BackendSqlite, BackendPostgres:
 		return nil
 	case BackendNone:
 		return common.NewBasicError("No backend set", nil)
@@ -225,14 +228,18 @@ func NewPathStorage(pdbConf PathDBConf,
 	return pdb, rc, nil
 }
 
+// sameBackend returns whether the path database and the revocation cache are configured to use
+// the same database.
 func sameBackend(pdbConf PathDBConf, rcConf RevCacheConf) bool {
-	return pdbConf.Backend() == rcConf.Backend() && pdbConf.Backend() != BackendNone
+	return pdbConf.Backend() == rcConf.Backend() && pdbConf.Backend() != BackendNone &&
+		pdbConf.Connection() == rcConf.Connection()
 }
 
 func newCombinedBackend(pdbConf PathDBConf,
 	rcConf RevCacheConf) (pathdb.PathDB, revcache.RevCache, error) {
 
-	panic("Combined backend not supported")
+	return nil, nil, common.NewBasicError("Combined backend not supported", nil,
+		"backend", pdbConf.Backend(), "connection", pdbConf.Connection())
 }
 
 func newPathDB(conf PathDBConf) (pathdb.PathDB, error) {
@@ -243,6 +250,8 @@ func newPathDB(conf PathDBConf) (pathdb.PathDB, error) {
 	switch conf.Backend() {
 	case BackendSqlite:
 		pdb, err = sqlitepathdb.New(conf.Connection())
+	case BackendPostgres:
+		pdb, err = postgrespathdb.New(conf.Connection())
 	case BackendNone:
 		return nil, nil
 	default:
@@ -261,6 +270,13 @@ func newRevCache(conf RevCacheConf) (revcache.RevCache, error) {
 	switch conf.Backend() {
 	case BackendMem:
 		return memrevcache.New(), nil
+	case BackendSqlite:
+		rc, err := sqliterevcache.New(conf.Connection())
+		if err != nil {
+			return nil, err
+		}
+		setConnLimits(&conf, rc)
+		return rc, nil
 	case BackendNone:
 		return nil, nil
 	default:
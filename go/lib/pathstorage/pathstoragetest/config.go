@@ -53,6 +53,7 @@ func CheckTestRevCacheConf(cfg *pathstorage.RevCacheConf) {
 	SoMsg("MaxOpenConns", isSet(cfg.MaxOpenConns()), ShouldBeFalse)
 	SoMsg("MaxIdleConns", isSet(cfg.MaxIdleConns()), ShouldBeFalse)
 	SoMsg("Backend correct", cfg.Backend(), ShouldEqual, pathstorage.BackendMem)
+	SoMsg("Connection correct", cfg.Connection(), ShouldBeEmpty)
 }
 
 func isSet(_ int, set bool) bool {
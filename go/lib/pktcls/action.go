@@ -16,6 +16,8 @@ package pktcls
 
 import (
 	"encoding/json"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
 )
 
 // Interface Action defines how paths and packets may be processed in a way
@@ -69,3 +71,41 @@ func (am *ActionMap) UnmarshalJSON(b []byte) error {
 	}
 	return nil
 }
+
+var _ Action = (*ActionFilterPaths)(nil)
+
+// ActionFilterPaths filters paths according to a path policy. Act takes a
+// spathmeta.AppPathSet and returns the paths that adhere to the policy. An
+// action without a policy does not filter any paths.
+type ActionFilterPaths struct {
+	Name   string `json:"-"`
+	Policy *pathpol.Policy
+}
+
+func NewActionFilterPaths(name string, policy *pathpol.Policy) *ActionFilterPaths {
+	a := &ActionFilterPaths{Policy: policy}
+	a.SetName(name)
+	return a
+}
+
+func (a *ActionFilterPaths) Act(values interface{}) interface{} {
+	if a.Policy == nil {
+		return values
+	}
+	return a.Policy.Act(values)
+}
+
+func (a *ActionFilterPaths) GetName() string {
+	return a.Name
+}
+
+func (a *ActionFilterPaths) SetName(name string) {
+	a.Name = name
+	if a.Policy != nil {
+		a.Policy.Name = name
+	}
+}
+
+func (a *ActionFilterPaths) Type() string {
+	return TypeActionFilterPaths
+}
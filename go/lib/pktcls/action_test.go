@@ -0,0 +1,77 @@
+// Copyright 2017 ETH Zurich
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestActionMap(t *testing.T) {
+	var policy pathpol.Policy
+	xtest.FailOnErr(t, json.Unmarshal([]byte(`{"ACL": ["- 1-ff00:0:133#0", "+"]}`), &policy))
+	testCases := []struct {
+		Name     string
+		FileName string
+		Actions  ActionMap
+	}{
+		{
+			Name:     "Path filter",
+			FileName: "action_1",
+			Actions: ActionMap{
+				"voice": NewActionFilterPaths("voice", &policy),
+			},
+		},
+		{
+			Name:     "nil ActionMap stays nil",
+			FileName: "action_2",
+			Actions:  nil,
+		},
+	}
+
+	Convey("Test action marshal/unmarshal", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				if *update {
+					xtest.MustMarshalJSONToFile(t, tc.Actions, tc.FileName+".json")
+				}
+
+				expected, err := ioutil.ReadFile(xtest.ExpandPath(tc.FileName + ".json"))
+				xtest.FailOnErr(t, err)
+
+				// Check that marshaling matches reference files
+				enc, err := json.MarshalIndent(tc.Actions, "", "    ")
+				SoMsg("err marshal", err, ShouldBeNil)
+				SoMsg("bytes",
+					string(enc),
+					ShouldResemble,
+					strings.TrimRight(string(expected), "\n"))
+
+				// Check that unmarshaling from reference files matches structure
+				var actions ActionMap
+				err = json.Unmarshal(expected, &actions)
+				SoMsg("err unmarshal", err, ShouldBeNil)
+				SoMsg("object", actions, ShouldResemble, tc.Actions)
+			})
+		}
+	})
+}
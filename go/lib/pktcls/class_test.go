@@ -23,6 +23,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/gopacket/layers"
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/scionproto/scion/go/lib/xtest"
@@ -77,6 +78,39 @@ func TestClassMap(t *testing.T) {
 			FileName: "class_2",
 			Classes:  nil,
 		},
+		{
+			Name:     "IPv6 and L4",
+			FileName: "class_3",
+			Classes: ClassMap{
+				"voice": NewClass(
+					"voice",
+					NewCondAllOf(
+						NewCondIPv6(&IPv6MatchDSCP{DSCP: 0x2e}),
+						NewCondL4(&L4MatchProtocol{Protocol: layers.IPProtocolUDP}),
+						NewCondL4(&L4MatchDstPort{Min: 16384, Max: 32767}),
+					),
+				),
+				"site B": NewClass(
+					"site B",
+					NewCondAnyOf(
+						NewCondIPv6(&IPv6MatchDestination{
+							&net.IPNet{
+								IP:   net.ParseIP("2001:db8:b::"),
+								Mask: net.CIDRMask(48, 8*net.IPv6len),
+							},
+						}),
+						NewCondIPv6(&IPv6MatchSource{
+							&net.IPNet{
+								IP:   net.ParseIP("2001:db8:a::"),
+								Mask: net.CIDRMask(48, 8*net.IPv6len),
+							},
+						}),
+						NewCondIPv6(&IPv6MatchFlowLabel{FlowLabel: 0xbeef}),
+						NewCondL4(&L4MatchSrcPort{Min: 443, Max: 443}),
+					),
+				),
+			},
+		},
 	}
 
 	Convey("Test class marshal/unmarshal", t, func() {
@@ -222,6 +256,62 @@ func TestBadJSON(t *testing.T) {
 			},
 			"Name": "Unable to parse source operand string"
 		}
+		`, `
+		{
+			"CondIPv6": {
+				"MatchSource": {
+					"Net": "192.0.2.0/24"
+				}
+			},
+			"Name": "IPv4 network in IPv6 source operand"
+		}
+		`, `
+		{
+			"CondIPv6": {
+				"MatchToS": {
+					"TOS": "0x80"
+				}
+			},
+			"Name": "Nonexistent IPv6 predicate"
+		}
+		`, `
+		{
+			"CondIPv6": {
+				"MatchFlowLabel": {
+					"FlowLabel": "0x100000"
+				}
+			},
+			"Name": "Flow label too large"
+		}
+		`, `
+		{
+			"CondL4": {
+				"MatchDSCP": {
+					"DSCP": "0x2e"
+				}
+			},
+			"Name": "IPv4 predicate in L4 condition"
+		}
+		`, `
+		{
+			"CondL4": {
+				"MatchDstPort": {
+					"Min": "2000",
+					"Max": "1000"
+				}
+			},
+			"Name": "Invalid port range"
+		}
+		`, `
+		{
+			"CondL4": {
+				"MatchSrcPort": {
+					"Min": "80",
+					"Max": "65536"
+				}
+			},
+			"Name": "Port too large"
+		}
 	`}
 	Convey("Marshaling bad JSON should return errors", t, func() {
 		for i, tc := range testCases {
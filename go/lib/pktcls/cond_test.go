@@ -155,6 +155,178 @@ func TestIPCond(t *testing.T) {
 	})
 }
 
+func TestIPv6Cond(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Cond    Cond
+		Packet  *Packet
+		ExpEval bool
+	}{
+		{
+			Name: "Match IPv6 source and destination",
+			Cond: NewCondAllOf(
+				NewCondIPv6(
+					&IPv6MatchSource{
+						&net.IPNet{
+							IP:   net.ParseIP("2001:db8:1::"),
+							Mask: net.CIDRMask(48, 128),
+						},
+					},
+				),
+				NewCondIPv6(
+					&IPv6MatchDestination{
+						&net.IPNet{
+							IP:   net.ParseIP("2001:db8:2::"),
+							Mask: net.CIDRMask(48, 128),
+						},
+					},
+				),
+			),
+			Packet: newTestPacketLayers(
+				&layers.IPv6{
+					Version:    6,
+					NextHeader: layers.IPProtocolNoNextHeader,
+					SrcIP:      net.ParseIP("2001:db8:1::1"),
+					DstIP:      net.ParseIP("2001:db8:2::1"),
+				},
+			),
+			ExpEval: true,
+		},
+		{
+			Name: "Match IPv6 DSCP but not flow label",
+			Cond: NewCondAllOf(
+				NewCondIPv6(&IPv6MatchDSCP{DSCP: 0x2e}),
+				NewCondIPv6(&IPv6MatchFlowLabel{FlowLabel: 0x12345}),
+			),
+			Packet: newTestPacketLayers(
+				&layers.IPv6{
+					Version:      6,
+					TrafficClass: 0x2e << 2,
+					FlowLabel:    0x54321,
+					NextHeader:   layers.IPProtocolNoNextHeader,
+					SrcIP:        net.ParseIP("2001:db8:1::1"),
+					DstIP:        net.ParseIP("2001:db8:2::1"),
+				},
+			),
+			ExpEval: false,
+		},
+		{
+			Name: "IPv6 condition does not match IPv4 packet",
+			Cond: NewCondIPv6(
+				&IPv6MatchSource{
+					&net.IPNet{
+						IP:   net.ParseIP("::"),
+						Mask: net.CIDRMask(0, 128),
+					},
+				},
+			),
+			Packet: newTestPacket(
+				&layers.IPv4{
+					SrcIP: net.IP{192, 168, 1, 1},
+					DstIP: net.IP{10, 0, 0, 2},
+				},
+				[]byte{1, 1, 1, 1},
+			),
+			ExpEval: false,
+		},
+	}
+
+	Convey("TestIPv6Cond", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				SoMsg("eval", tc.Cond.Eval(tc.Packet), ShouldEqual, tc.ExpEval)
+			})
+		}
+	})
+}
+
+func TestL4Cond(t *testing.T) {
+	ipv4 := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    net.IP{192, 168, 1, 1},
+		DstIP:    net.IP{10, 0, 0, 2},
+	}
+	ipv6 := &layers.IPv6{
+		Version:    6,
+		NextHeader: layers.IPProtocolIPv6HopByHop,
+		HopLimit:   64,
+		SrcIP:      net.ParseIP("2001:db8:1::1"),
+		DstIP:      net.ParseIP("2001:db8:2::1"),
+	}
+	hopByHop := &layers.IPv6HopByHop{
+		Options: []*layers.IPv6HopByHopOption{
+			{OptionType: 1, OptionLength: 4, OptionData: []byte{0, 0, 0, 0}},
+		},
+	}
+	hopByHop.NextHeader = layers.IPProtocolTCP
+	testCases := []struct {
+		Name    string
+		Cond    Cond
+		Packet  *Packet
+		ExpEval bool
+	}{
+		{
+			Name: "Match UDP over IPv4 and destination port range",
+			Cond: NewCondAllOf(
+				NewCondL4(&L4MatchProtocol{Protocol: layers.IPProtocolUDP}),
+				NewCondL4(&L4MatchDstPort{Min: 5000, Max: 5010}),
+			),
+			Packet: newTestPacketLayers(
+				ipv4,
+				&layers.UDP{SrcPort: 40000, DstPort: 5010},
+				gopacket.Payload([]byte{1, 1, 1, 1}),
+			),
+			ExpEval: true,
+		},
+		{
+			Name: "Source port outside of range",
+			Cond: NewCondL4(&L4MatchSrcPort{Min: 1000, Max: 2000}),
+			Packet: newTestPacketLayers(
+				ipv4,
+				&layers.UDP{SrcPort: 2001, DstPort: 5010},
+				gopacket.Payload([]byte{1, 1, 1, 1}),
+			),
+			ExpEval: false,
+		},
+		{
+			Name: "Match TCP over IPv6 with extension header",
+			Cond: NewCondAllOf(
+				NewCondL4(&L4MatchProtocol{Protocol: layers.IPProtocolTCP}),
+				NewCondL4(&L4MatchSrcPort{Min: 443, Max: 443}),
+			),
+			Packet: newTestPacketLayers(
+				ipv6,
+				hopByHop,
+				&layers.TCP{SrcPort: 443, DstPort: 40000, DataOffset: 5},
+			),
+			ExpEval: true,
+		},
+		{
+			Name: "Port condition does not match packet without ports",
+			Cond: NewCondL4(&L4MatchDstPort{Min: 0, Max: 65535}),
+			Packet: newTestPacket(
+				&layers.IPv4{
+					SrcIP: net.IP{192, 168, 1, 1},
+					DstIP: net.IP{10, 0, 0, 2},
+				},
+				[]byte{1, 1, 1, 1},
+			),
+			ExpEval: false,
+		},
+	}
+
+	Convey("TestL4Cond", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				SoMsg("eval", tc.Cond.Eval(tc.Packet), ShouldEqual, tc.ExpEval)
+			})
+		}
+	})
+}
+
 func newTestPacket(ipv4 *layers.IPv4, pld []byte) *Packet {
 	buf := gopacket.NewSerializeBuffer()
 	gopacket.SerializeLayers(
@@ -165,3 +337,13 @@ func newTestPacket(ipv4 *layers.IPv4, pld []byte) *Packet {
 	)
 	return NewPacket(buf.Bytes())
 }
+
+func newTestPacketLayers(l ...gopacket.SerializableLayer) *Packet {
+	buf := gopacket.NewSerializeBuffer()
+	gopacket.SerializeLayers(
+		buf,
+		gopacket.SerializeOptions{FixLengths: true},
+		l...,
+	)
+	return NewPacket(buf.Bytes())
+}
@@ -19,18 +19,22 @@
 // true for a ClsPkt, that packet is considered to be part of that class.
 //
 // The following conditions are supported:
-// AnyOf, AllOf, Boolean true, Boolean false and IPv4. AnyOf returns true if at
-// least one subcondition returns true. AllOf returns true if all subconditions
-// return true.  AllOf or AnyOf without subconditions return true. Boolean
-// conditions always return their internal value. IPv4 conditions include
-// predicates that compare the analyzed packet to preset values. Supported IPv4
-// conditions currently include destination network match, source network match
-// and ToS/DSCP fields match. Multiple predicates can be checked by enumerating
-// them under AllOf or AnyOf.
+// AnyOf, AllOf, Boolean true, Boolean false, IPv4, IPv6 and L4. AnyOf returns
+// true if at least one subcondition returns true. AllOf returns true if all
+// subconditions return true.  AllOf or AnyOf without subconditions return
+// true. Boolean conditions always return their internal value. IPv4, IPv6 and
+// L4 conditions include predicates that compare the analyzed packet to preset
+// values. Supported IPv4 conditions currently include destination network
+// match, source network match and ToS/DSCP fields match. Supported IPv6
+// conditions include destination network match, source network match, DSCP
+// and flow label match. Supported L4 conditions include IP protocol match and
+// source/destination port range match; they apply to both IPv4 and IPv6
+// packets, IPv6 extension headers are skipped. Multiple predicates can be
+// checked by enumerating them under AllOf or AnyOf.
 //
 // Actions are marshalable objects that describe a process. Currently, the only
 // supported actions are Path Filters (ActionFilterPaths), which are containers
-// for a spathmeta.PathPredicate object.
+// for a pathpol.Policy object.
 //
 // Marshalable policies can be implemented by external code by mapping Cond
 // items to Action items.
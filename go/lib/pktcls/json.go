@@ -45,10 +45,20 @@ const (
 	TypeCondNot              = "CondNot"
 	TypeCondBool             = "CondBool"
 	TypeCondIPv4             = "CondIPv4"
+	TypeCondIPv6             = "CondIPv6"
+	TypeCondL4               = "CondL4"
 	TypeIPv4MatchSource      = "MatchSource"
 	TypeIPv4MatchDestination = "MatchDestination"
 	TypeIPv4MatchToS         = "MatchToS"
 	TypeIPv4MatchDSCP        = "MatchDSCP"
+	TypeIPv6MatchSource      = "MatchSource"
+	TypeIPv6MatchDestination = "MatchDestination"
+	TypeIPv6MatchDSCP        = "MatchDSCP"
+	TypeIPv6MatchFlowLabel   = "MatchFlowLabel"
+	TypeL4MatchProtocol      = "MatchProtocol"
+	TypeL4MatchSrcPort       = "MatchSrcPort"
+	TypeL4MatchDstPort       = "MatchDstPort"
+	TypeActionFilterPaths    = "ActionFilterPaths"
 )
 
 // generic container for marshaling custom data
@@ -95,6 +105,14 @@ func unmarshalInterface(b []byte) (Typer, error) {
 			var c CondIPv4
 			err := json.Unmarshal(*v, &c)
 			return &c, err
+		case TypeCondIPv6:
+			var c CondIPv6
+			err := json.Unmarshal(*v, &c)
+			return &c, err
+		case TypeCondL4:
+			var c CondL4
+			err := json.Unmarshal(*v, &c)
+			return &c, err
 		case TypeIPv4MatchSource:
 			var p IPv4MatchSource
 			err := json.Unmarshal(*v, &p)
@@ -111,6 +129,22 @@ func unmarshalInterface(b []byte) (Typer, error) {
 			var p IPv4MatchDSCP
 			err := json.Unmarshal(*v, &p)
 			return &p, err
+		case TypeL4MatchProtocol:
+			var p L4MatchProtocol
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeL4MatchSrcPort:
+			var p L4MatchSrcPort
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeL4MatchDstPort:
+			var p L4MatchDstPort
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeActionFilterPaths:
+			var a ActionFilterPaths
+			err := json.Unmarshal(*v, &a)
+			return &a, err
 		default:
 			return nil, common.NewBasicError("Unknown type", nil, "type", k)
 		}
@@ -139,7 +173,7 @@ func unmarshalAction(b []byte) (Action, error) {
 	}
 	a, ok := t.(Action)
 	if !ok {
-		return nil, common.NewBasicError("Unable to extract Cond from interface", nil)
+		return nil, common.NewBasicError("Unable to extract Action from interface", nil)
 	}
 	return a, nil
 }
@@ -152,7 +186,51 @@ func unmarshalPredicate(b []byte) (IPv4Predicate, error) {
 	}
 	p, ok := t.(IPv4Predicate)
 	if !ok {
-		return nil, common.NewBasicError("Unable to extract Cond from interface", nil)
+		return nil, common.NewBasicError("Unable to extract IPv4Predicate from interface", nil)
+	}
+	return p, nil
+}
+
+// unmarshalIPv6Predicate extracts an IPv6Predicate from a JSON encoding. The
+// IPv6 predicates share their type names with the IPv4 predicates, thus they
+// are not handled by unmarshalInterface.
+func unmarshalIPv6Predicate(b []byte) (IPv6Predicate, error) {
+	var container map[string]*json.RawMessage
+	err := json.Unmarshal(b, &container)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range container {
+		var p IPv6Predicate
+		switch k {
+		case TypeIPv6MatchSource:
+			p = &IPv6MatchSource{}
+		case TypeIPv6MatchDestination:
+			p = &IPv6MatchDestination{}
+		case TypeIPv6MatchDSCP:
+			p = &IPv6MatchDSCP{}
+		case TypeIPv6MatchFlowLabel:
+			p = &IPv6MatchFlowLabel{}
+		default:
+			return nil, common.NewBasicError("Unknown type", nil, "type", k)
+		}
+		if v == nil {
+			return nil, common.NewBasicError("Missing operand", nil, "type", k)
+		}
+		return p, json.Unmarshal(*v, p)
+	}
+	return nil, common.NewBasicError("Unable to extract IPv6Predicate from interface", nil)
+}
+
+// unmarshalL4Predicate extracts an L4Predicate from a JSON encoding
+func unmarshalL4Predicate(b []byte) (L4Predicate, error) {
+	t, err := unmarshalInterface(b)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := t.(L4Predicate)
+	if !ok {
+		return nil, common.NewBasicError("Unable to extract L4Predicate from interface", nil)
 	}
 	return p, nil
 }
@@ -28,9 +28,57 @@ type Packet struct {
 	parsedPkt gopacket.Packet
 }
 
+// NewPacket parses raw as an IPv4 or IPv6 packet, depending on the version
+// field of the IP header.
 func NewPacket(raw common.RawBytes) *Packet {
+	firstLayer := layers.LayerTypeIPv4
+	if len(raw) > 0 && raw[0]>>4 == 6 {
+		firstLayer = layers.LayerTypeIPv6
+	}
 	return &Packet{
 		rawPkt:    raw,
-		parsedPkt: gopacket.NewPacket(raw, layers.LayerTypeIPv4, gopacket.NoCopy),
+		parsedPkt: gopacket.NewPacket(raw, firstLayer, gopacket.NoCopy),
+	}
+}
+
+// L4Header contains the transport layer information of a packet that is
+// matched by L4 predicates.
+type L4Header struct {
+	// Protocol is the IP protocol number of the transport layer. For IPv6,
+	// extension headers are skipped.
+	Protocol layers.IPProtocol
+	// HasPorts is true if the transport layer is TCP, UDP or SCTP and the
+	// ports could be parsed.
+	HasPorts bool
+	SrcPort  uint16
+	DstPort  uint16
+}
+
+// l4Header returns the transport layer information of the packet. If the
+// packet does not contain an IP header, nil is returned.
+func (p *Packet) l4Header() *L4Header {
+	var h *L4Header
+	for _, layer := range p.parsedPkt.Layers() {
+		switch l := layer.(type) {
+		case *layers.IPv4:
+			h = &L4Header{Protocol: l.Protocol}
+		case *layers.IPv6:
+			h = &L4Header{Protocol: l.NextHeader}
+		case *layers.IPv6HopByHop:
+			h.Protocol = l.NextHeader
+		case *layers.IPv6Routing:
+			h.Protocol = l.NextHeader
+		case *layers.IPv6Fragment:
+			h.Protocol = l.NextHeader
+		case *layers.IPv6Destination:
+			h.Protocol = l.NextHeader
+		case *layers.TCP:
+			h.HasPorts, h.SrcPort, h.DstPort = true, uint16(l.SrcPort), uint16(l.DstPort)
+		case *layers.UDP:
+			h.HasPorts, h.SrcPort, h.DstPort = true, uint16(l.SrcPort), uint16(l.DstPort)
+		case *layers.SCTP:
+			h.HasPorts, h.SrcPort, h.DstPort = true, uint16(l.SrcPort), uint16(l.DstPort)
+		}
 	}
+	return h
 }
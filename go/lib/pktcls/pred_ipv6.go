@@ -0,0 +1,180 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// IPv6Predicate describes a single test on various IPv6 packet fields.
+type IPv6Predicate interface {
+	// Eval returns true if the IPv6 packet matched the predicate
+	Eval(*layers.IPv6) bool
+	Typer
+}
+
+var _ IPv6Predicate = (*IPv6MatchSource)(nil)
+
+// IPv6MatchSource checks whether the source IPv6 address is contained in Net.
+type IPv6MatchSource struct {
+	Net *net.IPNet
+}
+
+func (m *IPv6MatchSource) Type() string {
+	return TypeIPv6MatchSource
+}
+
+func (m *IPv6MatchSource) Eval(p *layers.IPv6) bool {
+	return m.Net.Contains(p.SrcIP)
+}
+
+func (m *IPv6MatchSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Net": m.Net.String(),
+		},
+	)
+}
+
+func (m *IPv6MatchSource) UnmarshalJSON(b []byte) error {
+	network, err := unmarshalIPv6NetField(b, "MatchSource")
+	if err != nil {
+		return err
+	}
+	m.Net = network
+	return nil
+}
+
+var _ IPv6Predicate = (*IPv6MatchDestination)(nil)
+
+// IPv6MatchDestination checks whether the destination IPv6 address is
+// contained in Net.
+type IPv6MatchDestination struct {
+	Net *net.IPNet
+}
+
+func (m *IPv6MatchDestination) Type() string {
+	return TypeIPv6MatchDestination
+}
+
+func (m *IPv6MatchDestination) Eval(p *layers.IPv6) bool {
+	return m.Net.Contains(p.DstIP)
+}
+
+func (m *IPv6MatchDestination) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Net": m.Net.String(),
+		},
+	)
+}
+
+func (m *IPv6MatchDestination) UnmarshalJSON(b []byte) error {
+	network, err := unmarshalIPv6NetField(b, "MatchDestination")
+	if err != nil {
+		return err
+	}
+	m.Net = network
+	return nil
+}
+
+var _ IPv6Predicate = (*IPv6MatchDSCP)(nil)
+
+// IPv6MatchDSCP checks whether the DSCP subset of the Traffic Class field
+// matches.
+type IPv6MatchDSCP struct {
+	DSCP uint8
+}
+
+func (m *IPv6MatchDSCP) Type() string {
+	return TypeIPv6MatchDSCP
+}
+
+func (m *IPv6MatchDSCP) Eval(p *layers.IPv6) bool {
+	return m.DSCP == p.TrafficClass>>2
+}
+
+func (m *IPv6MatchDSCP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"DSCP": fmt.Sprintf("%#x", m.DSCP),
+		},
+	)
+}
+
+func (m *IPv6MatchDSCP) UnmarshalJSON(b []byte) error {
+	// Format is 0x hex number in quoted string
+	i, err := unmarshalUintField(b, "DSCP", "DSCP", 6)
+	if err != nil {
+		return err
+	}
+	m.DSCP = uint8(i)
+	return nil
+}
+
+var _ IPv6Predicate = (*IPv6MatchFlowLabel)(nil)
+
+// IPv6MatchFlowLabel checks whether the Flow Label field matches.
+type IPv6MatchFlowLabel struct {
+	FlowLabel uint32
+}
+
+func (m *IPv6MatchFlowLabel) Type() string {
+	return TypeIPv6MatchFlowLabel
+}
+
+func (m *IPv6MatchFlowLabel) Eval(p *layers.IPv6) bool {
+	return m.FlowLabel == p.FlowLabel
+}
+
+func (m *IPv6MatchFlowLabel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"FlowLabel": fmt.Sprintf("%#x", m.FlowLabel),
+		},
+	)
+}
+
+func (m *IPv6MatchFlowLabel) UnmarshalJSON(b []byte) error {
+	// Format is 0x hex number in quoted string
+	i, err := unmarshalUintField(b, "FlowLabel", "FlowLabel", 20)
+	if err != nil {
+		return err
+	}
+	m.FlowLabel = uint32(i)
+	return nil
+}
+
+// unmarshalIPv6NetField parses the Net field of an IPv6 network predicate.
+func unmarshalIPv6NetField(b []byte, name string) (*net.IPNet, error) {
+	s, err := unmarshalStringField(b, name, "Net")
+	if err != nil {
+		return nil, err
+	}
+	ip, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to parse "+name+" operand", err)
+	}
+	if ip.To4() != nil {
+		return nil, common.NewBasicError("Network is not IPv6", nil, "name", name, "net", s)
+	}
+	return network, nil
+}
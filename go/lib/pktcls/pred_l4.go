@@ -0,0 +1,144 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// L4Predicate describes a single test on the transport layer of a packet.
+type L4Predicate interface {
+	// Eval returns true if the transport layer matched the predicate
+	Eval(*L4Header) bool
+	Typer
+}
+
+var _ L4Predicate = (*L4MatchProtocol)(nil)
+
+// L4MatchProtocol checks whether the IP protocol number of the transport layer
+// matches.
+type L4MatchProtocol struct {
+	Protocol layers.IPProtocol
+}
+
+func (m *L4MatchProtocol) Type() string {
+	return TypeL4MatchProtocol
+}
+
+func (m *L4MatchProtocol) Eval(h *L4Header) bool {
+	return m.Protocol == h.Protocol
+}
+
+func (m *L4MatchProtocol) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Protocol": fmt.Sprintf("%d", m.Protocol),
+		},
+	)
+}
+
+func (m *L4MatchProtocol) UnmarshalJSON(b []byte) error {
+	// Format is a number in quoted string
+	i, err := unmarshalUintField(b, "MatchProtocol", "Protocol", 8)
+	if err != nil {
+		return err
+	}
+	m.Protocol = layers.IPProtocol(i)
+	return nil
+}
+
+var _ L4Predicate = (*L4MatchSrcPort)(nil)
+
+// L4MatchSrcPort checks whether the source port is in the range [Min, Max].
+// Packets without ports never match.
+type L4MatchSrcPort struct {
+	Min uint16
+	Max uint16
+}
+
+func (m *L4MatchSrcPort) Type() string {
+	return TypeL4MatchSrcPort
+}
+
+func (m *L4MatchSrcPort) Eval(h *L4Header) bool {
+	return h.HasPorts && m.Min <= h.SrcPort && h.SrcPort <= m.Max
+}
+
+func (m *L4MatchSrcPort) MarshalJSON() ([]byte, error) {
+	return marshalPortRange(m.Min, m.Max)
+}
+
+func (m *L4MatchSrcPort) UnmarshalJSON(b []byte) error {
+	var err error
+	m.Min, m.Max, err = unmarshalPortRange(b, "MatchSrcPort")
+	return err
+}
+
+var _ L4Predicate = (*L4MatchDstPort)(nil)
+
+// L4MatchDstPort checks whether the destination port is in the range
+// [Min, Max]. Packets without ports never match.
+type L4MatchDstPort struct {
+	Min uint16
+	Max uint16
+}
+
+func (m *L4MatchDstPort) Type() string {
+	return TypeL4MatchDstPort
+}
+
+func (m *L4MatchDstPort) Eval(h *L4Header) bool {
+	return h.HasPorts && m.Min <= h.DstPort && h.DstPort <= m.Max
+}
+
+func (m *L4MatchDstPort) MarshalJSON() ([]byte, error) {
+	return marshalPortRange(m.Min, m.Max)
+}
+
+func (m *L4MatchDstPort) UnmarshalJSON(b []byte) error {
+	var err error
+	m.Min, m.Max, err = unmarshalPortRange(b, "MatchDstPort")
+	return err
+}
+
+func marshalPortRange(min, max uint16) ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Min": fmt.Sprintf("%d", min),
+			"Max": fmt.Sprintf("%d", max),
+		},
+	)
+}
+
+func unmarshalPortRange(b []byte, name string) (uint16, uint16, error) {
+	min, err := unmarshalUintField(b, name, "Min", 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := unmarshalUintField(b, name, "Max", 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if min > max {
+		return 0, 0, common.NewBasicError("Invalid port range", nil,
+			"name", name, "min", min, "max", max)
+	}
+	return uint16(min), uint16(max), nil
+}
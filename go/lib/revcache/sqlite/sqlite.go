@@ -0,0 +1,204 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite contains an SQLite backend for the RevCache.
+//
+// Revocations are stored in a database file and therefore survive a restart
+// of the process. All modifications are executed in transactions and the
+// database runs in WAL journal mode, so a crash never leaves a partially
+// written revocation behind. Expired revocations that are still in the
+// database when it is reopened are never returned and are removed by the next
+// call to DeleteExpired.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/infra/modules/db"
+	"github.com/scionproto/scion/go/lib/revcache"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ revcache.RevCache = (*Backend)(nil)
+
+// Backend is an SQLite backed RevCache.
+type Backend struct {
+	db *sql.DB
+}
+
+// New returns a new SQLite backend opening a database at the given path. If
+// no database exists a new database is be created. If the schema version of the
+// stored database is different from the one in schema.go, an error is returned.
+func New(path string) (*Backend, error) {
+	db, err := db.NewSqlite(path, Schema, SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{
+		db: db,
+	}, nil
+}
+
+func (b *Backend) Get(ctx context.Context,
+	keys revcache.KeySet) (revcache.Revocations, error) {
+
+	if len(keys) == 0 {
+		return revcache.Revocations{}, nil
+	}
+	subQ := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, 3*len(keys)+1)
+	args = append(args, time.Now().Unix())
+	for k := range keys {
+		subQ = append(subQ, "(IsdID=? AND AsID=? AND IfID=?)")
+		args = append(args, k.IA.I, k.IA.A, k.IfId)
+	}
+	query := fmt.Sprintf("SELECT RawSignedRev FROM Revocations WHERE Expiration>? AND (%s)",
+		strings.Join(subQ, " OR "))
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, common.NewBasicError("Error looking up revocations", err, "q", query)
+	}
+	defer rows.Close()
+	revs := make(revcache.Revocations, len(keys))
+	for rows.Next() {
+		var rawRev []byte
+		if err = rows.Scan(&rawRev); err != nil {
+			return nil, common.NewBasicError("Error reading DB response", err)
+		}
+		rev, info, err := parse(common.RawBytes(rawRev))
+		if err != nil {
+			return nil, err
+		}
+		revs[*revcache.NewKey(info.IA(), info.IfID)] = rev
+	}
+	if err := rows.Err(); err != nil {
+		return nil, common.NewBasicError("Error reading DB response", err)
+	}
+	return revs, nil
+}
+
+func (b *Backend) GetAll(ctx context.Context) (revcache.ResultChan, error) {
+	query := "SELECT RawSignedRev FROM Revocations WHERE Expiration>?"
+	rows, err := b.db.QueryContext(ctx, query, time.Now().Unix())
+	if err != nil {
+		return nil, common.NewBasicError("Error looking up revocations", err, "q", query)
+	}
+	resCh := make(chan revcache.RevOrErr)
+	go func() {
+		defer close(resCh)
+		defer rows.Close()
+		for rows.Next() {
+			var rawRev []byte
+			if err := rows.Scan(&rawRev); err != nil {
+				resCh <- revcache.RevOrErr{
+					Err: common.NewBasicError("Error reading DB response", err)}
+				return
+			}
+			rev, _, err := parse(common.RawBytes(rawRev))
+			resCh <- revcache.RevOrErr{Rev: rev, Err: err}
+		}
+		if err := rows.Err(); err != nil {
+			resCh <- revcache.RevOrErr{
+				Err: common.NewBasicError("Error reading DB response", err)}
+		}
+	}()
+	return resCh, nil
+}
+
+func (b *Backend) Insert(ctx context.Context, rev *path_mgmt.SignedRevInfo) (bool, error) {
+	newInfo, err := rev.RevInfo()
+	if err != nil {
+		panic(err)
+	}
+	if !newInfo.Expiration().After(time.Now()) {
+		return false, nil
+	}
+	packedRev, err := proto.PackRoot(rev)
+	if err != nil {
+		return false, err
+	}
+	ia := newInfo.IA()
+	inserted := false
+	err = db.DoInTx(ctx, b.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := "SELECT IssuingTime FROM Revocations WHERE IsdID=? AND AsID=? AND IfID=?"
+		var existingTs uint32
+		err := tx.QueryRowContext(ctx, query, ia.I, ia.A, newInfo.IfID).Scan(&existingTs)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return common.NewBasicError("Failed to lookup revocation", err)
+		case newInfo.RawTimestamp <= existingTs:
+			// The existing revocation is at least as new as the given one.
+			return nil
+		}
+		inst := `INSERT OR REPLACE INTO Revocations
+			(IsdID, AsID, IfID, LinkType, IssuingTime, Expiration, RawSignedRev)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`
+		_, err = tx.ExecContext(ctx, inst, ia.I, ia.A, newInfo.IfID, newInfo.LinkType,
+			newInfo.RawTimestamp, newInfo.Expiration().Unix(), packedRev)
+		if err != nil {
+			return common.NewBasicError("Failed to insert revocation", err)
+		}
+		inserted = true
+		return nil
+	})
+	return inserted, err
+}
+
+func (b *Backend) DeleteExpired(ctx context.Context) (int64, error) {
+	var res sql.Result
+	err := db.DoInTx(ctx, b.db, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		delStmt := "DELETE FROM Revocations WHERE Expiration<=?"
+		res, err = tx.ExecContext(ctx, delStmt, time.Now().Unix())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (b *Backend) Close() error {
+	return b.db.Close()
+}
+
+func (b *Backend) SetMaxOpenConns(maxOpenConns int) {
+	b.db.SetMaxOpenConns(maxOpenConns)
+}
+
+func (b *Backend) SetMaxIdleConns(maxIdleConns int) {
+	b.db.SetMaxIdleConns(maxIdleConns)
+}
+
+// parse parses the raw signed revocation and its contained revocation info.
+func parse(raw common.RawBytes) (*path_mgmt.SignedRevInfo, *path_mgmt.RevInfo, error) {
+	rev, err := path_mgmt.NewSignedRevInfoFromRaw(raw)
+	if err != nil {
+		return nil, nil, common.NewBasicError("Error unmarshalling revocation", err)
+	}
+	info, err := rev.RevInfo()
+	if err != nil {
+		return nil, nil, common.NewBasicError("Error unmarshalling revocation info", err)
+	}
+	return rev, info, nil
+}
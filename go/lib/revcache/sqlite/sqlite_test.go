@@ -0,0 +1,133 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/revcache"
+	"github.com/scionproto/scion/go/lib/revcache/revcachetest"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var (
+	ia110   = xtest.MustParseIA("1-ff00:0:110")
+	timeout = time.Second
+)
+
+var _ revcachetest.TestableRevCache = (*testRevCache)(nil)
+
+type testRevCache struct {
+	*Backend
+}
+
+func (c *testRevCache) InsertExpired(t *testing.T, ctx context.Context,
+	rev *path_mgmt.SignedRevInfo) {
+
+	info, err := rev.RevInfo()
+	xtest.FailOnErr(t, err)
+	packed, err := proto.PackRoot(rev)
+	xtest.FailOnErr(t, err)
+	ia := info.IA()
+	_, err = c.db.ExecContext(ctx, `INSERT OR REPLACE INTO Revocations
+		(IsdID, AsID, IfID, LinkType, IssuingTime, Expiration, RawSignedRev)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, ia.I, ia.A, info.IfID, info.LinkType,
+		info.RawTimestamp, info.Expiration().Unix(), packed)
+	xtest.FailOnErr(t, err)
+}
+
+func (c *testRevCache) Prepare(t *testing.T, _ context.Context) {
+	if c.Backend != nil {
+		xtest.FailOnErr(t, c.Backend.Close())
+	}
+	db, err := New(":memory:")
+	xtest.FailOnErr(t, err)
+	c.Backend = db
+}
+
+func TestRevCacheSuite(t *testing.T) {
+	Convey("RevCache Suite", t, func() {
+		revcachetest.TestRevCache(t, &testRevCache{})
+	})
+}
+
+func TestOpenExisting(t *testing.T) {
+	Convey("New should not overwrite an existing database if versions match", t, func() {
+		b, tmpF := setupDB(t)
+		defer os.Remove(tmpF)
+		ctx, cancelF := context.WithTimeout(context.Background(), timeout)
+		defer cancelF()
+		sr, err := path_mgmt.NewSignedRevInfo(&path_mgmt.RevInfo{
+			IfID:         15,
+			RawIsdas:     ia110.IAInt(),
+			LinkType:     proto.LinkType_core,
+			RawTimestamp: util.TimeToSecs(time.Now()),
+			RawTTL:       10,
+		}, nil)
+		xtest.FailOnErr(t, err)
+		_, err = b.Insert(ctx, sr)
+		xtest.FailOnErr(t, err)
+		b.db.Close()
+		// Call
+		b, err = New(tmpF)
+		xtest.FailOnErr(t, err)
+		// Test
+		// Check that the revocation is still there.
+		revs, err := b.Get(ctx, revcache.SingleKey(ia110, 15))
+		xtest.FailOnErr(t, err)
+		SoMsg("Revocation still exists", revs, ShouldResemble,
+			revcache.Revocations{*revcache.NewKey(ia110, 15): sr})
+	})
+}
+
+func TestOpenNewer(t *testing.T) {
+	Convey("New should not overwrite an existing database if it's of a newer version", t, func() {
+		b, tmpF := setupDB(t)
+		defer os.Remove(tmpF)
+		// Write a newer version
+		_, err := b.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion+1))
+		xtest.FailOnErr(t, err)
+		b.db.Close()
+		// Call
+		b, err = New(tmpF)
+		// Test
+		SoMsg("Backend nil", b, ShouldBeNil)
+		SoMsg("Err returned", err, ShouldNotBeNil)
+	})
+}
+
+func setupDB(t *testing.T) (*Backend, string) {
+	tmpFile := tempFilename(t)
+	b, err := New(tmpFile)
+	xtest.FailOnErr(t, err, "Failed to open DB")
+	return b, tmpFile
+}
+
+func tempFilename(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "revcache-sqlite")
+	xtest.FailOnErr(t, err)
+	return path.Join(dir, t.Name())
+}
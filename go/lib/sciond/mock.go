@@ -21,6 +21,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/hostinfo"
 	"github.com/scionproto/scion/go/lib/spath"
@@ -160,6 +161,13 @@ func (m *MockConn) RevNotification(ctx context.Context,
 	}, nil
 }
 
+// DRKeyLvl2 is not implemented.
+func (m *MockConn) DRKeyLvl2(ctx context.Context,
+	req *drkey_mgmt.Lvl2Req) (*drkey_mgmt.Lvl2Rep, error) {
+
+	panic("not implemented")
+}
+
 // Close is a no-op.
 func (m *MockConn) Close(ctx context.Context) error {
 	return nil
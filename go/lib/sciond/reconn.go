@@ -20,6 +20,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/proto"
 )
@@ -124,6 +125,17 @@ func (c *reconnector) RevNotification(ctx context.Context,
 	return conn.RevNotification(ctx, sRevInfo)
 }
 
+func (c *reconnector) DRKeyLvl2(ctx context.Context,
+	req *drkey_mgmt.Lvl2Req) (*drkey_mgmt.Lvl2Rep, error) {
+
+	conn, err := c.ctxAwareConnect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+	return conn.DRKeyLvl2(ctx, req)
+}
+
 func (c *reconnector) Close(ctx context.Context) error {
 	return nil
 }
@@ -39,6 +39,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra/disp"
 	"github.com/scionproto/scion/go/lib/infra/transport"
@@ -127,6 +128,9 @@ type Connector interface {
 	RevNotificationFromRaw(ctx context.Context, b []byte) (*RevReply, error)
 	// RevNotification sends a RevocationInfo message to SCIOND.
 	RevNotification(ctx context.Context, sRevInfo *path_mgmt.SignedRevInfo) (*RevReply, error)
+	// DRKeyLvl2 requests a second-level DRKey from SCIOND, which fetches it
+	// from the local certificate server.
+	DRKeyLvl2(ctx context.Context, req *drkey_mgmt.Lvl2Req) (*drkey_mgmt.Lvl2Rep, error)
 	// Close shuts down the connection to a SCIOND server.
 	Close(ctx context.Context) error
 }
@@ -353,6 +357,30 @@ func (c *connector) RevNotification(ctx context.Context,
 	return reply.(*Pld).RevReply, nil
 }
 
+func (c *connector) DRKeyLvl2(ctx context.Context,
+	req *drkey_mgmt.Lvl2Req) (*drkey_mgmt.Lvl2Rep, error) {
+
+	c.Lock()
+	defer c.Unlock()
+	reply, err := c.dispatcher.Request(
+		ctx,
+		&Pld{
+			Id:           c.nextID(),
+			Which:        proto.SCIONDMsg_Which_drkeyLvl2Req,
+			DRKeyLvl2Req: req,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, common.NewBasicError("[sciond-API] Failed to get DRKey", err)
+	}
+	rep := reply.(*Pld).DRKeyLvl2Rep
+	if len(rep.DRKey) == 0 {
+		return nil, common.NewBasicError("[sciond-API] SCIOND was unable to fetch DRKey", nil)
+	}
+	return rep, nil
+}
+
 func (c *connector) Close(ctx context.Context) error {
 	return c.dispatcher.Close(ctx)
 }
@@ -16,6 +16,7 @@ package scmp
 
 import (
 	"fmt"
+	"strings"
 )
 
 // https://github.com/scionproto/scion/blob/master/lib/packet/scmp/types.py
@@ -34,12 +35,21 @@ const (
 var classNames = []string{"GENERAL", "ROUTING", "CMNHDR", "PATH", "EXT", "SIBRA"}
 
 func (c Class) String() string {
-	if int(c) > len(classNames) {
+	if int(c) >= len(classNames) {
 		return fmt.Sprintf("Class(%d)", c)
 	}
 	return fmt.Sprintf("%s(%d)", classNames[c], c)
 }
 
+// Label returns the lower case name of the class, e.g., path. It is suitable
+// as metrics label.
+func (c Class) Label() string {
+	if int(c) >= len(classNames) {
+		return fmt.Sprintf("class_%d", c)
+	}
+	return strings.ToLower(classNames[c])
+}
+
 type Type uint16
 
 // C_General types
@@ -105,7 +115,8 @@ const (
 )
 
 var typeNameMap = map[Class][]string{
-	C_General: {"UNSPECIFIED", "ECHO_REQEST", "ECHO_REPLY"},
+	C_General: {"UNSPECIFIED", "ECHO_REQEST", "ECHO_REPLY", "TRACEROUTE_REQUEST",
+		"TRACEROUTE_REPLY", "RECORDPATH_REQUEST", "RECORDPATH_REPLY"},
 	C_Routing: {"UNREACH_NET", "UNREACH_HOST", "L2_ERROR", "UNREACH_PROTO",
 		"UNREACH_PORT", "UNKNOWN_HOST", "BAD_HOST", "OVERSIZE_PKT", "ADMIN_DENIED"},
 	C_CmnHdr: {"BAD_VERSION", "BAD_DST_TYPE", "BAD_SRC_TYPE",
@@ -120,12 +131,22 @@ var typeNameMap = map[Class][]string{
 
 func (t Type) Name(c Class) string {
 	names, ok := typeNameMap[c]
-	if !ok || int(t) > len(names) {
+	if !ok || int(t) >= len(names) {
 		return fmt.Sprintf("Type(%d)", t)
 	}
 	return fmt.Sprintf("%s(%d)", names[t], t)
 }
 
+// Label returns the lower case name of the type in class c, e.g., bad_mac. It
+// is suitable as metrics label.
+func (t Type) Label(c Class) string {
+	names, ok := typeNameMap[c]
+	if !ok || int(t) >= len(names) {
+		return fmt.Sprintf("type_%d", t)
+	}
+	return strings.ToLower(names[t])
+}
+
 type ClassType struct {
 	Class Class
 	Type  Type
@@ -135,6 +156,12 @@ func (ct ClassType) String() string {
 	return fmt.Sprintf("%v:%v", ct.Class, ct.Type.Name(ct.Class))
 }
 
+// Label returns the class and type labels joined by an underscore, e.g.,
+// path_bad_mac.
+func (ct ClassType) Label() string {
+	return ct.Class.Label() + "_" + ct.Type.Label(ct.Class)
+}
+
 // Used to specify parts of packets to quote
 type RawBlock int
 
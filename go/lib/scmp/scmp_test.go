@@ -0,0 +1,72 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scmp
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClass(t *testing.T) {
+	Convey("Class", t, func() {
+		Convey("known classes are named", func() {
+			SoMsg("string", C_Path.String(), ShouldEqual, "PATH(3)")
+			SoMsg("label", C_Path.Label(), ShouldEqual, "path")
+			SoMsg("last string", C_Sibra.String(), ShouldEqual, "SIBRA(5)")
+			SoMsg("last label", C_Sibra.Label(), ShouldEqual, "sibra")
+		})
+		Convey("unknown classes are numbered", func() {
+			c := Class(len(classNames))
+			SoMsg("string", c.String(), ShouldEqual, "Class(6)")
+			SoMsg("label", c.Label(), ShouldEqual, "class_6")
+		})
+	})
+}
+
+func TestType(t *testing.T) {
+	Convey("Type", t, func() {
+		Convey("known types are named", func() {
+			SoMsg("name", T_P_BadMac.Name(C_Path), ShouldEqual, "BAD_MAC(1)")
+			SoMsg("label", T_P_BadMac.Label(C_Path), ShouldEqual, "bad_mac")
+			SoMsg("traceroute", T_G_TraceRouteRequest.Name(C_General), ShouldEqual,
+				"TRACEROUTE_REQUEST(3)")
+			SoMsg("last name", T_G_RecordPathReply.Name(C_General), ShouldEqual,
+				"RECORDPATH_REPLY(6)")
+			SoMsg("last label", T_G_RecordPathReply.Label(C_General), ShouldEqual,
+				"recordpath_reply")
+		})
+		Convey("unknown types are numbered", func() {
+			typ := Type(len(typeNameMap[C_General]))
+			SoMsg("name", typ.Name(C_General), ShouldEqual, "Type(7)")
+			SoMsg("label", typ.Label(C_General), ShouldEqual, "type_7")
+		})
+		Convey("types of unknown classes are numbered", func() {
+			c := Class(len(classNames))
+			SoMsg("name", T_G_EchoRequest.Name(c), ShouldEqual, "Type(1)")
+			SoMsg("label", T_G_EchoRequest.Label(c), ShouldEqual, "type_1")
+		})
+	})
+}
+
+func TestClassTypeLabel(t *testing.T) {
+	Convey("ClassType.Label joins the class and type labels", t, func() {
+		SoMsg("bad mac", ClassType{C_Path, T_P_BadMac}.Label(), ShouldEqual, "path_bad_mac")
+		SoMsg("expired", ClassType{C_Path, T_P_ExpiredHopF}.Label(), ShouldEqual,
+			"path_expired_hopf")
+		SoMsg("unknown", ClassType{C_Path, Type(len(typeNameMap[C_Path]))}.Label(),
+			ShouldEqual, "path_type_10")
+	})
+}
@@ -15,9 +15,11 @@
 package scrypto
 
 import (
+	"crypto/elliptic"
 	"crypto/rand"
 	"strings"
 
+	"golang.org/x/crypto/curve25519"
 	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/nacl/box"
 
@@ -27,6 +29,8 @@ import (
 // Available asymmetric crypto algorithms. The values must be lower case.
 const (
 	Ed25519                    = "ed25519"
+	ECDSAP256                  = "ecdsap256"
+	ECDSAP384                  = "ecdsap384"
 	Curve25519xSalsa20Poly1305 = "curve25519xsalsa20poly1305"
 )
 
@@ -39,6 +43,8 @@ const (
 const (
 	InvalidPubKeySize       = "Invalid public key size"
 	InvalidPrivKeySize      = "Invalid private key size"
+	InvalidPubKeyFormat     = "Invalid public key format"
+	InvalidPrivKeyFormat    = "Invalid private key format"
 	InvalidSignatureSize    = "Invalid signature size"
 	InvalidSignatureFormat  = "Invalid signature format: sig[63]&224 should equal 0"
 	VerificationError       = "Signature verification failed"
@@ -66,13 +72,50 @@ func GenKeyPair(algo string) (common.RawBytes, common.RawBytes, error) {
 				"algo", algo)
 		}
 		return common.RawBytes(pubkey), common.RawBytes(privkey), nil
+	case ECDSAP256, ECDSAP384:
+		pubkey, privkey, err := ecdsaAlgos[strings.ToLower(algo)].genKeyPair()
+		if err != nil {
+			return nil, nil, common.NewBasicError(UnableToGenerateKeyPair, err,
+				"algo", algo)
+		}
+		return pubkey, privkey, nil
 	default:
 		return nil, nil, common.NewBasicError(UnsupportedAlgo, nil, "algo", algo)
 	}
 }
 
-// Sign takes a signature input and a signing key to create a signature. Currently only
-// ed25519 is supported.
+// PubKey derives the public key from a private key as returned by GenKeyPair.
+func PubKey(privKey common.RawBytes, algo string) (common.RawBytes, error) {
+	switch strings.ToLower(algo) {
+	case Curve25519xSalsa20Poly1305:
+		if len(privKey) != NaClBoxKeySize {
+			return nil, common.NewBasicError(InvalidPrivKeySize, nil, "expected",
+				NaClBoxKeySize, "actual", len(privKey))
+		}
+		var privKeyRaw, pubKeyRaw [NaClBoxKeySize]byte
+		copy(privKeyRaw[:], privKey)
+		curve25519.ScalarBaseMult(&pubKeyRaw, &privKeyRaw)
+		return pubKeyRaw[:], nil
+	case Ed25519:
+		if len(privKey) != ed25519.PrivateKeySize {
+			return nil, common.NewBasicError(InvalidPrivKeySize, nil, "expected",
+				ed25519.PrivateKeySize, "actual", len(privKey))
+		}
+		return common.RawBytes(ed25519.PrivateKey(privKey).Public().(ed25519.PublicKey)), nil
+	case ECDSAP256, ECDSAP384:
+		a := ecdsaAlgos[strings.ToLower(algo)]
+		priv, err := a.privKey(privKey)
+		if err != nil {
+			return nil, err
+		}
+		return elliptic.Marshal(a.curve, priv.X, priv.Y), nil
+	default:
+		return nil, common.NewBasicError(UnsupportedAlgo, nil, "algo", algo)
+	}
+}
+
+// Sign takes a signature input and a signing key to create a signature. The
+// supported algorithms are ed25519, ecdsap256 and ecdsap384.
 func Sign(sigInput, signKey common.RawBytes, signAlgo string) (common.RawBytes, error) {
 	switch strings.ToLower(signAlgo) {
 	case Ed25519:
@@ -81,13 +124,16 @@ func Sign(sigInput, signKey common.RawBytes, signAlgo string) (common.RawBytes,
 				ed25519.PrivateKeySize, "actual", len(signKey))
 		}
 		return ed25519.Sign(ed25519.PrivateKey(signKey), sigInput), nil
+	case ECDSAP256, ECDSAP384:
+		return ecdsaAlgos[strings.ToLower(signAlgo)].sign(sigInput, signKey)
 	default:
 		return nil, common.NewBasicError(UnsupportedSignAlgo, nil, "algo", signAlgo)
 	}
 }
 
 // Verify takes a signature input and a verifying key and returns an error, if the
-// signature does not match. Currently only ed25519 is supported.
+// signature does not match. The supported algorithms are ed25519, ecdsap256
+// and ecdsap384.
 func Verify(sigInput, sig, verifyKey common.RawBytes, signAlgo string) error {
 	switch strings.ToLower(signAlgo) {
 	case Ed25519:
@@ -106,6 +152,8 @@ func Verify(sigInput, sig, verifyKey common.RawBytes, signAlgo string) error {
 			return common.NewBasicError(VerificationError, nil, "msg", sigInput)
 		}
 		return nil
+	case ECDSAP256, ECDSAP384:
+		return ecdsaAlgos[strings.ToLower(signAlgo)].verify(sigInput, sig, verifyKey)
 	default:
 		return common.NewBasicError(UnsupportedSignAlgo, nil, "algo", signAlgo)
 	}
@@ -40,6 +40,18 @@ var (
 		`6bd710a368c1249923fc7a1610747403040f0cc30815a00f9ff548a896bbda0b4eb2ca19ebcf917f0f34200a9e
 		dbad3901b64ab09cc5ef7b9bcc3c40c0ff7509`)
 
+	// ECDSA P-256 test vectors
+	// Taken from RFC 6979, Appendix A.2.5 (SHA-256, message "sample")
+	ECDSAP256TestPrivateKey = xtest.MustParseHexString(
+		`c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721`)
+	ECDSAP256TestPublicKey = xtest.MustParseHexString(
+		`0460fed4ba255a9d31c961eb74c6356d68c049b8923b61fa6ce669622e60f29fb67903fe1008b8bc99a41ae9
+		e95628bc64f2f1b20c2d7e9f5177a3c294d4462299`)
+	ECDSAP256TestMsg       = common.RawBytes("sample")
+	ECDSAP256TestSignature = xtest.MustParseHexString(
+		`efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716f7cb1c942d657c41d436c7a1
+		b6e29f65f3e900dbb9aff4064dc4ab2f843acda8`)
+
 	// NaClBox test vectors
 	// Taken from the NaCl distribution:
 	// https://github.com/jedisct1/libsodium/blob/1.0.16/test/default/box.c
@@ -82,12 +94,61 @@ func TestGenKeyPairs(t *testing.T) {
 		SoMsg("rawPrivkey", rawPrivkey, ShouldNotResemble, newPrivkey)
 	})
 
+	Convey("GenKeyPairs should return valid ECDSA key pairs", t, func() {
+		tests := []struct {
+			algo     string
+			pubSize  int
+			privSize int
+		}{
+			{ECDSAP256, ECDSAP256PubKeySize, ECDSAP256PrivKeySize},
+			{ECDSAP384, ECDSAP384PubKeySize, ECDSAP384PrivKeySize},
+		}
+		for _, test := range tests {
+			rawPubkey, rawPrivkey, err := GenKeyPair(test.algo)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("rawPubkey", len(rawPubkey), ShouldEqual, test.pubSize)
+			SoMsg("rawPrivkey", len(rawPrivkey), ShouldEqual, test.privSize)
+			pubKey, err := PubKey(rawPrivkey, test.algo)
+			SoMsg("PubKey err", err, ShouldBeNil)
+			SoMsg("PubKey", pubKey, ShouldResemble, rawPubkey)
+		}
+	})
+
 	Convey("GenKeyPairs should throw error for unknown algo", t, func() {
 		_, _, err := GenKeyPair("asdf")
 		SoMsg("err", err, ShouldNotBeNil)
 	})
 }
 
+func TestPubKey(t *testing.T) {
+	Convey("PubKey should derive the public key", t, func() {
+		tests := []struct {
+			algo    string
+			privKey common.RawBytes
+			pubKey  common.RawBytes
+		}{
+			{Ed25519, common.RawBytes(ed25519.NewKeyFromSeed(Ed25519TestPrivateKey)),
+				Ed25519TestPublicKey},
+			{ECDSAP256, ECDSAP256TestPrivateKey, ECDSAP256TestPublicKey},
+		}
+		for _, test := range tests {
+			pubKey, err := PubKey(test.privKey, test.algo)
+			SoMsg(test.algo+" err", err, ShouldBeNil)
+			SoMsg(test.algo+" pubKey", pubKey, ShouldResemble, test.pubKey)
+		}
+	})
+
+	Convey("PubKey should throw error for invalid key size", t, func() {
+		_, err := PubKey(ECDSAP256TestPrivateKey[:31], ECDSAP256)
+		SoMsg("err", err, ShouldNotBeNil)
+	})
+
+	Convey("PubKey should throw error for unknown algo", t, func() {
+		_, err := PubKey(ECDSAP256TestPrivateKey, "asdf")
+		SoMsg("err", err, ShouldNotBeNil)
+	})
+}
+
 func TestSign(t *testing.T) {
 	// Note from: https://godoc.org/golang.org/x/crypto/ed25519
 	// "...this package's private key representation includes a public key suffix to make
@@ -104,6 +165,23 @@ func TestSign(t *testing.T) {
 		SoMsg("err", err, ShouldNotBeNil)
 	})
 
+	Convey("Sign should create verifiable ECDSA signatures", t, func() {
+		for _, algo := range []string{ECDSAP256, ECDSAP384} {
+			pubKey, privKey, err := GenKeyPair(algo)
+			SoMsg("GenKeyPair err", err, ShouldBeNil)
+			sig, err := Sign(Ed25519TestMsg, privKey, algo)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("Verify", Verify(Ed25519TestMsg, sig, pubKey, algo), ShouldBeNil)
+		}
+	})
+
+	Convey("Sign should throw error for invalid ECDSA key", t, func() {
+		_, err := Sign(ECDSAP256TestMsg, ECDSAP256TestPrivateKey[:31], ECDSAP256)
+		SoMsg("size err", err, ShouldNotBeNil)
+		_, err = Sign(ECDSAP256TestMsg, make(common.RawBytes, ECDSAP256PrivKeySize), ECDSAP256)
+		SoMsg("zero err", err, ShouldNotBeNil)
+	})
+
 	Convey("Sign should throw error for unknown algo", t, func() {
 		_, err := Sign(Ed25519TestMsg, privKey, "asdf")
 		SoMsg("err", err, ShouldNotBeNil)
@@ -126,6 +204,31 @@ func TestVerify(t *testing.T) {
 		SoMsg("err", err, ShouldNotBeNil)
 	})
 
+	Convey("Verify should verify ECDSA signature correctly", t, func() {
+		err := Verify(ECDSAP256TestMsg, ECDSAP256TestSignature, ECDSAP256TestPublicKey, ECDSAP256)
+		SoMsg("err", err, ShouldBeNil)
+	})
+
+	Convey("Verify should throw an error for an invalid ECDSA signature", t, func() {
+		sig := append(common.RawBytes(nil), ECDSAP256TestSignature...)
+		sig[0] ^= 0xff
+		err := Verify(ECDSAP256TestMsg, sig, ECDSAP256TestPublicKey, ECDSAP256)
+		SoMsg("modified", err, ShouldNotBeNil)
+		err = Verify(ECDSAP256TestMsg, ECDSAP256TestSignature[:63], ECDSAP256TestPublicKey,
+			ECDSAP256)
+		SoMsg("size", err, ShouldNotBeNil)
+	})
+
+	Convey("Verify should throw an error for an invalid ECDSA key", t, func() {
+		err := Verify(ECDSAP256TestMsg, ECDSAP256TestSignature, ECDSAP256TestPublicKey[:64],
+			ECDSAP256)
+		SoMsg("size", err, ShouldNotBeNil)
+		key := append(common.RawBytes(nil), ECDSAP256TestPublicKey...)
+		key[64] ^= 0xff
+		err = Verify(ECDSAP256TestMsg, ECDSAP256TestSignature, key, ECDSAP256)
+		SoMsg("not on curve", err, ShouldNotBeNil)
+	})
+
 	Convey("Verify should throw an error for unknown algo", t, func() {
 		err := Verify(Ed25519TestMsg, Ed25519TestSignature, Ed25519TestPublicKey, "asdf")
 		SoMsg("err", err, ShouldNotBeNil)
@@ -0,0 +1,133 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/big"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Sizes of the ECDSA keys and signatures. Private keys are encoded as the
+// big-endian private scalar, public keys as uncompressed curve points, and
+// signatures as the concatenation of r and s. All integers are padded to the
+// size of the curve.
+const (
+	ECDSAP256PrivKeySize   = 32
+	ECDSAP256PubKeySize    = 65
+	ECDSAP256SignatureSize = 64
+	ECDSAP384PrivKeySize   = 48
+	ECDSAP384PubKeySize    = 97
+	ECDSAP384SignatureSize = 96
+)
+
+// ecdsaAlgo contains the parameters of an ECDSA signature algorithm.
+type ecdsaAlgo struct {
+	curve elliptic.Curve
+	hash  func() hash.Hash
+	// size is the size of the private scalar and the point coordinates.
+	size int
+}
+
+var ecdsaAlgos = map[string]ecdsaAlgo{
+	ECDSAP256: {curve: elliptic.P256(), hash: sha256.New, size: ECDSAP256PrivKeySize},
+	ECDSAP384: {curve: elliptic.P384(), hash: sha512.New384, size: ECDSAP384PrivKeySize},
+}
+
+func (a ecdsaAlgo) genKeyPair() (common.RawBytes, common.RawBytes, error) {
+	priv, err := ecdsa.GenerateKey(a.curve, rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return elliptic.Marshal(a.curve, priv.X, priv.Y), a.pad(priv.D), nil
+}
+
+func (a ecdsaAlgo) sign(sigInput, signKey common.RawBytes) (common.RawBytes, error) {
+	priv, err := a.privKey(signKey)
+	if err != nil {
+		return nil, err
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, priv, a.digest(sigInput))
+	if err != nil {
+		return nil, err
+	}
+	return append(a.pad(r), a.pad(s)...), nil
+}
+
+func (a ecdsaAlgo) verify(sigInput, sig, verifyKey common.RawBytes) error {
+	pub, err := a.pubKey(verifyKey)
+	if err != nil {
+		return err
+	}
+	if len(sig) != 2*a.size {
+		return common.NewBasicError(InvalidSignatureSize, nil,
+			"expected", 2*a.size, "actual", len(sig))
+	}
+	r := new(big.Int).SetBytes(sig[:a.size])
+	s := new(big.Int).SetBytes(sig[a.size:])
+	if !ecdsa.Verify(pub, a.digest(sigInput), r, s) {
+		return common.NewBasicError(VerificationError, nil, "msg", sigInput)
+	}
+	return nil
+}
+
+// privKey parses the private scalar and derives the public point.
+func (a ecdsaAlgo) privKey(raw common.RawBytes) (*ecdsa.PrivateKey, error) {
+	if len(raw) != a.size {
+		return nil, common.NewBasicError(InvalidPrivKeySize, nil,
+			"expected", a.size, "actual", len(raw))
+	}
+	d := new(big.Int).SetBytes(raw)
+	if d.Sign() == 0 || d.Cmp(a.curve.Params().N) >= 0 {
+		return nil, common.NewBasicError(InvalidPrivKeyFormat, nil)
+	}
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.Curve = a.curve
+	priv.X, priv.Y = a.curve.ScalarBaseMult(raw)
+	return priv, nil
+}
+
+// pubKey parses the uncompressed public point.
+func (a ecdsaAlgo) pubKey(raw common.RawBytes) (*ecdsa.PublicKey, error) {
+	if len(raw) != 1+2*a.size {
+		return nil, common.NewBasicError(InvalidPubKeySize, nil,
+			"expected", 1+2*a.size, "actual", len(raw))
+	}
+	x, y := elliptic.Unmarshal(a.curve, raw)
+	if x == nil || !a.curve.IsOnCurve(x, y) {
+		return nil, common.NewBasicError(InvalidPubKeyFormat, nil)
+	}
+	return &ecdsa.PublicKey{Curve: a.curve, X: x, Y: y}, nil
+}
+
+func (a ecdsaAlgo) digest(input common.RawBytes) []byte {
+	h := a.hash()
+	h.Write(input)
+	return h.Sum(nil)
+}
+
+// pad encodes the integer in big-endian and pads it to the size of the curve.
+func (a ecdsaAlgo) pad(i *big.Int) common.RawBytes {
+	b := i.Bytes()
+	raw := make(common.RawBytes, a.size)
+	copy(raw[a.size-len(b):], b)
+	return raw
+}
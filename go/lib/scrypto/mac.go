@@ -16,9 +16,11 @@ package scrypto
 
 import (
 	"crypto/aes"
+	"crypto/sha256"
 	"hash"
 
 	"github.com/dchest/cmac"
+	"golang.org/x/crypto/pbkdf2"
 
 	"github.com/scionproto/scion/go/lib/common"
 )
@@ -39,3 +41,19 @@ func InitMac(key common.RawBytes) (hash.Hash, error) {
 	}
 	return mac, nil
 }
+
+// HFMacFactory derives the hop field MAC key from the AS master key and
+// returns a function that creates MAC instances for that key.
+func HFMacFactory(masterKey common.RawBytes) (func() hash.Hash, error) {
+	// This uses 16B keys with 1000 hash iterations, which is the same as the
+	// defaults used by pycrypto.
+	hfGenKey := pbkdf2.Key(masterKey, []byte("Derive OF Key"), 1000, 16, sha256.New)
+	// First check for MAC creation errors.
+	if _, err := InitMac(hfGenKey); err != nil {
+		return nil, err
+	}
+	return func() hash.Hash {
+		mac, _ := InitMac(hfGenKey)
+		return mac
+	}, nil
+}
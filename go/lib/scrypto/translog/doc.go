@@ -0,0 +1,34 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package translog implements an append-only transparency log of issued
+// certificate chains.
+//
+// The log is a Merkle tree as specified in RFC 6962, Section 2.1. The tree
+// head is signed by the issuer that maintains the log. Auditors fetch signed
+// tree heads, and verify with inclusion proofs that a certificate chain is
+// part of the log, and with consistency proofs that the log has only been
+// appended to between two tree heads.
+//
+// The log is exposed over HTTP (see NewHandler) with the following endpoints:
+//
+//	GET /translog/sth
+//		The current signed tree head.
+//	GET /translog/entry?index=<index>
+//		The log entry at index.
+//	GET /translog/proof/inclusion?hash=<hex leaf hash>&size=<tree size>
+//		The inclusion proof of the leaf in the tree of the given size.
+//	GET /translog/proof/consistency?first=<tree size>&second=<tree size>
+//		The consistency proof between the two tree sizes.
+package translog
@@ -0,0 +1,243 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+)
+
+// HTTP endpoints of the log. PathPrefix is the common prefix of all
+// endpoints.
+const (
+	PathPrefix      = "/translog/"
+	PathSTH         = PathPrefix + "sth"
+	PathEntry       = PathPrefix + "entry"
+	PathInclusion   = PathPrefix + "proof/inclusion"
+	PathConsistency = PathPrefix + "proof/consistency"
+)
+
+// STHFunc returns a freshly signed tree head for the current log size.
+type STHFunc func() (*SignedTreeHead, error)
+
+// NewHandler returns the HTTP handler that serves the log endpoints. Tree
+// heads are created with sth.
+func NewHandler(l *Log, sth STHFunc) http.Handler {
+	h := &handler{log: l, sth: sth, mux: http.NewServeMux()}
+	h.mux.HandleFunc(PathSTH, h.handleSTH)
+	h.mux.HandleFunc(PathEntry, h.handleEntry)
+	h.mux.HandleFunc(PathInclusion, h.handleInclusion)
+	h.mux.HandleFunc(PathConsistency, h.handleConsistency)
+	return h
+}
+
+type handler struct {
+	log *Log
+	sth STHFunc
+	mux *http.ServeMux
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) handleSTH(w http.ResponseWriter, r *http.Request) {
+	sth, err := h.sth()
+	if err != nil {
+		log.Error("[translog] Unable to create signed tree head", "err", err)
+		http.Error(w, "Unable to create signed tree head", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, sth)
+}
+
+func (h *handler) handleEntry(w http.ResponseWriter, r *http.Request) {
+	index, err := parseUint(r, "index")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry, err := h.log.Entry(index)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(entry)
+}
+
+func (h *handler) handleInclusion(w http.ResponseWriter, r *http.Request) {
+	leafHash, err := hex.DecodeString(r.URL.Query().Get("hash"))
+	if err != nil {
+		http.Error(w, "Invalid hash", http.StatusBadRequest)
+		return
+	}
+	size, err := parseUint(r, "size")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	index, ok := h.log.Index(leafHash)
+	if !ok {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+	proof, err := h.log.InclusionProof(index, size)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, proof)
+}
+
+func (h *handler) handleConsistency(w http.ResponseWriter, r *http.Request) {
+	first, err := parseUint(r, "first")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	second, err := parseUint(r, "second")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	proof, err := h.log.ConsistencyProof(first, second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, proof)
+}
+
+func parseUint(r *http.Request, param string) (uint64, error) {
+	v, err := strconv.ParseUint(r.URL.Query().Get(param), 10, 64)
+	if err != nil {
+		return 0, common.NewBasicError("Invalid parameter", err, "param", param)
+	}
+	return v, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(raw)
+}
+
+// Client fetches tree heads and proofs from a log.
+type Client struct {
+	// URL is the base URL of the log, e.g., http://192.0.2.1:30454.
+	URL string
+	// HTTP is the client used for the requests. If nil, http.DefaultClient
+	// is used.
+	HTTP *http.Client
+}
+
+// STH fetches the current signed tree head. The signature is not verified.
+func (c *Client) STH(ctx context.Context) (*SignedTreeHead, error) {
+	sth := &SignedTreeHead{}
+	if err := c.get(ctx, PathSTH, nil, sth); err != nil {
+		return nil, err
+	}
+	return sth, nil
+}
+
+// Entry fetches the entry at index.
+func (c *Client) Entry(ctx context.Context, index uint64) (common.RawBytes, error) {
+	var entry json.RawMessage
+	q := url.Values{"index": {strconv.FormatUint(index, 10)}}
+	if err := c.get(ctx, PathEntry, q, &entry); err != nil {
+		return nil, err
+	}
+	return common.RawBytes(entry), nil
+}
+
+// InclusionProof fetches the inclusion proof of the leaf in the tree of the
+// given size.
+func (c *Client) InclusionProof(ctx context.Context, leafHash common.RawBytes,
+	size uint64) (*InclusionProof, error) {
+
+	q := url.Values{
+		"hash": {hex.EncodeToString(leafHash)},
+		"size": {strconv.FormatUint(size, 10)},
+	}
+	proof := &InclusionProof{}
+	if err := c.get(ctx, PathInclusion, q, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// ConsistencyProof fetches the consistency proof between the two tree sizes.
+func (c *Client) ConsistencyProof(ctx context.Context,
+	first, second uint64) (*ConsistencyProof, error) {
+
+	q := url.Values{
+		"first":  {strconv.FormatUint(first, 10)},
+		"second": {strconv.FormatUint(second, 10)},
+	}
+	proof := &ConsistencyProof{}
+	if err := c.get(ctx, PathConsistency, q, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, q url.Values, v interface{}) error {
+	u := c.URL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rep, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return common.NewBasicError("Request failed", err, "url", u)
+	}
+	defer rep.Body.Close()
+	raw, err := ioutil.ReadAll(rep.Body)
+	if err != nil {
+		return common.NewBasicError("Unable to read reply", err, "url", u)
+	}
+	if rep.StatusCode != http.StatusOK {
+		return common.NewBasicError("Request failed", nil, "url", u,
+			"status", rep.Status, "msg", string(raw))
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return common.NewBasicError("Unable to parse reply", err, "url", u)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestClient(t *testing.T) {
+	Convey("Client fetches verifiable tree heads and proofs", t, func() {
+		l, cleanF := newTestLog(t, 5)
+		defer cleanF()
+		pub, priv, err := scrypto.GenKeyPair(scrypto.Ed25519)
+		SoMsg("err", err, ShouldBeNil)
+		issuer := &cert.Certificate{
+			Subject:        xtest.MustParseIA("1-ff00:0:110"),
+			Version:        2,
+			SubjectSignKey: pub,
+			SignAlgorithm:  scrypto.Ed25519,
+		}
+		sthF := func() (*SignedTreeHead, error) {
+			size := l.Size()
+			root, err := l.RootHash(size)
+			if err != nil {
+				return nil, err
+			}
+			sth := &SignedTreeHead{
+				IA:            issuer.Subject,
+				IssuerVersion: issuer.Version,
+				TreeSize:      size,
+				Timestamp:     util.TimeToSecs(time.Now()),
+				RootHash:      root,
+			}
+			return sth, sth.Sign(priv, scrypto.Ed25519)
+		}
+		srv := httptest.NewServer(NewHandler(l, sthF))
+		defer srv.Close()
+		c := &Client{URL: srv.URL}
+		ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+		defer cancelF()
+
+		oldSTH, err := c.STH(ctx)
+		SoMsg("sth err", err, ShouldBeNil)
+		SoMsg("sth size", oldSTH.TreeSize, ShouldEqual, 5)
+		SoMsg("sth verify", oldSTH.Verify(issuer), ShouldBeNil)
+
+		entry := common.RawBytes("{\"new\":\"entry\"}")
+		l.Append(entry)
+		sth, err := c.STH(ctx)
+		SoMsg("new sth err", err, ShouldBeNil)
+		SoMsg("new sth verify", sth.Verify(issuer), ShouldBeNil)
+
+		p, err := c.InclusionProof(ctx, LeafHash(entry), sth.TreeSize)
+		SoMsg("inclusion err", err, ShouldBeNil)
+		SoMsg("inclusion verify", p.Verify(LeafHash(entry), sth.RootHash), ShouldBeNil)
+
+		cp, err := c.ConsistencyProof(ctx, oldSTH.TreeSize, sth.TreeSize)
+		SoMsg("consistency err", err, ShouldBeNil)
+		SoMsg("consistency verify", cp.Verify(oldSTH.RootHash, sth.RootHash), ShouldBeNil)
+
+		raw, err := c.Entry(ctx, 5)
+		SoMsg("entry err", err, ShouldBeNil)
+		SoMsg("entry", raw, ShouldResemble, entry)
+
+		_, err = c.InclusionProof(ctx, LeafHash(common.RawBytes("missing")), sth.TreeSize)
+		SoMsg("missing entry", err, ShouldNotBeNil)
+	})
+}
+
+func TestSignedTreeHead(t *testing.T) {
+	Convey("Signed tree heads are bound to the issuer certificate", t, func() {
+		pub, priv, _ := scrypto.GenKeyPair(scrypto.Ed25519)
+		issuer := &cert.Certificate{
+			Subject:        xtest.MustParseIA("1-ff00:0:110"),
+			Version:        1,
+			SubjectSignKey: pub,
+			SignAlgorithm:  scrypto.Ed25519,
+		}
+		sth := &SignedTreeHead{IA: issuer.Subject, IssuerVersion: 1, TreeSize: 3,
+			RootHash: LeafHash(nil)}
+		SoMsg("sign", sth.Sign(priv, scrypto.Ed25519), ShouldBeNil)
+		SoMsg("verify", sth.Verify(issuer), ShouldBeNil)
+		sth.TreeSize = 4
+		SoMsg("modified", sth.Verify(issuer), ShouldNotBeNil)
+		sth.TreeSize = 3
+		issuer.Version = 2
+		SoMsg("wrong issuer", sth.Verify(issuer), ShouldNotBeNil)
+	})
+}
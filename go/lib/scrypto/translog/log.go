@@ -0,0 +1,245 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+const (
+	// entryHdrLen is the length of the entry header, which contains the
+	// length of the entry.
+	entryHdrLen = 4
+	// MaxEntryLen is the maximum length of a log entry.
+	MaxEntryLen = 1 << 20
+)
+
+const (
+	ErrEntryTooLong = "Entry too long"
+	ErrInvalidIndex = "Invalid index"
+	ErrInvalidSize  = "Invalid tree size"
+)
+
+// Log is an append-only log that is stored in a file. Each entry is stored as
+// its length (4 bytes, big endian), followed by the entry itself. Entries are
+// never modified or removed. All methods are safe for concurrent use.
+type Log struct {
+	mu   sync.RWMutex
+	file logFile
+	// size is the size of the file up to the end of the last complete entry.
+	size    int64
+	entries []common.RawBytes
+	hashes  []common.RawBytes
+}
+
+// logFile is the subset of the *os.File methods that are used by the log.
+type logFile interface {
+	io.Writer
+	io.Seeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// Open opens the log stored in the file. If the file does not exist, an empty
+// log is created. An incomplete entry at the end of the file, which is the
+// result of an interrupted append, is discarded.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to open log", err, "path", path)
+	}
+	l := &Log{file: file}
+	var end int64
+	for {
+		entry, err := readEntry(file)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, common.NewBasicError("Unable to read log", err, "path", path,
+				"index", len(l.entries))
+		}
+		l.add(entry)
+		end += int64(entryHdrLen + len(entry))
+	}
+	if err := file.Truncate(end); err != nil {
+		file.Close()
+		return nil, common.NewBasicError("Unable to truncate log", err, "path", path)
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		file.Close()
+		return nil, common.NewBasicError("Unable to seek log", err, "path", path)
+	}
+	l.size = end
+	return l, nil
+}
+
+func readEntry(r io.Reader) (common.RawBytes, error) {
+	hdr := make([]byte, entryHdrLen)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	n := common.Order.Uint32(hdr)
+	if n > MaxEntryLen {
+		return nil, common.NewBasicError(ErrEntryTooLong, nil, "len", n)
+	}
+	entry := make(common.RawBytes, n)
+	if _, err := io.ReadFull(r, entry); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Append appends the entry to the log and returns its index. The entry is
+// synced to disk before Append returns. If the entry cannot be written, the
+// file is truncated to its previous size, such that no partial entry remains.
+func (l *Log) Append(entry common.RawBytes) (uint64, error) {
+	if len(entry) > MaxEntryLen {
+		return 0, common.NewBasicError(ErrEntryTooLong, nil, "len", len(entry))
+	}
+	raw := make([]byte, entryHdrLen+len(entry))
+	common.Order.PutUint32(raw, uint32(len(entry)))
+	copy(raw[entryHdrLen:], entry)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(raw); err != nil {
+		return 0, l.rollback(common.NewBasicError("Unable to write entry", err))
+	}
+	if err := l.file.Sync(); err != nil {
+		return 0, l.rollback(common.NewBasicError("Unable to sync log", err))
+	}
+	l.size += int64(len(raw))
+	l.add(append(common.RawBytes(nil), entry...))
+	return uint64(len(l.entries) - 1), nil
+}
+
+// rollback truncates the file to the end of the last complete entry after a
+// failed append and returns err. The caller must hold the write lock.
+func (l *Log) rollback(err error) error {
+	if tErr := l.file.Truncate(l.size); tErr != nil {
+		return common.NewBasicError("Unable to truncate log", tErr, "appendErr", err)
+	}
+	if _, sErr := l.file.Seek(l.size, io.SeekStart); sErr != nil {
+		return common.NewBasicError("Unable to seek log", sErr, "appendErr", err)
+	}
+	return err
+}
+
+func (l *Log) add(entry common.RawBytes) {
+	l.entries = append(l.entries, entry)
+	l.hashes = append(l.hashes, LeafHash(entry))
+}
+
+// Size returns the number of entries in the log.
+func (l *Log) Size() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return uint64(len(l.entries))
+}
+
+// Entry returns the entry at index.
+func (l *Log) Entry(index uint64) (common.RawBytes, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if index >= uint64(len(l.entries)) {
+		return nil, common.NewBasicError(ErrInvalidIndex, nil, "index", index,
+			"size", len(l.entries))
+	}
+	return l.entries[index], nil
+}
+
+// Index returns the index of the first entry with the leaf hash. The boolean
+// is false, if no such entry exists.
+func (l *Log) Index(leafHash common.RawBytes) (uint64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for i, h := range l.hashes {
+		if bytes.Equal(h, leafHash) {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+// RootHash returns the root hash of the tree containing the first size
+// entries.
+func (l *Log) RootHash(size uint64) (common.RawBytes, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.checkSize(size); err != nil {
+		return nil, err
+	}
+	return rootHash(l.hashes[:size]), nil
+}
+
+// InclusionProof returns the proof that the entry at index is part of the
+// tree containing the first size entries.
+func (l *Log) InclusionProof(index, size uint64) (*InclusionProof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.checkSize(size); err != nil {
+		return nil, err
+	}
+	if index >= size {
+		return nil, common.NewBasicError(ErrInvalidIndex, nil, "index", index, "size", size)
+	}
+	return &InclusionProof{
+		LeafIndex: index,
+		TreeSize:  size,
+		Hashes:    inclusionPath(int(index), l.hashes[:size]),
+	}, nil
+}
+
+// ConsistencyProof returns the proof that the tree containing the first
+// entries is a prefix of the tree containing the second entries.
+func (l *Log) ConsistencyProof(first, second uint64) (*ConsistencyProof, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if err := l.checkSize(second); err != nil {
+		return nil, err
+	}
+	if first > second {
+		return nil, common.NewBasicError(ErrInvalidSize, nil, "first", first, "second", second)
+	}
+	p := &ConsistencyProof{FirstSize: first, SecondSize: second}
+	if first > 0 && first < second {
+		p.Hashes = consistencyPath(int(first), l.hashes[:second], true)
+	}
+	return p, nil
+}
+
+func (l *Log) checkSize(size uint64) error {
+	if size > uint64(len(l.hashes)) {
+		return common.NewBasicError(ErrInvalidSize, nil, "size", size, "max", len(l.hashes))
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
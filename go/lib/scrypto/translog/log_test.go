@@ -0,0 +1,123 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func TestLog(t *testing.T) {
+	Convey("Log", t, func() {
+		dir, err := ioutil.TempDir("", "translog")
+		SoMsg("err", err, ShouldBeNil)
+		path := filepath.Join(dir, "test.log")
+		l, err := Open(path)
+		SoMsg("open err", err, ShouldBeNil)
+		Reset(func() {
+			l.Close()
+			os.RemoveAll(dir)
+		})
+		SoMsg("empty", l.Size(), ShouldEqual, 0)
+		for i, e := range []string{"a", "b", "c"} {
+			index, err := l.Append(common.RawBytes(e))
+			SoMsg("append err", err, ShouldBeNil)
+			SoMsg("index", index, ShouldEqual, i)
+		}
+		root, _ := l.RootHash(3)
+		Convey("Entries can be looked up", func() {
+			entry, err := l.Entry(1)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("entry", entry, ShouldResemble, common.RawBytes("b"))
+			index, ok := l.Index(LeafHash(common.RawBytes("c")))
+			SoMsg("ok", ok, ShouldBeTrue)
+			SoMsg("index", index, ShouldEqual, 2)
+			_, ok = l.Index(LeafHash(common.RawBytes("d")))
+			SoMsg("missing", ok, ShouldBeFalse)
+			_, err = l.Entry(3)
+			SoMsg("out of range", err, ShouldNotBeNil)
+		})
+		Convey("Reopening restores the log", func() {
+			l.Close()
+			l, err = Open(path)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("size", l.Size(), ShouldEqual, 3)
+			newRoot, _ := l.RootHash(3)
+			SoMsg("root", newRoot, ShouldResemble, root)
+		})
+		Convey("Incomplete entries are discarded", func() {
+			l.Close()
+			f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+			f.Write([]byte{0, 0, 0, 5, 'x'})
+			f.Close()
+			l, err = Open(path)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("size", l.Size(), ShouldEqual, 3)
+			_, err = l.Append(common.RawBytes("d"))
+			SoMsg("append err", err, ShouldBeNil)
+			l.Close()
+			l, err = Open(path)
+			SoMsg("reopen err", err, ShouldBeNil)
+			SoMsg("reopen size", l.Size(), ShouldEqual, 4)
+		})
+		Convey("Failed appends leave no partial entry", func() {
+			l.file = &partialFile{File: l.file.(*os.File), n: 3}
+			_, err := l.Append(common.RawBytes("d"))
+			SoMsg("append err", err, ShouldNotBeNil)
+			SoMsg("size", l.Size(), ShouldEqual, 3)
+			l.file = l.file.(*partialFile).File
+			index, err := l.Append(common.RawBytes("e"))
+			SoMsg("retry err", err, ShouldBeNil)
+			SoMsg("index", index, ShouldEqual, 3)
+			l.Close()
+			l, err = Open(path)
+			SoMsg("reopen err", err, ShouldBeNil)
+			SoMsg("reopen size", l.Size(), ShouldEqual, 4)
+			entry, err := l.Entry(3)
+			SoMsg("entry err", err, ShouldBeNil)
+			SoMsg("entry", entry, ShouldResemble, common.RawBytes("e"))
+		})
+		Convey("Invalid sizes are rejected", func() {
+			_, err := l.RootHash(4)
+			SoMsg("root", err, ShouldNotBeNil)
+			_, err = l.InclusionProof(3, 3)
+			SoMsg("inclusion", err, ShouldNotBeNil)
+			_, err = l.ConsistencyProof(3, 2)
+			SoMsg("consistency", err, ShouldNotBeNil)
+		})
+	})
+}
+
+// partialFile simulates a partial write, e.g., when the disk is full. Only the
+// first n bytes are written.
+type partialFile struct {
+	*os.File
+	n int
+}
+
+func (f *partialFile) Write(b []byte) (int, error) {
+	n, err := f.File.Write(b[:f.n])
+	if err != nil {
+		return n, err
+	}
+	return n, errors.New("no space left")
+}
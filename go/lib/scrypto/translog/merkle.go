@@ -0,0 +1,93 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"crypto/sha256"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash returns the hash of a log entry.
+func LeafHash(entry common.RawBytes) common.RawBytes {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(entry)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right common.RawBytes) common.RawBytes {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// rootHash computes the Merkle tree hash of the leaf hashes.
+func rootHash(leaves []common.RawBytes) common.RawBytes {
+	switch len(leaves) {
+	case 0:
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(rootHash(leaves[:k]), rootHash(leaves[k:]))
+}
+
+// inclusionPath computes the audit path of leaf m, i.e., PATH(m, D[n]).
+func inclusionPath(m int, leaves []common.RawBytes) []common.RawBytes {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if m < k {
+		return append(inclusionPath(m, leaves[:k]), rootHash(leaves[k:]))
+	}
+	return append(inclusionPath(m-k, leaves[k:]), rootHash(leaves[:k]))
+}
+
+// consistencyPath computes the consistency proof between the tree of size m
+// and the tree of all leaves, i.e., SUBPROOF(m, D[n], complete).
+func consistencyPath(m int, leaves []common.RawBytes, complete bool) []common.RawBytes {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return []common.RawBytes{rootHash(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(consistencyPath(m, leaves[:k], complete), rootHash(leaves[k:]))
+	}
+	return append(consistencyPath(m-k, leaves[k:], false), rootHash(leaves[:k]))
+}
+
+// split returns the largest power of two smaller than n.
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
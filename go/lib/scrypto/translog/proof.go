@@ -0,0 +1,143 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"bytes"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+const (
+	ErrInvalidProof = "Invalid proof"
+	ErrRootMismatch = "Root hash mismatch"
+)
+
+// InclusionProof proves that the leaf at LeafIndex is part of the tree of
+// size TreeSize.
+type InclusionProof struct {
+	LeafIndex uint64
+	TreeSize  uint64
+	Hashes    []common.RawBytes
+}
+
+// Verify checks that the proof links the leaf hash to the root hash of the
+// tree. The algorithm is specified in RFC 9162, Section 2.1.3.2.
+func (p *InclusionProof) Verify(leafHash, root common.RawBytes) error {
+	if p.LeafIndex >= p.TreeSize {
+		return common.NewBasicError(ErrInvalidProof, nil, "index", p.LeafIndex,
+			"size", p.TreeSize)
+	}
+	fn, sn := p.LeafIndex, p.TreeSize-1
+	r := leafHash
+	for _, h := range p.Hashes {
+		if sn == 0 {
+			return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(h, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, h)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof too short")
+	}
+	if !bytes.Equal(r, root) {
+		return common.NewBasicError(ErrRootMismatch, nil, "expected", root, "actual", r)
+	}
+	return nil
+}
+
+// ConsistencyProof proves that the tree of size FirstSize is a prefix of the
+// tree of size SecondSize.
+type ConsistencyProof struct {
+	FirstSize  uint64
+	SecondSize uint64
+	Hashes     []common.RawBytes
+}
+
+// Verify checks that the proof links the root hashes of the two trees. The
+// algorithm is specified in RFC 9162, Section 2.1.4.2.
+func (p *ConsistencyProof) Verify(firstRoot, secondRoot common.RawBytes) error {
+	switch {
+	case p.FirstSize > p.SecondSize:
+		return common.NewBasicError(ErrInvalidProof, nil, "first", p.FirstSize,
+			"second", p.SecondSize)
+	case p.FirstSize == p.SecondSize:
+		if len(p.Hashes) != 0 {
+			return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof not empty")
+		}
+		if !bytes.Equal(firstRoot, secondRoot) {
+			return common.NewBasicError(ErrRootMismatch, nil, "first", firstRoot,
+				"second", secondRoot)
+		}
+		return nil
+	case p.FirstSize == 0:
+		// Every tree is consistent with the empty tree.
+		if len(p.Hashes) != 0 {
+			return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof not empty")
+		}
+		return nil
+	case len(p.Hashes) == 0:
+		return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof empty")
+	}
+	path := p.Hashes
+	if p.FirstSize&(p.FirstSize-1) == 0 {
+		// The first tree is a complete subtree of the second tree.
+		path = append([]common.RawBytes{firstRoot}, path...)
+	}
+	fn, sn := p.FirstSize-1, p.SecondSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := path[0], path[0]
+	for _, h := range path[1:] {
+		if sn == 0 {
+			return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = nodeHash(h, fr)
+			sr = nodeHash(h, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = nodeHash(sr, h)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return common.NewBasicError(ErrInvalidProof, nil, "reason", "proof too short")
+	}
+	if !bytes.Equal(fr, firstRoot) {
+		return common.NewBasicError(ErrRootMismatch, nil, "tree", "first",
+			"expected", firstRoot, "actual", fr)
+	}
+	if !bytes.Equal(sr, secondRoot) {
+		return common.NewBasicError(ErrRootMismatch, nil, "tree", "second",
+			"expected", secondRoot, "actual", sr)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func TestInclusionProof(t *testing.T) {
+	Convey("Inclusion proofs verify for all leaves and tree sizes", t, func() {
+		l, cleanF := newTestLog(t, 17)
+		defer cleanF()
+		for size := uint64(1); size <= l.Size(); size++ {
+			root, err := l.RootHash(size)
+			SoMsg("root err", err, ShouldBeNil)
+			for index := uint64(0); index < size; index++ {
+				p, err := l.InclusionProof(index, size)
+				SoMsg("proof err", err, ShouldBeNil)
+				SoMsg(fmt.Sprintf("verify %d/%d", index, size),
+					p.Verify(l.hashes[index], root), ShouldBeNil)
+			}
+		}
+	})
+	Convey("Inclusion proofs do not verify for wrong inputs", t, func() {
+		l, cleanF := newTestLog(t, 7)
+		defer cleanF()
+		root, _ := l.RootHash(7)
+		p, _ := l.InclusionProof(3, 7)
+		SoMsg("wrong leaf", p.Verify(l.hashes[2], root), ShouldNotBeNil)
+		SoMsg("wrong root", p.Verify(l.hashes[3], l.hashes[3]), ShouldNotBeNil)
+		p.LeafIndex = 4
+		SoMsg("wrong index", p.Verify(l.hashes[3], root), ShouldNotBeNil)
+		p.LeafIndex = 3
+		p.Hashes = p.Hashes[1:]
+		SoMsg("short proof", p.Verify(l.hashes[3], root), ShouldNotBeNil)
+	})
+}
+
+func TestConsistencyProof(t *testing.T) {
+	Convey("Consistency proofs verify for all tree sizes", t, func() {
+		l, cleanF := newTestLog(t, 17)
+		defer cleanF()
+		for second := uint64(1); second <= l.Size(); second++ {
+			secondRoot, _ := l.RootHash(second)
+			for first := uint64(0); first <= second; first++ {
+				firstRoot, _ := l.RootHash(first)
+				p, err := l.ConsistencyProof(first, second)
+				SoMsg("proof err", err, ShouldBeNil)
+				SoMsg(fmt.Sprintf("verify %d/%d", first, second),
+					p.Verify(firstRoot, secondRoot), ShouldBeNil)
+			}
+		}
+	})
+	Convey("Consistency proofs do not verify for wrong inputs", t, func() {
+		l, cleanF := newTestLog(t, 7)
+		defer cleanF()
+		firstRoot, _ := l.RootHash(3)
+		secondRoot, _ := l.RootHash(7)
+		otherRoot, _ := l.RootHash(4)
+		p, _ := l.ConsistencyProof(3, 7)
+		SoMsg("wrong first", p.Verify(otherRoot, secondRoot), ShouldNotBeNil)
+		SoMsg("wrong second", p.Verify(firstRoot, otherRoot), ShouldNotBeNil)
+		p.Hashes = p.Hashes[1:]
+		SoMsg("short proof", p.Verify(firstRoot, secondRoot), ShouldNotBeNil)
+	})
+}
+
+func newTestLog(t *testing.T, n int) (*Log, func()) {
+	dir, err := ioutil.TempDir("", "translog")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	l, err := Open(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Fatalf("Unable to open log: %s", err)
+	}
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(common.RawBytes(fmt.Sprintf("entry %d", i))); err != nil {
+			t.Fatalf("Unable to append: %s", err)
+		}
+	}
+	return l, func() {
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translog
+
+import (
+	"fmt"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+const (
+	ErrIssuerMismatch = "Issuer certificate does not match tree head"
+)
+
+// SignedTreeHead is the root hash of the log at a given size, signed by the
+// issuer that maintains the log.
+type SignedTreeHead struct {
+	// IA is the issuer AS that maintains the log.
+	IA addr.IA
+	// IssuerVersion is the version of the issuer certificate that
+	// authenticates the signing key.
+	IssuerVersion uint64
+	// TreeSize is the number of entries in the tree.
+	TreeSize uint64
+	// Timestamp is the unix timestamp in seconds at which the tree head was
+	// signed.
+	Timestamp uint32
+	// RootHash is the root hash of the tree.
+	RootHash common.RawBytes
+	// Signature is computed over all other fields.
+	Signature common.RawBytes `json:",omitempty"`
+}
+
+// Sign signs the tree head with the issuer signing key.
+func (s *SignedTreeHead) Sign(signKey common.RawBytes, signAlgo string) error {
+	sig, err := scrypto.Sign(s.sigPack(), signKey, signAlgo)
+	if err != nil {
+		return err
+	}
+	s.Signature = sig
+	return nil
+}
+
+// Verify checks the signature of the tree head with the key of the issuer
+// certificate.
+func (s *SignedTreeHead) Verify(issuer *cert.Certificate) error {
+	if !issuer.Subject.Equal(s.IA) || issuer.Version != s.IssuerVersion {
+		return common.NewBasicError(ErrIssuerMismatch, nil,
+			"expected", fmt.Sprintf("%sv%d", s.IA, s.IssuerVersion),
+			"actual", fmt.Sprintf("%sv%d", issuer.Subject, issuer.Version))
+	}
+	return scrypto.Verify(s.sigPack(), s.Signature, issuer.SubjectSignKey,
+		issuer.SignAlgorithm)
+}
+
+func (s *SignedTreeHead) sigPack() common.RawBytes {
+	raw := make(common.RawBytes, addr.IABytes+20, addr.IABytes+20+len(s.RootHash))
+	s.IA.Write(raw)
+	common.Order.PutUint64(raw[addr.IABytes:], s.IssuerVersion)
+	common.Order.PutUint64(raw[addr.IABytes+8:], s.TreeSize)
+	common.Order.PutUint32(raw[addr.IABytes+16:], s.Timestamp)
+	return append(raw, s.RootHash...)
+}
+
+func (s *SignedTreeHead) String() string {
+	return fmt.Sprintf("STH %s size: %d issued: %s root: %s", s.IA, s.TreeSize,
+		util.TimeToString(util.SecsToTime(s.Timestamp)), s.RootHash)
+}
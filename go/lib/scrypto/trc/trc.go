@@ -277,33 +277,48 @@ func (t *TRC) Verify(trust *TRC) (*TRCVerResult, error) {
 
 // verifyUpdate checks the validity of a updated TRC.
 func (t *TRC) verifyUpdate(old *TRC) (*TRCVerResult, error) {
+	if err := t.ValidateUpdate(old); err != nil {
+		return nil, err
+	}
+	return t.VerifySignatures(old)
+}
+
+// ValidateUpdate checks that the TRC is a valid successor of the old TRC. In
+// contrast to Verify, the signatures are not checked.
+func (t *TRC) ValidateUpdate(old *TRC) error {
 	if old.ISD != t.ISD {
-		return nil, common.NewBasicError(InvalidISD, nil, "expected", old.ISD, "actual", t.ISD)
+		return common.NewBasicError(InvalidISD, nil, "expected", old.ISD, "actual", t.ISD)
 	}
 	if old.Version+1 != t.Version {
-		return nil, common.NewBasicError(InvalidVersion, nil,
+		return common.NewBasicError(InvalidVersion, nil,
 			"expected", old.Version+1, "actual", t.Version)
 	}
 	if t.CreationTime < old.CreationTime+old.GracePeriod {
-		return nil, common.NewBasicError(
+		return common.NewBasicError(
 			InvalidCreationTime, nil,
 			"expected >", timeToString(old.CreationTime+old.GracePeriod),
 			"actual", timeToString(t.CreationTime),
 		)
 	}
 	if t.Quarantine || old.Quarantine {
-		return nil, common.NewBasicError(EarlyAnnouncement, nil)
+		return common.NewBasicError(EarlyAnnouncement, nil)
 	}
-	return t.verifySignatures(old)
+	return nil
 }
 
-// verifySignatures checks the signatures of the updated TRC.
-func (t *TRC) verifySignatures(old *TRC) (*TRCVerResult, error) {
+// VerifySignatures checks the signatures of the TRC against the online keys of
+// the core ASes in old. Only the signatures are checked, use Verify to check
+// that the TRC is a valid successor of old. A base TRC is verified against
+// itself.
+func (t *TRC) VerifySignatures(old *TRC) (*TRCVerResult, error) {
 	sigInput, err := t.sigPack()
 	if err != nil {
 		return nil, err
 	}
-	var tvr = &TRCVerResult{}
+	var tvr = &TRCVerResult{
+		Quorum: old.QuorumTRC,
+		Failed: make(map[addr.IA]error),
+	}
 	// Only verify signatures which are from core ASes defined in old TRC
 	for signer, coreAS := range old.CoreASes {
 		sig, ok := t.Signatures[signer.String()]
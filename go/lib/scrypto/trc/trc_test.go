@@ -272,6 +272,68 @@ func Test_TRC_CheckActive(t *testing.T) {
 	})
 }
 
+func Test_TRC_Verify(t *testing.T) {
+	Convey("Verify should check updated TRCs", t, func() {
+		old := loadTRC(fnTRC, t)
+		keys := make(map[addr.IA]common.RawBytes)
+		for ia, coreAS := range old.CoreASes {
+			pub, priv, err := scrypto.GenKeyPair(scrypto.Ed25519)
+			xtest.FailOnErr(t, err)
+			coreAS.OnlineKey, coreAS.OnlineKeyAlg = pub, scrypto.Ed25519
+			keys[ia] = priv
+		}
+		upd := loadTRC(fnTRC, t)
+		upd.Version = old.Version + 1
+		upd.CreationTime = old.CreationTime + old.GracePeriod
+		upd.Signatures = make(map[string]common.RawBytes)
+		signers := []addr.IA{xtest.MustParseIA("1-ff00:0:300"), xtest.MustParseIA("1-ff00:0:301")}
+		sign := func(ias ...addr.IA) {
+			for _, ia := range ias {
+				xtest.FailOnErr(t, upd.Sign(ia.String(), keys[ia], scrypto.Ed25519))
+			}
+		}
+
+		Convey("Quorum of valid signatures", func() {
+			sign(signers...)
+			tvr, err := upd.Verify(old)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("Verified", len(tvr.Verified), ShouldEqual, 2)
+			SoMsg("Failed", len(tvr.Failed), ShouldEqual, 1)
+			SoMsg("QuorumOk", tvr.QuorumOk(), ShouldBeTrue)
+		})
+		Convey("Not enough signatures", func() {
+			sign(signers[0])
+			tvr, err := upd.Verify(old)
+			SoMsg("err", err, ShouldNotBeNil)
+			SoMsg("QuorumOk", tvr.QuorumOk(), ShouldBeFalse)
+		})
+		Convey("Invalid signature", func() {
+			sign(signers...)
+			upd.Description = "modified"
+			_, err := upd.Verify(old)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("Invalid version", func() {
+			upd.Version += 1
+			sign(signers...)
+			_, err := upd.Verify(old)
+			SoMsg("err", err, ShouldNotBeNil)
+			SoMsg("validate", upd.ValidateUpdate(old), ShouldNotBeNil)
+		})
+		Convey("Created during grace period", func() {
+			upd.CreationTime -= 1
+			SoMsg("validate", upd.ValidateUpdate(old), ShouldNotBeNil)
+		})
+		Convey("Signatures only", func() {
+			upd.Version += 1
+			sign(signers...)
+			tvr, err := upd.VerifySignatures(old)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("Verified", len(tvr.Verified), ShouldEqual, 2)
+		})
+	})
+}
+
 func Test_TRC_Compress(t *testing.T) {
 	Convey("TRC is compressed correctly", t, func() {
 		trc := loadTRC(fnTRC, t)
@@ -30,7 +30,11 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/spkt"
 	"github.com/scionproto/scion/go/lib/spse"
 )
 
@@ -54,6 +58,10 @@ const (
 	DirectionOffset  = spse.SecModeLength
 	MACOffset        = DirectionOffset + DirectionLength + PaddingLength
 	DRKeyTotalLength = MACOffset + MACLength
+
+	// DRKeyProtocol is the protocol of the level 2 keys that authenticate
+	// SCMP messages.
+	DRKeyProtocol = "scmp"
 )
 
 type Dir uint8
@@ -86,13 +94,61 @@ func (d Dir) String() string {
 	}
 }
 
+// Lvl2Meta returns the description of the key indicated by the direction, for
+// an SCMP message from srcHost in srcIA to dstHost in dstIA.
+func (d Dir) Lvl2Meta(srcIA, dstIA addr.IA,
+	srcHost, dstHost addr.HostAddr) (drkey.Lvl2Meta, error) {
+
+	meta := drkey.Lvl2Meta{Protocol: DRKeyProtocol}
+	switch d {
+	case AsToAs:
+		meta.KeyType, meta.SrcIA, meta.DstIA = drkey.AS2AS, srcIA, dstIA
+	case AsToHost:
+		meta.KeyType, meta.SrcIA, meta.DstIA = drkey.AS2Host, srcIA, dstIA
+		meta.DstHost = dstHost
+	case HostToHost:
+		meta.KeyType, meta.SrcIA, meta.DstIA = drkey.Host2Host, srcIA, dstIA
+		meta.SrcHost, meta.DstHost = srcHost, dstHost
+	case HostToAs:
+		meta.KeyType, meta.SrcIA, meta.DstIA = drkey.AS2Host, dstIA, srcIA
+		meta.DstHost = srcHost
+	case AsToAsReversed:
+		meta.KeyType, meta.SrcIA, meta.DstIA = drkey.AS2AS, dstIA, srcIA
+	case HostToHostReversed:
+		meta.KeyType, meta.SrcIA, meta.DstIA = drkey.Host2Host, dstIA, srcIA
+		meta.SrcHost, meta.DstHost = dstHost, srcHost
+	default:
+		return drkey.Lvl2Meta{}, common.NewBasicError("Invalid direction", nil, "dir", d)
+	}
+	return meta, nil
+}
+
+// ComputeMAC computes the MAC of the raw SCION packet pkt with key. The MAC
+// field of the SCMPAuthDRKey extension in pkt must be zero. The current info
+// and hop field offsets change along the path, they are set to zero on a
+// copy of pkt before computing the MAC.
+func ComputeMAC(key drkey.DRKey, pkt common.RawBytes) (common.RawBytes, error) {
+	var cmnHdr spkt.CmnHdr
+	if err := cmnHdr.Parse(pkt); err != nil {
+		return nil, err
+	}
+	b := append(common.RawBytes(nil), pkt...)
+	cmnHdr.UpdatePathOffsets(b, 0, 0)
+	mac, err := scrypto.InitMac(common.RawBytes(key))
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(b)
+	return mac.Sum(nil), nil
+}
+
 func NewDRKeyExtn() *DRKeyExtn {
 	s := &DRKeyExtn{BaseExtn: &spse.BaseExtn{SecMode: spse.ScmpAuthDRKey}}
 	s.MAC = make(common.RawBytes, MACLength)
 	return s
 }
 
-func (s DRKeyExtn) SetDirection(dir Dir) error {
+func (s *DRKeyExtn) SetDirection(dir Dir) error {
 	if dir > HostToHostReversed {
 		return common.NewBasicError("Invalid direction", nil, "dir", dir)
 	}
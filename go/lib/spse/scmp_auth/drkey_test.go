@@ -0,0 +1,163 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scmp_auth
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	srcIA   = xtest.MustParseIA("1-ff00:0:110")
+	dstIA   = xtest.MustParseIA("1-ff00:0:111")
+	srcHost = addr.HostFromIPStr("192.0.2.1")
+	dstHost = addr.HostFromIPStr("192.0.2.2")
+)
+
+func TestDirLvl2Meta(t *testing.T) {
+	tests := []struct {
+		dir      Dir
+		expected drkey.Lvl2Meta
+	}{
+		{
+			dir:      AsToAs,
+			expected: drkey.Lvl2Meta{KeyType: drkey.AS2AS, SrcIA: srcIA, DstIA: dstIA},
+		},
+		{
+			dir: AsToHost,
+			expected: drkey.Lvl2Meta{KeyType: drkey.AS2Host, SrcIA: srcIA, DstIA: dstIA,
+				DstHost: dstHost},
+		},
+		{
+			dir: HostToHost,
+			expected: drkey.Lvl2Meta{KeyType: drkey.Host2Host, SrcIA: srcIA, DstIA: dstIA,
+				SrcHost: srcHost, DstHost: dstHost},
+		},
+		{
+			dir: HostToAs,
+			expected: drkey.Lvl2Meta{KeyType: drkey.AS2Host, SrcIA: dstIA, DstIA: srcIA,
+				DstHost: srcHost},
+		},
+		{
+			dir:      AsToAsReversed,
+			expected: drkey.Lvl2Meta{KeyType: drkey.AS2AS, SrcIA: dstIA, DstIA: srcIA},
+		},
+		{
+			dir: HostToHostReversed,
+			expected: drkey.Lvl2Meta{KeyType: drkey.Host2Host, SrcIA: dstIA, DstIA: srcIA,
+				SrcHost: dstHost, DstHost: srcHost},
+		},
+	}
+	Convey("Lvl2Meta returns the key of the direction", t, func() {
+		for _, test := range tests {
+			Convey(test.dir.String(), func() {
+				meta, err := test.dir.Lvl2Meta(srcIA, dstIA, srcHost, dstHost)
+				SoMsg("err", err, ShouldBeNil)
+				test.expected.Protocol = DRKeyProtocol
+				SoMsg("meta", meta, ShouldResemble, test.expected)
+			})
+		}
+		Convey("Invalid direction", func() {
+			_, err := (HostToHostReversed + 1).Lvl2Meta(srcIA, dstIA, srcHost, dstHost)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestComputeMAC(t *testing.T) {
+	key := drkey.DRKey("0123456789abcdef")
+	Convey("ComputeMAC", t, func() {
+		pkt := testPkt(t)
+		mac, err := ComputeMAC(key, pkt)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("len", len(mac), ShouldEqual, MACLength)
+		Convey("does not modify the packet", func() {
+			SoMsg("pkt", pkt, ShouldResemble, testPkt(t))
+		})
+		Convey("ignores the path offsets", func() {
+			var cmnHdr spkt.CmnHdr
+			xtest.FailOnErr(t, cmnHdr.Parse(pkt))
+			cmnHdr.UpdatePathOffsets(pkt, cmnHdr.CurrInfoF+1, cmnHdr.CurrHopF+2)
+			other, err := ComputeMAC(key, pkt)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("mac", other, ShouldResemble, mac)
+		})
+		Convey("covers the rest of the packet", func() {
+			pkt[len(pkt)-1] ^= 0xff
+			other, err := ComputeMAC(key, pkt)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("mac", other, ShouldNotResemble, mac)
+		})
+		Convey("depends on the key", func() {
+			other, err := ComputeMAC(drkey.DRKey("fedcba9876543210"), pkt)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("mac", other, ShouldNotResemble, mac)
+		})
+		Convey("rejects truncated packets", func() {
+			_, err := ComputeMAC(key, pkt[:spkt.CmnHdrLen-1])
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDRKeyExtnMAC(t *testing.T) {
+	key := drkey.DRKey("0123456789abcdef")
+	Convey("A MAC written to the extension can be verified", t, func() {
+		pkt := testPkt(t)
+		extn := NewDRKeyExtn()
+		xtest.FailOnErr(t, extn.SetDirection(HostToHost))
+		raw := pkt[len(pkt)-extn.Len():]
+		xtest.FailOnErr(t, extn.Write(raw))
+		mac, err := ComputeMAC(key, pkt)
+		xtest.FailOnErr(t, err)
+		SoMsg("set", extn.SetMAC(mac), ShouldBeNil)
+		xtest.FailOnErr(t, extn.Write(raw))
+		SoMsg("mac", raw[MACOffset:DRKeyTotalLength], ShouldResemble, mac)
+		// Verification recomputes the MAC with a zero MAC field.
+		copy(raw[MACOffset:DRKeyTotalLength], make(common.RawBytes, MACLength))
+		verify, err := ComputeMAC(key, pkt)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("verify", verify, ShouldResemble, mac)
+		SoMsg("invalid size", extn.SetMAC(mac[1:]), ShouldNotBeNil)
+	})
+}
+
+// testPkt returns a raw packet with a SCION common header, followed by
+// arbitrary data.
+func testPkt(t *testing.T) common.RawBytes {
+	cmnHdr := spkt.CmnHdr{
+		Ver:       spkt.SCIONVersion,
+		DstType:   addr.HostTypeIPv4,
+		SrcType:   addr.HostTypeIPv4,
+		TotalLen:  64,
+		HdrLen:    4,
+		CurrInfoF: 3,
+		CurrHopF:  4,
+		NextHdr:   common.End2EndClass,
+	}
+	pkt := make(common.RawBytes, cmnHdr.TotalLen)
+	cmnHdr.Write(pkt)
+	for i := spkt.CmnHdrLen; i < len(pkt); i++ {
+		pkt[i] = byte(i)
+	}
+	return pkt
+}
@@ -28,9 +28,11 @@ package scmp_auth
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spkt"
 	"github.com/scionproto/scion/go/lib/spse"
 )
 
@@ -47,8 +49,9 @@ type HashTreeExtn struct {
 	Order common.RawBytes
 	// Signature is the signature of the root hash.
 	Signature common.RawBytes
-	// Hashes are the hashes to verify the proof.
-	// At index 0 is the leaf hash. At index height is the root hash.
+	// Hashes are the hashes to verify the proof. At index 0 is the sibling of
+	// the leaf, at index height-1 the sibling of the child of the root, see
+	// ComputeRoot.
 	Hashes common.RawBytes
 }
 
@@ -67,6 +70,63 @@ const (
 	HashesOffset    = SignatureOffset + SignatureLength
 )
 
+// Prefixes of the hash inputs, such that leaves and inner nodes of the hash
+// tree cannot be confused.
+const (
+	leafPrefix byte = 0
+	nodePrefix byte = 1
+)
+
+// ComputeLeaf computes the leaf hash of the raw SCION packet pkt. The order,
+// signature and hashes of the SCMPAuthHashTree extension in pkt must be zero.
+// The current info and hop field offsets change along the path, they are set
+// to zero on a copy of pkt before computing the hash.
+func ComputeLeaf(pkt common.RawBytes) (common.RawBytes, error) {
+	var cmnHdr spkt.CmnHdr
+	if err := cmnHdr.Parse(pkt); err != nil {
+		return nil, err
+	}
+	b := append(common.RawBytes(nil), pkt...)
+	cmnHdr.UpdatePathOffsets(b, 0, 0)
+	return hash(leafPrefix, b), nil
+}
+
+// ComputeRoot computes the root of the hash tree from the leaf hash and the
+// hashes of the proof. Bit i of order, counted from the least significant
+// bit, indicates whether hash i is the left (0) or right (1) input of the
+// node at height i+1. The root is signed by the AS that created the tree.
+func ComputeRoot(leaf, order, hashes common.RawBytes) (common.RawBytes, error) {
+	if len(order) != OrderLength {
+		return nil, common.NewBasicError("Invalid order length", nil,
+			"expected", OrderLength, "actual", len(order))
+	}
+	if len(hashes)%HashLength != 0 || len(hashes) > MaxHeight*HashLength {
+		return nil, common.NewBasicError("Invalid hashes length", nil, "actual", len(hashes))
+	}
+	bits := common.Order.Uint16(order)
+	node := leaf
+	for i := 0; i < len(hashes)/HashLength; i++ {
+		h := hashes[i*HashLength : (i+1)*HashLength]
+		if bits&(1<<uint(i)) == 0 {
+			node = hash(nodePrefix, h, node)
+		} else {
+			node = hash(nodePrefix, node, h)
+		}
+	}
+	return node, nil
+}
+
+// hash returns the SHA-256 hash of the prefix and inputs, truncated to
+// HashLength.
+func hash(prefix byte, inputs ...common.RawBytes) common.RawBytes {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	for _, input := range inputs {
+		h.Write(input)
+	}
+	return h.Sum(nil)[:HashLength]
+}
+
 func NewHashTreeExtn(height uint8) (*HashTreeExtn, error) {
 	if height > MaxHeight {
 		return nil, common.NewBasicError("Invalid height", nil,
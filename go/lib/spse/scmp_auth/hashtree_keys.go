@@ -0,0 +1,79 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scmp_auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// HashTreeKeys provides the public keys with which the ASes sign the roots of
+// the hash trees that authenticate their SCMP messages.
+type HashTreeKeys interface {
+	// VerifyKey returns the Ed25519 public key of ia.
+	VerifyKey(ia addr.IA) (common.RawBytes, error)
+}
+
+var _ HashTreeKeys = StaticHashTreeKeys(nil)
+
+// StaticHashTreeKeys is a fixed set of public keys, keyed by AS.
+type StaticHashTreeKeys map[addr.IA]common.RawBytes
+
+// LoadStaticHashTreeKeys loads the public keys from a JSON file that maps
+// ISD-AS strings to base64 encoded Ed25519 public keys, e.g.:
+//
+//   {"1-ff00:0:110": "yVaDu1Ve1s3+wJHTqd1cPRXgzaBtpThMfUhoT5G8aPE="}
+func LoadStaticHashTreeKeys(file string) (StaticHashTreeKeys, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to read hash tree keys", err, "file", file)
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, common.NewBasicError("Unable to parse hash tree keys", err, "file", file)
+	}
+	keys := make(StaticHashTreeKeys, len(raw))
+	for rawIA, rawKey := range raw {
+		ia, err := addr.IAFromString(rawIA)
+		if err != nil {
+			return nil, common.NewBasicError("Invalid ISD-AS", err, "file", file)
+		}
+		key, err := base64.StdEncoding.DecodeString(rawKey)
+		if err != nil {
+			return nil, common.NewBasicError("Unable to decode hash tree key", err,
+				"file", file, "ia", ia)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, common.NewBasicError("Invalid hash tree key size", nil,
+				"file", file, "ia", ia, "expected", ed25519.PublicKeySize, "actual", len(key))
+		}
+		keys[ia] = key
+	}
+	return keys, nil
+}
+
+func (k StaticHashTreeKeys) VerifyKey(ia addr.IA) (common.RawBytes, error) {
+	key, ok := k[ia]
+	if !ok {
+		return nil, common.NewBasicError("No hash tree key for AS", nil, "ia", ia)
+	}
+	return key, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scmp_auth
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestComputeLeaf(t *testing.T) {
+	Convey("ComputeLeaf", t, func() {
+		pkt := testPkt(t)
+		leaf, err := ComputeLeaf(pkt)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("len", len(leaf), ShouldEqual, HashLength)
+		Convey("does not modify the packet", func() {
+			SoMsg("pkt", pkt, ShouldResemble, testPkt(t))
+		})
+		Convey("ignores the path offsets", func() {
+			var cmnHdr spkt.CmnHdr
+			xtest.FailOnErr(t, cmnHdr.Parse(pkt))
+			cmnHdr.UpdatePathOffsets(pkt, cmnHdr.CurrInfoF+1, cmnHdr.CurrHopF+2)
+			other, err := ComputeLeaf(pkt)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("leaf", other, ShouldResemble, leaf)
+		})
+		Convey("covers the rest of the packet", func() {
+			pkt[len(pkt)-1] ^= 0xff
+			other, err := ComputeLeaf(pkt)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("leaf", other, ShouldNotResemble, leaf)
+		})
+		Convey("rejects truncated packets", func() {
+			_, err := ComputeLeaf(pkt[:spkt.CmnHdrLen-1])
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestComputeRoot(t *testing.T) {
+	leaf := common.RawBytes("leaf hash 012345")
+	hashes := common.RawBytes("0123456789abcdef0123456789ABCDEF")
+	Convey("ComputeRoot", t, func() {
+		Convey("returns the leaf for height 0", func() {
+			root, err := ComputeRoot(leaf, common.RawBytes{0, 0}, nil)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("root", root, ShouldResemble, leaf)
+		})
+		Convey("uses the hashes as left or right input according to order", func() {
+			root, err := ComputeRoot(leaf, common.RawBytes{0x00, 0x02}, hashes)
+			SoMsg("err", err, ShouldBeNil)
+			node := sum(nodePrefix, hashes[:HashLength], leaf)
+			SoMsg("root", root, ShouldResemble, sum(nodePrefix, node, hashes[HashLength:]))
+			other, err := ComputeRoot(leaf, common.RawBytes{0x00, 0x01}, hashes)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("other order", other, ShouldNotResemble, root)
+		})
+		Convey("rejects invalid lengths", func() {
+			_, err := ComputeRoot(leaf, common.RawBytes{0}, hashes)
+			SoMsg("order", err, ShouldNotBeNil)
+			_, err = ComputeRoot(leaf, common.RawBytes{0, 0}, hashes[1:])
+			SoMsg("hashes", err, ShouldNotBeNil)
+			_, err = ComputeRoot(leaf, common.RawBytes{0, 0},
+				make(common.RawBytes, (MaxHeight+1)*HashLength))
+			SoMsg("height", err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLoadStaticHashTreeKeys(t *testing.T) {
+	Convey("LoadStaticHashTreeKeys", t, func() {
+		Convey("loads the keys of all ASes", func() {
+			keys, err := LoadStaticHashTreeKeys("testdata/hashtree_keys.json")
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("len", len(keys), ShouldEqual, 2)
+			key, err := keys.VerifyKey(srcIA)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("key", len(key), ShouldEqual, 32)
+			_, err = keys.VerifyKey(xtest.MustParseIA("1-ff00:0:112"))
+			SoMsg("unknown AS", err, ShouldNotBeNil)
+		})
+		Convey("rejects keys with invalid size", func() {
+			_, err := LoadStaticHashTreeKeys("testdata/invalid_key_size.json")
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("rejects missing files", func() {
+			_, err := LoadStaticHashTreeKeys("testdata/missing.json")
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
+
+// sum returns the truncated SHA-256 hash of the prefix and inputs.
+func sum(prefix byte, inputs ...common.RawBytes) common.RawBytes {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	for _, input := range inputs {
+		h.Write(input)
+	}
+	return h.Sum(nil)[:HashLength]
+}
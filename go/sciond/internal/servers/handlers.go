@@ -21,14 +21,17 @@ import (
 	"time"
 
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/hostinfo"
 	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
 	"github.com/scionproto/scion/go/lib/infra/modules/itopo"
 	"github.com/scionproto/scion/go/lib/infra/modules/segverifier"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/revcache"
 	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/proto"
 	"github.com/scionproto/scion/go/sciond/internal/fetcher"
@@ -359,3 +362,61 @@ func isInvalid(err error) bool {
 func isUnknown(err error) bool {
 	return err != nil
 }
+
+// DRKeyLvl2RequestHandler represents the shared global state for the handling
+// of all DRKeyLvl2Req queries. The requests are forwarded to a CS in the local
+// AS. If the key cannot be fetched, a reply without key is sent.
+type DRKeyLvl2RequestHandler struct {
+	Messenger infra.Messenger
+}
+
+func (h *DRKeyLvl2RequestHandler) Handle(ctx context.Context, transport infra.Transport,
+	src net.Addr, pld *sciond.Pld) {
+
+	logger := log.FromCtx(ctx)
+	req := pld.DRKeyLvl2Req
+	logger.Debug("[DRKeyLvl2RequestHandler] Received request", "req", req)
+	workCtx, workCancelF := context.WithTimeout(ctx, DefaultWorkTimeout)
+	defer workCancelF()
+	rep, err := h.fetch(workCtx, req)
+	if err != nil {
+		logger.Error("[DRKeyLvl2RequestHandler] Unable to fetch key", "req", req, "err", err)
+		rep = &drkey_mgmt.Lvl2Rep{}
+	}
+	reply := &sciond.Pld{
+		Id:           pld.Id,
+		Which:        proto.SCIONDMsg_Which_drkeyLvl2Rep,
+		DRKeyLvl2Rep: rep,
+	}
+	b, err := proto.PackRoot(reply)
+	if err != nil {
+		panic(err)
+	}
+	ctx, cancelF := context.WithTimeout(ctx, DefaultReplyTimeout)
+	defer cancelF()
+	if err := transport.SendMsgTo(ctx, b, src); err != nil {
+		logger.Warn("Unable to reply to client", "client", src, "err", err)
+		return
+	}
+	logger.Trace("Sent reply", "drkey", rep)
+}
+
+func (h *DRKeyLvl2RequestHandler) fetch(ctx context.Context,
+	req *drkey_mgmt.Lvl2Req) (*drkey_mgmt.Lvl2Rep, error) {
+
+	topo := itopo.Get()
+	svcInfo, err := topo.GetSvcInfo(proto.ServiceType_cs)
+	if err != nil {
+		return nil, err
+	}
+	topoAddr := svcInfo.GetAnyTopoAddr()
+	if topoAddr == nil {
+		return nil, common.NewBasicError("Failed to look up CS in topology", nil)
+	}
+	cs := &snet.Addr{
+		IA:      topo.ISD_AS,
+		Host:    topoAddr.PublicAddr(topo.Overlay),
+		NextHop: topoAddr.OverlayAddr(topo.Overlay),
+	}
+	return h.Messenger.RequestDRKeyLvl2(ctx, req, cs, messenger.NextId())
+}
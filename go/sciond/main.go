@@ -145,6 +145,9 @@ func realMain() int {
 			RevCache:   revCache,
 			TrustStore: trustStore,
 		},
+		proto.SCIONDMsg_Which_drkeyLvl2Req: &servers.DRKeyLvl2RequestHandler{
+			Messenger: msger,
+		},
 	}
 	cleaner := periodic.StartPeriodicTask(pathdb.NewCleaner(pathDB),
 		periodic.NewTicker(300*time.Second), 295*time.Second)
@@ -0,0 +1,270 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/sig/base"
+	"github.com/scionproto/scion/go/sig/config"
+	"github.com/scionproto/scion/go/sig/disp"
+	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+)
+
+const (
+	// AnnounceInterval is the interval in which the local networks are
+	// announced to the remote SIGs.
+	AnnounceInterval = 10 * time.Second
+	// AnnounceTimeout is the time after which the networks announced by a
+	// remote SIG are removed, if no new announcement has been received.
+	AnnounceTimeout = 3 * AnnounceInterval
+	// AnnounceMaxSkew is the maximum time an announcement may be created in
+	// the future, to account for clock skew between the SIGs. Announcements
+	// with a later timestamp are ignored, such that they cannot block the
+	// legitimate announcements that follow.
+	AnnounceMaxSkew = 5 * time.Second
+
+	verifyTimeout = 2 * time.Second
+)
+
+// localNets contains the []*net.IPNet that are announced to the remote SIGs.
+var localNets atomic.Value
+
+func init() {
+	localNets.Store([]*net.IPNet(nil))
+}
+
+// setLocalNets sets the networks that are announced to the remote SIGs.
+func setLocalNets(ipnets []*config.IPNet) {
+	nets := make([]*net.IPNet, 0, len(ipnets))
+	for _, ipnet := range ipnets {
+		nets = append(nets, ipnet.IPNet())
+	}
+	localNets.Store(nets)
+}
+
+// Announcer periodically announces the local networks to the SIGs of all
+// remote ASes, and removes the networks of remote SIGs whose announcements
+// timed out. If no local networks are configured, nothing is announced, and
+// the remote SIGs remove the previously announced networks after
+// AnnounceTimeout.
+func Announcer() {
+	log.Info("Announcer: starting")
+	ticker := time.NewTicker(AnnounceInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		raw := packAnnounce(now)
+		Map.Range(func(_ addr.IAInt, ae *ASEntry) bool {
+			if raw != nil {
+				ae.announce(raw)
+			}
+			ae.expireAnnounced(now)
+			return true
+		})
+	}
+}
+
+// packAnnounce returns the signed announcement of the local networks, or nil
+// if there is nothing to announce.
+func packAnnounce(now time.Time) common.RawBytes {
+	nets := localNets.Load().([]*net.IPNet)
+	if len(nets) == 0 {
+		return nil
+	}
+	if sigcmn.Signer == nil {
+		log.Error("Announcer: Unable to sign announcement, trust store not initialized")
+		return nil
+	}
+	spld, err := mgmt.NewPld(mgmt.MsgIdType(now.UnixNano()), mgmt.NewPrefixAnnounce(now, nets))
+	if err != nil {
+		log.Error("Announcer: Error creating SIGCtrl payload", "err", err)
+		return nil
+	}
+	cpld, err := ctrl.NewPld(spld, nil)
+	if err != nil {
+		log.Error("Announcer: Error creating Ctrl payload", "err", err)
+		return nil
+	}
+	scpld, err := cpld.SignedPld(sigcmn.Signer)
+	if err != nil {
+		log.Error("Announcer: Error creating signed Ctrl payload", "err", err)
+		return nil
+	}
+	raw, err := scpld.PackPld()
+	if err != nil {
+		log.Error("Announcer: Error packing signed Ctrl payload", "err", err)
+		return nil
+	}
+	return raw
+}
+
+// announce sends the announcement to the remote SIG of the default session.
+// Nothing is sent if the session has not discovered a remote SIG yet.
+func (ae *ASEntry) announce(raw common.RawBytes) {
+	sess := ae.selector.Load().(*base.PktPolicySelector).Default
+	if sess == nil {
+		return
+	}
+	remote := sess.Remote()
+	if remote == nil || remote.Sig == nil || remote.SessPath == nil {
+		return
+	}
+	raddr := remote.Sig.CtrlSnetAddr()
+	raddr.Path = spath.New(remote.SessPath.PathEntry().Path.FwdPath)
+	if err := raddr.Path.InitOffsets(); err != nil {
+		ae.Error("Announcer: Error initializing path offsets", "err", err)
+		return
+	}
+	nh, err := remote.SessPath.PathEntry().HostInfo.Overlay()
+	if err != nil {
+		ae.Error("Announcer: Unsupported NextHop", "err", err)
+		return
+	}
+	raddr.NextHop = nh
+	if _, err := sigcmn.CtrlConn.WriteToSCION(raw, raddr); err != nil {
+		ae.Error("Announcer: Error sending signed Ctrl payload", "dst", raddr, "err", err)
+	}
+}
+
+// expireAnnounced removes the networks announced by the remote SIG if no
+// announcement has been received for AnnounceTimeout.
+func (ae *ASEntry) expireAnnounced(now time.Time) {
+	ae.Lock()
+	defer ae.Unlock()
+	if len(ae.dynNets) == 0 || now.Sub(ae.lastAnnounceRecv) < AnnounceTimeout {
+		return
+	}
+	ae.Info("Announced networks timed out", "lastAnnounce", ae.lastAnnounceRecv)
+	for key, ipnet := range ae.dynNets {
+		ae.delDynNet(key, ipnet)
+	}
+}
+
+// PrefixAnnounceHdlr handles the announcements of remote SIGs. The networks
+// of an announcement replace the networks previously announced by the remote
+// SIG. Only networks allowed by the configuration of the remote AS are added.
+func PrefixAnnounceHdlr() {
+	log.Info("PrefixAnnounceHdlr: starting")
+	for rpld := range disp.Dispatcher.PrefixAnnounceC {
+		handleAnnounce(rpld)
+	}
+	log.Info("PrefixAnnounceHdlr: stopped")
+}
+
+func handleAnnounce(rpld *disp.RegPld) {
+	pa, ok := rpld.P.(*mgmt.PrefixAnnounce)
+	if !ok {
+		log.Error("PrefixAnnounceHdlr: non-SIGPrefixAnnounce payload received",
+			"src", rpld.Addr, "type", common.TypeOf(rpld.P), "Id", rpld.Id, "pld", rpld.P)
+		return
+	}
+	if sigcmn.Verifier == nil {
+		log.Error("PrefixAnnounceHdlr: Unable to verify announcement, "+
+			"trust store not initialized", "src", rpld.Addr)
+		return
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancelF()
+	_, err := sigcmn.Verifier.WithIA(rpld.Addr.IA).VerifyPld(ctx, rpld.Signed)
+	if err != nil {
+		log.Error("PrefixAnnounceHdlr: Unable to verify announcement",
+			"src", rpld.Addr, "err", err)
+		return
+	}
+	ae := Map.ASEntry(rpld.Addr.IA)
+	if ae == nil {
+		log.Warn("PrefixAnnounceHdlr: Announcement from unknown AS", "src", rpld.Addr)
+		return
+	}
+	nets, err := pa.IPNets()
+	if err != nil {
+		ae.Error("PrefixAnnounceHdlr: Invalid announcement", "src", rpld.Addr, "err", err)
+		return
+	}
+	ae.applyAnnounce(pa.Time(), time.Now(), nets)
+}
+
+// applyAnnounce replaces the networks announced by the remote SIG with the
+// allowed networks in ipnets. Announcements that are not newer than the last
+// applied one, or that are created more than AnnounceMaxSkew after now, are
+// ignored.
+func (ae *ASEntry) applyAnnounce(ts, now time.Time, ipnets []*net.IPNet) {
+	ae.Lock()
+	defer ae.Unlock()
+	if ts.After(now.Add(AnnounceMaxSkew)) {
+		ae.Warn("Ignoring announcement from the future", "ts", ts, "now", now)
+		return
+	}
+	if !ts.After(ae.lastAnnounce) {
+		ae.Debug("Ignoring outdated announcement", "ts", ts, "last", ae.lastAnnounce)
+		return
+	}
+	ae.lastAnnounce = ts
+	ae.lastAnnounceRecv = now
+	announced := make(map[string]*net.IPNet, len(ipnets))
+	for _, ipnet := range ipnets {
+		if !ae.cfg.AllowsNet(ipnet) {
+			ae.Warn("Ignoring announced network, not allowed", "net", ipnet)
+			continue
+		}
+		announced[ipnet.String()] = ipnet
+	}
+	for key, ipnet := range announced {
+		if _, ok := ae.Nets[key]; ok {
+			// Statically configured or already announced.
+			continue
+		}
+		if err := ae.addNet(ipnet); err != nil {
+			ae.Error("Unable to add announced network", "net", ipnet, "err", err)
+			continue
+		}
+		ae.dynNets[key] = ipnet
+	}
+	for key, ipnet := range ae.dynNets {
+		if _, ok := announced[key]; !ok {
+			ae.delDynNet(key, ipnet)
+		}
+	}
+}
+
+// delDisallowedNets deletes the announced networks that are not allowed by the
+// current configuration anymore.
+func (ae *ASEntry) delDisallowedNets() bool {
+	s := true
+	for key, ipnet := range ae.dynNets {
+		if !ae.cfg.AllowsNet(ipnet) {
+			s = ae.delDynNet(key, ipnet) && s
+		}
+	}
+	return s
+}
+
+func (ae *ASEntry) delDynNet(key string, ipnet *net.IPNet) bool {
+	delete(ae.dynNets, key)
+	if err := ae.delNet(ipnet); err != nil {
+		ae.Error("Unable to delete announced network", "net", ipnet, "err", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/infra/mock_infra"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/config"
+	"github.com/scionproto/scion/go/sig/disp"
+	"github.com/scionproto/scion/go/sig/egress"
+	"github.com/scionproto/scion/go/sig/egress/router"
+	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+)
+
+func TestMain(m *testing.M) {
+	ringbuf.InitMetrics("sig", []string{"ringId", "sessId"})
+	os.Exit(m.Run())
+}
+
+func TestApplyAnnounce(t *testing.T) {
+	Convey("applyAnnounce", t, func() {
+		ae := newTestASEntry(xtest.MustParseIA("1-ff00:0:110"), "198.51.100.0/24")
+		Reset(func() { cleanupTestASEntry(ae) })
+		now := time.Now()
+		allowed, other := mustParseNet("198.51.100.0/25"), mustParseNet("198.51.100.128/25")
+
+		Convey("Allowed networks are added", func() {
+			ae.applyAnnounce(now, now, []*net.IPNet{allowed, mustParseNet("203.0.113.0/24")})
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				allowed.String(): allowed,
+			})
+			SoMsg("dynNets", ae.dynNets, ShouldResemble, ae.Nets)
+			SoMsg("lastAnnounceRecv", ae.lastAnnounceRecv.Equal(now), ShouldBeTrue)
+		})
+		Convey("Newer announcements replace the announced networks", func() {
+			ae.applyAnnounce(now, now, []*net.IPNet{allowed})
+			ae.applyAnnounce(now.Add(time.Second), now, []*net.IPNet{other})
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				other.String(): other,
+			})
+		})
+		Convey("Statically configured networks are kept", func() {
+			xtest.FailOnErr(t, ae.addNet(allowed))
+			ae.applyAnnounce(now, now, []*net.IPNet{allowed, other})
+			ae.applyAnnounce(now.Add(time.Second), now, nil)
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				allowed.String(): allowed,
+			})
+			SoMsg("dynNets", ae.dynNets, ShouldBeEmpty)
+		})
+		Convey("Outdated announcements are ignored", func() {
+			ae.applyAnnounce(now, now, []*net.IPNet{allowed})
+			ae.applyAnnounce(now, now, []*net.IPNet{other})
+			ae.applyAnnounce(now.Add(-time.Second), now, []*net.IPNet{other})
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				allowed.String(): allowed,
+			})
+		})
+		Convey("Announcements within the clock skew are applied", func() {
+			ae.applyAnnounce(now.Add(AnnounceMaxSkew), now, []*net.IPNet{allowed})
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				allowed.String(): allowed,
+			})
+		})
+		Convey("Announcements from the future do not block later announcements", func() {
+			ae.applyAnnounce(now.Add(time.Hour), now, []*net.IPNet{other})
+			SoMsg("future nets", ae.Nets, ShouldBeEmpty)
+			SoMsg("lastAnnounce", ae.lastAnnounce.IsZero(), ShouldBeTrue)
+			ae.applyAnnounce(now, now, []*net.IPNet{allowed})
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				allowed.String(): allowed,
+			})
+		})
+	})
+}
+
+func TestHandleAnnounce(t *testing.T) {
+	Convey("handleAnnounce", t, func() {
+		mctrl := gomock.NewController(t)
+		defer mctrl.Finish()
+		ia := xtest.MustParseIA("1-ff00:0:111")
+		ae := newTestASEntry(ia, "192.0.2.0/24")
+		Map.Store(ia.IAInt(), ae)
+		verifier := mock_infra.NewMockVerifier(mctrl)
+		sigcmn.Verifier = verifier
+		Reset(func() {
+			sigcmn.Verifier = nil
+			Map.Delete(ia.IAInt())
+			cleanupTestASEntry(ae)
+		})
+		ipnet := mustParseNet("192.0.2.0/24")
+		rpld := &disp.RegPld{
+			P:      mgmt.NewPrefixAnnounce(time.Now(), []*net.IPNet{ipnet}),
+			Addr:   &snet.Addr{IA: ia},
+			Signed: &ctrl.SignedPld{},
+		}
+
+		Convey("Verified announcements are applied", func() {
+			verifier.EXPECT().WithIA(ia).Return(verifier)
+			verifier.EXPECT().VerifyPld(gomock.Any(), rpld.Signed).Return(nil, nil)
+			handleAnnounce(rpld)
+			SoMsg("nets", ae.Nets, ShouldResemble, map[string]*net.IPNet{
+				ipnet.String(): ipnet,
+			})
+		})
+		Convey("Announcements with invalid signature are ignored", func() {
+			verifier.EXPECT().WithIA(ia).Return(verifier)
+			verifier.EXPECT().VerifyPld(gomock.Any(), rpld.Signed).Return(nil,
+				errors.New("invalid signature"))
+			handleAnnounce(rpld)
+			SoMsg("nets", ae.Nets, ShouldBeEmpty)
+		})
+		Convey("Announcements are ignored without trust store", func() {
+			sigcmn.Verifier = nil
+			handleAnnounce(rpld)
+			SoMsg("nets", ae.Nets, ShouldBeEmpty)
+		})
+		Convey("Announcements from unknown ASes are ignored", func() {
+			other := xtest.MustParseIA("1-ff00:0:112")
+			rpld.Addr = &snet.Addr{IA: other}
+			verifier.EXPECT().WithIA(other).Return(verifier)
+			verifier.EXPECT().VerifyPld(gomock.Any(), rpld.Signed).Return(nil, nil)
+			handleAnnounce(rpld)
+			SoMsg("nets", ae.Nets, ShouldBeEmpty)
+		})
+		Convey("Invalid announcements are ignored", func() {
+			rpld.P = &mgmt.PrefixAnnounce{
+				Timestamp: uint64(time.Now().UnixNano()),
+				Nets:      []*mgmt.Net{{IP: []byte{192, 0, 2, 1}, PrefixLen: 24}},
+			}
+			verifier.EXPECT().WithIA(ia).Return(verifier)
+			verifier.EXPECT().VerifyPld(gomock.Any(), rpld.Signed).Return(nil, nil)
+			handleAnnounce(rpld)
+			SoMsg("nets", ae.Nets, ShouldBeEmpty)
+		})
+		Convey("Other payloads are ignored", func() {
+			rpld.P = &mgmt.PollReq{}
+			handleAnnounce(rpld)
+			SoMsg("nets", ae.Nets, ShouldBeEmpty)
+		})
+	})
+}
+
+// newTestASEntry returns an AS entry without sessions that accepts the
+// announced networks contained in allowed.
+func newTestASEntry(ia addr.IA, allowed ...string) *ASEntry {
+	cfg := &config.ASEntry{}
+	for _, s := range allowed {
+		cfg.AllowedNets = append(cfg.AllowedNets, (*config.IPNet)(mustParseNet(s)))
+	}
+	return &ASEntry{
+		Logger:   log.New("ia", ia),
+		IA:       ia,
+		IAString: ia.String(),
+		Nets:     make(map[string]*net.IPNet),
+		egressRing: ringbuf.New(egress.EgressRemotePkts, nil, "egress",
+			prometheus.Labels{"ringId": ia.String(), "sessId": ""}),
+		cfg:     cfg,
+		dynNets: make(map[string]*net.IPNet),
+	}
+}
+
+// cleanupTestASEntry removes the networks of the AS entry from the global
+// network map.
+func cleanupTestASEntry(ae *ASEntry) {
+	for _, ipnet := range ae.Nets {
+		router.NetMap.Delete(ipnet)
+	}
+}
+
+func mustParseNet(s string) *net.IPNet {
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return ipnet
+}
@@ -17,7 +17,10 @@ package core
 
 import (
 	"net"
+	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -25,6 +28,8 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/pktcls"
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/sig/base"
 	"github.com/scionproto/scion/go/sig/config"
@@ -33,6 +38,7 @@ import (
 	"github.com/scionproto/scion/go/sig/egress/router"
 	"github.com/scionproto/scion/go/sig/egress/session"
 	"github.com/scionproto/scion/go/sig/egress/worker"
+	"github.com/scionproto/scion/go/sig/mgmt"
 )
 
 const (
@@ -50,9 +56,26 @@ type ASEntry struct {
 	version           uint64 // used to track certain changes made to ASEntry
 	log.Logger
 
-	Session *session.Session
+	// sessions contains the sessions to the remote AS, keyed by session ID.
+	sessions map[mgmt.SessionType]*session.Session
+	// policies contains the path policies the sessions were created with.
+	policies map[mgmt.SessionType]*pathpol.Policy
+	// selector contains the *base.PktPolicySelector that is used to choose
+	// the session for egress packets.
+	selector atomic.Value
+	// cfg is the configuration the entry was last reloaded with.
+	cfg *config.ASEntry
+	// dynNets contains the networks in Nets that were announced by the remote
+	// SIG, as opposed to the statically configured ones.
+	dynNets map[string]*net.IPNet
+	// lastAnnounce is the creation time of the last applied announcement.
+	lastAnnounce time.Time
+	// lastAnnounceRecv is the time the last applied announcement was received.
+	lastAnnounceRecv time.Time
 }
 
+var _ egress.SessionSelector = (*ASEntry)(nil)
+
 func newASEntry(ia addr.IA) (*ASEntry, error) {
 	ae := &ASEntry{
 		Logger:            log.New("ia", ia),
@@ -60,31 +83,153 @@ func newASEntry(ia addr.IA) (*ASEntry, error) {
 		IAString:          ia.String(),
 		Nets:              make(map[string]*net.IPNet),
 		healthMonitorStop: make(chan struct{}),
+		sessions:          make(map[mgmt.SessionType]*session.Session),
+		policies:          make(map[mgmt.SessionType]*pathpol.Policy),
+		cfg:               &config.ASEntry{},
+		dynNets:           make(map[string]*net.IPNet),
 	}
-	var err error
-	pool, err := session.NewPathPool(ia)
-	if err != nil {
-		return nil, err
-	}
-	ae.Session, err = session.NewSession(ia, 0, ae.Logger, pool, worker.DefaultFactory)
-	if err != nil {
+	if err := ae.addSession(config.DefaultSessionID, nil); err != nil {
 		return nil, err
 	}
+	ae.updateSelector(nil, nil)
 	return ae, nil
 }
 
-func (ae *ASEntry) ReloadConfig(cfg *config.ASEntry) bool {
+// ReloadConfig updates the networks, sessions and packet policies of the
+// remote AS. The classes and actions are the ones referenced by cfg.
+func (ae *ASEntry) ReloadConfig(cfg *config.ASEntry, classes pktcls.ClassMap,
+	actions pktcls.ActionMap) bool {
+
 	ae.Lock()
 	defer ae.Unlock()
+	ae.cfg = cfg
 	// Method calls first to prevent skips due to logical short-circuit
-	s := ae.addNewNets(cfg.Nets)
-	return ae.delOldNets(cfg.Nets) && s
+	s := ae.reloadSessions(cfg, classes, actions)
+	s = ae.addNewNets(cfg.Nets) && s
+	s = ae.delOldNets(cfg.Nets) && s
+	return ae.delDisallowedNets() && s
+}
+
+// reloadSessions creates the sessions in cfg that do not exist yet, recreates
+// the sessions whose path policy changed and removes the sessions that are not
+// in cfg anymore. Afterwards, the packet policies are updated.
+func (ae *ASEntry) reloadSessions(cfg *config.ASEntry, classes pktcls.ClassMap,
+	actions pktcls.ActionMap) bool {
+
+	s := true
+	policies := cfg.SessionPolicies(actions)
+	var stale []*session.Session
+	for sessId, policy := range policies {
+		sess, ok := ae.sessions[sessId]
+		if ok && reflect.DeepEqual(ae.policies[sessId], policy) {
+			continue
+		}
+		if err := ae.addSession(sessId, policy); err != nil {
+			ae.Error("Unable to add session", "sessId", sessId, "err", err)
+			s = false
+			continue
+		}
+		if ok {
+			stale = append(stale, sess)
+		}
+	}
+	for sessId, sess := range ae.sessions {
+		if _, ok := policies[sessId]; !ok {
+			delete(ae.sessions, sessId)
+			delete(ae.policies, sessId)
+			stale = append(stale, sess)
+		}
+	}
+	s = ae.updateSelector(cfg.PktPolicies, classes) && s
+	// The old sessions are cleaned up after the selector has been replaced,
+	// such that no packets are dispatched to them anymore.
+	for _, sess := range stale {
+		if err := sess.Cleanup(); err != nil {
+			sess.Error("Error cleaning up session", "err", err)
+			s = false
+		}
+	}
+	return s
+}
+
+// addSession creates a session with the path policy and stores it under the
+// session ID, replacing any existing session. The session is started if the
+// network setup is already done.
+func (ae *ASEntry) addSession(sessId mgmt.SessionType, policy *pathpol.Policy) error {
+	pool, err := session.NewPathPool(ae.IA, policy)
+	if err != nil {
+		return err
+	}
+	sess, err := session.NewSession(ae.IA, sessId, ae.Logger, pool, worker.DefaultFactory)
+	if err != nil {
+		return err
+	}
+	ae.sessions[sessId] = sess
+	ae.policies[sessId] = policy
+	if ae.egressRing != nil {
+		sess.Start()
+	}
+	ae.Info("Added session", "sessId", sessId, "filtered", policy != nil)
+	return nil
+}
+
+// updateSelector replaces the session selector with one for the packet
+// policies. Packets that do not match any policy are sent on the session with
+// the lowest ID.
+func (ae *ASEntry) updateSelector(pktPolicies []*config.PktPolicy,
+	classes pktcls.ClassMap) bool {
+
+	s := true
+	selector := &base.PktPolicySelector{}
+	if ids := ae.sessionIDs(); len(ids) > 0 {
+		selector.Default = ae.sessions[ids[0]]
+	}
+	for _, pol := range pktPolicies {
+		class, ok := classes[pol.ClassName]
+		if !ok {
+			ae.Error("Unknown class in packet policy", "class", pol.ClassName)
+			s = false
+			continue
+		}
+		p := &base.PktPolicy{Class: class}
+		for _, sessId := range pol.SessIds {
+			sess, ok := ae.sessions[sessId]
+			if !ok {
+				ae.Error("Unknown session in packet policy", "class", pol.ClassName,
+					"sessId", sessId)
+				s = false
+				continue
+			}
+			p.Sessions = append(p.Sessions, sess)
+		}
+		selector.Policies = append(selector.Policies, p)
+	}
+	ae.selector.Store(selector)
+	return s
+}
+
+// sessionIDs returns the IDs of the sessions in ascending order.
+func (ae *ASEntry) sessionIDs() []mgmt.SessionType {
+	ids := make([]mgmt.SessionType, 0, len(ae.sessions))
+	for sessId := range ae.sessions {
+		ids = append(ids, sessId)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// ChooseSess implements egress.SessionSelector. It chooses the session
+// according to the packet policies of the remote AS.
+func (ae *ASEntry) ChooseSess(b common.RawBytes) egress.Session {
+	return ae.selector.Load().(*base.PktPolicySelector).ChooseSess(b)
 }
 
 // addNewNets adds the networks in ipnets that are not currently configured.
+// Networks that were announced by the remote SIG become static.
 func (ae *ASEntry) addNewNets(ipnets []*config.IPNet) bool {
 	s := true
 	for _, ipnet := range ipnets {
+		delete(ae.dynNets, ipnet.IPNet().String())
 		err := ae.addNet(ipnet.IPNet())
 		if err != nil {
 			ae.Error("Unable to add network", "net", ipnet, "err", err)
@@ -95,10 +240,14 @@ func (ae *ASEntry) addNewNets(ipnets []*config.IPNet) bool {
 }
 
 // delOldNets deletes currently configured networks that are not in ipnets.
+// Networks that were announced by the remote SIG are kept.
 func (ae *ASEntry) delOldNets(ipnets []*config.IPNet) bool {
 	s := true
 Top:
 	for k, v := range ae.Nets {
+		if _, ok := ae.dynNets[k]; ok {
+			continue
+		}
 		for _, ipnet := range ipnets {
 			if k == ipnet.IPNet().String() {
 				continue Top
@@ -218,8 +367,14 @@ func (ae *ASEntry) performHealthCheck(prevHealth *bool, prevVersion *uint64) {
 	*prevVersion = ae.version
 }
 
+// checkHealth returns true if all sessions to the remote AS are healthy.
 func (ae *ASEntry) checkHealth() bool {
-	return ae.Session.Healthy()
+	for _, sess := range ae.sessions {
+		if !sess.Healthy() {
+			return false
+		}
+	}
+	return true
 }
 
 func (ae *ASEntry) Cleanup() error {
@@ -240,8 +395,10 @@ func (ae *ASEntry) Cleanup() error {
 }
 
 func (ae *ASEntry) cleanSessions() {
-	if err := ae.Session.Cleanup(); err != nil {
-		ae.Session.Error("Error cleaning up session", "err", err)
+	for _, sess := range ae.sessions {
+		if err := sess.Cleanup(); err != nil {
+			sess.Error("Error cleaning up session", "err", err)
+		}
 	}
 }
 
@@ -250,13 +407,14 @@ func (ae *ASEntry) setupNet() {
 		prometheus.Labels{"ringId": ae.IAString, "sessId": ""})
 	go func() {
 		defer log.LogPanicAndExit()
-		dispatcher.NewDispatcher(ae.IA, ae.egressRing,
-			&base.SingleSession{Session: ae.Session}).Run()
+		dispatcher.NewDispatcher(ae.IA, ae.egressRing, ae).Run()
 	}()
 	go func() {
 		defer log.LogPanicAndExit()
 		ae.monitorHealth()
 	}()
-	ae.Session.Start()
+	for _, sess := range ae.sessions {
+		sess.Start()
+	}
 	ae.Info("Network setup done")
 }
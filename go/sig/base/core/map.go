@@ -61,6 +61,7 @@ func (am *ASMap) Range(f func(key addr.IAInt, value *ASEntry) bool) {
 }
 
 func (am *ASMap) ReloadConfig(cfg *config.Cfg) bool {
+	setLocalNets(cfg.AnnounceNets)
 	// Method calls first to prevent skips due to logical short-circuit
 	s := am.addNewIAs(cfg)
 	return am.delOldIAs(cfg) && s
@@ -77,7 +78,7 @@ func (am *ASMap) addNewIAs(cfg *config.Cfg) bool {
 			s = false
 			continue
 		}
-		s = ae.ReloadConfig(cfgEntry) && s
+		s = ae.ReloadConfig(cfgEntry, cfg.Classes, cfg.Actions) && s
 		log.Info("ReloadConfig: Added AS", "ia", ia)
 	}
 	return s
@@ -0,0 +1,112 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package base
+
+import (
+	"context"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/proto"
+	"github.com/scionproto/scion/go/sig/disp"
+	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/seal"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+)
+
+const keyExchangeVerifyTimeout = 2 * time.Second
+
+// KeyExchangeReqHdlr answers the key exchange requests of remote SIGs. The
+// established keys are added to sigcmn.FrameKeys, such that the frames the
+// remote SIGs seal with them can be opened.
+func KeyExchangeReqHdlr() {
+	log.Info("KeyExchangeReqHdlr: starting")
+	for rpld := range disp.Dispatcher.KeyExchangeReqC {
+		handleKeyExchangeReq(rpld)
+	}
+	log.Info("KeyExchangeReqHdlr: stopped")
+}
+
+func handleKeyExchangeReq(rpld *disp.RegPld) {
+	req, ok := rpld.P.(*mgmt.KeyExchangeReq)
+	if !ok {
+		log.Error("KeyExchangeReqHdlr: non-SIGKeyExchangeReq payload received",
+			"src", rpld.Addr, "type", common.TypeOf(rpld.P), "Id", rpld.Id, "pld", rpld.P)
+		return
+	}
+	if !sigcmn.Encryption {
+		log.Warn("KeyExchangeReqHdlr: Ignoring request, encryption is disabled",
+			"src", rpld.Addr)
+		return
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), keyExchangeVerifyTimeout)
+	defer cancelF()
+	if _, err := sigcmn.Verifier.WithIA(rpld.Addr.IA).VerifyPld(ctx, rpld.Signed); err != nil {
+		log.Error("KeyExchangeReqHdlr: Unable to verify request", "src", rpld.Addr, "err", err)
+		return
+	}
+	e, err := seal.NewExchange()
+	if err != nil {
+		log.Error("KeyExchangeReqHdlr: Unable to create exchange", "err", err)
+		return
+	}
+	info := seal.KeyInfo{
+		Src:     rpld.Addr.IA,
+		Dst:     sigcmn.IA,
+		Session: req.Session,
+		KeyId:   req.KeyId,
+	}
+	key, err := e.Key(req.PubKey, false, info)
+	if err != nil {
+		log.Error("KeyExchangeReqHdlr: Unable to derive key", "src", rpld.Addr, "err", err)
+		return
+	}
+	peer := seal.NewPeer(rpld.Addr.IA, rpld.Addr.Host.L3, req.Session)
+	if err := sigcmn.FrameKeys.Add(peer, key, req.Time()); err != nil {
+		log.Warn("KeyExchangeReqHdlr: Ignoring request", "src", rpld.Addr, "err", err)
+		return
+	}
+	rep := mgmt.NewKeyExchangeRep(req.Session, req.KeyId, e.PubKey, time.Now())
+	raw, err := packSigned(rpld.Id, rep)
+	if err != nil {
+		log.Error("KeyExchangeReqHdlr: Unable to create reply", "err", err)
+		return
+	}
+	if _, err := sigcmn.CtrlConn.WriteToSCION(raw, rpld.Addr); err != nil {
+		log.Error("KeyExchangeReqHdlr: Error sending reply", "dst", rpld.Addr, "err", err)
+		return
+	}
+	log.Debug("KeyExchangeReqHdlr: Established key", "peer", peer, "keyId", req.KeyId)
+}
+
+// packSigned returns the packed SIG ctrl payload with the given id and
+// content, signed with sigcmn.Signer.
+func packSigned(id mgmt.MsgIdType, u proto.Cerealizable) (common.RawBytes, error) {
+	spld, err := mgmt.NewPld(id, u)
+	if err != nil {
+		return nil, common.NewBasicError("Error creating SIGCtrl payload", err)
+	}
+	cpld, err := ctrl.NewPld(spld, nil)
+	if err != nil {
+		return nil, common.NewBasicError("Error creating Ctrl payload", err)
+	}
+	scpld, err := cpld.SignedPld(sigcmn.Signer)
+	if err != nil {
+		return nil, common.NewBasicError("Error creating signed Ctrl payload", err)
+	}
+	return scpld.PackPld()
+}
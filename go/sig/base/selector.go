@@ -16,6 +16,7 @@ package base
 
 import (
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pktcls"
 	"github.com/scionproto/scion/go/sig/egress"
 )
 
@@ -30,3 +31,42 @@ type SingleSession struct {
 func (ss *SingleSession) ChooseSess(b common.RawBytes) egress.Session {
 	return ss.Session
 }
+
+var _ egress.SessionSelector = (*PktPolicySelector)(nil)
+
+// PktPolicySelector implements egress.SessionSelector. It classifies packets
+// according to the packet policies and chooses a session of the first policy
+// whose class matches. Among the sessions of a policy, the first healthy one
+// is chosen. If all of them are unhealthy, the first one is chosen. Packets
+// that do not match any class are sent on the default session.
+type PktPolicySelector struct {
+	Policies []*PktPolicy
+	Default  egress.Session
+}
+
+func (ps *PktPolicySelector) ChooseSess(b common.RawBytes) egress.Session {
+	if len(ps.Policies) == 0 {
+		return ps.Default
+	}
+	pkt := pktcls.NewPacket(b)
+	for _, pol := range ps.Policies {
+		if !pol.Class.Eval(pkt) {
+			continue
+		}
+		for _, sess := range pol.Sessions {
+			if sess.Healthy() {
+				return sess
+			}
+		}
+		if len(pol.Sessions) > 0 {
+			return pol.Sessions[0]
+		}
+	}
+	return ps.Default
+}
+
+// PktPolicy maps a traffic class to the sessions, in order of preference.
+type PktPolicy struct {
+	Class    *pktcls.Class
+	Sessions []egress.Session
+}
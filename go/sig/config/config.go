@@ -23,11 +23,27 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/pktcls"
+	"github.com/scionproto/scion/go/sig/mgmt"
 )
 
+// DefaultSessionID is the ID of the session that is used for remote ASes that
+// do not configure any sessions.
+const DefaultSessionID mgmt.SessionType = 0
+
 // Cfg is a direct Go representation of the JSON file format.
 type Cfg struct {
-	ASes          map[addr.IA]*ASEntry
+	ASes map[addr.IA]*ASEntry
+	// Classes contains the traffic classes that can be referenced by the
+	// packet policies of the AS entries.
+	Classes pktcls.ClassMap `json:",omitempty"`
+	// Actions contains the path filters that can be referenced by the
+	// sessions of the AS entries.
+	Actions pktcls.ActionMap `json:",omitempty"`
+	// AnnounceNets contains the local networks that are announced to the
+	// SIGs of all remote ASes.
+	AnnounceNets  []*IPNet `json:",omitempty"`
 	ConfigVersion uint64
 }
 
@@ -41,11 +57,157 @@ func LoadFromFile(path string) (*Cfg, error) {
 	if err := json.Unmarshal(b, cfg); err != nil {
 		return nil, common.NewBasicError("Unable to parse SIG config", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, common.NewBasicError("Invalid SIG config", err)
+	}
 	return cfg, nil
 }
 
+// SetAttributes sets the attribute database of the path policies of all
+// actions, such that their attribute predicates are evaluated against db.
+func (cfg *Cfg) SetAttributes(db pathpol.AttributeDB) {
+	for _, action := range cfg.Actions {
+		if a, ok := action.(*pktcls.ActionFilterPaths); ok && a.Policy != nil {
+			a.Policy.Attributes = db
+		}
+	}
+}
+
+// PrefixAnnouncements returns true if the configuration announces local
+// networks or accepts networks announced by remote SIGs.
+func (cfg *Cfg) PrefixAnnouncements() bool {
+	if len(cfg.AnnounceNets) > 0 {
+		return true
+	}
+	for _, ae := range cfg.ASes {
+		if len(ae.AllowedNets) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that all classes, actions and sessions referenced by the AS
+// entries exist.
+func (cfg *Cfg) Validate() error {
+	for ia, ae := range cfg.ASes {
+		if err := ae.validate(cfg.Classes, cfg.Actions); err != nil {
+			return common.NewBasicError("Invalid AS entry", err, "ia", ia)
+		}
+	}
+	return nil
+}
+
 type ASEntry struct {
 	Nets []*IPNet
+	// AllowedNets contains the networks the remote AS may announce. An
+	// announced network is accepted if it is contained in one of the allowed
+	// networks. If empty, all announcements of the remote AS are ignored.
+	AllowedNets []*IPNet `json:",omitempty"`
+	// Sessions maps the IDs of the sessions to the remote AS to the name of
+	// the path filter action in Cfg.Actions. An empty name means the paths of
+	// the session are not filtered. If no sessions are configured, a single
+	// session with DefaultSessionID is used.
+	Sessions SessionMap `json:",omitempty"`
+	// PktPolicies map traffic classes to sessions. The first policy whose
+	// class matches a packet determines the session. Packets that do not
+	// match any class are sent on the session with the lowest ID.
+	PktPolicies []*PktPolicy `json:",omitempty"`
+}
+
+// SessionPolicies returns the path policy of every session of the AS entry. A
+// nil policy means the paths are not filtered. The actions must have been
+// validated with Cfg.Validate.
+func (ae *ASEntry) SessionPolicies(actions pktcls.ActionMap) map[mgmt.SessionType]*pathpol.Policy {
+	if len(ae.Sessions) == 0 {
+		return map[mgmt.SessionType]*pathpol.Policy{DefaultSessionID: nil}
+	}
+	policies := make(map[mgmt.SessionType]*pathpol.Policy, len(ae.Sessions))
+	for sessId, actionName := range ae.Sessions {
+		var policy *pathpol.Policy
+		if action, ok := actions[actionName].(*pktcls.ActionFilterPaths); ok {
+			policy = action.Policy
+		}
+		policies[sessId] = policy
+	}
+	return policies
+}
+
+// AllowsNet returns true if ipnet is contained in one of the allowed networks.
+func (ae *ASEntry) AllowsNet(ipnet *net.IPNet) bool {
+	ones, bits := ipnet.Mask.Size()
+	for _, allowed := range ae.AllowedNets {
+		allowedOnes, allowedBits := allowed.Mask.Size()
+		if bits == allowedBits && ones >= allowedOnes && allowed.IPNet().Contains(ipnet.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ae *ASEntry) validate(classes pktcls.ClassMap, actions pktcls.ActionMap) error {
+	for sessId, actionName := range ae.Sessions {
+		if actionName == "" {
+			continue
+		}
+		action, ok := actions[actionName]
+		if !ok {
+			return common.NewBasicError("Unknown action", nil,
+				"sessId", sessId, "action", actionName)
+		}
+		if _, ok := action.(*pktcls.ActionFilterPaths); !ok {
+			return common.NewBasicError("Action is not a path filter", nil,
+				"sessId", sessId, "action", actionName, "type", action.Type())
+		}
+	}
+	for _, pol := range ae.PktPolicies {
+		if _, ok := classes[pol.ClassName]; !ok {
+			return common.NewBasicError("Unknown class", nil, "class", pol.ClassName)
+		}
+		if len(pol.SessIds) == 0 {
+			return common.NewBasicError("No sessions for class", nil, "class", pol.ClassName)
+		}
+		for _, sessId := range pol.SessIds {
+			if !ae.hasSession(sessId) {
+				return common.NewBasicError("Unknown session", nil,
+					"class", pol.ClassName, "sessId", sessId)
+			}
+		}
+	}
+	return nil
+}
+
+func (ae *ASEntry) hasSession(sessId mgmt.SessionType) bool {
+	if len(ae.Sessions) == 0 {
+		return sessId == DefaultSessionID
+	}
+	_, ok := ae.Sessions[sessId]
+	return ok
+}
+
+// SessionMap maps session IDs to the names of path filter actions.
+type SessionMap map[mgmt.SessionType]string
+
+// PktPolicy sends the traffic of a class on the listed sessions. The first
+// healthy session in the list is used.
+type PktPolicy struct {
+	ClassName string
+	SessIds   SessionIDs
+}
+
+// SessionIDs is a list of session IDs. It is marshaled as a JSON array of
+// numbers instead of the base64 string that is used for byte slices.
+type SessionIDs []mgmt.SessionType
+
+func (ids SessionIDs) MarshalJSON() ([]byte, error) {
+	if ids == nil {
+		return json.Marshal(nil)
+	}
+	nums := make([]int, 0, len(ids))
+	for _, id := range ids {
+		nums = append(nums, int(id))
+	}
+	return json.Marshal(nums)
 }
 
 // IPNet is custom type of net.IPNet, to allow custom unmarshalling.
@@ -15,6 +15,7 @@
 package config
 
 import (
+	"encoding/json"
 	"flag"
 	"net"
 	"path/filepath"
@@ -23,7 +24,10 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/pktcls"
 	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/mgmt"
 )
 
 var (
@@ -31,6 +35,8 @@ var (
 )
 
 func TestLoadFromFile(t *testing.T) {
+	var policy pathpol.Policy
+	xtest.FailOnErr(t, json.Unmarshal([]byte(`{"ACL": ["- 1-ff00:0:133#0", "+"]}`), &policy))
 	testCases := []struct {
 		Name     string
 		FileName string
@@ -71,6 +77,71 @@ func TestLoadFromFile(t *testing.T) {
 				ConfigVersion: 9001,
 			},
 		},
+		{
+			Name:     "traffic classes",
+			FileName: "02-trafficclasses",
+			Config: Cfg{
+				ASes: map[addr.IA]*ASEntry{
+					xtest.MustParseIA("1-ff00:0:1"): {
+						Nets: []*IPNet{
+							{
+								IP:   net.IP{192, 0, 2, 0},
+								Mask: net.CIDRMask(24, 8*net.IPv4len),
+							},
+						},
+						Sessions: SessionMap{0: "", 1: "avoid 133"},
+						PktPolicies: []*PktPolicy{
+							{ClassName: "voice", SessIds: SessionIDs{1, 0}},
+						},
+					},
+				},
+				Classes: pktcls.ClassMap{
+					"voice": pktcls.NewClass("voice",
+						pktcls.NewCondIPv4(&pktcls.IPv4MatchDSCP{DSCP: 0x2e})),
+				},
+				Actions: pktcls.ActionMap{
+					"avoid 133": pktcls.NewActionFilterPaths("avoid 133", &policy),
+				},
+				ConfigVersion: 2,
+			},
+		},
+		{
+			Name:     "prefix announcement",
+			FileName: "03-prefixannounce",
+			Config: Cfg{
+				ASes: map[addr.IA]*ASEntry{
+					xtest.MustParseIA("1-ff00:0:1"): {
+						Nets: []*IPNet{
+							{
+								IP:   net.IP{192, 0, 2, 0},
+								Mask: net.CIDRMask(24, 8*net.IPv4len),
+							},
+						},
+						AllowedNets: []*IPNet{
+							{
+								IP:   net.IP{198, 51, 100, 0},
+								Mask: net.CIDRMask(24, 8*net.IPv4len),
+							},
+							{
+								IP:   net.ParseIP("2001:DB8:1::"),
+								Mask: net.CIDRMask(48, 8*net.IPv6len),
+							},
+						},
+					},
+				},
+				AnnounceNets: []*IPNet{
+					{
+						IP:   net.IP{203, 0, 113, 0},
+						Mask: net.CIDRMask(24, 8*net.IPv4len),
+					},
+					{
+						IP:   net.ParseIP("2001:DB8:2::"),
+						Mask: net.CIDRMask(48, 8*net.IPv6len),
+					},
+				},
+				ConfigVersion: 3,
+			},
+		},
 	}
 
 	Convey("Test SIG config marshal/unmarshal", t, func() {
@@ -88,6 +159,179 @@ func TestLoadFromFile(t *testing.T) {
 	})
 }
 
+func TestValidate(t *testing.T) {
+	ia := xtest.MustParseIA("1-ff00:0:1")
+	classes := pktcls.ClassMap{
+		"voice": pktcls.NewClass("voice", pktcls.CondTrue),
+	}
+	actions := pktcls.ActionMap{
+		"paths": pktcls.NewActionFilterPaths("paths", nil),
+	}
+	testCases := []struct {
+		Name  string
+		Error bool
+		Entry *ASEntry
+	}{
+		{
+			Name:  "No sessions and no policies",
+			Error: false,
+			Entry: &ASEntry{},
+		},
+		{
+			Name:  "Default session",
+			Error: false,
+			Entry: &ASEntry{
+				PktPolicies: []*PktPolicy{{ClassName: "voice", SessIds: SessionIDs{0}}},
+			},
+		},
+		{
+			Name:  "Configured sessions",
+			Error: false,
+			Entry: &ASEntry{
+				Sessions:    SessionMap{1: "paths", 2: ""},
+				PktPolicies: []*PktPolicy{{ClassName: "voice", SessIds: SessionIDs{2, 1}}},
+			},
+		},
+		{
+			Name:  "Unknown action",
+			Error: true,
+			Entry: &ASEntry{Sessions: SessionMap{1: "foo"}},
+		},
+		{
+			Name:  "Unknown class",
+			Error: true,
+			Entry: &ASEntry{
+				PktPolicies: []*PktPolicy{{ClassName: "foo", SessIds: SessionIDs{0}}},
+			},
+		},
+		{
+			Name:  "No sessions for class",
+			Error: true,
+			Entry: &ASEntry{
+				PktPolicies: []*PktPolicy{{ClassName: "voice"}},
+			},
+		},
+		{
+			Name:  "Unknown session",
+			Error: true,
+			Entry: &ASEntry{
+				Sessions:    SessionMap{1: "paths"},
+				PktPolicies: []*PktPolicy{{ClassName: "voice", SessIds: SessionIDs{0}}},
+			},
+		},
+	}
+
+	Convey("Test SIG config validation", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				cfg := &Cfg{
+					ASes:    map[addr.IA]*ASEntry{ia: tc.Entry},
+					Classes: classes,
+					Actions: actions,
+				}
+				xtest.SoMsgError("err", cfg.Validate(), tc.Error)
+			})
+		}
+	})
+}
+
+func TestSessionPolicies(t *testing.T) {
+	policy := &pathpol.Policy{}
+	actions := pktcls.ActionMap{
+		"paths": pktcls.NewActionFilterPaths("paths", policy),
+	}
+	Convey("Test session policies", t, func() {
+		Convey("Default session is used without configured sessions", func() {
+			ae := &ASEntry{}
+			SoMsg("policies", ae.SessionPolicies(actions), ShouldResemble,
+				map[mgmt.SessionType]*pathpol.Policy{DefaultSessionID: nil})
+		})
+		Convey("Configured sessions use the policy of their action", func() {
+			ae := &ASEntry{Sessions: SessionMap{1: "paths", 2: ""}}
+			policies := ae.SessionPolicies(actions)
+			SoMsg("len", len(policies), ShouldEqual, 2)
+			SoMsg("1", policies[1], ShouldEqual, policy)
+			SoMsg("2", policies[2], ShouldBeNil)
+		})
+	})
+}
+
+func TestSetAttributes(t *testing.T) {
+	Convey("SetAttributes sets the database of all path policies", t, func() {
+		policy := &pathpol.Policy{}
+		cfg := &Cfg{
+			Actions: pktcls.ActionMap{
+				"paths":    pktcls.NewActionFilterPaths("paths", policy),
+				"nopolicy": pktcls.NewActionFilterPaths("nopolicy", nil),
+			},
+		}
+		db := pathpol.AttributeDB{
+			xtest.MustParseIA("1-ff00:0:110"): pathpol.Labels{"country": "CH"},
+		}
+		cfg.SetAttributes(db)
+		SoMsg("attributes", policy.Attributes, ShouldResemble, db)
+	})
+}
+
+func TestPrefixAnnouncements(t *testing.T) {
+	ipnet := &IPNet{IP: net.IP{192, 0, 2, 0}, Mask: net.CIDRMask(24, 8*net.IPv4len)}
+	ia := xtest.MustParseIA("1-ff00:0:110")
+	Convey("PrefixAnnouncements", t, func() {
+		Convey("Static configuration", func() {
+			cfg := &Cfg{ASes: map[addr.IA]*ASEntry{ia: {Nets: []*IPNet{ipnet}}}}
+			SoMsg("announce", cfg.PrefixAnnouncements(), ShouldBeFalse)
+		})
+		Convey("Announcing local networks", func() {
+			cfg := &Cfg{AnnounceNets: []*IPNet{ipnet}}
+			SoMsg("announce", cfg.PrefixAnnouncements(), ShouldBeTrue)
+		})
+		Convey("Accepting announced networks", func() {
+			cfg := &Cfg{ASes: map[addr.IA]*ASEntry{ia: {AllowedNets: []*IPNet{ipnet}}}}
+			SoMsg("announce", cfg.PrefixAnnouncements(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestAllowsNet(t *testing.T) {
+	ae := &ASEntry{
+		AllowedNets: []*IPNet{
+			{
+				IP:   net.IP{198, 51, 100, 0},
+				Mask: net.CIDRMask(24, 8*net.IPv4len),
+			},
+			{
+				IP:   net.ParseIP("2001:DB8:1::"),
+				Mask: net.CIDRMask(48, 8*net.IPv6len),
+			},
+		},
+	}
+	testCases := []struct {
+		Net     string
+		Allowed bool
+	}{
+		{Net: "198.51.100.0/24", Allowed: true},
+		{Net: "198.51.100.128/25", Allowed: true},
+		{Net: "198.51.0.0/16", Allowed: false},
+		{Net: "203.0.113.0/24", Allowed: false},
+		{Net: "2001:db8:1:2::/64", Allowed: true},
+		{Net: "2001:db8::/32", Allowed: false},
+		{Net: "::ffff:c633:6400/120", Allowed: false},
+	}
+	Convey("Test allowed networks", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Net, func() {
+				_, ipnet, err := net.ParseCIDR(tc.Net)
+				xtest.FailOnErr(t, err)
+				SoMsg("allowed", ae.AllowsNet(ipnet), ShouldEqual, tc.Allowed)
+			})
+		}
+		Convey("No allowed networks", func() {
+			_, ipnet, _ := net.ParseCIDR("198.51.100.0/24")
+			SoMsg("allowed", (&ASEntry{}).AllowsNet(ipnet), ShouldBeFalse)
+		})
+	})
+}
+
 func TestIPNetUnmarshalJSON(t *testing.T) {
 	testCases := []struct {
 		Name  string
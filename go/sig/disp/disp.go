@@ -39,12 +39,15 @@ type RegType int
 
 const (
 	RegPollRep RegType = iota
+	RegKeyExchangeRep
 )
 
 func (rt RegType) String() string {
 	switch rt {
 	case RegPollRep:
 		return "RegPollRep"
+	case RegKeyExchangeRep:
+		return "RegKeyExchangeRep"
 	}
 	return fmt.Sprintf("UNKNOWN (%d)", rt)
 }
@@ -53,6 +56,9 @@ type RegPld struct {
 	Id   mgmt.MsgIdType
 	P    interface{}
 	Addr *snet.Addr
+	// Signed is the signed ctrl payload that contained P. It allows handlers
+	// to verify the signature of the payload.
+	Signed *ctrl.SignedPld
 }
 
 type RegPldChan chan *RegPld
@@ -63,14 +69,20 @@ var (
 
 type dispRegistry struct {
 	sync.RWMutex
-	PollReqC RegPldChan
-	pollRep  map[RegPollKey]RegPldChan
+	PollReqC        RegPldChan
+	PrefixAnnounceC RegPldChan
+	KeyExchangeReqC RegPldChan
+	pollRep         map[RegPollKey]RegPldChan
+	keyExchangeRep  map[RegPollKey]RegPldChan
 }
 
 func newDispReg() *dispRegistry {
 	return &dispRegistry{
-		PollReqC: make(RegPldChan, 16),
-		pollRep:  make(map[RegPollKey]RegPldChan),
+		PollReqC:        make(RegPldChan, 16),
+		PrefixAnnounceC: make(RegPldChan, 16),
+		KeyExchangeReqC: make(RegPldChan, 16),
+		pollRep:         make(map[RegPollKey]RegPldChan),
+		keyExchangeRep:  make(map[RegPollKey]RegPldChan),
 	}
 }
 
@@ -80,6 +92,8 @@ func (dm *dispRegistry) Register(regType RegType, key RegPollKey, c RegPldChan)
 	switch regType {
 	case RegPollRep:
 		dm.pollRep[key] = c
+	case RegKeyExchangeRep:
+		dm.keyExchangeRep[key] = c
 	default:
 		return common.NewBasicError("Register: Unsupported dispatcher RegType", nil, "v", regType)
 	}
@@ -92,13 +106,15 @@ func (dm *dispRegistry) Unregister(regType RegType, key RegPollKey) error {
 	switch regType {
 	case RegPollRep:
 		delete(dm.pollRep, key)
+	case RegKeyExchangeRep:
+		delete(dm.keyExchangeRep, key)
 	default:
 		return common.NewBasicError("Unregister: Unsupported dispatcher RegType", nil, "v", regType)
 	}
 	return nil
 }
 
-func (dm *dispRegistry) sigCtrl(pld *mgmt.Pld, addr *snet.Addr) {
+func (dm *dispRegistry) sigCtrl(pld *mgmt.Pld, addr *snet.Addr, scpld *ctrl.SignedPld) {
 	dm.Lock()
 	defer dm.Unlock()
 	u, err := pld.Union()
@@ -122,6 +138,29 @@ func (dm *dispRegistry) sigCtrl(pld *mgmt.Pld, addr *snet.Addr) {
 			return
 		}
 		entry <- regPld
+	case *mgmt.PrefixAnnounce:
+		select {
+		case dm.PrefixAnnounceC <- &RegPld{Id: msgId, P: pld, Addr: addr, Signed: scpld}:
+		default:
+			log.Warn("Dropping SIG PrefixAnnounce, handler is busy", "src", addr)
+		}
+	case *mgmt.KeyExchangeReq:
+		select {
+		case dm.KeyExchangeReqC <- &RegPld{Id: msgId, P: pld, Addr: addr, Signed: scpld}:
+		default:
+			log.Warn("Dropping SIG KeyExchangeReq, handler is busy", "src", addr)
+		}
+	case *mgmt.KeyExchangeRep:
+		entry, ok := dm.keyExchangeRep[MkRegPollKey(addr.IA, pld.Session)]
+		if !ok {
+			log.Warn("Unexpected SIG KeyExchangeRep received", "src", addr, "pld", pld)
+			return
+		}
+		select {
+		case entry <- &RegPld{Id: msgId, P: pld, Addr: addr, Signed: scpld}:
+		default:
+			log.Warn("Dropping SIG KeyExchangeRep, session is busy", "src", addr)
+		}
 	default:
 		log.Error("Unsupported ctrl payload type", common.TypeOf(pld), "src", addr)
 	}
@@ -146,7 +185,7 @@ func dispFunc(dp *pktdisp.DispPkt) {
 	}
 	switch pld := u.(type) {
 	case *mgmt.Pld:
-		Dispatcher.sigCtrl(pld, src)
+		Dispatcher.sigCtrl(pld, src, scpld)
 	default:
 		log.Error("Unsupported ctrl payload type", "type", common.TypeOf(pld))
 	}
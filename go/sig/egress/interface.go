@@ -17,6 +17,7 @@ package egress
 import (
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,6 +30,7 @@ import (
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/spath/spathmeta"
 	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/seal"
 	"github.com/scionproto/scion/go/sig/siginfo"
 )
 
@@ -84,17 +86,27 @@ type WorkerFactory func(Session, log.Logger) Runner
 type RemoteInfo struct {
 	Sig      *siginfo.Sig
 	SessPath *SessPath
+	// SessPaths contains the healthy paths to Sig that the traffic is spread
+	// over, starting with SessPath. If it contains less than two paths, all
+	// traffic is sent on SessPath.
+	SessPaths []*SessPath
+	// Key seals the frames sent to Sig. It is nil if frame encryption is
+	// disabled, or if no key has been established with Sig yet.
+	Key *seal.Key
 }
 
 func (r *RemoteInfo) String() string {
+	if len(r.SessPaths) > 1 {
+		return fmt.Sprintf("Sig: %s Path: %s Paths: %d", r.Sig, r.SessPath, len(r.SessPaths))
+	}
 	return fmt.Sprintf("Sig: %s Path: %s", r.Sig, r.SessPath)
 }
 
 // PathPool is implemented by objects that maintain sets of paths. PathPools
 // must be safe for concurrent use by multiple goroutines.
 type PathPool interface {
-	// Paths returns the paths contained in the pool.
-	Paths() spathmeta.AppPathSet
+	// Paths returns the paths contained in the pool, in order of preference.
+	Paths() []*spathmeta.AppPath
 	// Destroy cleans up any resources associated with the PathPool.
 	Destroy() error
 }
@@ -105,23 +117,21 @@ const pathFailExpiration = 5 * time.Minute
 
 type SessPathPool map[spathmeta.PathKey]*SessPath
 
-// Return the most suitable path. Exclude a specific path, if possible.
+// Return the most suitable path. Exclude a specific path, if possible. Among
+// paths with the same number of failures, the one that comes first in the
+// preference order of the pool is returned.
 func (spp SessPathPool) Get(exclude spathmeta.PathKey) *SessPath {
 	var bestSessPath *SessPath
-	var minFail uint16 = math.MaxUint16
 	var bestNonExpiringSessPath *SessPath
-	var minNonExpiringFail uint16 = math.MaxUint16
 	for k, v := range spp {
 		if k == exclude {
 			continue
 		}
-		if v.failCount < minFail {
+		if v.betterThan(bestSessPath) {
 			bestSessPath = v
-			minFail = v.failCount
 		}
-		if v.failCount < minNonExpiringFail && !v.IsCloseToExpiry() {
+		if !v.IsCloseToExpiry() && v.betterThan(bestNonExpiringSessPath) {
 			bestNonExpiringSessPath = v
-			minNonExpiringFail = v.failCount
 		}
 	}
 	// Return a non-expiring path with least failures.
@@ -137,22 +147,49 @@ func (spp SessPathPool) Get(exclude spathmeta.PathKey) *SessPath {
 	return spp[exclude]
 }
 
-func (spp SessPathPool) Update(aps spathmeta.AppPathSet) {
+// Ordered returns the paths in the pool from the most to the least suitable
+// one, excluding a specific path. Paths that are close to expiry come last.
+func (spp SessPathPool) Ordered(exclude spathmeta.PathKey) []*SessPath {
+	paths := make([]*SessPath, 0, len(spp))
+	for k, v := range spp {
+		if k != exclude {
+			paths = append(paths, v)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if iExp, jExp := paths[i].IsCloseToExpiry(), paths[j].IsCloseToExpiry(); iExp != jExp {
+			return jExp
+		}
+		return paths[i].betterThan(paths[j])
+	})
+	return paths
+}
+
+// Update replaces the paths in the pool with the given paths. The paths must be
+// in order of preference.
+func (spp SessPathPool) Update(paths []*spathmeta.AppPath) {
+	ranks := make(map[spathmeta.PathKey]int, len(paths))
+	for i, ap := range paths {
+		ranks[ap.Key()] = i
+	}
 	// Remove any old entries that aren't present in the update.
 	for key := range spp {
-		if _, ok := aps[key]; !ok {
+		if _, ok := ranks[key]; !ok {
 			delete(spp, key)
 		}
 	}
-	for key, ap := range aps {
+	for _, ap := range paths {
+		key := ap.Key()
 		e, ok := spp[key]
 		if !ok {
 			// This is a new path, add an entry.
-			spp[key] = NewSessPath(key, ap.Entry)
+			e = NewSessPath(key, ap.Entry)
+			spp[key] = e
 		} else {
 			// This path already exists, update it.
 			e.pathEntry = ap.Entry
 		}
+		e.rank = ranks[key]
 	}
 }
 
@@ -162,6 +199,8 @@ type SessPath struct {
 	pathEntry *sciond.PathReplyEntry
 	lastFail  time.Time
 	failCount uint16
+	// rank is the position of the path in the preference order of the pool.
+	rank int
 }
 
 func NewSessPath(key spathmeta.PathKey, pathEntry *sciond.PathReplyEntry) *SessPath {
@@ -187,6 +226,18 @@ func (sp *SessPath) Fail() {
 	}
 }
 
+// betterThan returns whether sp should be preferred over other. Paths with
+// fewer failures are preferred, ties are broken by the preference order.
+func (sp *SessPath) betterThan(other *SessPath) bool {
+	if other == nil {
+		return true
+	}
+	if sp.failCount != other.failCount {
+		return sp.failCount < other.failCount
+	}
+	return sp.rank < other.rank
+}
+
 func (sp *SessPath) ExpireFails() {
 	if time.Since(sp.lastFail) > pathFailExpiration {
 		sp.failCount /= 2
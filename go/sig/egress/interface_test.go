@@ -0,0 +1,105 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestSessPathPoolOrdering(t *testing.T) {
+	exp := uint32(time.Now().Add(time.Hour).Unix())
+	short := newTestPath(t, 1400, exp, "1-ff00:0:110#1", "1-ff00:0:111#2")
+	long := newTestPath(t, 1500, exp+100, "1-ff00:0:110#2", "2-ff00:0:210#3",
+		"2-ff00:0:210#4", "1-ff00:0:111#5")
+	expiring := newTestPath(t, 1472, uint32(time.Now().Add(SafetyInterval/2).Unix()),
+		"1-ff00:0:110#3", "1-ff00:0:111#6")
+	aps := spathmeta.AppPathSet{}
+	for _, p := range []*spathmeta.AppPath{short, long, expiring} {
+		aps[p.Key()] = p
+	}
+	Convey("SessPathPool selects paths in the order of the path policy", t, func() {
+		spp := make(SessPathPool)
+		Convey("Default ordering prefers the paths with fewer hops", func() {
+			var policy *pathpol.Policy
+			spp.Update(policy.Order(aps))
+			SoMsg("ordered", sessPathKeys(spp.Ordered("")), ShouldResemble,
+				[]spathmeta.PathKey{short.Key(), long.Key(), expiring.Key()})
+			SoMsg("get", spp.Get("").Key(), ShouldEqual, short.Key())
+			SoMsg("get excluded", spp.Get(short.Key()).Key(), ShouldEqual, long.Key())
+		})
+		Convey("Descending MTU prefers the paths with the largest MTU", func() {
+			policy := &pathpol.Policy{
+				Ordering: pathpol.Ordering{{Attribute: pathpol.OrderMTU, Descending: true}},
+			}
+			spp.Update(policy.Order(aps))
+			SoMsg("ordered", sessPathKeys(spp.Ordered("")), ShouldResemble,
+				[]spathmeta.PathKey{long.Key(), short.Key(), expiring.Key()})
+			SoMsg("get", spp.Get("").Key(), ShouldEqual, long.Key())
+			Convey("Failures take precedence over the ordering", func() {
+				spp[long.Key()].Fail()
+				SoMsg("ordered", sessPathKeys(spp.Ordered("")), ShouldResemble,
+					[]spathmeta.PathKey{short.Key(), long.Key(), expiring.Key()})
+				SoMsg("get", spp.Get("").Key(), ShouldEqual, short.Key())
+			})
+			Convey("Paths close to expiry are only selected as last resort", func() {
+				SoMsg("get", spp.Get(long.Key()).Key(), ShouldEqual, short.Key())
+				delete(spp, short.Key())
+				SoMsg("get expiring", spp.Get(long.Key()).Key(), ShouldEqual,
+					expiring.Key())
+			})
+		})
+		Convey("Updating the policy reorders the existing paths", func() {
+			var policy *pathpol.Policy
+			spp.Update(policy.Order(aps))
+			policy = &pathpol.Policy{Ordering: pathpol.Ordering{{Attribute: pathpol.OrderHops,
+				Descending: true}}}
+			spp.Update(policy.Order(aps))
+			SoMsg("get", spp.Get("").Key(), ShouldEqual, long.Key())
+		})
+	})
+}
+
+func newTestPath(t *testing.T, mtu uint16, expTime uint32,
+	ifaces ...string) *spathmeta.AppPath {
+
+	entry := &sciond.PathReplyEntry{
+		Path: &sciond.FwdPathMeta{
+			Mtu:     mtu,
+			ExpTime: expTime,
+		},
+	}
+	for _, str := range ifaces {
+		iface, err := sciond.NewPathInterface(str)
+		xtest.FailOnErr(t, err)
+		entry.Path.Interfaces = append(entry.Path.Interfaces, iface)
+	}
+	return &spathmeta.AppPath{Entry: entry}
+}
+
+func sessPathKeys(paths []*SessPath) []spathmeta.PathKey {
+	keys := make([]spathmeta.PathKey, 0, len(paths))
+	for _, p := range paths {
+		keys = append(keys, p.Key())
+	}
+	return keys
+}
@@ -0,0 +1,152 @@
+// Copyright 2017 ETH Zurich
+// Copyright 2018 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/sig/disp"
+	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/seal"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+	"github.com/scionproto/scion/go/sig/siginfo"
+)
+
+const keyExchangeVerifyTimeout = 2 * time.Second
+
+// keyExchanger holds the state of the key exchanges a session monitor runs
+// with the remote SIGs of its session. A key is bound to the remote SIG it has
+// been established with; when the remote SIG changes, a new key is exchanged.
+type keyExchanger struct {
+	// key is the key established with sig at established.
+	key         *seal.Key
+	sig         *siginfo.Sig
+	established time.Time
+	// pending is the outstanding exchange, if any.
+	pending      *seal.Exchange
+	pendingId    mgmt.MsgIdType
+	pendingKeyId uint16
+	pendingSig   *siginfo.Sig
+	sentAt       time.Time
+	// nextKeyId is the key id of the next exchange. It starts at a random
+	// value, such that the ids are not reused after a restart.
+	nextKeyId uint16
+}
+
+func newKeyExchanger() *keyExchanger {
+	return &keyExchanger{nextKeyId: uint16(scrypto.RandUint64())}
+}
+
+// keyFor returns the key to seal the frames sent to sig, or nil if there is
+// no valid key for sig.
+func (kx *keyExchanger) keyFor(sig *siginfo.Sig) *seal.Key {
+	if kx.key == nil || sig == nil || !kx.sig.Equal(sig) || kx.key.Expired() {
+		return nil
+	}
+	return kx.key
+}
+
+// exchangeKey starts a key exchange with the current remote SIG, if there is
+// no key for it yet or the key is due for rekeying. Unanswered exchanges are
+// restarted after tout.
+func (sm *sessMonitor) exchangeKey() {
+	kx := sm.kx
+	remote := sm.sess.Remote()
+	if remote == nil || remote.Sig == nil || remote.SessPath == nil {
+		return
+	}
+	if kx.keyFor(remote.Sig) != nil && time.Since(kx.established) < sigcmn.RekeyInterval {
+		return
+	}
+	if kx.pending != nil && kx.pendingSig.Equal(remote.Sig) && time.Since(kx.sentAt) < tout {
+		return
+	}
+	e, err := seal.NewExchange()
+	if err != nil {
+		sm.Error("sessMonitor: Unable to create key exchange", "err", err)
+		return
+	}
+	id := mgmt.MsgIdType(time.Now().UnixNano())
+	req := mgmt.NewKeyExchangeReq(sm.sess.SessId, kx.nextKeyId, e.PubKey, time.Now())
+	raw, err := packCtrl(id, req, sigcmn.Signer)
+	if err != nil {
+		sm.Error("sessMonitor: Unable to create KeyExchangeReq", "err", err)
+		return
+	}
+	// The request is sent from the ctrl socket, such that the reply reaches
+	// the ctrl dispatcher.
+	if err := sm.writeCtrl(sigcmn.CtrlConn, raw, remote.Sig, remote.SessPath); err != nil {
+		sm.Error("sessMonitor: Error sending KeyExchangeReq", "err", err)
+		return
+	}
+	kx.pending = e
+	kx.pendingId = id
+	kx.pendingKeyId = kx.nextKeyId
+	kx.pendingSig = remote.Sig
+	kx.sentAt = time.Now()
+	kx.nextKeyId++
+}
+
+func (sm *sessMonitor) handleKeyExchangeRep(rpld *disp.RegPld) {
+	rep, ok := rpld.P.(*mgmt.KeyExchangeRep)
+	if !ok {
+		sm.Error("sessMonitor: non-SIGKeyExchangeRep payload received",
+			"src", rpld.Addr, "type", common.TypeOf(rpld.P), "pld", rpld.P)
+		return
+	}
+	kx := sm.kx
+	if kx.pending == nil || rpld.Id != kx.pendingId || rep.KeyId != kx.pendingKeyId {
+		sm.Debug("sessMonitor: Ignoring unexpected KeyExchangeRep",
+			"src", rpld.Addr, "id", rpld.Id, "keyId", rep.KeyId)
+		return
+	}
+	if !sm.sess.IA().Equal(rpld.Addr.IA) || rep.Session != sm.sess.SessId {
+		sm.Error("sessMonitor: KeyExchangeRep for wrong session",
+			"expected", sm.sess.IA(), "src", rpld.Addr, "session", rep.Session)
+		return
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), keyExchangeVerifyTimeout)
+	defer cancelF()
+	if _, err := sigcmn.Verifier.WithIA(sm.sess.IA()).VerifyPld(ctx, rpld.Signed); err != nil {
+		sm.Error("sessMonitor: Unable to verify KeyExchangeRep", "src", rpld.Addr, "err", err)
+		return
+	}
+	info := seal.KeyInfo{
+		Src:     sigcmn.IA,
+		Dst:     sm.sess.IA(),
+		Session: sm.sess.SessId,
+		KeyId:   rep.KeyId,
+	}
+	key, err := kx.pending.Key(rep.PubKey, true, info)
+	if err != nil {
+		sm.Error("sessMonitor: Unable to derive key", "src", rpld.Addr, "err", err)
+		return
+	}
+	kx.key = key
+	kx.sig = kx.pendingSig
+	kx.established = time.Now()
+	kx.pending = nil
+	sm.Debug("sessMonitor: Established key", "sig", kx.sig, "keyId", key.Id)
+	// Hand the key to the egress worker with a new snapshot.
+	if remote := sm.sess.Remote(); remote != nil {
+		updated := *remote
+		updated.Key = kx.keyFor(updated.Sig)
+		sm.sess.currRemote.Store(&updated)
+	}
+}
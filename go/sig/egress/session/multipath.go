@@ -0,0 +1,159 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"time"
+
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/sig/egress"
+	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+	"github.com/scionproto/scion/go/sig/siginfo"
+)
+
+// pathProber checks the health of the paths that are used in addition to the
+// primary path of a session in multipath mode. It sends PollReqs to the
+// remote SIG of the session over each candidate path. A path is healthy if a
+// PollRep was received over it within the timeout.
+type pathProber struct {
+	// sig is the remote SIG the paths are probed to.
+	sig *siginfo.Sig
+	// pending maps the IDs of the outstanding PollReqs to the probed paths.
+	pending map[mgmt.MsgIdType]spathmeta.PathKey
+	// lastReply contains the time the last PollRep was received per path.
+	lastReply map[spathmeta.PathKey]time.Time
+}
+
+func newPathProber() *pathProber {
+	return &pathProber{
+		pending:   make(map[mgmt.MsgIdType]spathmeta.PathKey),
+		lastReply: make(map[spathmeta.PathKey]time.Time),
+	}
+}
+
+// reset discards the probing state if the remote SIG changed.
+func (p *pathProber) reset(sig *siginfo.Sig) {
+	if p.sig != nil && p.sig.Equal(sig) {
+		return
+	}
+	p.sig = sig
+	p.pending = make(map[mgmt.MsgIdType]spathmeta.PathKey)
+	p.lastReply = make(map[spathmeta.PathKey]time.Time)
+}
+
+// expire removes the outstanding PollReqs that timed out and the state of the
+// paths that are no longer in the pool. Message IDs are creation timestamps.
+func (p *pathProber) expire(pool egress.SessPathPool) {
+	for id := range p.pending {
+		if time.Since(time.Unix(0, int64(id))) > tout {
+			delete(p.pending, id)
+		}
+	}
+	for key := range p.lastReply {
+		if _, ok := pool[key]; !ok {
+			delete(p.lastReply, key)
+		}
+	}
+}
+
+// handleRep records the PollRep with the given ID. It returns false if the ID
+// does not belong to a probe.
+func (p *pathProber) handleRep(id mgmt.MsgIdType) bool {
+	key, ok := p.pending[id]
+	if !ok {
+		return false
+	}
+	delete(p.pending, id)
+	p.lastReply[key] = time.Now()
+	return true
+}
+
+func (p *pathProber) healthy(key spathmeta.PathKey) bool {
+	last, ok := p.lastReply[key]
+	return ok && time.Since(last) <= tout
+}
+
+// probePaths probes the candidate paths to the remote SIG of the session and
+// updates the set of paths the session spreads its traffic over. Twice as many
+// paths as needed are probed, such that replacements are at hand when a path
+// fails.
+func (sm *sessMonitor) probePaths() {
+	remote := sm.sess.Remote()
+	if remote == nil || remote.Sig == nil || remote.SessPath == nil {
+		return
+	}
+	sm.prober.reset(remote.Sig)
+	sm.prober.expire(sm.sessPathPool)
+	maxPaths := sigcmn.EgressPaths
+	candidates := sm.sessPathPool.Ordered(remote.SessPath.Key())
+	if len(candidates) > 2*(maxPaths-1) {
+		candidates = candidates[:2*(maxPaths-1)]
+	}
+	id := mgmt.MsgIdType(time.Now().UnixNano())
+	for _, sp := range candidates {
+		id++
+		if err := sm.sendPollReq(id, remote.Sig, sp); err != nil {
+			sm.Error("sessMonitor: Error probing path", "path", sp, "err", err)
+			continue
+		}
+		sm.prober.pending[id] = sp.Key()
+	}
+	paths := []*egress.SessPath{remote.SessPath}
+	if sm.sess.Healthy() {
+		for _, sp := range candidates {
+			if len(paths) < maxPaths && sm.prober.healthy(sp.Key()) {
+				paths = append(paths, sp)
+			}
+		}
+	}
+	if len(paths) == 1 {
+		paths = nil
+	}
+	if sameSessPaths(paths, remote.SessPaths) {
+		return
+	}
+	for _, sp := range remote.SessPaths {
+		if sp.Key() == remote.SessPath.Key() || sm.prober.healthy(sp.Key()) {
+			continue
+		}
+		// Like for the primary path, a missing reply is attributed to the path.
+		sm.Info("sessMonitor: Path unhealthy", "path", sp)
+		sp.Fail()
+	}
+	// Copy the remote to avoid modifying the snapshot used by the worker.
+	updated := *remote
+	updated.SessPaths = paths
+	sm.sess.currRemote.Store(&updated)
+	sm.Info("sessMonitor: Updated egress paths", "remote", &updated)
+}
+
+// sameSessPaths returns whether a and b contain the same paths, regardless of
+// their order.
+func sameSessPaths(a, b []*egress.SessPath) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keys := make(map[spathmeta.PathKey]struct{}, len(a))
+	for _, sp := range a {
+		keys[sp.Key()] = struct{}{}
+	}
+	for _, sp := range b {
+		if _, ok := keys[sp.Key()]; !ok {
+			return false
+		}
+	}
+	return true
+}
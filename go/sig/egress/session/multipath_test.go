@@ -0,0 +1,140 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/hostinfo"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/egress"
+	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+	"github.com/scionproto/scion/go/sig/siginfo"
+)
+
+func TestProbePaths(t *testing.T) {
+	Convey("probePaths spreads the traffic over the healthy paths", t, func() {
+		oldPaths, oldAddr := sigcmn.EgressPaths, sigcmn.MgmtAddr
+		defer func() { sigcmn.EgressPaths, sigcmn.MgmtAddr = oldPaths, oldAddr }()
+		sigcmn.EgressPaths = 3
+		sigcmn.MgmtAddr = mgmt.NewAddr(addr.HostFromIP(net.IP{192, 0, 2, 1}), 30256, 30056)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		conn := mock_snet.NewMockConn(ctrl)
+		conn.EXPECT().WriteToSCION(gomock.Any(), gomock.Any()).AnyTimes().Return(0, nil)
+
+		p0, p1, p2, p3 := newTestSessPath("p0"), newTestSessPath("p1"),
+			newTestSessPath("p2"), newTestSessPath("p3")
+		sess := &Session{Logger: log.Root(), conn: conn}
+		sess.currRemote.Store(&egress.RemoteInfo{
+			Sig: &siginfo.Sig{
+				IA:          xtest.MustParseIA("1-ff00:0:111"),
+				Host:        addr.HostFromIP(net.IP{192, 0, 2, 2}),
+				CtrlL4Port:  30256,
+				EncapL4Port: 30056,
+			},
+			SessPath: p0,
+		})
+		sess.healthy.Store(true)
+		sm := &sessMonitor{
+			Logger:       sess.Logger,
+			sess:         sess,
+			sessPathPool: egress.SessPathPool{},
+			prober:       newPathProber(),
+		}
+		for _, sp := range []*egress.SessPath{p0, p1, p2, p3} {
+			sm.sessPathPool[sp.Key()] = sp
+		}
+
+		sm.probePaths()
+		SoMsg("probes", len(sm.prober.pending), ShouldEqual, 3)
+		SoMsg("no replies", sess.Remote().SessPaths, ShouldBeNil)
+
+		replyProbes(sm, p1, p2)
+		sm.probePaths()
+		SoMsg("healthy paths", sessPathKeys(sess.Remote().SessPaths), ShouldResemble,
+			[]spathmeta.PathKey{"p0", "p1", "p2"})
+		SoMsg("primary first", sess.Remote().SessPaths[0], ShouldEqual, p0)
+
+		Convey("A path that disappears from the pool is removed", func() {
+			delete(sm.sessPathPool, p2.Key())
+			sm.probePaths()
+			SoMsg("paths", sessPathKeys(sess.Remote().SessPaths), ShouldResemble,
+				[]spathmeta.PathKey{"p0", "p1"})
+			Convey("A new healthy path is added", func() {
+				replyProbes(sm, p3)
+				sm.probePaths()
+				SoMsg("paths", sessPathKeys(sess.Remote().SessPaths), ShouldResemble,
+					[]spathmeta.PathKey{"p0", "p1", "p3"})
+			})
+		})
+		Convey("Unchanged paths keep the remote", func() {
+			remote := sess.Remote()
+			replyProbes(sm, p1, p2)
+			sm.probePaths()
+			SoMsg("remote", sess.Remote(), ShouldEqual, remote)
+		})
+		Convey("An unhealthy session only uses the primary path", func() {
+			sess.healthy.Store(false)
+			sm.probePaths()
+			SoMsg("paths", sess.Remote().SessPaths, ShouldBeNil)
+		})
+	})
+}
+
+func newTestSessPath(key spathmeta.PathKey) *egress.SessPath {
+	entry := &sciond.PathReplyEntry{
+		Path: &sciond.FwdPathMeta{
+			Mtu:     1400,
+			ExpTime: uint32(time.Now().Add(time.Hour).Unix()),
+		},
+		HostInfo: *hostinfo.FromHostAddr(addr.HostFromIP(net.IP{192, 0, 2, 3}), 30041),
+	}
+	return egress.NewSessPath(key, entry)
+}
+
+// replyProbes handles a PollRep for the outstanding probes of the paths.
+func replyProbes(sm *sessMonitor, paths ...*egress.SessPath) {
+	for _, sp := range paths {
+		for id, key := range sm.prober.pending {
+			if key == sp.Key() {
+				sm.prober.handleRep(id)
+			}
+		}
+	}
+}
+
+// sessPathKeys returns the sorted keys of the paths.
+func sessPathKeys(paths []*egress.SessPath) []spathmeta.PathKey {
+	keys := make([]spathmeta.PathKey, 0, len(paths))
+	for _, sp := range paths {
+		keys = append(keys, sp.Key())
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
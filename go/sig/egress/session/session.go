@@ -28,6 +28,7 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/pktdisp"
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/lib/snet"
@@ -59,7 +60,9 @@ type Session struct {
 	pktDispStop    chan struct{}
 	pktDispStopped chan struct{}
 	workerStopped  chan struct{}
-	factory        egress.WorkerFactory
+	// started is set once the session monitor and the worker are running.
+	started bool
+	factory egress.WorkerFactory
 }
 
 func NewSession(dstIA addr.IA, sessId mgmt.SessionType, logger log.Logger,
@@ -95,6 +98,7 @@ func NewSession(dstIA addr.IA, sessId mgmt.SessionType, logger log.Logger,
 }
 
 func (s *Session) Start() {
+	s.started = true
 	go func() {
 		defer log.LogPanicAndExit()
 		newSessMonitor(s).run()
@@ -108,10 +112,12 @@ func (s *Session) Start() {
 func (s *Session) Cleanup() error {
 	s.ring.Close()
 	close(s.sessMonStop)
-	s.Debug("egress.Session Cleanup: wait for worker")
-	<-s.workerStopped
-	s.Debug("egress.Session Cleanup: wait for session monitor")
-	<-s.sessMonStopped
+	if s.started {
+		s.Debug("egress.Session Cleanup: wait for worker")
+		<-s.workerStopped
+		s.Debug("egress.Session Cleanup: wait for session monitor")
+		<-s.sessMonStopped
+	}
 	close(s.pktDispStop)
 	s.Debug("egress.Session Cleanup: wait for pktDisp")
 	s.conn.SetReadDeadline(time.Now())
@@ -166,8 +172,10 @@ type PathPool struct {
 
 var _ egress.PathPool = (*PathPool)(nil)
 
-func NewPathPool(dst addr.IA) (*PathPool, error) {
-	pool, err := sigcmn.PathMgr.Watch(context.TODO(), sigcmn.IA, dst)
+// NewPathPool returns a pool of the paths to dst that match the policy. If the
+// policy is nil, the paths are not filtered.
+func NewPathPool(dst addr.IA, policy *pathpol.Policy) (*PathPool, error) {
+	pool, err := sigcmn.PathMgr.WatchFilter(context.TODO(), sigcmn.IA, dst, policy)
 	if err != nil {
 		return nil, common.NewBasicError("Unable to register watch", err)
 	}
@@ -182,6 +190,6 @@ func (pp *PathPool) Destroy() error {
 	return nil
 }
 
-func (pp *PathPool) Paths() spathmeta.AppPathSet {
-	return pp.pool.Load().APS
+func (pp *PathPool) Paths() []*spathmeta.AppPath {
+	return pp.pool.Load().Ordered
 }
@@ -23,7 +23,9 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/proto"
 	"github.com/scionproto/scion/go/sig/disp"
 	"github.com/scionproto/scion/go/sig/egress"
 	"github.com/scionproto/scion/go/sig/mgmt"
@@ -59,12 +61,25 @@ type sessMonitor struct {
 	updateMsgId mgmt.MsgIdType
 	// the last time a PollRep was received.
 	lastReply time.Time
+	// prober checks the health of the additional paths in multipath mode. It
+	// is nil if the session uses a single path.
+	prober *pathProber
+	// kx establishes the keys that seal the frames of the session. It is nil
+	// if frame encryption is disabled.
+	kx *keyExchanger
 }
 
 func newSessMonitor(sess *Session) *sessMonitor {
-	return &sessMonitor{
+	sm := &sessMonitor{
 		Logger: sess.Logger, sess: sess, pool: sess.pool, sessPathPool: make(egress.SessPathPool),
 	}
+	if sigcmn.EgressPaths > 1 {
+		sm.prober = newPathProber()
+	}
+	if sigcmn.Encryption {
+		sm.kx = newKeyExchanger()
+	}
+	return sm
 }
 
 func (sm *sessMonitor) run() {
@@ -77,6 +92,14 @@ func (sm *sessMonitor) run() {
 	// Register with SIG ctrl dispatcher
 	regc := make(disp.RegPldChan, 1)
 	disp.Dispatcher.Register(disp.RegPollRep, disp.MkRegPollKey(sm.sess.IA(), sm.sess.SessId), regc)
+	// The channel stays nil if frame encryption is disabled, such that it is
+	// never selected.
+	var kxc disp.RegPldChan
+	if sm.kx != nil {
+		kxc = make(disp.RegPldChan, 1)
+		disp.Dispatcher.Register(disp.RegKeyExchangeRep,
+			disp.MkRegPollKey(sm.sess.IA(), sm.sess.SessId), kxc)
+	}
 	sm.lastReply = time.Now()
 	// Start by querying for the remote SIG instance.
 	sm.smRemote = &egress.RemoteInfo{
@@ -96,8 +119,16 @@ Top:
 			sm.sessPathPool.Update(sm.pool.Paths())
 			sm.updateRemote()
 			sm.sendReq()
+			if sm.prober != nil {
+				sm.probePaths()
+			}
+			if sm.kx != nil {
+				sm.exchangeKey()
+			}
 		case rpld := <-regc:
 			sm.handleRep(rpld)
+		case rpld := <-kxc:
+			sm.handleKeyExchangeRep(rpld)
 		case <-pathExpiryTick.C:
 			for _, path := range sm.sessPathPool {
 				path.ExpireFails()
@@ -109,6 +140,13 @@ Top:
 	if err != nil {
 		log.Error("sessMonitor: unable to unregister from ctrl dispatcher", "err", err)
 	}
+	if sm.kx != nil {
+		err := disp.Dispatcher.Unregister(disp.RegKeyExchangeRep,
+			disp.MkRegPollKey(sm.sess.IA(), sm.sess.SessId))
+		if err != nil {
+			log.Error("sessMonitor: unable to unregister from ctrl dispatcher", "err", err)
+		}
+	}
 	sm.Info("sessMonitor: stopped")
 }
 
@@ -194,6 +232,9 @@ func (sm *sessMonitor) updateSessSnap() {
 		}
 		remote.Sig = old.Sig
 	}
+	if sm.kx != nil {
+		remote.Key = sm.kx.keyFor(remote.Sig)
+	}
 	sm.sess.currRemote.Store(&remote)
 }
 
@@ -209,32 +250,57 @@ func (sm *sessMonitor) sendReq() {
 		return
 	}
 	sm.updateMsgId = mgmt.MsgIdType(time.Now().UnixNano())
-	spld, err := mgmt.NewPld(sm.updateMsgId, mgmt.NewPollReq(sigcmn.MgmtAddr, sm.sess.SessId))
+	if err := sm.sendPollReq(sm.updateMsgId, sm.smRemote.Sig, sm.smRemote.SessPath); err != nil {
+		sm.Error("sessMonitor: Error sending PollReq", "err", err)
+	}
+}
+
+// sendPollReq sends a PollReq with the given ID to the remote SIG over the path.
+func (sm *sessMonitor) sendPollReq(id mgmt.MsgIdType, sig *siginfo.Sig,
+	sessPath *egress.SessPath) error {
+
+	raw, err := packCtrl(id, mgmt.NewPollReq(sigcmn.MgmtAddr, sm.sess.SessId), infra.NullSigner)
 	if err != nil {
-		sm.Error("sessMonitor: Error creating SIGCtrl payload", "err", err)
-		return
+		return err
+	}
+	return sm.writeCtrl(sm.sess.conn, raw, sig, sessPath)
+}
+
+// packCtrl returns the packed SIG ctrl payload with the given id and content,
+// signed with signer.
+func packCtrl(id mgmt.MsgIdType, u proto.Cerealizable,
+	signer infra.Signer) (common.RawBytes, error) {
+
+	spld, err := mgmt.NewPld(id, u)
+	if err != nil {
+		return nil, common.NewBasicError("Error creating SIGCtrl payload", err)
 	}
 	cpld, err := ctrl.NewPld(spld, nil)
 	if err != nil {
-		sm.Error("sessMonitor: Error creating Ctrl payload", "err", err)
-		return
+		return nil, common.NewBasicError("Error creating Ctrl payload", err)
 	}
-	scpld, err := cpld.SignedPld(infra.NullSigner)
+	scpld, err := cpld.SignedPld(signer)
 	if err != nil {
-		sm.Error("sessMonitor: Error creating signed Ctrl payload", "err", err)
-		return
+		return nil, common.NewBasicError("Error creating signed Ctrl payload", err)
 	}
 	raw, err := scpld.PackPld()
 	if err != nil {
-		sm.Error("sessMonitor: Error packing signed Ctrl payload", "err", err)
-		return
+		return nil, common.NewBasicError("Error packing signed Ctrl payload", err)
 	}
-	raddr := sm.smRemote.Sig.CtrlSnetAddr()
-	raddr.Path = spath.New(sm.smRemote.SessPath.PathEntry().Path.FwdPath)
+	return raw, nil
+}
+
+// writeCtrl sends the packed ctrl payload on conn to the remote SIG over the
+// path.
+func (sm *sessMonitor) writeCtrl(conn snet.Conn, raw common.RawBytes, sig *siginfo.Sig,
+	sessPath *egress.SessPath) error {
+
+	raddr := sig.CtrlSnetAddr()
+	raddr.Path = spath.New(sessPath.PathEntry().Path.FwdPath)
 	if err := raddr.Path.InitOffsets(); err != nil {
 		sm.Error("sessMonitor: Error initializing path offsets", "err", err)
 	}
-	nh, err := sm.smRemote.SessPath.PathEntry().HostInfo.Overlay()
+	nh, err := sessPath.PathEntry().HostInfo.Overlay()
 	if err != nil {
 		sm.Error("sessMonitor: Unsupported NextHop", "err", err)
 	}
@@ -242,10 +308,10 @@ func (sm *sessMonitor) sendReq() {
 	// XXX(kormat): if this blocks, both the sessMon and egress worker
 	// goroutines will block. Can't just use SetWriteDeadline, as both
 	// goroutines write to it.
-	_, err = sm.sess.conn.WriteToSCION(raw, raddr)
-	if err != nil {
-		sm.Error("sessMonitor: Error sending signed Ctrl payload", "err", err)
+	if _, err := conn.WriteToSCION(raw, raddr); err != nil {
+		return common.NewBasicError("Error sending signed Ctrl payload", err)
 	}
+	return nil
 }
 
 func (sm *sessMonitor) handleRep(rpld *disp.RegPld) {
@@ -278,7 +344,7 @@ func (sm *sessMonitor) handleRep(rpld *disp.RegPld) {
 			sm.Info("sessMonitor: updating remote Info", "msgId", rpld.Id, "remote", sm.smRemote)
 		}
 		sm.sess.healthy.Store(true)
-	} else {
+	} else if sm.prober == nil || !sm.prober.handleRep(rpld.Id) {
 		// This is going to happen if latency of the path is greater than the poll ticker period.
 		// TODO(sustrik): We should monitor this to spot paths where the latency is high enough to
 		// to disrupt orderly SIG operation.
@@ -0,0 +1,105 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+
+	protoTCP = 6
+	protoUDP = 17
+)
+
+// flowHash returns a hash of the flow the IP packet belongs to. The flow is
+// identified by the addresses, the protocol, and the ports for TCP and UDP.
+// The ports are not used for IPv4 fragments, such that all fragments of a
+// packet belong to the same flow.
+func flowHash(pkt common.RawBytes) uint64 {
+	h := uint64(fnvOffset)
+	if len(pkt) == 0 {
+		return h
+	}
+	var proto byte
+	var l4 common.RawBytes
+	switch pkt[0] >> 4 {
+	case 4:
+		if len(pkt) < 20 {
+			return h
+		}
+		proto = pkt[9]
+		h = fnv1a(h, pkt[12:20])
+		hdrLen := int(pkt[0]&0xF) * 4
+		// Fragmented if the more fragments flag or the offset are set.
+		if common.Order.Uint16(pkt[6:8])&0x3FFF == 0 && hdrLen <= len(pkt) {
+			l4 = pkt[hdrLen:]
+		}
+	case 6:
+		if len(pkt) < 40 {
+			return h
+		}
+		proto = pkt[6]
+		h = fnv1a(h, pkt[8:40])
+		l4 = pkt[40:]
+	default:
+		return h
+	}
+	h = fnv1a(h, []byte{proto})
+	if (proto == protoTCP || proto == protoUDP) && len(l4) >= 4 {
+		h = fnv1a(h, l4[:4])
+	}
+	return h
+}
+
+// pathSeed returns the seed of the stream on the path.
+func pathSeed(key spathmeta.PathKey) uint64 {
+	return fnv1a(fnvOffset, []byte(key))
+}
+
+// selectStream selects the stream for the flow with rendezvous hashing, i.e.,
+// the stream with the highest score for the flow is chosen. When a stream is
+// added or removed, only the flows of that stream move to a different stream.
+func selectStream(flow uint64, streams []*stream) *stream {
+	var best *stream
+	var bestScore uint64
+	for _, s := range streams {
+		if score := mix(flow ^ s.seed); best == nil || score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	return best
+}
+
+func fnv1a(h uint64, b []byte) uint64 {
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= fnvPrime
+	}
+	return h
+}
+
+// mix is the finalizer of SplitMix64. It spreads the bits of x.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
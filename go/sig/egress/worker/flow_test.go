@@ -0,0 +1,123 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+)
+
+func ipv4Pkt(proto byte, src, dst byte, sport, dport uint16, fragOff uint16) common.RawBytes {
+	pkt := make(common.RawBytes, 28)
+	pkt[0] = 0x45
+	common.Order.PutUint16(pkt[6:8], fragOff)
+	pkt[9] = proto
+	copy(pkt[12:16], []byte{192, 0, 2, src})
+	copy(pkt[16:20], []byte{192, 0, 2, dst})
+	common.Order.PutUint16(pkt[20:22], sport)
+	common.Order.PutUint16(pkt[22:24], dport)
+	return pkt
+}
+
+func ipv6Pkt(proto byte, sport, dport uint16) common.RawBytes {
+	pkt := make(common.RawBytes, 48)
+	pkt[0] = 0x60
+	pkt[6] = proto
+	pkt[23] = 1
+	pkt[39] = 2
+	common.Order.PutUint16(pkt[40:42], sport)
+	common.Order.PutUint16(pkt[42:44], dport)
+	return pkt
+}
+
+func TestFlowHash(t *testing.T) {
+	Convey("flowHash", t, func() {
+		tcp := ipv4Pkt(protoTCP, 1, 2, 1000, 80, 0)
+		Convey("Payload and length are ignored", func() {
+			other := append(ipv4Pkt(protoTCP, 1, 2, 1000, 80, 0), 1, 2, 3, 4)
+			other[27] = 0xff
+			SoMsg("hash", flowHash(other), ShouldEqual, flowHash(tcp))
+		})
+		Convey("Addresses, protocol and ports are used", func() {
+			SoMsg("src", flowHash(ipv4Pkt(protoTCP, 3, 2, 1000, 80, 0)), ShouldNotEqual,
+				flowHash(tcp))
+			SoMsg("dst", flowHash(ipv4Pkt(protoTCP, 1, 3, 1000, 80, 0)), ShouldNotEqual,
+				flowHash(tcp))
+			SoMsg("proto", flowHash(ipv4Pkt(protoUDP, 1, 2, 1000, 80, 0)), ShouldNotEqual,
+				flowHash(tcp))
+			SoMsg("sport", flowHash(ipv4Pkt(protoTCP, 1, 2, 1001, 80, 0)), ShouldNotEqual,
+				flowHash(tcp))
+			SoMsg("dport", flowHash(ipv4Pkt(protoTCP, 1, 2, 1000, 81, 0)), ShouldNotEqual,
+				flowHash(tcp))
+		})
+		Convey("Ports are ignored for other protocols", func() {
+			SoMsg("hash", flowHash(ipv4Pkt(1, 1, 2, 1, 2, 0)), ShouldEqual,
+				flowHash(ipv4Pkt(1, 1, 2, 3, 4, 0)))
+		})
+		Convey("Fragments of a packet have the same hash", func() {
+			first := ipv4Pkt(protoUDP, 1, 2, 1000, 53, 0x2000)
+			last := ipv4Pkt(protoUDP, 1, 2, 0, 0, 0x0010)
+			SoMsg("hash", flowHash(first), ShouldEqual, flowHash(last))
+		})
+		Convey("IPv6 ports are used", func() {
+			SoMsg("same", flowHash(ipv6Pkt(protoTCP, 1000, 80)), ShouldEqual,
+				flowHash(ipv6Pkt(protoTCP, 1000, 80)))
+			SoMsg("sport", flowHash(ipv6Pkt(protoTCP, 1001, 80)), ShouldNotEqual,
+				flowHash(ipv6Pkt(protoTCP, 1000, 80)))
+		})
+		Convey("Truncated packets do not panic", func() {
+			SoMsg("empty", func() { flowHash(nil) }, ShouldNotPanic)
+			SoMsg("ipv4", func() { flowHash(tcp[:19]) }, ShouldNotPanic)
+			SoMsg("ipv4 options", func() { flowHash(tcp[:20]) }, ShouldNotPanic)
+			SoMsg("ipv6", func() { flowHash(ipv6Pkt(protoTCP, 1, 2)[:39]) }, ShouldNotPanic)
+		})
+	})
+}
+
+func TestSelectStream(t *testing.T) {
+	Convey("selectStream", t, func() {
+		var streams []*stream
+		for i := 0; i < 4; i++ {
+			streams = append(streams,
+				&stream{seed: pathSeed(spathmeta.PathKey(fmt.Sprintf("path %d", i)))})
+		}
+		counts := make(map[*stream]int)
+		selected := make(map[uint64]*stream)
+		for i := uint16(0); i < 1000; i++ {
+			flow := flowHash(ipv4Pkt(protoTCP, 1, 2, 1000+i, 80, 0))
+			selected[flow] = selectStream(flow, streams)
+			counts[selected[flow]]++
+		}
+		Convey("Flows are spread over all streams", func() {
+			for i, s := range streams {
+				SoMsg(fmt.Sprintf("stream %d", i), counts[s], ShouldBeGreaterThan, 150)
+			}
+		})
+		Convey("Removing a stream only moves its flows", func() {
+			removed := streams[1]
+			remaining := []*stream{streams[0], streams[2], streams[3]}
+			for flow, s := range selected {
+				if s != removed {
+					SoMsg("stream", selectStream(flow, remaining), ShouldEqual, s)
+				}
+			}
+		})
+	})
+}
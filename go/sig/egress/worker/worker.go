@@ -25,11 +25,13 @@ import (
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
 	"github.com/scionproto/scion/go/lib/spkt"
 	"github.com/scionproto/scion/go/lib/util"
 	"github.com/scionproto/scion/go/sig/egress"
 	"github.com/scionproto/scion/go/sig/metrics"
 	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/seal"
 	"github.com/scionproto/scion/go/sig/sigcmn"
 	"github.com/scionproto/scion/go/sig/siginfo"
 )
@@ -66,17 +68,43 @@ type worker struct {
 	log.Logger
 	iaString      string
 	sess          egress.Session
+	remote        *egress.RemoteInfo
 	currSig       *siginfo.Sig
-	currPathEntry *sciond.PathReplyEntry
 	frameSentCtrs metrics.CtrPair
 
+	// streams contains a stream per path the traffic is spread over. The
+	// first stream is sent on the primary path of the session.
+	streams   []*stream
+	lastEpoch uint16
+	pkts      ringbuf.EntryList
+
+	// key seals the frames if frame encryption is enabled. sealed is the
+	// buffer the sealed frames are written to.
+	key    *seal.Key
+	sealed common.RawBytes
+}
+
+// stream is a sequence of frames that is sent on a single path. Every stream
+// has its own epoch, such that the remote SIG reassembles the streams
+// independently.
+type stream struct {
+	f *frame
+	// sessPath is the path of an additional stream. It is nil for the first
+	// stream, which follows the primary path of the session.
+	sessPath  *egress.SessPath
+	pathEntry *sciond.PathReplyEntry
+	// seed is derived from the path key and used to map flows to streams.
+	seed  uint64
 	epoch uint16
 	seq   uint32
-	pkts  ringbuf.EntryList
+}
+
+func (s *stream) empty() bool {
+	return s.f.offset == sigcmn.SIGHdrSize
 }
 
 func NewWorker(sess egress.Session, logger log.Logger) *worker {
-	return &worker{
+	w := &worker{
 		Logger:   logger,
 		iaString: sess.IA().String(),
 		sess:     sess,
@@ -84,37 +112,49 @@ func NewWorker(sess egress.Session, logger log.Logger) *worker {
 			Pkts:  metrics.FramesSent.WithLabelValues(sess.IA().String(), sess.ID().String()),
 			Bytes: metrics.FrameBytesSent.WithLabelValues(sess.IA().String(), sess.ID().String()),
 		},
-		pkts: make(ringbuf.EntryList, 0, egress.EgressBufPkts),
+		streams: []*stream{{f: newFrame()}},
+		pkts:    make(ringbuf.EntryList, 0, egress.EgressBufPkts),
+	}
+	if sigcmn.Encryption {
+		w.sealed = make(common.RawBytes, 0, common.MaxMTU)
 	}
+	return w
 }
 
 func (w *worker) Run() {
 	defer log.LogPanicAndExit()
 	w.Info("EgressWorker: starting")
-	f := newFrame()
 
 TopLoop:
 	for {
-		// If the frame is empty, block indefinitely for more packets.
-		fEmpty := f.offset == sigcmn.SIGHdrSize
-		if !w.read(fEmpty) {
+		// If all frames are empty, block indefinitely for more packets.
+		empty := w.empty()
+		if !w.read(empty) {
 			break TopLoop
 		}
-		if fEmpty {
+		w.updateStreams()
+		if empty {
 			// Cover the case where no packets have arrived in a while, and the
-			// current path is stale.
-			w.resetFrame(f)
+			// current paths are stale.
+			for _, s := range w.streams {
+				w.resetFrame(s)
+			}
 		} else if len(w.pkts) == 0 {
-			// Didn't read any new packets, send partial frame.
-			if err := w.write(f); err != nil {
-				w.Error("Error sending frame", "err", err)
+			// Didn't read any new packets, send partial frames.
+			for _, s := range w.streams {
+				if s.empty() {
+					continue
+				}
+				if err := w.write(s); err != nil {
+					w.Error("Error sending frame", "err", err)
+				}
 			}
 			continue TopLoop
 		}
 		// Process buffered packets.
 		for i := range w.pkts {
 			pkt := w.pkts[i].(common.RawBytes)
-			if err := w.processPkt(f, pkt); err != nil {
+			if err := w.processPkt(w.chooseStream(pkt), pkt); err != nil {
 				w.Error("Error sending frame", "err", err)
 			}
 		}
@@ -128,7 +168,8 @@ TopLoop:
 	w.sess.AnnounceWorkerStopped()
 }
 
-func (w *worker) processPkt(f *frame, pkt common.RawBytes) error {
+func (w *worker) processPkt(s *stream, pkt common.RawBytes) error {
+	f := s.f
 	f.startPkt(uint16(len(pkt)))
 	pktOff := 0
 	// Write chunks of the packet to frames, sending off frames as they fill up.
@@ -136,7 +177,7 @@ func (w *worker) processPkt(f *frame, pkt common.RawBytes) error {
 		pktOff += f.readFrom(pkt[pktOff:])
 		if f.isFull() {
 			// There's no point in trying to fit another packet into this frame.
-			if err := w.write(f); err != nil {
+			if err := w.write(s); err != nil {
 				// Skip the rest of this packet.
 				return err
 			}
@@ -149,6 +190,73 @@ func (w *worker) processPkt(f *frame, pkt common.RawBytes) error {
 	}
 }
 
+func (w *worker) empty() bool {
+	for _, s := range w.streams {
+		if !s.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// updateStreams adapts the streams to the paths of the session's current
+// remote. Streams of paths that are no longer used are flushed and removed.
+// New streams are created for new paths.
+func (w *worker) updateStreams() {
+	remote := w.sess.Remote()
+	if remote == nil || remote == w.remote {
+		return
+	}
+	w.remote = remote
+	if remote.Key != w.key {
+		// Restart all streams, such that they continue with epochs claimed
+		// for the new key.
+		w.key = remote.Key
+		for _, s := range w.streams {
+			s.seq = 0
+		}
+	}
+	var sessPaths []*egress.SessPath
+	if len(remote.SessPaths) > 1 {
+		sessPaths = remote.SessPaths[1:]
+	}
+	streams := make([]*stream, 1, 1+len(sessPaths))
+	streams[0] = w.streams[0]
+	old := make(map[spathmeta.PathKey]*stream, len(w.streams)-1)
+	for _, s := range w.streams[1:] {
+		old[s.sessPath.Key()] = s
+	}
+	for _, sp := range sessPaths {
+		s, ok := old[sp.Key()]
+		if !ok {
+			s = &stream{f: newFrame(), sessPath: sp}
+			w.resetFrame(s)
+		}
+		delete(old, sp.Key())
+		s.sessPath = sp
+		streams = append(streams, s)
+	}
+	for _, s := range old {
+		if s.empty() {
+			continue
+		}
+		if err := w.send(s); err != nil {
+			w.Error("Error sending frame", "err", err)
+		}
+	}
+	w.streams = streams
+}
+
+// chooseStream returns the stream the packet is sent on. All packets of a
+// flow are sent on the same stream as long as the set of paths does not
+// change.
+func (w *worker) chooseStream(pkt common.RawBytes) *stream {
+	if len(w.streams) == 1 {
+		return w.streams[0]
+	}
+	return selectStream(flowHash(pkt), w.streams)
+}
+
 // Return false if the ringbuf is closed.
 func (w *worker) read(block bool) bool {
 	w.pkts = w.pkts[:cap(w.pkts)]
@@ -161,11 +269,16 @@ func (w *worker) read(block bool) bool {
 	return true
 }
 
-func (w *worker) write(f *frame) error {
+func (w *worker) write(s *stream) error {
 	// TODO(kormat): consider looking for an updated path here, and switching
 	// to it if the mtu isn't smaller than the current one.
-	defer w.resetFrame(f)
-	if w.currPathEntry == nil {
+	defer w.resetFrame(s)
+	return w.send(s)
+}
+
+// send sends the frame of the stream on the path of the stream.
+func (w *worker) send(s *stream) error {
+	if s.pathEntry == nil {
 		// FIXME(kormat): add some metrics to track this.
 		return nil
 	}
@@ -174,25 +287,42 @@ func (w *worker) write(f *frame) error {
 		return nil
 	}
 	snetAddr := w.currSig.EncapSnetAddr()
-	snetAddr.Path = spath.New(w.currPathEntry.Path.FwdPath)
+	snetAddr.Path = spath.New(s.pathEntry.Path.FwdPath)
 	if err := snetAddr.Path.InitOffsets(); err != nil {
 		return common.NewBasicError("Error initializing path offsets", err)
 	}
-	nh, err := w.currPathEntry.HostInfo.Overlay()
+	nh, err := s.pathEntry.HostInfo.Overlay()
 	if err != nil {
 		return common.NewBasicError("Egress unsupported NextHop", err)
 	}
 	snetAddr.NextHop = nh
-	if w.seq == 0 {
-		w.epoch = uint16(time.Now().Unix() & 0xFFFF)
+	if sigcmn.Encryption && (w.key == nil || w.key.Expired()) {
+		// No key has been established with the remote SIG yet.
+		metrics.FramesNotSealed.Inc()
+		return nil
 	}
-	f.writeHdr(w.sess.ID(), w.epoch, w.seq)
+	if s.seq == 0 {
+		epoch, err := w.nextEpoch()
+		if err != nil {
+			metrics.FramesNotSealed.Inc()
+			return err
+		}
+		s.epoch = epoch
+	}
+	s.f.writeHdr(w.sess.ID(), s.epoch, s.seq)
 	// Update sequence number for next packet
-	w.seq += 1
-	if w.seq > MaxSeq {
-		w.seq = 0
+	s.seq += 1
+	if s.seq > MaxSeq {
+		s.seq = 0
+	}
+	raw := s.f.raw()
+	if sigcmn.Encryption {
+		if raw, err = w.key.Seal(w.sealed[:0], raw); err != nil {
+			metrics.FramesNotSealed.Inc()
+			return common.NewBasicError("Unable to seal frame", err)
+		}
 	}
-	bytesWritten, err := w.sess.Conn().WriteToSCION(f.raw(), snetAddr)
+	bytesWritten, err := w.sess.Conn().WriteToSCION(raw, snetAddr)
 	if err != nil {
 		return common.NewBasicError("Egress write error", err)
 	}
@@ -201,7 +331,26 @@ func (w *worker) write(f *frame) error {
 	return nil
 }
 
-func (w *worker) resetFrame(f *frame) {
+// nextEpoch returns the epoch for a stream that (re)starts its sequence
+// numbers. Streams that start within the same second get distinct epochs. If
+// frame encryption is enabled, the epoch is claimed for the current key, such
+// that a key never seals two streams with the same epoch.
+func (w *worker) nextEpoch() (uint16, error) {
+	epoch := uint16(time.Now().Unix() & 0xFFFF)
+	if int16(epoch-w.lastEpoch) <= 0 {
+		epoch = w.lastEpoch + 1
+	}
+	if sigcmn.Encryption {
+		var err error
+		if epoch, err = w.key.ClaimEpoch(epoch); err != nil {
+			return 0, err
+		}
+	}
+	w.lastEpoch = epoch
+	return epoch, nil
+}
+
+func (w *worker) resetFrame(s *stream) {
 	var mtu uint16 = common.MinMTU
 	var addrLen, pathLen uint16
 	remote := w.sess.Remote()
@@ -210,17 +359,26 @@ func (w *worker) resetFrame(f *frame) {
 		if w.currSig != nil {
 			addrLen = uint16(spkt.AddrHdrLen(w.currSig.Host, sigcmn.Host))
 		}
-		w.currPathEntry = nil
-		if remote.SessPath != nil {
-			w.currPathEntry = remote.SessPath.PathEntry()
+		sessPath := s.sessPath
+		if sessPath == nil {
+			sessPath = remote.SessPath
 		}
-		if w.currPathEntry != nil {
-			mtu = w.currPathEntry.Path.Mtu
-			pathLen = uint16(len(w.currPathEntry.Path.FwdPath))
+		s.pathEntry = nil
+		if sessPath != nil {
+			s.pathEntry = sessPath.PathEntry()
+			s.seed = pathSeed(sessPath.Key())
+		}
+		if s.pathEntry != nil {
+			mtu = s.pathEntry.Path.Mtu
+			pathLen = uint16(len(s.pathEntry.Path.FwdPath))
 		}
 	}
 	// FIXME(kormat): to do this properly, need to account for any ext headers.
-	f.reset(mtu - spkt.CmnHdrLen - addrLen - pathLen - l4.UDPLen)
+	frameLen := mtu - spkt.CmnHdrLen - addrLen - pathLen - l4.UDPLen
+	if sigcmn.Encryption {
+		frameLen -= seal.Overhead
+	}
+	s.f.reset(frameLen)
 }
 
 type frame struct {
@@ -0,0 +1,148 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/sig/egress"
+	"github.com/scionproto/scion/go/sig/mgmt"
+)
+
+var _ egress.Session = (*testSession)(nil)
+
+// testSession is a session that only provides its remote.
+type testSession struct {
+	log.Logger
+	remote *egress.RemoteInfo
+}
+
+func (s *testSession) IA() addr.IA                { return addr.IA{} }
+func (s *testSession) ID() mgmt.SessionType       { return 0 }
+func (s *testSession) Conn() snet.Conn            { return nil }
+func (s *testSession) Ring() *ringbuf.Ring        { return nil }
+func (s *testSession) Remote() *egress.RemoteInfo { return s.remote }
+func (s *testSession) Cleanup() error             { return nil }
+func (s *testSession) Healthy() bool              { return true }
+func (s *testSession) PathPool() egress.PathPool  { return nil }
+func (s *testSession) AnnounceWorkerStopped()     {}
+
+func TestUpdateStreams(t *testing.T) {
+	Convey("updateStreams follows the paths of the remote", t, func() {
+		p0, p1, p2, p3 := newTestSessPath("p0"), newTestSessPath("p1"),
+			newTestSessPath("p2"), newTestSessPath("p3")
+		sess := &testSession{Logger: log.Root()}
+		w := NewWorker(sess, log.Root())
+		sess.remote = &egress.RemoteInfo{SessPath: p0, SessPaths: []*egress.SessPath{p0, p1, p2}}
+		w.updateStreams()
+		SoMsg("streams", streamKeys(w.streams), ShouldResemble,
+			[]spathmeta.PathKey{"", "p1", "p2"})
+		before := streamsByKey(w.streams)
+		flows := make([]*stream, 256)
+		for i := range flows {
+			flows[i] = w.chooseStream(ipv4Pkt(protoUDP, 1, 2, uint16(1000+i), 53, 0))
+		}
+
+		Convey("Flows stay on their stream if the paths do not change", func() {
+			sess.remote = &egress.RemoteInfo{SessPath: p0,
+				SessPaths: []*egress.SessPath{p0, p2, p1}}
+			w.updateStreams()
+			SoMsg("streams", streamsByKey(w.streams), ShouldResemble, before)
+			for i, s := range flows {
+				SoMsg("flow", w.chooseStream(ipv4Pkt(protoUDP, 1, 2, uint16(1000+i), 53, 0)),
+					ShouldEqual, s)
+			}
+		})
+		Convey("The stream of a path that disappears is dropped", func() {
+			sess.remote = &egress.RemoteInfo{SessPath: p0, SessPaths: []*egress.SessPath{p0, p1}}
+			w.updateStreams()
+			SoMsg("streams", streamKeys(w.streams), ShouldResemble,
+				[]spathmeta.PathKey{"", "p1"})
+			SoMsg("kept", w.streams[1], ShouldEqual, before["p1"])
+			for i, s := range flows {
+				if s == before["p2"] {
+					continue
+				}
+				SoMsg("flow", w.chooseStream(ipv4Pkt(protoUDP, 1, 2, uint16(1000+i), 53, 0)),
+					ShouldEqual, s)
+			}
+		})
+		Convey("A new path gains a stream", func() {
+			sess.remote = &egress.RemoteInfo{SessPath: p0,
+				SessPaths: []*egress.SessPath{p0, p1, p2, p3}}
+			w.updateStreams()
+			SoMsg("streams", streamKeys(w.streams), ShouldResemble,
+				[]spathmeta.PathKey{"", "p1", "p2", "p3"})
+			moved := 0
+			for i, s := range flows {
+				c := w.chooseStream(ipv4Pkt(protoUDP, 1, 2, uint16(1000+i), 53, 0))
+				if c != s {
+					SoMsg("moved to new stream", c, ShouldEqual, w.streams[3])
+					moved++
+				}
+			}
+			SoMsg("moved", moved, ShouldBeGreaterThan, 0)
+		})
+		Convey("A single path uses only the primary stream", func() {
+			sess.remote = &egress.RemoteInfo{SessPath: p0}
+			w.updateStreams()
+			SoMsg("streams", len(w.streams), ShouldEqual, 1)
+			SoMsg("primary", w.streams[0], ShouldEqual, before[""])
+		})
+	})
+}
+
+func newTestSessPath(key spathmeta.PathKey) *egress.SessPath {
+	return egress.NewSessPath(key, &sciond.PathReplyEntry{
+		Path: &sciond.FwdPathMeta{
+			Mtu:     1400,
+			ExpTime: uint32(time.Now().Add(time.Hour).Unix()),
+		},
+	})
+}
+
+// streamKeys returns the path keys of the streams. The key of the primary
+// stream is empty.
+func streamKeys(streams []*stream) []spathmeta.PathKey {
+	keys := make([]spathmeta.PathKey, 0, len(streams))
+	for _, s := range streams {
+		keys = append(keys, streamKey(s))
+	}
+	return keys
+}
+
+func streamsByKey(streams []*stream) map[spathmeta.PathKey]*stream {
+	m := make(map[spathmeta.PathKey]*stream, len(streams))
+	for _, s := range streams {
+		m[streamKey(s)] = s
+	}
+	return m
+}
+
+func streamKey(s *stream) spathmeta.PathKey {
+	if s.sessPath == nil {
+		return ""
+	}
+	return s.sessPath.Key()
+}
@@ -36,11 +36,19 @@ type ReassemblyList struct {
 	markedForDeletion bool
 	entries           *list.List
 	buf               *bytes.Buffer
+	// replayCheck indicates whether frames that have already been accepted or
+	// that are below the replay window are dropped, even if the list is empty.
+	replayCheck bool
+	replays     replayWindow
+	// seqs tracks the received sequence numbers for the session statistics.
+	seqs seqWindow
 }
 
 // NewReassemblyList returns a ReassemblyList object for the given epoch and with
-// given maximum capacity.
-func NewReassemblyList(epoch int, capacity int, s sender) *ReassemblyList {
+// given maximum capacity. If replayCheck is set, every sequence number is
+// accepted at most once. Frames that are reordered within the replay window
+// are still accepted.
+func NewReassemblyList(epoch int, capacity int, s sender, replayCheck bool) *ReassemblyList {
 	list := &ReassemblyList{
 		epoch:             epoch,
 		capacity:          capacity,
@@ -48,6 +56,7 @@ func NewReassemblyList(epoch int, capacity int, s sender) *ReassemblyList {
 		markedForDeletion: false,
 		entries:           list.New(),
 		buf:               bytes.NewBuffer(make(common.RawBytes, 0, frameBufCap)),
+		replayCheck:       replayCheck,
 	}
 	return list
 }
@@ -57,6 +66,11 @@ func NewReassemblyList(epoch int, capacity int, s sender) *ReassemblyList {
 // that involve the newly added frame. Completely processed frames get removed from the
 // list and released to the pool of frame buffers.
 func (l *ReassemblyList) Insert(frame *FrameBuf) {
+	if l.replayCheck && !l.replays.accept(frame.seqNr) {
+		metrics.FramesReplayed.Inc()
+		frame.Release()
+		return
+	}
 	// If this is the first frame, write all complete packets to the wire and
 	// add the frame to the reassembly list if it contains a fragment at the end.
 	if l.entries.Len() == 0 {
@@ -228,6 +242,50 @@ func (l *ReassemblyList) removeBefore(ele *list.Element) {
 	}
 }
 
+// replayWindowSize is the number of sequence numbers up to the highest
+// accepted one that are tracked by the replay window.
+const replayWindowSize = 64
+
+// replayWindow is a sliding window over the sequence numbers of an epoch. It
+// accepts every sequence number at most once, but in any order as long as it
+// is within the window. Sequence numbers below the window are not accepted.
+type replayWindow struct {
+	init    bool
+	highest int
+	// seen has bit i set if sequence number highest-i has been accepted.
+	seen uint64
+}
+
+// accept returns whether seq has not been accepted before and is within the
+// window. If so, seq is recorded and the window is advanced if necessary.
+func (w *replayWindow) accept(seq int) bool {
+	if !w.init {
+		w.init = true
+		w.highest = seq
+		w.seen = 1
+		return true
+	}
+	if seq > w.highest {
+		if shift := seq - w.highest; shift < replayWindowSize {
+			w.seen = w.seen<<uint(shift) | 1
+		} else {
+			w.seen = 1
+		}
+		w.highest = seq
+		return true
+	}
+	depth := w.highest - seq
+	if depth >= replayWindowSize {
+		return false
+	}
+	bit := uint64(1) << uint(depth)
+	if w.seen&bit != 0 {
+		return false
+	}
+	w.seen |= bit
+	return true
+}
+
 func intMin(x, y int) int {
 	if x <= y {
 		return x
@@ -0,0 +1,134 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/metrics"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Init("sig")
+	freeFrames = ringbuf.New(freeFramesCap, nil, "ingress",
+		prometheus.Labels{"ringId": "freeFrames", "sessId": ""})
+	os.Exit(m.Run())
+}
+
+func TestReassemblyListInsert(t *testing.T) {
+	pkt1, pkt2 := common.RawBytes("packet 1"), common.RawBytes("packet 2")
+	Convey("Insert with replay check drops replayed frames", t, func() {
+		snd := &testSender{}
+		l := NewReassemblyList(0, reassemblyListCap, snd, true)
+		replayed := counterValue(t, metrics.FramesReplayed)
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		l.Insert(newTestFrame(snd, 2, pkt2))
+		l.Insert(newTestFrame(snd, 2, pkt2))
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		SoMsg("pkts", snd.pkts, ShouldResemble, []common.RawBytes{pkt1, pkt2})
+		SoMsg("replayed", counterValue(t, metrics.FramesReplayed)-replayed, ShouldEqual, 2)
+	})
+	Convey("Insert with replay check accepts reordered frames", t, func() {
+		snd := &testSender{}
+		l := NewReassemblyList(0, reassemblyListCap, snd, true)
+		replayed := counterValue(t, metrics.FramesReplayed)
+		l.Insert(newTestFrame(snd, 2, pkt2))
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		SoMsg("pkts", snd.pkts, ShouldResemble, []common.RawBytes{pkt2, pkt1})
+		SoMsg("replayed", counterValue(t, metrics.FramesReplayed)-replayed, ShouldEqual, 1)
+	})
+	Convey("Insert with replay check drops frames below the replay window", t, func() {
+		snd := &testSender{}
+		l := NewReassemblyList(0, reassemblyListCap, snd, true)
+		replayed := counterValue(t, metrics.FramesReplayed)
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		l.Insert(newTestFrame(snd, 2+replayWindowSize, pkt2))
+		l.Insert(newTestFrame(snd, 2, pkt1))
+		l.Insert(newTestFrame(snd, 3, pkt1))
+		SoMsg("pkts", snd.pkts, ShouldResemble, []common.RawBytes{pkt1, pkt2, pkt1})
+		SoMsg("replayed", counterValue(t, metrics.FramesReplayed)-replayed, ShouldEqual, 1)
+	})
+	Convey("Insert without replay check accepts old frames if the list is empty", t, func() {
+		snd := &testSender{}
+		l := NewReassemblyList(0, reassemblyListCap, snd, false)
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		l.Insert(newTestFrame(snd, 1, pkt1))
+		SoMsg("pkts", snd.pkts, ShouldResemble, []common.RawBytes{pkt1, pkt1})
+	})
+}
+
+func TestReplayWindow(t *testing.T) {
+	Convey("replayWindow accepts every sequence number within the window once", t, func() {
+		var w replayWindow
+		SoMsg("first", w.accept(10), ShouldBeTrue)
+		SoMsg("first again", w.accept(10), ShouldBeFalse)
+		SoMsg("below first", w.accept(9), ShouldBeTrue)
+		SoMsg("next", w.accept(12), ShouldBeTrue)
+		SoMsg("reordered", w.accept(11), ShouldBeTrue)
+		SoMsg("reordered again", w.accept(11), ShouldBeFalse)
+		SoMsg("oldest in window", w.accept(12-replayWindowSize+1), ShouldBeTrue)
+		SoMsg("below window", w.accept(12-replayWindowSize), ShouldBeFalse)
+		SoMsg("jump", w.accept(12+2*replayWindowSize), ShouldBeTrue)
+		SoMsg("skipped", w.accept(13), ShouldBeFalse)
+		SoMsg("skipped in window", w.accept(11+2*replayWindowSize), ShouldBeTrue)
+		SoMsg("highest again", w.accept(12+2*replayWindowSize), ShouldBeFalse)
+	})
+}
+
+// newTestFrame creates a frame with index 1 that contains the complete packet.
+func newTestFrame(snd sender, seqNr int, pkt common.RawBytes) *FrameBuf {
+	frame := NewFrameBuf()
+	frame.frameLen = copy(frame.raw, rawTestFrame(0, seqNr, pkt))
+	frame.seqNr = seqNr
+	frame.index = 1
+	frame.snd = snd
+	return frame
+}
+
+// rawTestFrame returns the raw SIG frame with index 1 that contains the
+// complete packet.
+func rawTestFrame(epoch, seqNr int, pkt common.RawBytes) common.RawBytes {
+	raw := make(common.RawBytes, 10+len(pkt))
+	common.Order.PutUint16(raw[1:3], uint16(epoch))
+	common.Order.PutUintN(raw[3:6], uint64(seqNr), 3)
+	common.Order.PutUint16(raw[6:8], 1)
+	common.Order.PutUint16(raw[8:10], uint16(len(pkt)))
+	copy(raw[10:], pkt)
+	return raw
+}
+
+type testSender struct {
+	pkts []common.RawBytes
+}
+
+func (s *testSender) send(pkt common.RawBytes) error {
+	s.pkts = append(s.pkts, append(common.RawBytes(nil), pkt...))
+	return nil
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	xtest.FailOnErr(t, c.Write(m))
+	return m.GetCounter().GetValue()
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"math/bits"
+)
+
+// seqWindowSize is the number of sequence numbers up to the highest received
+// one that are tracked. Frames that are not received within the window are
+// considered lost.
+const seqWindowSize = 64
+
+// seqWindow tracks the sequence numbers received in an epoch, to detect lost,
+// reordered and duplicated frames. Frames with sequence numbers below the
+// first received one are not accounted for.
+type seqWindow struct {
+	init    bool
+	highest int
+	// seen has bit i set if sequence number highest-i has been received.
+	seen uint64
+}
+
+// seqStats records the sequence statistics. It is implemented by
+// metrics.SessionStats.
+type seqStats interface {
+	Received()
+	Lost(n int)
+	Reordered(depth int)
+	Duplicated()
+}
+
+// track records the sequence number of a received frame in stats.
+func (sw *seqWindow) track(seq int, stats seqStats) {
+	stats.Received()
+	if !sw.init {
+		sw.init = true
+		sw.highest = seq
+		sw.seen = ^uint64(0)
+		return
+	}
+	if seq > sw.highest {
+		shift := seq - sw.highest
+		var lost int
+		if shift < seqWindowSize {
+			// The sequence numbers that leave the window unseen are lost.
+			lost = shift - bits.OnesCount64(sw.seen>>uint(seqWindowSize-shift))
+			sw.seen = sw.seen<<uint(shift) | 1
+		} else {
+			// Sequence numbers of the gap that do not fit into the window are
+			// lost as well.
+			lost = sw.missing() + shift - seqWindowSize
+			sw.seen = 1
+		}
+		sw.highest = seq
+		if lost > 0 {
+			stats.Lost(lost)
+		}
+		return
+	}
+	depth := sw.highest - seq
+	if depth >= seqWindowSize {
+		// The frame has already been counted as lost, it is not counted
+		// again as reordered.
+		return
+	}
+	bit := uint64(1) << uint(depth)
+	if sw.seen&bit != 0 {
+		stats.Duplicated()
+		return
+	}
+	sw.seen |= bit
+	stats.Reordered(depth)
+}
+
+// missing returns the number of sequence numbers in the window that have not
+// been received.
+func (sw *seqWindow) missing() int {
+	if !sw.init {
+		return 0
+	}
+	return seqWindowSize - bits.OnesCount64(sw.seen)
+}
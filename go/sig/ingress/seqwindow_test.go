@@ -0,0 +1,112 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSeqWindowTrack(t *testing.T) {
+	tests := []struct {
+		name       string
+		seqs       []int
+		lost       int
+		depths     []int
+		duplicated int
+		missing    int
+	}{
+		{
+			name: "In order",
+			seqs: []int{0, 1, 2, 3},
+		},
+		{
+			name:   "Reordered within the window",
+			seqs:   []int{0, 3, 1, 2},
+			depths: []int{2, 1},
+		},
+		{
+			name:       "Duplicates",
+			seqs:       []int{0, 1, 1, 0},
+			duplicated: 2,
+		},
+		{
+			name:    "Missing at the end of the epoch",
+			seqs:    []int{0, 1, 3, 5},
+			missing: 2,
+		},
+		{
+			name:    "Gap of the window size",
+			seqs:    []int{0, seqWindowSize},
+			missing: seqWindowSize - 1,
+		},
+		{
+			name:    "Gap larger than the window",
+			seqs:    []int{0, 100},
+			lost:    100 - seqWindowSize,
+			missing: seqWindowSize - 1,
+		},
+		{
+			name:    "Frames later than the window are only counted as lost",
+			seqs:    []int{0, 100, 1},
+			lost:    100 - seqWindowSize,
+			missing: seqWindowSize - 1,
+		},
+		{
+			name:    "Frames that leave the window unseen are lost",
+			seqs:    []int{0, 2, seqWindowSize + 1},
+			lost:    1,
+			missing: seqWindowSize - 2,
+		},
+	}
+	for _, test := range tests {
+		Convey(test.name, t, func() {
+			var sw seqWindow
+			stats := &testSeqStats{}
+			for _, seq := range test.seqs {
+				sw.track(seq, stats)
+			}
+			SoMsg("received", stats.received, ShouldEqual, len(test.seqs))
+			SoMsg("lost", stats.lost, ShouldEqual, test.lost)
+			SoMsg("depths", stats.depths, ShouldResemble, test.depths)
+			SoMsg("duplicated", stats.duplicated, ShouldEqual, test.duplicated)
+			SoMsg("missing", sw.missing(), ShouldEqual, test.missing)
+		})
+	}
+}
+
+type testSeqStats struct {
+	received   int
+	lost       int
+	depths     []int
+	duplicated int
+}
+
+func (s *testSeqStats) Received() {
+	s.received++
+}
+
+func (s *testSeqStats) Lost(n int) {
+	s.lost += n
+}
+
+func (s *testSeqStats) Reordered(depth int) {
+	s.depths = append(s.depths, depth)
+}
+
+func (s *testSeqStats) Duplicated() {
+	s.duplicated++
+}
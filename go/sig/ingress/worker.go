@@ -26,6 +26,8 @@ import (
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/sig/metrics"
 	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/seal"
+	"github.com/scionproto/scion/go/sig/sigcmn"
 )
 
 const (
@@ -48,6 +50,13 @@ type Worker struct {
 	rlists           map[int]*ReassemblyList
 	markedForCleanup bool
 	sentCtrs         metrics.CtrPair
+	stats            *metrics.SessionStats
+	// peer identifies the keys of the remote SIG if frame encryption is
+	// enabled. With frame encryption, the reassembly lists are keyed by key
+	// id and epoch, and retired contains the replay windows of the removed
+	// reassembly lists, such that their frames cannot be replayed.
+	peer    seal.Peer
+	retired map[int]replayWindow
 }
 
 func NewWorker(remote *snet.Addr, sessId mgmt.SessionType) *Worker {
@@ -69,11 +78,17 @@ func NewWorker(remote *snet.Addr, sessId mgmt.SessionType) *Worker {
 			Bytes: metrics.PktBytesSent.WithLabelValues(remote.IA.String(),
 				sessId.String()),
 		},
+		stats: metrics.NewSessionStats(remote.IA, remote.Host.String(), sessId),
+	}
+	if sigcmn.Encryption {
+		worker.peer = seal.NewPeer(remote.IA, remote.Host.L3, sessId)
+		worker.retired = make(map[int]replayWindow)
 	}
 	return worker
 }
 
 func (w *Worker) Stop() {
+	w.stats.Unregister()
 	w.Ring.Close()
 }
 
@@ -106,6 +121,16 @@ func (w *Worker) Run() {
 // packets to the wire and then adding the frame to the corresponding reassembly
 // list if needed.
 func (w *Worker) processFrame(frame *FrameBuf) {
+	var keyId uint16
+	if sigcmn.Encryption {
+		var err error
+		if keyId, err = w.open(frame); err != nil {
+			w.Debug("Unable to open frame", "err", err)
+			metrics.FramesNotOpened.Inc()
+			frame.Release()
+			return
+		}
+	}
 	epoch := int(common.Order.Uint16(frame.raw[1:3]))
 	seqNr := int(common.Order.UintN(frame.raw[3:6], 3))
 	index := int(common.Order.Uint16(frame.raw[6:8]))
@@ -121,28 +146,63 @@ func (w *Worker) processFrame(frame *FrameBuf) {
 	// frame.
 	frame.completePktsProcessed = index == 0
 	// Add to frame buf reassembly list.
-	rlist := w.getRlist(epoch)
+	rlist := w.getRlist(int(keyId)<<16|epoch, epoch)
+	rlist.seqs.track(seqNr, w.stats)
 	rlist.Insert(frame)
 }
 
-func (w *Worker) getRlist(epoch int) *ReassemblyList {
-	rlist, ok := w.rlists[epoch]
+// open authenticates and decrypts the sealed frame in place. It returns the id
+// of the key that sealed the frame.
+func (w *Worker) open(frame *FrameBuf) (uint16, error) {
+	sealed := frame.raw[:frame.frameLen]
+	keyId, err := seal.KeyId(sealed)
+	if err != nil {
+		return 0, err
+	}
+	key := sigcmn.FrameKeys.Get(w.peer, keyId)
+	if key == nil {
+		return 0, common.NewBasicError("No valid key", nil, "keyId", keyId)
+	}
+	plain, err := key.Open(sealed)
+	if err != nil {
+		return 0, err
+	}
+	frame.frameLen = len(plain)
+	return keyId, nil
+}
+
+func (w *Worker) getRlist(rlistKey, epoch int) *ReassemblyList {
+	rlist, ok := w.rlists[rlistKey]
 	if !ok {
-		rlist = NewReassemblyList(epoch, reassemblyListCap, w)
-		w.rlists[epoch] = rlist
+		rlist = NewReassemblyList(epoch, reassemblyListCap, w, sigcmn.Encryption)
+		if replays, ok := w.retired[rlistKey]; ok {
+			rlist.replays = replays
+			delete(w.retired, rlistKey)
+		}
+		w.rlists[rlistKey] = rlist
 	}
 	rlist.markedForDeletion = false
 	return rlist
 }
 
 func (w *Worker) cleanup() {
-	for epoch := range w.rlists {
-		rlist := w.rlists[epoch]
+	for rlistKey := range w.rlists {
+		rlist := w.rlists[rlistKey]
 		if rlist.markedForDeletion {
 			// Reassembly list has been marked for deletion in a previous cleanup run.
 			// Remove the reassembly list from the map and then release all frames
 			// back to the bufpool.
-			delete(w.rlists, epoch)
+			delete(w.rlists, rlistKey)
+			// Frames still missing at the end of the epoch are lost.
+			if n := rlist.seqs.missing(); n > 0 {
+				w.stats.Lost(n)
+			}
+			if rlist.entries.Len() > 0 {
+				w.stats.ReassemblyTimeout()
+			}
+			if w.retired != nil {
+				w.retired[rlistKey] = rlist.replays
+			}
 			go func() {
 				defer log.LogPanicAndExit()
 				rlist.removeAll()
@@ -153,6 +213,12 @@ func (w *Worker) cleanup() {
 			rlist.markedForDeletion = true
 		}
 	}
+	// Frames sealed with invalid keys are rejected anyway.
+	for rlistKey := range w.retired {
+		if sigcmn.FrameKeys.Get(w.peer, uint16(rlistKey>>16)) == nil {
+			delete(w.retired, rlistKey)
+		}
+	}
 }
 
 func (w *Worker) send(packet common.RawBytes) error {
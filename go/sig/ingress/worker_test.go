@@ -0,0 +1,106 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/seal"
+	"github.com/scionproto/scion/go/sig/sigcmn"
+)
+
+func TestWorkerReplay(t *testing.T) {
+	pkt1, pkt2 := common.RawBytes("packet 1"), common.RawBytes("packet 2")
+	Convey("Replayed sealed frames are dropped", t, func() {
+		encryption, frameKeys, tio := sigcmn.Encryption, sigcmn.FrameKeys, tunIO
+		Reset(func() {
+			sigcmn.Encryption, sigcmn.FrameKeys, tunIO = encryption, frameKeys, tio
+		})
+		sigcmn.Encryption = true
+		sigcmn.FrameKeys = seal.NewKeyStore()
+		tun := &testTun{}
+		tunIO = tun
+		remote := &snet.Addr{
+			IA: xtest.MustParseIA("1-ff00:0:110"),
+			Host: &addr.AppAddr{
+				L3: addr.HostFromIP(net.IPv4(192, 0, 2, 1)),
+				L4: addr.NewL4UDPInfo(30256),
+			},
+		}
+		w := NewWorker(remote, 0)
+		Reset(w.Stop)
+		key, err := seal.NewKey(1, make(common.RawBytes, seal.KeyLen))
+		xtest.FailOnErr(t, err)
+		xtest.FailOnErr(t, sigcmn.FrameKeys.Add(w.peer, key, time.Now()))
+		epoch, err := key.ClaimEpoch(7)
+		xtest.FailOnErr(t, err)
+		sealed1 := sealTestFrame(t, key, int(epoch), 1, pkt1)
+
+		w.processFrame(newSealedFrame(sealed1))
+		w.processFrame(newSealedFrame(sealed1))
+		SoMsg("pkts", tun.pkts, ShouldResemble, []common.RawBytes{pkt1})
+
+		Convey("after the reassembly list is retired and recreated", func() {
+			// The first run marks the list for deletion, the second removes it.
+			w.cleanup()
+			w.cleanup()
+			SoMsg("rlists", w.rlists, ShouldBeEmpty)
+			w.processFrame(newSealedFrame(sealed1))
+			SoMsg("replayed", tun.pkts, ShouldResemble, []common.RawBytes{pkt1})
+			w.processFrame(newSealedFrame(sealTestFrame(t, key, int(epoch), 2, pkt2)))
+			SoMsg("new", tun.pkts, ShouldResemble, []common.RawBytes{pkt1, pkt2})
+		})
+	})
+}
+
+func sealTestFrame(t *testing.T, key *seal.Key, epoch, seqNr int,
+	pkt common.RawBytes) common.RawBytes {
+
+	sealed, err := key.Seal(nil, rawTestFrame(epoch, seqNr, pkt))
+	xtest.FailOnErr(t, err)
+	return sealed
+}
+
+func newSealedFrame(sealed common.RawBytes) *FrameBuf {
+	frame := NewFrameBuf()
+	frame.frameLen = copy(frame.raw, sealed)
+	return frame
+}
+
+type testTun struct {
+	pkts []common.RawBytes
+}
+
+func (t *testTun) Read(b []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (t *testTun) Write(b []byte) (int, error) {
+	t.pkts = append(t.pkts, append(common.RawBytes(nil), b...))
+	return len(b), nil
+}
+
+func (t *testTun) Close() error {
+	return nil
+}
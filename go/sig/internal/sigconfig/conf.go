@@ -18,11 +18,15 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/truststorage"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/sig/seal"
 )
 
 const (
@@ -30,6 +34,12 @@ const (
 	DefaultEncapPort   = 10080
 	DefaultTunName     = "sig"
 	DefaultTunRTableId = 11
+	DefaultEgressPaths = 1
+	DefaultInfraPort   = 10082
+)
+
+var (
+	DefaultRekeyInterval = 10 * time.Minute
 )
 
 var _ config.Config = (*Config)(nil)
@@ -38,6 +48,7 @@ type Config struct {
 	Logging env.Logging
 	Metrics env.Metrics
 	Sciond  env.SciondClient `toml:"sd_client"`
+	TrustDB truststorage.TrustDBConf
 	Sig     SigConf
 }
 
@@ -46,6 +57,7 @@ func (cfg *Config) InitDefaults() {
 		&cfg.Logging,
 		&cfg.Metrics,
 		&cfg.Sciond,
+		&cfg.TrustDB,
 		&cfg.Sig,
 	)
 }
@@ -55,6 +67,7 @@ func (cfg *Config) Validate() error {
 		&cfg.Logging,
 		&cfg.Metrics,
 		&cfg.Sciond,
+		&cfg.TrustDB,
 		&cfg.Sig,
 	)
 }
@@ -64,6 +77,7 @@ func (cfg *Config) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
 		&cfg.Logging,
 		&cfg.Metrics,
 		&cfg.Sciond,
+		&cfg.TrustDB,
 		&cfg.Sig,
 	)
 }
@@ -80,6 +94,10 @@ type SigConf struct {
 	ID string
 	// The SIG config json file. (required)
 	SIGConfig string
+	// AttributeDB is the AS attribute database file that the attribute
+	// predicates of the path policies in SIGConfig are evaluated against.
+	// It is reloaded together with SIGConfig. (default "")
+	AttributeDB string
 	// IA the local IA (required)
 	IA addr.IA
 	// IP the bind IP address (required)
@@ -98,6 +116,25 @@ type SigConf struct {
 	SrcIP4 net.IP
 	// IPv6 source address hint to put into routing table.
 	SrcIP6 net.IP
+	// EgressPaths is the maximum number of paths a session spreads its
+	// traffic over. Flows are hashed onto the healthy paths, such that the
+	// packets of a flow are not reordered. (default DefaultEgressPaths)
+	EgressPaths int
+	// Encryption enables the authenticated encryption of the frames sent to
+	// and received from remote SIGs. The keys are established with a key
+	// exchange that is authenticated with the AS certificates.
+	Encryption bool
+	// ConfigDir is the directory that contains the certs and keys
+	// directories of the AS. If set, the trust store is initialized, which is
+	// required for Encryption and for prefix announcements. (required if
+	// Encryption is set)
+	ConfigDir string
+	// InfraPort is the port used to fetch the certificates of remote ASes
+	// from the control plane. (default DefaultInfraPort)
+	InfraPort uint16
+	// RekeyInterval is the interval after which a new key is established
+	// for a session. (default DefaultRekeyInterval)
+	RekeyInterval util.DurWrap
 }
 
 // InitDefaults sets the default values to unset values.
@@ -114,6 +151,15 @@ func (cfg *SigConf) InitDefaults() {
 	if cfg.TunRTableId == 0 {
 		cfg.TunRTableId = DefaultTunRTableId
 	}
+	if cfg.EgressPaths == 0 {
+		cfg.EgressPaths = DefaultEgressPaths
+	}
+	if cfg.InfraPort == 0 {
+		cfg.InfraPort = DefaultInfraPort
+	}
+	if cfg.RekeyInterval.Duration == 0 {
+		cfg.RekeyInterval.Duration = DefaultRekeyInterval
+	}
 }
 
 // Validate validate the config and returns an error if a value is not valid.
@@ -133,6 +179,17 @@ func (cfg *SigConf) Validate() error {
 	if cfg.IP.IsUnspecified() {
 		return common.NewBasicError("IP must be set", nil)
 	}
+	if cfg.EgressPaths < 1 {
+		return common.NewBasicError("EgressPaths must be positive", nil,
+			"actual", cfg.EgressPaths)
+	}
+	if cfg.Encryption && cfg.ConfigDir == "" {
+		return common.NewBasicError("ConfigDir must be set if Encryption is enabled", nil)
+	}
+	if cfg.RekeyInterval.Duration <= 0 || cfg.RekeyInterval.Duration > seal.MaxKeyLifetime/2 {
+		return common.NewBasicError("RekeyInterval out of range", nil,
+			"max", seal.MaxKeyLifetime/2, "actual", cfg.RekeyInterval)
+	}
 	return nil
 }
 
@@ -23,6 +23,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/scionproto/scion/go/lib/env/envtest"
+	"github.com/scionproto/scion/go/lib/truststorage/truststoragetest"
 	"github.com/scionproto/scion/go/lib/xtest"
 )
 
@@ -42,6 +43,7 @@ func TestConfigSample(t *testing.T) {
 
 func InitTestConfig(cfg *Config) {
 	envtest.InitTest(nil, &cfg.Logging, &cfg.Metrics, &cfg.Sciond)
+	truststoragetest.InitTestConfig(&cfg.TrustDB)
 	InitTestSigConf(&cfg.Sig)
 }
 
@@ -51,12 +53,14 @@ func InitTestSigConf(cfg *SigConf) {
 
 func CheckTestConfig(cfg *Config, id string) {
 	envtest.CheckTest(nil, &cfg.Logging, &cfg.Metrics, &cfg.Sciond, id)
+	truststoragetest.CheckTestConfig(&cfg.TrustDB, id)
 	CheckTestSigConf(&cfg.Sig, id)
 }
 
 func CheckTestSigConf(cfg *SigConf, id string) {
 	SoMsg("ID correct", cfg.ID, ShouldEqual, "sig4")
 	SoMsg("SIGConfig correct", cfg.SIGConfig, ShouldEqual, "/etc/scion/sig/sig.json")
+	SoMsg("AttributeDB correct", cfg.AttributeDB, ShouldEqual, "")
 	SoMsg("IA correct", cfg.IA, ShouldResemble, xtest.MustParseIA("1-ff00:0:113"))
 	SoMsg("IP correct", cfg.IP, ShouldResemble, net.ParseIP("192.0.2.100"))
 	SoMsg("CtrlPort correct", cfg.CtrlPort, ShouldEqual, DefaultCtrlPort)
@@ -64,4 +68,10 @@ func CheckTestSigConf(cfg *SigConf, id string) {
 	SoMsg("Dispatcher correct", cfg.Dispatcher, ShouldEqual, "")
 	SoMsg("Tun correct", cfg.Tun, ShouldEqual, DefaultTunName)
 	SoMsg("TunRTableId correct", cfg.TunRTableId, ShouldEqual, DefaultTunRTableId)
+	SoMsg("EgressPaths correct", cfg.EgressPaths, ShouldEqual, DefaultEgressPaths)
+	SoMsg("Encryption correct", cfg.Encryption, ShouldBeFalse)
+	SoMsg("ConfigDir correct", cfg.ConfigDir, ShouldEqual, "/etc/scion/sig")
+	SoMsg("InfraPort correct", cfg.InfraPort, ShouldEqual, DefaultInfraPort)
+	SoMsg("RekeyInterval correct", cfg.RekeyInterval.Duration, ShouldEqual,
+		DefaultRekeyInterval)
 }
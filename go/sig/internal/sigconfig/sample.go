@@ -23,6 +23,10 @@ ID = "%s"
 # The SIG config json file. (required)
 SIGConfig = "/etc/scion/sig/sig.json"
 
+# The AS attribute database file used by the attribute predicates of the path
+# policies in SIGConfig. It is reloaded together with SIGConfig. (default "")
+AttributeDB = ""
+
 # The local IA. (required)
 IA = "1-ff00:0:113"
 
@@ -43,4 +47,24 @@ Tun = "sig"
 
 # Id of the routing table. (default 11)
 TunRTableId = 11
+
+# Maximum number of paths a session spreads its traffic over. Flows are hashed
+# onto the healthy paths, such that the packets of a flow are not reordered.
+# (default 1)
+EgressPaths = 1
+
+# Enable the authenticated encryption of the frames exchanged with remote SIGs.
+# Requires the remote SIGs to enable it as well. (default false)
+Encryption = false
+
+# The directory that contains the certs and keys directories of the AS. If set,
+# the trust store is initialized, which is required for Encryption and for
+# prefix announcements. (required if Encryption is set)
+ConfigDir = "/etc/scion/sig"
+
+# Port used to fetch the certificates of remote ASes. (default 10082)
+InfraPort = 10082
+
+# Interval after which a new key is established for a session. (default 10m)
+RekeyInterval = "10m"
 `
@@ -31,6 +31,7 @@ import (
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/fatal"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/sig/base"
 	"github.com/scionproto/scion/go/sig/base/core"
 	"github.com/scionproto/scion/go/sig/config"
@@ -88,9 +89,21 @@ func realMain() int {
 		defer log.LogPanicAndExit()
 		base.PollReqHdlr()
 	}()
+	go func() {
+		defer log.LogPanicAndExit()
+		core.PrefixAnnounceHdlr()
+	}()
+	go func() {
+		defer log.LogPanicAndExit()
+		base.KeyExchangeReqHdlr()
+	}()
+	go func() {
+		defer log.LogPanicAndExit()
+		core.Announcer()
+	}()
 	environment := env.SetupEnv(
 		func() {
-			success := loadConfig(cfg.Sig.SIGConfig)
+			success := loadConfig(cfg.Sig.SIGConfig, cfg.Sig.AttributeDB)
 			// Errors already logged in loadConfig
 			log.Info("reloadOnSIGHUP: reload done", "success", success)
 		},
@@ -192,21 +205,38 @@ func setup() error {
 	if err := sigcmn.Init(cfg.Sig, cfg.Sciond); err != nil {
 		return common.NewBasicError("Error during initialization", err)
 	}
+	if cfg.Sig.ConfigDir != "" {
+		if err := sigcmn.InitTrust(cfg.Sig, cfg.TrustDB); err != nil {
+			return common.NewBasicError("Unable to initialize trust store", err)
+		}
+	}
 	egress.Init()
 	disp.Init(sigcmn.CtrlConn)
 	// Parse sig config
-	if loadConfig(cfg.Sig.SIGConfig) != true {
+	if loadConfig(cfg.Sig.SIGConfig, cfg.Sig.AttributeDB) != true {
 		return common.NewBasicError("Unable to load sig config on startup", nil)
 	}
 	return nil
 }
 
-func loadConfig(path string) bool {
+func loadConfig(path, attrPath string) bool {
 	cfg, err := config.LoadFromFile(path)
 	if err != nil {
 		log.Error("loadConfig: Failed", "err", err)
 		return false
 	}
+	if cfg.PrefixAnnouncements() && sigcmn.Verifier == nil {
+		log.Error("loadConfig: Prefix announcements require the trust store, set ConfigDir")
+		return false
+	}
+	if attrPath != "" {
+		db, err := pathpol.LoadAttributeDB(attrPath)
+		if err != nil {
+			log.Error("loadConfig: Failed to load attribute database", "err", err)
+			return false
+		}
+		cfg.SetAttributes(db)
+	}
 	ok := core.Map.ReloadConfig(cfg)
 	if !ok {
 		return false
@@ -44,6 +44,15 @@ var (
 	FramesDiscarded    prometheus.Counter
 	FramesTooOld       prometheus.Counter
 	FramesDuplicated   prometheus.Counter
+	FramesNotSealed    prometheus.Counter
+	FramesNotOpened    prometheus.Counter
+	FramesReplayed     prometheus.Counter
+
+	FramesLost           *prometheus.CounterVec
+	FramesReordered      *prometheus.CounterVec
+	FramesRecvDuplicated *prometheus.CounterVec
+	FrameReorderDepth    *prometheus.HistogramVec
+	ReassemblyTimeouts   *prometheus.CounterVec
 
 	EgressRxQueueFull *prometheus.CounterVec
 )
@@ -77,6 +86,23 @@ func Init(elem string) {
 	FramesDiscarded = newC("frames_discarded_total", "Number of frames discarded.")
 	FramesTooOld = newC("frames_too_old_total", "Number of frames that are too old.")
 	FramesDuplicated = newC("frames_duplicated_total", "Number of duplicate frames.")
+	FramesNotSealed = newC("frames_not_sealed_total",
+		"Number of frames dropped because they could not be sealed.")
+	FramesNotOpened = newC("frames_not_opened_total",
+		"Number of frames dropped because they could not be opened.")
+	FramesReplayed = newC("frames_replayed_total", "Number of replayed frames.")
+
+	FramesLost = newCVec("frames_lost_total",
+		"Number of frames not received within the reorder window.", iaLabels)
+	FramesReordered = newCVec("frames_reordered_total",
+		"Number of frames received out of order.", iaLabels)
+	FramesRecvDuplicated = newCVec("frames_recv_duplicated_total",
+		"Number of frames received more than once.", iaLabels)
+	FrameReorderDepth = prom.NewHistogramVec(namespace, "", "frame_reorder_depth",
+		"Number of frames by which out of order frames are late.", iaLabels,
+		prometheus.ExponentialBuckets(1, 2, 7))
+	ReassemblyTimeouts = newCVec("reassembly_timeouts_total",
+		"Number of reassembly lists removed with incomplete packets.", iaLabels)
 
 	EgressRxQueueFull = newCVec("egress_recv_queue_full_total",
 		"Egress packets dropped due to full queues.", []string{"IA"})
@@ -87,6 +113,7 @@ func Init(elem string) {
 	http.HandleFunc("/configversion", func(w http.ResponseWriter, _ *http.Request) {
 		fmt.Fprintln(w, atomic.LoadUint64(&ConfigVersion))
 	})
+	http.HandleFunc("/status", statusHandler)
 }
 
 // CtrPair is a pair of counters, one for packets and one for bytes.
@@ -0,0 +1,176 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/sig/mgmt"
+)
+
+var sessStats = struct {
+	sync.Mutex
+	m map[*SessionStats]struct{}
+}{m: make(map[*SessionStats]struct{})}
+
+// SessionStats tracks the sequence statistics of the frames received from a
+// remote SIG for a session. The statistics are exported as prometheus metrics
+// and on the /status endpoint.
+type SessionStats struct {
+	ia     addr.IA
+	host   string
+	sessId mgmt.SessionType
+	// The counters are accessed atomically, as the status endpoint reads them
+	// while the ingress worker updates them.
+	frames             uint64
+	lost               uint64
+	reordered          uint64
+	maxReorderDepth    uint64
+	duplicated         uint64
+	reassemblyTimeouts uint64
+
+	lostCtr      prometheus.Counter
+	reorderedCtr prometheus.Counter
+	dupCtr       prometheus.Counter
+	depthObs     prometheus.Observer
+	timeoutCtr   prometheus.Counter
+}
+
+// NewSessionStats creates and registers the statistics of the frames received
+// from host in ia for session sessId. Unregister must be called once the
+// frames are not tracked anymore.
+func NewSessionStats(ia addr.IA, host string, sessId mgmt.SessionType) *SessionStats {
+	l := []string{ia.String(), sessId.String()}
+	s := &SessionStats{
+		ia:           ia,
+		host:         host,
+		sessId:       sessId,
+		lostCtr:      FramesLost.WithLabelValues(l...),
+		reorderedCtr: FramesReordered.WithLabelValues(l...),
+		dupCtr:       FramesRecvDuplicated.WithLabelValues(l...),
+		depthObs:     FrameReorderDepth.WithLabelValues(l...),
+		timeoutCtr:   ReassemblyTimeouts.WithLabelValues(l...),
+	}
+	sessStats.Lock()
+	defer sessStats.Unlock()
+	sessStats.m[s] = struct{}{}
+	return s
+}
+
+// Unregister removes the statistics from the status endpoint.
+func (s *SessionStats) Unregister() {
+	sessStats.Lock()
+	defer sessStats.Unlock()
+	delete(sessStats.m, s)
+}
+
+// Received records a received frame.
+func (s *SessionStats) Received() {
+	atomic.AddUint64(&s.frames, 1)
+}
+
+// Lost records n frames that have not been received within the reorder
+// window.
+func (s *SessionStats) Lost(n int) {
+	atomic.AddUint64(&s.lost, uint64(n))
+	s.lostCtr.Add(float64(n))
+}
+
+// Reordered records a frame that has been received depth frames late.
+func (s *SessionStats) Reordered(depth int) {
+	atomic.AddUint64(&s.reordered, 1)
+	for {
+		max := atomic.LoadUint64(&s.maxReorderDepth)
+		if uint64(depth) <= max ||
+			atomic.CompareAndSwapUint64(&s.maxReorderDepth, max, uint64(depth)) {
+			break
+		}
+	}
+	s.reorderedCtr.Inc()
+	s.depthObs.Observe(float64(depth))
+}
+
+// Duplicated records a frame that has been received before.
+func (s *SessionStats) Duplicated() {
+	atomic.AddUint64(&s.duplicated, 1)
+	s.dupCtr.Inc()
+}
+
+// ReassemblyTimeout records a reassembly list that has been removed while it
+// still contained frames of incomplete packets.
+func (s *SessionStats) ReassemblyTimeout() {
+	atomic.AddUint64(&s.reassemblyTimeouts, 1)
+	s.timeoutCtr.Inc()
+}
+
+type sessionStatus struct {
+	IA                 addr.IA
+	Host               string
+	SessId             mgmt.SessionType
+	Frames             uint64
+	Lost               uint64
+	Reordered          uint64
+	MaxReorderDepth    uint64
+	Duplicated         uint64
+	ReassemblyTimeouts uint64
+}
+
+func (s *SessionStats) status() sessionStatus {
+	return sessionStatus{
+		IA:                 s.ia,
+		Host:               s.host,
+		SessId:             s.sessId,
+		Frames:             atomic.LoadUint64(&s.frames),
+		Lost:               atomic.LoadUint64(&s.lost),
+		Reordered:          atomic.LoadUint64(&s.reordered),
+		MaxReorderDepth:    atomic.LoadUint64(&s.maxReorderDepth),
+		Duplicated:         atomic.LoadUint64(&s.duplicated),
+		ReassemblyTimeouts: atomic.LoadUint64(&s.reassemblyTimeouts),
+	}
+}
+
+// statusHandler writes the statistics of the ingress sessions as JSON.
+func statusHandler(w http.ResponseWriter, _ *http.Request) {
+	sessStats.Lock()
+	sessions := make([]sessionStatus, 0, len(sessStats.m))
+	for s := range sessStats.m {
+		sessions = append(sessions, s.status())
+	}
+	sessStats.Unlock()
+	sort.Slice(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if a.IA != b.IA {
+			return a.IA.IAInt() < b.IA.IAInt()
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		return a.SessId < b.SessId
+	})
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(map[string]interface{}{"IngressSessions": sessions}); err != nil {
+		log.Error("Unable to write status", "err", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgmt
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ proto.Cerealizable = (*PrefixAnnounce)(nil)
+
+// PrefixAnnounce contains the complete set of networks served by the
+// announcing SIG.
+type PrefixAnnounce struct {
+	// Timestamp is the creation time in nanoseconds since the Unix epoch.
+	Timestamp uint64
+	Nets      []*Net
+}
+
+func NewPrefixAnnounce(ts time.Time, nets []*net.IPNet) *PrefixAnnounce {
+	pa := &PrefixAnnounce{Timestamp: uint64(ts.UnixNano())}
+	for _, ipnet := range nets {
+		pa.Nets = append(pa.Nets, NewNet(ipnet))
+	}
+	return pa
+}
+
+// Time returns the creation time of the announcement.
+func (pa *PrefixAnnounce) Time() time.Time {
+	return time.Unix(0, int64(pa.Timestamp))
+}
+
+// IPNets returns the announced networks. An error is returned if any of the
+// networks is invalid.
+func (pa *PrefixAnnounce) IPNets() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(pa.Nets))
+	for _, n := range pa.Nets {
+		ipnet, err := n.IPNet()
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets, nil
+}
+
+func (pa *PrefixAnnounce) ProtoId() proto.ProtoIdType {
+	return proto.SIGPrefixAnnounce_TypeID
+}
+
+func (pa *PrefixAnnounce) String() string {
+	nets := make([]string, 0, len(pa.Nets))
+	for _, n := range pa.Nets {
+		nets = append(nets, n.String())
+	}
+	return fmt.Sprintf("Timestamp: %d Nets: [%s]", pa.Timestamp, strings.Join(nets, ", "))
+}
+
+// Net is an IP network in an announcement.
+type Net struct {
+	IP        common.RawBytes `capnp:"ip"`
+	PrefixLen uint8
+}
+
+func NewNet(ipnet *net.IPNet) *Net {
+	ones, _ := ipnet.Mask.Size()
+	ip := ipnet.IP.To4()
+	if ip == nil {
+		ip = ipnet.IP.To16()
+	}
+	return &Net{IP: common.RawBytes(ip), PrefixLen: uint8(ones)}
+}
+
+// IPNet returns the network. An error is returned if the IP length or the
+// prefix length are invalid, or if the address has host bits set.
+func (n *Net) IPNet() (*net.IPNet, error) {
+	if len(n.IP) != net.IPv4len && len(n.IP) != net.IPv6len {
+		return nil, common.NewBasicError("Invalid IP length", nil, "len", len(n.IP))
+	}
+	if int(n.PrefixLen) > 8*len(n.IP) {
+		return nil, common.NewBasicError("Invalid prefix length", nil,
+			"ip", net.IP(n.IP), "prefixLen", n.PrefixLen)
+	}
+	ipnet := &net.IPNet{
+		IP:   append(net.IP(nil), n.IP...),
+		Mask: net.CIDRMask(int(n.PrefixLen), 8*len(n.IP)),
+	}
+	if !ipnet.IP.Equal(ipnet.IP.Mask(ipnet.Mask)) {
+		return nil, common.NewBasicError("Network is not canonical", nil, "net", ipnet)
+	}
+	return ipnet, nil
+}
+
+func (n *Net) String() string {
+	return fmt.Sprintf("%s/%d", net.IP(n.IP), n.PrefixLen)
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgmt
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func TestNetIPNet(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Net    *Net
+		Expect string
+	}{
+		{
+			Name:   "IPv4",
+			Net:    &Net{IP: common.RawBytes{192, 0, 2, 0}, PrefixLen: 24},
+			Expect: "192.0.2.0/24",
+		},
+		{
+			Name:   "IPv6",
+			Net:    &Net{IP: common.RawBytes(net.ParseIP("2001:db8::")), PrefixLen: 32},
+			Expect: "2001:db8::/32",
+		},
+		{
+			Name:   "Full IPv4 prefix",
+			Net:    &Net{IP: common.RawBytes{192, 0, 2, 1}, PrefixLen: 32},
+			Expect: "192.0.2.1/32",
+		},
+		{
+			Name:   "Default route",
+			Net:    &Net{IP: common.RawBytes{0, 0, 0, 0}, PrefixLen: 0},
+			Expect: "0.0.0.0/0",
+		},
+	}
+	errCases := []struct {
+		Name string
+		Net  *Net
+	}{
+		{
+			Name: "Invalid IP length",
+			Net:  &Net{IP: common.RawBytes{192, 0, 2}, PrefixLen: 24},
+		},
+		{
+			Name: "IPv4 prefix too long",
+			Net:  &Net{IP: common.RawBytes{192, 0, 2, 0}, PrefixLen: 33},
+		},
+		{
+			Name: "IPv6 prefix too long",
+			Net:  &Net{IP: common.RawBytes(net.ParseIP("2001:db8::")), PrefixLen: 129},
+		},
+		{
+			Name: "Host bits set",
+			Net:  &Net{IP: common.RawBytes{192, 0, 2, 1}, PrefixLen: 24},
+		},
+	}
+	Convey("Net.IPNet", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				ipnet, err := tc.Net.IPNet()
+				SoMsg("err", err, ShouldBeNil)
+				SoMsg("net", ipnet.String(), ShouldEqual, tc.Expect)
+			})
+		}
+		for _, tc := range errCases {
+			Convey(tc.Name, func() {
+				_, err := tc.Net.IPNet()
+				SoMsg("err", err, ShouldNotBeNil)
+			})
+		}
+	})
+}
+
+func TestNewPrefixAnnounce(t *testing.T) {
+	Convey("NewPrefixAnnounce", t, func() {
+		ts := time.Unix(1500000000, 1)
+		_, v4, _ := net.ParseCIDR("192.0.2.0/24")
+		_, v6, _ := net.ParseCIDR("2001:db8::/32")
+		pa := NewPrefixAnnounce(ts, []*net.IPNet{v4, v6})
+		SoMsg("time", pa.Time().Equal(ts), ShouldBeTrue)
+		SoMsg("v4 len", len(pa.Nets[0].IP), ShouldEqual, net.IPv4len)
+		SoMsg("v6 len", len(pa.Nets[1].IP), ShouldEqual, net.IPv6len)
+		nets, err := pa.IPNets()
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("nets", nets, ShouldResemble, []*net.IPNet{v4, v6})
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mgmt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ proto.Cerealizable = (*KeyExchange)(nil)
+
+// KeyExchange carries the ephemeral public key of one side of the handshake
+// that establishes the key for the frames of a session.
+type KeyExchange struct {
+	Session SessionType
+	KeyId   uint16
+	PubKey  common.RawBytes
+	// Timestamp is the creation time in nanoseconds since the Unix epoch.
+	Timestamp uint64
+}
+
+func newKeyExchange(s SessionType, keyId uint16, pubKey common.RawBytes,
+	ts time.Time) *KeyExchange {
+
+	return &KeyExchange{Session: s, KeyId: keyId, PubKey: pubKey, Timestamp: uint64(ts.UnixNano())}
+}
+
+// Time returns the creation time of the message.
+func (k *KeyExchange) Time() time.Time {
+	return time.Unix(0, int64(k.Timestamp))
+}
+
+func (k *KeyExchange) ProtoId() proto.ProtoIdType {
+	return proto.SIGKeyExchange_TypeID
+}
+
+func (k *KeyExchange) String() string {
+	return fmt.Sprintf("Session: %s KeyId: %d PubKey: %s Timestamp: %d",
+		k.Session, k.KeyId, k.PubKey, k.Timestamp)
+}
+
+type KeyExchangeReq struct {
+	*KeyExchange
+}
+
+func NewKeyExchangeReq(s SessionType, keyId uint16, pubKey common.RawBytes,
+	ts time.Time) *KeyExchangeReq {
+
+	return &KeyExchangeReq{newKeyExchange(s, keyId, pubKey, ts)}
+}
+
+type KeyExchangeRep struct {
+	*KeyExchange
+}
+
+func NewKeyExchangeRep(s SessionType, keyId uint16, pubKey common.RawBytes,
+	ts time.Time) *KeyExchangeRep {
+
+	return &KeyExchangeRep{newKeyExchange(s, keyId, pubKey, ts)}
+}
@@ -30,9 +30,12 @@ func (m MsgIdType) String() string {
 
 // union represents the contents of the unnamed capnp union.
 type union struct {
-	Which   proto.SIGCtrl_Which
-	PollReq *PollReq
-	PollRep *PollRep
+	Which          proto.SIGCtrl_Which
+	PollReq        *PollReq
+	PollRep        *PollRep
+	PrefixAnnounce *PrefixAnnounce
+	KeyExchangeReq *KeyExchangeReq
+	KeyExchangeRep *KeyExchangeRep
 }
 
 func (u *union) set(c proto.Cerealizable) error {
@@ -43,6 +46,15 @@ func (u *union) set(c proto.Cerealizable) error {
 	case *PollRep:
 		u.Which = proto.SIGCtrl_Which_pollRep
 		u.PollRep = p
+	case *PrefixAnnounce:
+		u.Which = proto.SIGCtrl_Which_prefixAnnounce
+		u.PrefixAnnounce = p
+	case *KeyExchangeReq:
+		u.Which = proto.SIGCtrl_Which_keyExchangeReq
+		u.KeyExchangeReq = p
+	case *KeyExchangeRep:
+		u.Which = proto.SIGCtrl_Which_keyExchangeRep
+		u.KeyExchangeRep = p
 	default:
 		return common.NewBasicError("Unsupported SIG ctrl union type (set)", nil,
 			"type", common.TypeOf(c))
@@ -56,6 +68,12 @@ func (u *union) get() (proto.Cerealizable, error) {
 		return u.PollReq, nil
 	case proto.SIGCtrl_Which_pollRep:
 		return u.PollRep, nil
+	case proto.SIGCtrl_Which_prefixAnnounce:
+		return u.PrefixAnnounce, nil
+	case proto.SIGCtrl_Which_keyExchangeReq:
+		return u.KeyExchangeReq, nil
+	case proto.SIGCtrl_Which_keyExchangeRep:
+		return u.KeyExchangeRep, nil
 	}
 	return nil, common.NewBasicError("Unsupported SIG ctrl union type (get)", nil,
 		"type", u.Which)
@@ -0,0 +1,107 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/sig/mgmt"
+)
+
+const (
+	// PubKeyLen is the length of the ephemeral public keys.
+	PubKeyLen = 32
+
+	keyLabel = "SIG frame key"
+)
+
+// KeyInfo describes the traffic a key protects. It is bound to the key during
+// the key derivation.
+type KeyInfo struct {
+	// Src is the AS of the SIG that seals the frames, i.e., the initiator of
+	// the key exchange.
+	Src addr.IA
+	// Dst is the AS of the SIG that opens the frames.
+	Dst     addr.IA
+	Session mgmt.SessionType
+	KeyId   uint16
+}
+
+func (i KeyInfo) pack() common.RawBytes {
+	b := make(common.RawBytes, len(keyLabel)+2*addr.IABytes+3)
+	off := copy(b, keyLabel)
+	i.Src.Write(b[off:])
+	off += addr.IABytes
+	i.Dst.Write(b[off:])
+	off += addr.IABytes
+	b[off] = uint8(i.Session)
+	common.Order.PutUint16(b[off+1:], i.KeyId)
+	return b
+}
+
+// Exchange is one side of a key exchange. It holds an ephemeral X25519 key
+// pair, which must only be used for a single exchange.
+type Exchange struct {
+	// PubKey is the ephemeral public key that is sent to the remote SIG.
+	PubKey  common.RawBytes
+	private [32]byte
+}
+
+// NewExchange creates an exchange with a fresh ephemeral key pair.
+func NewExchange() (*Exchange, error) {
+	e := &Exchange{}
+	if _, err := io.ReadFull(rand.Reader, e.private[:]); err != nil {
+		return nil, common.NewBasicError("Unable to generate ephemeral key", err)
+	}
+	var pub [PubKeyLen]byte
+	curve25519.ScalarBaseMult(&pub, &e.private)
+	e.PubKey = pub[:]
+	return e, nil
+}
+
+// Key derives the frame key from the public key of the remote SIG. initiator
+// indicates whether the local SIG initiated the exchange.
+func (e *Exchange) Key(remotePub common.RawBytes, initiator bool, info KeyInfo) (*Key, error) {
+	if len(remotePub) != PubKeyLen {
+		return nil, common.NewBasicError("Invalid public key length", nil,
+			"expected", PubKeyLen, "actual", len(remotePub))
+	}
+	var pub, shared, zero [32]byte
+	copy(pub[:], remotePub)
+	curve25519.ScalarMult(&shared, &e.private, &pub)
+	if subtle.ConstantTimeCompare(shared[:], zero[:]) == 1 {
+		return nil, common.NewBasicError("Invalid public key, low order point", nil)
+	}
+	salt := append(common.RawBytes(nil), e.PubKey...)
+	if initiator {
+		salt = append(salt, remotePub...)
+	} else {
+		salt = append(append(common.RawBytes(nil), remotePub...), e.PubKey...)
+	}
+	secret := make(common.RawBytes, KeyLen)
+	kdf := hkdf.New(sha256.New, shared[:], salt, info.pack())
+	if _, err := io.ReadFull(kdf, secret); err != nil {
+		return nil, common.NewBasicError("Unable to derive key", err)
+	}
+	return NewKey(info.KeyId, secret)
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/sig/mgmt"
+)
+
+// KeyGracePeriod is the time a key is still accepted after it has been
+// replaced by a new key. It covers the frames that are in flight during
+// rekeying.
+const KeyGracePeriod = 5 * time.Second
+
+// Peer identifies the sending side of a session.
+type Peer struct {
+	IA      addr.IA
+	Host    string
+	Session mgmt.SessionType
+}
+
+func NewPeer(ia addr.IA, host addr.HostAddr, session mgmt.SessionType) Peer {
+	return Peer{IA: ia, Host: host.String(), Session: session}
+}
+
+func (p Peer) String() string {
+	return fmt.Sprintf("%s/%s/%s", p.IA, p.Host, p.Session)
+}
+
+// KeyStore contains the keys to open the frames received from remote SIGs.
+type KeyStore struct {
+	mu    sync.RWMutex
+	peers map[Peer]*peerKeys
+}
+
+type peerKeys struct {
+	keys []*Key
+	// lastExchange is the creation time of the last accepted key exchange.
+	lastExchange time.Time
+}
+
+func NewKeyStore() *KeyStore {
+	return &KeyStore{peers: make(map[Peer]*peerKeys)}
+}
+
+// Add adds the key established by a key exchange created at ts. The keys
+// previously added for the peer expire after KeyGracePeriod. An error is
+// returned if the exchange is not newer than the last one of the peer, i.e.,
+// if it is replayed.
+func (s *KeyStore) Add(p Peer, k *Key, ts time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	pk, ok := s.peers[p]
+	if !ok {
+		pk = &peerKeys{}
+		s.peers[p] = pk
+	}
+	if !ts.After(pk.lastExchange) {
+		return common.NewBasicError("Outdated key exchange", nil,
+			"peer", p, "ts", ts, "last", pk.lastExchange)
+	}
+	pk.lastExchange = ts
+	graceExpiry := time.Now().Add(KeyGracePeriod)
+	for _, old := range pk.keys {
+		if old.Expiry.After(graceExpiry) {
+			old.Expiry = graceExpiry
+		}
+	}
+	pk.keys = append(pk.keys, k)
+	return nil
+}
+
+// Get returns the unexpired key with the given id of the peer, or nil if there
+// is no such key.
+func (s *KeyStore) Get(p Peer, id uint16) *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pk, ok := s.peers[p]
+	if !ok {
+		return nil
+	}
+	for i := len(pk.keys) - 1; i >= 0; i-- {
+		if k := pk.keys[i]; k.Id == id && !k.Expired() {
+			return k
+		}
+	}
+	return nil
+}
+
+// expire removes the expired keys. Peers without keys are kept, such that
+// their last exchange time is remembered for the maximum key lifetime.
+func (s *KeyStore) expire() {
+	for p, pk := range s.peers {
+		keys := pk.keys[:0]
+		for _, k := range pk.keys {
+			if !k.Expired() {
+				keys = append(keys, k)
+			}
+		}
+		pk.keys = keys
+		if len(keys) == 0 && time.Since(pk.lastExchange) > MaxKeyLifetime {
+			delete(s.peers, p)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package seal implements the authenticated encryption of SIG frames.
+//
+// The frames a SIG sends in a session are protected with an AES-256-GCM key
+// that is established by a key exchange between the two SIGs. The sending
+// SIG initiates the exchange with a signed request containing an ephemeral
+// X25519 public key, the remote SIG answers with a signed reply containing
+// its own ephemeral public key. Both sides derive the key from the shared
+// secret with HKDF-SHA256. Thus, a key only protects the traffic in one
+// direction of one session.
+//
+// A sealed frame keeps the plain SIG frame header, followed by the key id and
+// the encrypted payload:
+//
+//	0B       1        2        3        4        5        6        7
+//	+--------+--------+--------+--------+--------+--------+--------+--------+
+//	| Sess Id|      Epoch      |    Sequence number       |     Index       |
+//	+--------+--------+--------+--------+--------+--------+--------+--------+
+//	|     Key Id      |  Encrypted payload and authentication tag ...       |
+//	+--------+--------+--------+--------+--------+--------+--------+--------+
+//
+// The header and the key id are authenticated, but not encrypted. The nonce is
+// built from the session id, the epoch and the sequence number. A key never
+// seals two streams with the same epoch, such that a nonce is never reused.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+const (
+	// KeyIdLen is the length of the key id that follows the frame header.
+	KeyIdLen = 2
+	// Overhead is the number of bytes sealing adds to a frame.
+	Overhead = KeyIdLen + tagLen
+	// KeyLen is the length of the frame keys.
+	KeyLen = 32
+	// MaxKeyLifetime is the maximum time a key is used for.
+	MaxKeyLifetime = time.Hour
+	// MaxEpochs is the maximum number of epochs a key seals. It is well below
+	// the number of distinct epochs, such that the remote SIG never confuses
+	// the streams of a key.
+	MaxEpochs = 1 << 15
+
+	// frameHdrLen is the length of the SIG frame header.
+	frameHdrLen = 8
+	tagLen      = 16
+	nonceLen    = 12
+	// hdrLen is the length of the authenticated cleartext of a sealed frame.
+	hdrLen = frameHdrLen + KeyIdLen
+)
+
+// Key seals and opens the frames of one direction of a session.
+type Key struct {
+	// Id identifies the key in the sealed frames.
+	Id uint16
+	// Expiry is the time after which the key must not be used anymore.
+	Expiry time.Time
+	aead   cipher.AEAD
+
+	mu sync.Mutex
+	// epochs contains a bit per epoch that has been claimed for sealing.
+	epochs  [(1 << 16) / 64]uint64
+	nEpochs int
+}
+
+// NewKey creates a key with the given id from the secret. The secret must be
+// KeyLen bytes long.
+func NewKey(id uint16, secret common.RawBytes) (*Key, error) {
+	if len(secret) != KeyLen {
+		return nil, common.NewBasicError("Invalid key length", nil,
+			"expected", KeyLen, "actual", len(secret))
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to initialize cipher", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, common.NewBasicError("Unable to initialize AEAD", err)
+	}
+	return &Key{Id: id, Expiry: time.Now().Add(MaxKeyLifetime), aead: aead}, nil
+}
+
+// Expired returns true if the key must not be used anymore.
+func (k *Key) Expired() bool {
+	return !time.Now().Before(k.Expiry)
+}
+
+// ClaimEpoch reserves an epoch for a new stream of frames sealed with the
+// key. The first unclaimed epoch starting at epoch is returned. An error is
+// returned if the key has sealed MaxEpochs epochs already.
+func (k *Key) ClaimEpoch(epoch uint16) (uint16, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.nEpochs >= MaxEpochs {
+		return 0, common.NewBasicError("Key epochs exhausted", nil, "keyId", k.Id)
+	}
+	for k.epochs[epoch/64]&(1<<(epoch%64)) != 0 {
+		epoch++
+	}
+	k.epochs[epoch/64] |= 1 << (epoch % 64)
+	k.nEpochs++
+	return epoch, nil
+}
+
+func (k *Key) claimed(epoch uint16) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.epochs[epoch/64]&(1<<(epoch%64)) != 0
+}
+
+// Seal appends the sealed frame to dst and returns the resulting slice. The
+// frame must start with the SIG frame header and its epoch must have been
+// claimed with ClaimEpoch. dst and frame must not overlap.
+func (k *Key) Seal(dst, frame common.RawBytes) (common.RawBytes, error) {
+	if len(frame) < frameHdrLen {
+		return nil, common.NewBasicError("Frame too short", nil, "len", len(frame))
+	}
+	if epoch := common.Order.Uint16(frame[1:3]); !k.claimed(epoch) {
+		return nil, common.NewBasicError("Epoch not claimed", nil,
+			"keyId", k.Id, "epoch", epoch)
+	}
+	dst = append(dst, frame[:frameHdrLen]...)
+	dst = append(dst, 0, 0)
+	common.Order.PutUint16(dst[len(dst)-KeyIdLen:], k.Id)
+	hdr := dst[len(dst)-hdrLen:]
+	var nonce [nonceLen]byte
+	makeNonce(&nonce, hdr)
+	return k.aead.Seal(dst, nonce[:], frame[frameHdrLen:], hdr), nil
+}
+
+// Open authenticates and decrypts the sealed frame in place. The returned
+// slice contains the plain frame, i.e., the header followed by the payload,
+// and shares the memory with the sealed frame.
+func (k *Key) Open(sealed common.RawBytes) (common.RawBytes, error) {
+	id, err := KeyId(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if id != k.Id {
+		return nil, common.NewBasicError("Key id mismatch", nil, "expected", k.Id, "actual", id)
+	}
+	var nonce [nonceLen]byte
+	makeNonce(&nonce, sealed)
+	ct := sealed[hdrLen:]
+	pld, err := k.aead.Open(ct[:0], nonce[:], ct, sealed[:hdrLen])
+	if err != nil {
+		return nil, common.NewBasicError("Unable to open frame", err)
+	}
+	n := copy(sealed[frameHdrLen:], pld)
+	return sealed[:frameHdrLen+n], nil
+}
+
+// KeyId returns the id of the key that sealed the frame.
+func KeyId(sealed common.RawBytes) (uint16, error) {
+	if len(sealed) < frameHdrLen+Overhead {
+		return 0, common.NewBasicError("Sealed frame too short", nil, "len", len(sealed))
+	}
+	return common.Order.Uint16(sealed[frameHdrLen:hdrLen]), nil
+}
+
+// makeNonce builds the nonce from the session id, the epoch and the sequence
+// number in the frame header.
+func makeNonce(nonce *[nonceLen]byte, hdr common.RawBytes) {
+	copy(nonce[:], hdr[:6])
+}
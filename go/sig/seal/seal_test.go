@@ -0,0 +1,184 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seal
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var testInfo = KeyInfo{
+	Src:     xtest.MustParseIA("1-ff00:0:110"),
+	Dst:     xtest.MustParseIA("2-ff00:0:220"),
+	Session: 1,
+	KeyId:   7,
+}
+
+// exchangeKeys runs a key exchange and returns the keys of the initiator and
+// the responder.
+func exchangeKeys(info KeyInfo) (*Key, *Key) {
+	init, err := NewExchange()
+	SoMsg("init err", err, ShouldBeNil)
+	resp, err := NewExchange()
+	SoMsg("resp err", err, ShouldBeNil)
+	initKey, err := init.Key(resp.PubKey, true, info)
+	SoMsg("init key err", err, ShouldBeNil)
+	respKey, err := resp.Key(init.PubKey, false, info)
+	SoMsg("resp key err", err, ShouldBeNil)
+	return initKey, respKey
+}
+
+func testFrame(epoch uint16, seq uint32) common.RawBytes {
+	frame := make(common.RawBytes, frameHdrLen+100)
+	frame[0] = 1
+	common.Order.PutUint16(frame[1:3], epoch)
+	common.Order.PutUintN(frame[3:6], uint64(seq), 3)
+	common.Order.PutUint16(frame[6:8], 1)
+	for i := frameHdrLen; i < len(frame); i++ {
+		frame[i] = byte(i)
+	}
+	return frame
+}
+
+func TestExchange(t *testing.T) {
+	Convey("Both sides of an exchange derive the same key", t, func() {
+		initKey, respKey := exchangeKeys(testInfo)
+		SoMsg("id", initKey.Id, ShouldEqual, testInfo.KeyId)
+		epoch, err := initKey.ClaimEpoch(10)
+		SoMsg("claim err", err, ShouldBeNil)
+		frame := testFrame(epoch, 0)
+		sealed, err := initKey.Seal(nil, frame)
+		SoMsg("seal err", err, ShouldBeNil)
+		_, err = respKey.Open(sealed)
+		SoMsg("open err", err, ShouldBeNil)
+	})
+	Convey("The key is bound to the key info", t, func() {
+		init, _ := NewExchange()
+		resp, _ := NewExchange()
+		initKey, _ := init.Key(resp.PubKey, true, testInfo)
+		info := testInfo
+		info.Session = 2
+		respKey, _ := resp.Key(init.PubKey, false, info)
+		epoch, _ := initKey.ClaimEpoch(10)
+		sealed, _ := initKey.Seal(nil, testFrame(epoch, 0))
+		respKey.Id = initKey.Id
+		_, err := respKey.Open(sealed)
+		SoMsg("err", err, ShouldNotBeNil)
+	})
+	Convey("Invalid public keys are rejected", t, func() {
+		e, _ := NewExchange()
+		_, err := e.Key(make(common.RawBytes, PubKeyLen-1), true, testInfo)
+		SoMsg("short", err, ShouldNotBeNil)
+		_, err = e.Key(make(common.RawBytes, PubKeyLen), true, testInfo)
+		SoMsg("zero", err, ShouldNotBeNil)
+	})
+}
+
+func TestSealOpen(t *testing.T) {
+	Convey("Sealed frames", t, func() {
+		initKey, respKey := exchangeKeys(testInfo)
+		epoch, _ := initKey.ClaimEpoch(10)
+		frame := testFrame(epoch, 5)
+		sealed, err := initKey.Seal(nil, frame)
+		SoMsg("seal err", err, ShouldBeNil)
+		SoMsg("len", len(sealed), ShouldEqual, len(frame)+Overhead)
+		SoMsg("hdr", sealed[:frameHdrLen], ShouldResemble, frame[:frameHdrLen])
+		id, err := KeyId(sealed)
+		SoMsg("id err", err, ShouldBeNil)
+		SoMsg("id", id, ShouldEqual, testInfo.KeyId)
+		Convey("are opened in place", func() {
+			plain, err := respKey.Open(sealed)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("frame", plain, ShouldResemble, frame)
+		})
+		Convey("are rejected if the header is modified", func() {
+			sealed[6] ^= 1
+			_, err := respKey.Open(sealed)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("are rejected if the payload is modified", func() {
+			sealed[len(sealed)-1] ^= 1
+			_, err := respKey.Open(sealed)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+		Convey("are rejected if truncated", func() {
+			_, err := respKey.Open(sealed[:frameHdrLen+Overhead-1])
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+	Convey("Frames of unclaimed epochs are not sealed", t, func() {
+		initKey, _ := exchangeKeys(testInfo)
+		_, err := initKey.Seal(nil, testFrame(10, 0))
+		SoMsg("err", err, ShouldNotBeNil)
+	})
+}
+
+func TestClaimEpoch(t *testing.T) {
+	Convey("Epochs are claimed once", t, func() {
+		k, _ := exchangeKeys(testInfo)
+		e, err := k.ClaimEpoch(0xffff)
+		SoMsg("err", err, ShouldBeNil)
+		SoMsg("first", e, ShouldEqual, 0xffff)
+		e, _ = k.ClaimEpoch(0xffff)
+		SoMsg("wrapped", e, ShouldEqual, 0)
+		e, _ = k.ClaimEpoch(0xffff)
+		SoMsg("next", e, ShouldEqual, 1)
+	})
+	Convey("Claiming fails after MaxEpochs", t, func() {
+		k, _ := exchangeKeys(testInfo)
+		for i := 0; i < MaxEpochs; i++ {
+			_, err := k.ClaimEpoch(uint16(i))
+			SoMsg("err", err, ShouldBeNil)
+		}
+		_, err := k.ClaimEpoch(0)
+		SoMsg("exhausted", err, ShouldNotBeNil)
+	})
+}
+
+func TestKeyStore(t *testing.T) {
+	Convey("KeyStore", t, func() {
+		s := NewKeyStore()
+		p := NewPeer(testInfo.Src, addr.HostFromIPStr("192.0.2.1"), testInfo.Session)
+		_, k1 := exchangeKeys(testInfo)
+		ts := time.Now()
+		SoMsg("add", s.Add(p, k1, ts), ShouldBeNil)
+		SoMsg("get", s.Get(p, k1.Id), ShouldEqual, k1)
+		SoMsg("unknown id", s.Get(p, k1.Id+1), ShouldBeNil)
+		other := p
+		other.Session = 2
+		SoMsg("unknown peer", s.Get(other, k1.Id), ShouldBeNil)
+		Convey("rejects replayed exchanges", func() {
+			_, k2 := exchangeKeys(testInfo)
+			SoMsg("err", s.Add(p, k2, ts), ShouldNotBeNil)
+		})
+		Convey("limits the lifetime of replaced keys", func() {
+			info := testInfo
+			info.KeyId++
+			_, k2 := exchangeKeys(info)
+			SoMsg("add", s.Add(p, k2, ts.Add(time.Second)), ShouldBeNil)
+			SoMsg("new", s.Get(p, k2.Id), ShouldEqual, k2)
+			SoMsg("old", s.Get(p, k1.Id), ShouldEqual, k1)
+			SoMsg("grace", k1.Expiry, ShouldHappenBefore, time.Now().Add(KeyGracePeriod+1))
+			k1.Expiry = time.Now()
+			SoMsg("expired", s.Get(p, k1.Id), ShouldBeNil)
+		})
+	})
+}
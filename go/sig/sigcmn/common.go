@@ -23,11 +23,13 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/pathmgr"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/sock/reliable"
 	"github.com/scionproto/scion/go/sig/internal/sigconfig"
 	"github.com/scionproto/scion/go/sig/mgmt"
+	"github.com/scionproto/scion/go/sig/seal"
 )
 
 const (
@@ -47,6 +49,29 @@ var (
 	CtrlConn  snet.Conn
 	MgmtAddr  *mgmt.Addr
 	encapPort uint16
+	// EgressPaths is the maximum number of paths a session spreads its
+	// traffic over.
+	EgressPaths int
+	// Encryption indicates whether the frames exchanged with remote SIGs are
+	// sealed.
+	Encryption bool
+	// RekeyInterval is the interval after which a session establishes a new
+	// key for its frames.
+	RekeyInterval time.Duration
+	// FrameKeys contains the keys to open the frames received from remote
+	// SIGs. It is only set if Encryption is enabled.
+	FrameKeys *seal.KeyStore
+)
+
+var (
+	// Signer signs the SIG ctrl payloads that carry routing or key
+	// information, i.e., the prefix announcements and the key exchanges. It
+	// is nil unless the trust store was initialized with InitTrust.
+	Signer infra.Signer
+	// Verifier verifies the signed SIG ctrl payloads. It must accept the
+	// payloads signed by Signer. It is nil unless the trust store was
+	// initialized with InitTrust.
+	Verifier infra.Verifier
 )
 
 func Init(cfg sigconfig.SigConf, sdCfg env.SciondClient) error {
@@ -55,6 +80,9 @@ func Init(cfg sigconfig.SigConf, sdCfg env.SciondClient) error {
 	Host = addr.HostFromIP(cfg.IP)
 	MgmtAddr = mgmt.NewAddr(Host, cfg.CtrlPort, cfg.EncapPort)
 	encapPort = cfg.EncapPort
+	EgressPaths = cfg.EgressPaths
+	Encryption = cfg.Encryption
+	RekeyInterval = cfg.RekeyInterval.Duration
 
 	// Initialize SCION local networking module
 	err = initSNET(cfg, sdCfg)
@@ -0,0 +1,122 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigcmn
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/infra/disp"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/infra/modules/trust"
+	"github.com/scionproto/scion/go/lib/infra/transport"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/truststorage"
+	"github.com/scionproto/scion/go/proto"
+	"github.com/scionproto/scion/go/sig/internal/sigconfig"
+	"github.com/scionproto/scion/go/sig/seal"
+)
+
+const trustInitTimeout = 5 * time.Second
+
+// InitTrust sets up the trust store that authenticates the prefix
+// announcements and the key exchanges of the frame encryption, and sets Signer
+// and Verifier to a signer that uses the AS signing key and a verifier that is
+// backed by the trust store. The certificates of remote ASes are fetched from
+// the local CS.
+func InitTrust(cfg sigconfig.SigConf, dbConf truststorage.TrustDBConf) error {
+	trustDB, err := dbConf.New()
+	if err != nil {
+		return common.NewBasicError("Unable to initialize trustDB", err)
+	}
+	store, err := trust.NewStore(trustDB, IA, nil, log.Root())
+	if err != nil {
+		return common.NewBasicError("Unable to initialize trust store", err)
+	}
+	certsDir := filepath.Join(cfg.ConfigDir, "certs")
+	if err := store.LoadAuthoritativeTRC(certsDir); err != nil {
+		return common.NewBasicError("Unable to load local TRC", err)
+	}
+	if err := store.LoadAuthoritativeChain(certsDir); err != nil {
+		return common.NewBasicError("Unable to load local certificate chain", err)
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), trustInitTimeout)
+	defer cancelF()
+	meta, err := trust.CreateSignMeta(ctx, IA, trustDB)
+	if err != nil {
+		return err
+	}
+	keys, err := keyconf.LoadWithAlgorithms(filepath.Join(cfg.ConfigDir, "keys"),
+		keyconf.Algorithms{Sign: meta.Algo}, false, false, false, false)
+	if err != nil {
+		return common.NewBasicError("Unable to load signing key", err)
+	}
+	signer, err := trust.NewBasicSigner(keys.SignKey, meta)
+	if err != nil {
+		return err
+	}
+	csAddr, err := localCSAddr(ctx)
+	if err != nil {
+		return common.NewBasicError("Unable to determine address of local CS", err)
+	}
+	laddr := &snet.Addr{IA: IA, Host: &addr.AppAddr{L3: Host, L4: addr.NewL4UDPInfo(cfg.InfraPort)}}
+	conn, err := snet.ListenSCION("udp4", laddr)
+	if err != nil {
+		return common.NewBasicError("Unable to create infra socket", err)
+	}
+	msgr := messenger.New(&messenger.Config{
+		IA: IA,
+		Dispatcher: disp.New(
+			transport.NewPacketTransport(conn),
+			messenger.DefaultAdapter,
+			log.Root(),
+		),
+		TrustStore: store,
+		AddressRewriter: &messenger.AddressRewriter{
+			Router: &snet.BaseRouter{IA: IA, PathResolver: PathMgr},
+		},
+	})
+	store.SetMessenger(msgr)
+	Signer = signer
+	Verifier = store.NewVerifier().WithServer(csAddr)
+	FrameKeys = seal.NewKeyStore()
+	return nil
+}
+
+// localCSAddr returns the address of a CS in the local AS, as reported by
+// sciond.
+func localCSAddr(ctx context.Context) (*snet.Addr, error) {
+	svcTypes := []proto.ServiceType{proto.ServiceType_cs}
+	reply, err := snet.DefNetwork.Sciond().SVCInfo(ctx, svcTypes)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply.Entries) == 0 || len(reply.Entries[0].HostInfos) == 0 {
+		return nil, common.NewBasicError("No CS found", nil)
+	}
+	hostInfo := reply.Entries[0].HostInfos[0]
+	return &snet.Addr{
+		IA: IA,
+		Host: &addr.AppAddr{
+			L3: hostInfo.Host(),
+			L4: addr.NewL4UDPInfo(hostInfo.Port),
+		},
+	}, nil
+}
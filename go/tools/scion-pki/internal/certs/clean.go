@@ -0,0 +1,63 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certs
+
+import (
+	"os"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func runCleanCert(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for _, ases := range asMap {
+		for _, ia := range ases {
+			if err := cleanChains(ia); err != nil {
+				pkicmn.ErrorAndExit("Error cleaning certs for %s: %s\n", ia, err)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// cleanChains removes the certificate chains of the AS in the output
+// directory. If keepLatest is set, the chain with the highest version is kept.
+func cleanChains(ia addr.IA) error {
+	files, err := loadChains(ia)
+	if err != nil {
+		return common.NewBasicError("Error loading certs", err)
+	}
+	if keepLatest && len(files) > 0 {
+		files = files[:len(files)-1]
+	}
+	if len(files) == 0 {
+		pkicmn.QuietPrint("No certs to remove for %s\n", ia)
+		return nil
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	pkicmn.QuietPrint("Cleaning certs for %s\n", ia)
+	if err := pkicmn.RemoveFiles(paths, dryRun); err != nil {
+		return common.NewBasicError("Error removing certs", err)
+	}
+	return nil
+}
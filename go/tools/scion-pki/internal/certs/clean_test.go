@@ -0,0 +1,81 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func TestCleanChains(t *testing.T) {
+	Convey("cleanChains removes the chains of the AS", t, func() {
+		cleanF := setupPKI(t)
+		defer cleanF()
+		xtest.FailOnErr(t, renewCert(leafIA, false))
+		xtest.FailOnErr(t, renewCert(leafIA, false))
+		certsDir := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, leafIA), pkicmn.CertsDir)
+		xtest.FailOnErr(t, ioutil.WriteFile(filepath.Join(certsDir, "README"), nil, 0644))
+		all := []string{"ISD1-ASff00_0_111-V1.crt", "ISD1-ASff00_0_111-V2.crt",
+			"ISD1-ASff00_0_111-V3.crt", "README"}
+
+		Convey("Dry-run keeps all files", func() {
+			dryRun = true
+			defer func() { dryRun = false }()
+			SoMsg("err", cleanChains(leafIA), ShouldBeNil)
+			SoMsg("files", listFiles(t, certsDir), ShouldResemble, all)
+		})
+		Convey("All chains are removed", func() {
+			SoMsg("err", cleanChains(leafIA), ShouldBeNil)
+			SoMsg("files", listFiles(t, certsDir), ShouldResemble, []string{"README"})
+		})
+		Convey("The latest chain is kept", func() {
+			keepLatest = true
+			defer func() { keepLatest = false }()
+			SoMsg("err", cleanChains(leafIA), ShouldBeNil)
+			SoMsg("files", listFiles(t, certsDir), ShouldResemble,
+				[]string{"ISD1-ASff00_0_111-V3.crt", "README"})
+			SoMsg("again", cleanChains(leafIA), ShouldBeNil)
+			SoMsg("files again", listFiles(t, certsDir), ShouldResemble,
+				[]string{"ISD1-ASff00_0_111-V3.crt", "README"})
+		})
+		Convey("Other ASes are not affected", func() {
+			SoMsg("err", cleanChains(leafIA), ShouldBeNil)
+			SoMsg("core", listFiles(t, filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, coreIA),
+				pkicmn.CertsDir)), ShouldResemble, []string{chainName(coreIA, 1)})
+		})
+	})
+}
+
+func chainName(ia addr.IA, version uint64) string {
+	return fmt.Sprintf(pkicmn.CertNameFmt, ia.I, ia.A.FileFmt(), version)
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	xtest.FailOnErr(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
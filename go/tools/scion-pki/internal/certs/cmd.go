@@ -15,12 +15,15 @@
 package certs
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
-var verify bool
+var (
+	verify     bool
+	rotateKeys bool
+	dryRun     bool
+	keepLatest bool
+)
 
 var Cmd = &cobra.Command{
 	Use:   "certs",
@@ -77,12 +80,15 @@ The AS Certificate and Issuer Certificate sections can contain the following val
 		with the subject’s public/private key
 	SignAlgorithm (ed25519) [optional]
 		cryptographic algorithm that must be used to sign/verify a message with
-		the subject’s private/public key.
+		the subject’s private/public key. Supported are ed25519, ecdsap256 and
+		ecdsap384.
 The Key Algorithms section that can contain following values
 	Online (ed25519) [optional]
 		cryptographic algorithm that must be used as signing algorithm by online key
+		(ed25519, ecdsap256 or ecdsap384)
 	Offline (ed25519) [optional]
 		cryptographic algorithm that must be used as signing algorithm by offline key
+		(ed25519, ecdsap256 or ecdsap384)
 `,
 }
 
@@ -97,17 +103,34 @@ var genCerts = &cobra.Command{
 
 var renewCerts = &cobra.Command{
 	Use:   "renew",
-	Short: "Renew the existing certificates [NOT IMPLEMENTED]",
+	Short: "Renew the existing certificates",
+	Long: `
+'renew' issues the next version of the certificate chains of the selected ASes. The
+new version is one higher than the newest existing chain, or the version in as.ini if
+that is higher. All other parameters are taken from as.ini, except that the certificates
+are issued now and their validity is shortened if they would outlive their issuer. The
+issuer certificates of core ASes are renewed as well, before the chains of the non-core
+ASes are issued.
+
+With --rotate-keys, fresh signing and decryption keys (and issuer signing keys for core
+ASes) are generated before the certificates are issued.
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("renew is not implemented yet")
+		runRenewCert(args)
 	},
 }
 
 var cleanCerts = &cobra.Command{
 	Use:   "clean",
-	Short: "Clean all the exisiting certificates. [NOT IMPLEMENTED]",
+	Short: "Clean all the existing certificates",
+	Long: `
+'clean' removes the certificate chains of the selected ASes. Use --keep-latest to keep
+the newest chain of each AS and --dry-run to list the chains that would be removed.
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("clean is not implemented yet")
+		runCleanCert(args)
 	},
 }
 
@@ -123,6 +146,12 @@ var verifyCert = &cobra.Command{
 func init() {
 	Cmd.PersistentFlags().BoolVarP(&verify, "verify", "v", true,
 		"verify the generated/renewed certificates")
+	renewCerts.Flags().BoolVarP(&rotateKeys, "rotate-keys", "k", false,
+		"generate fresh keys before renewing the certificates")
+	cleanCerts.Flags().BoolVarP(&dryRun, "dry-run", "n", false,
+		"only list the certificates that would be removed")
+	cleanCerts.Flags().BoolVarP(&keepLatest, "keep-latest", "l", false,
+		"keep the newest certificate chain of each AS")
 	Cmd.AddCommand(genCerts)
 	Cmd.AddCommand(renewCerts)
 	Cmd.AddCommand(cleanCerts)
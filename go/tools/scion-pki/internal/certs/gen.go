@@ -19,14 +19,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
-	"golang.org/x/crypto/curve25519"
-	"golang.org/x/crypto/ed25519"
-
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/scrypto"
 	"github.com/scionproto/scion/go/lib/scrypto/cert"
 	"github.com/scionproto/scion/go/lib/scrypto/trc"
 	"github.com/scionproto/scion/go/lib/util"
@@ -113,9 +112,15 @@ func genCert(ia addr.IA, isIssuer bool) error {
 	if err != nil {
 		return common.NewBasicError("Error generating cert", err, "subject", ia)
 	}
+	return writeChain(chain, ia)
+}
+
+// writeChain writes the chain to the certs directory of the AS in the output
+// directory.
+func writeChain(chain *cert.Chain, ia addr.IA) error {
 	// Check if out directory exists and if not create it.
-	out := filepath.Join(outDir, pkicmn.CertsDir)
-	if _, err = os.Stat(out); os.IsNotExist(err) {
+	out := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.CertsDir)
+	if _, err := os.Stat(out); os.IsNotExist(err) {
 		if err = os.MkdirAll(out, 0755); err != nil {
 			return common.NewBasicError("Cannot create output dir", err, "dir", out)
 		}
@@ -125,6 +130,7 @@ func genCert(ia addr.IA, isIssuer bool) error {
 	if err != nil {
 		return common.NewBasicError("Error json-encoding cert", err, "subject", ia)
 	}
+	fname := fmt.Sprintf(pkicmn.CertNameFmt, ia.I, ia.A.FileFmt(), chain.Leaf.Version)
 	if err = pkicmn.WriteToFile(raw, filepath.Join(out, fname), 0644); err != nil {
 		return common.NewBasicError("Error writing cert", err, "subject", ia)
 	}
@@ -142,14 +148,6 @@ func genIssuerCert(issuerConf *conf.IssuerCert, s addr.IA) (*cert.Certificate, e
 	if c.Comment == "" {
 		c.Comment = fmt.Sprintf("Issuer Certificate for %s version %d.", c.Subject, c.Version)
 	}
-	issuerKeyPath := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, c.Issuer), pkicmn.KeysDir,
-		keyconf.OnKeyFile)
-	// Load online root key to sign the certificate.
-	issuerKey, err := keyconf.LoadKey(issuerKeyPath, issuerConf.SignAlgorithm)
-	if err != nil {
-		return nil, err
-	}
-	// Sign the certificate.
 	currTrcPath := filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, s.I), pkicmn.TRCsDir,
 		fmt.Sprintf(pkicmn.TrcNameFmt, s.I, c.TRCVersion))
 	currTrc, err := trc.TRCFromFile(currTrcPath, false)
@@ -161,6 +159,14 @@ func genIssuerCert(issuerConf *conf.IssuerCert, s addr.IA) (*cert.Certificate, e
 		return nil, common.NewBasicError("Issuer of IssuerCert not found in Core ASes of TRC",
 			nil, "issuer", s)
 	}
+	issuerKeyPath := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, c.Issuer), pkicmn.KeysDir,
+		keyconf.OnKeyFile)
+	// Load online root key to sign the certificate.
+	issuerKey, err := keyconf.LoadKey(issuerKeyPath, coreAs.OnlineKeyAlg)
+	if err != nil {
+		return nil, err
+	}
+	// Sign the certificate.
 	if err = c.Sign(issuerKey, coreAs.OnlineKeyAlg); err != nil {
 		return nil, err
 	}
@@ -217,14 +223,18 @@ func genCertCommon(bc *conf.BaseCert, s addr.IA, signKeyFname string) (*cert.Cer
 	if err != nil {
 		return nil, err
 	}
-	signPub := common.RawBytes(ed25519.PrivateKey(signKey).Public().(ed25519.PublicKey))
+	signPub, err := scrypto.PubKey(signKey, bc.SignAlgorithm)
+	if err != nil {
+		return nil, err
+	}
 	decKey, err := keyconf.LoadKey(filepath.Join(keyDir, keyconf.DecKeyFile), bc.EncAlgorithm)
 	if err != nil {
 		return nil, err
 	}
-	var decKeyFixed, decPub [32]byte
-	copy(decKeyFixed[:], decKey)
-	curve25519.ScalarBaseMult(&decPub, &decKeyFixed)
+	decPub, err := scrypto.PubKey(decKey, bc.EncAlgorithm)
+	if err != nil {
+		return nil, err
+	}
 	// Determine issuingTime and calculate expiration time from validity.
 	issuingTime := bc.IssuingTime
 	if issuingTime == 0 {
@@ -235,7 +245,7 @@ func genCertCommon(bc *conf.BaseCert, s addr.IA, signKeyFname string) (*cert.Cer
 		Comment:        bc.Comment,
 		SubjectSignKey: signPub,
 		SignAlgorithm:  bc.SignAlgorithm,
-		SubjectEncKey:  decPub[:],
+		SubjectEncKey:  decPub,
 		EncAlgorithm:   bc.EncAlgorithm,
 		Subject:        s,
 		IssuingTime:    issuingTime,
@@ -247,12 +257,34 @@ func genCertCommon(bc *conf.BaseCert, s addr.IA, signKeyFname string) (*cert.Cer
 
 // getIssuerCert returns the newest issuer certificate (if any).
 func getIssuerCert(issuer addr.IA) (*cert.Certificate, error) {
-	fnames, err := filepath.Glob(fmt.Sprintf("%s/*.crt",
-		filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, issuer), pkicmn.CertsDir)))
+	files, err := loadChains(issuer)
 	if err != nil {
 		return nil, err
 	}
 	var issuerCert *cert.Certificate
+	for _, f := range files {
+		if issuerCert == nil || f.chain.Issuer.Version > issuerCert.Version {
+			issuerCert = f.chain.Issuer
+		}
+	}
+	return issuerCert, nil
+}
+
+// chainFile is a certificate chain stored on disk.
+type chainFile struct {
+	path  string
+	chain *cert.Chain
+}
+
+// loadChains loads the certificate chains of the AS in the output directory,
+// sorted by ascending leaf version.
+func loadChains(ia addr.IA) ([]chainFile, error) {
+	fnames, err := filepath.Glob(fmt.Sprintf("%s/*.crt",
+		filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.CertsDir)))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]chainFile, 0, len(fnames))
 	for _, fname := range fnames {
 		raw, err := ioutil.ReadFile(fname)
 		if err != nil {
@@ -260,11 +292,12 @@ func getIssuerCert(issuer addr.IA) (*cert.Certificate, error) {
 		}
 		chain, err := cert.ChainFromRaw(raw, false)
 		if err != nil {
-			return nil, err
-		}
-		if issuerCert == nil || chain.Issuer.Version > issuerCert.Version {
-			issuerCert = chain.Issuer
+			return nil, common.NewBasicError("Unable to parse chain", err, "path", fname)
 		}
+		files = append(files, chainFile{path: fname, chain: chain})
 	}
-	return issuerCert, nil
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].chain.Leaf.Version < files[j].chain.Leaf.Version
+	})
+	return files, nil
 }
@@ -0,0 +1,177 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/conf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/keys"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func runRenewCert(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for isd, ases := range asMap {
+		iconf, err := conf.LoadIsdConf(pkicmn.GetIsdPath(pkicmn.RootDir, isd))
+		if err != nil {
+			pkicmn.ErrorAndExit("Error reading isd.ini: %s\n", err)
+		}
+		// Process cores first, such that the non-cores are issued by the
+		// renewed issuer certificates.
+		for _, ia := range ases {
+			if !pkicmn.Contains(iconf.Trc.CoreIAs, ia) {
+				continue
+			}
+			if err = renewCert(ia, true); err != nil {
+				pkicmn.ErrorAndExit("Error renewing cert for %s: %s\n", ia, err)
+			}
+		}
+		for _, ia := range ases {
+			if pkicmn.Contains(iconf.Trc.CoreIAs, ia) {
+				continue
+			}
+			if err = renewCert(ia, false); err != nil {
+				pkicmn.ErrorAndExit("Error renewing cert for %s: %s\n", ia, err)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// renewCert issues the next version of the certificate chain of the AS. The
+// new version is one higher than the newest existing chain, or the version in
+// as.ini if that is higher. All other parameters are taken from as.ini, except
+// that the certificates are issued now and do not expire after their issuer
+// (the TRC in case of issuer certificates). If the AS is an issuer, its issuer
+// certificate is renewed as well.
+func renewCert(ia addr.IA, isIssuer bool) error {
+	confDir := pkicmn.GetAsPath(pkicmn.RootDir, ia)
+	// Check that as.ini exists, otherwise skip directory.
+	cpath := filepath.Join(confDir, conf.AsConfFileName)
+	if _, err := os.Stat(cpath); os.IsNotExist(err) {
+		pkicmn.QuietPrint("Skipping %s. Missing %s\n", confDir, conf.AsConfFileName)
+		return nil
+	}
+	a, err := conf.LoadAsConf(confDir)
+	if err != nil {
+		return common.NewBasicError("Error loading as.ini", err, "path", cpath)
+	}
+	if isIssuer && a.IssuerCert == nil {
+		return common.NewBasicError(fmt.Sprintf("'%s' section missing from as.ini",
+			conf.IssuerSectionName), nil, "path", cpath)
+	}
+	files, err := loadChains(ia)
+	if err != nil {
+		return common.NewBasicError("Error loading existing certs", err, "subject", ia)
+	}
+	if len(files) == 0 {
+		return common.NewBasicError("No existing cert to renew, use 'certs gen'", nil,
+			"subject", ia)
+	}
+	latest := files[len(files)-1].chain
+	if rotateKeys {
+		if err := keys.RotateCertKeys(ia, isIssuer); err != nil {
+			return common.NewBasicError("Error rotating keys", err, "subject", ia)
+		}
+	}
+	pkicmn.QuietPrint("Renewing Certificate Chain for %s\n", ia)
+	now := util.TimeToSecs(time.Now())
+	var issuerCert *cert.Certificate
+	if isIssuer {
+		t, err := loadTRC(ia, a.IssuerCert.TRCVersion)
+		if err != nil {
+			return common.NewBasicError("Error loading TRC", err, "subject", ia)
+		}
+		version := nextVersion(a.IssuerCert.Version, newestIssuerVersion(files))
+		bc, err := renewConf(a.IssuerCert.BaseCert, version, now, t.ExpirationTime)
+		if err != nil {
+			return err
+		}
+		issuerCert, err = genIssuerCert(&conf.IssuerCert{BaseCert: bc}, ia)
+		if err != nil {
+			return common.NewBasicError("Error generating issuer cert", err, "subject", ia)
+		}
+	} else {
+		issuerCert, err = getIssuerCert(a.AsCert.IssuerIA)
+		if err != nil {
+			return common.NewBasicError("Error loading issuer cert", err, "subject", ia)
+		}
+		if issuerCert == nil {
+			return common.NewBasicError("Issuer cert not found", nil, "issuer", a.AsCert.Issuer)
+		}
+	}
+	version := nextVersion(a.AsCert.Version, latest.Leaf.Version)
+	bc, err := renewConf(a.AsCert.BaseCert, version, now, issuerCert.ExpirationTime)
+	if err != nil {
+		return err
+	}
+	asConf := &conf.AsCert{Issuer: a.AsCert.Issuer, IssuerIA: a.AsCert.IssuerIA, BaseCert: bc}
+	chain, err := genASCert(asConf, ia, issuerCert)
+	if err != nil {
+		return common.NewBasicError("Error generating cert", err, "subject", ia)
+	}
+	return writeChain(chain, ia)
+}
+
+// nextVersion returns the version following the newest existing version, or
+// the configured version if it is higher.
+func nextVersion(configured, newest uint64) uint64 {
+	if configured > newest {
+		return configured
+	}
+	return newest + 1
+}
+
+func newestIssuerVersion(files []chainFile) uint64 {
+	var version uint64
+	for _, f := range files {
+		if f.chain.Issuer.Version > version {
+			version = f.chain.Issuer.Version
+		}
+	}
+	return version
+}
+
+// renewConf returns a copy of bc for a certificate with the version that is
+// issued at now. The validity is shortened, such that the certificate does not
+// expire after maxExp.
+func renewConf(bc *conf.BaseCert, version uint64, now,
+	maxExp uint32) (*conf.BaseCert, error) {
+
+	if maxExp <= now {
+		return nil, common.NewBasicError("Issuer already expired", nil,
+			"expiration", util.TimeToString(util.SecsToTime(maxExp)))
+	}
+	c := *bc
+	c.Version = version
+	c.IssuingTime = now
+	if now+uint32(c.Validity.Seconds()) > maxExp {
+		c.Validity = time.Duration(maxExp-now) * time.Second
+		pkicmn.QuietPrint("Validity of version %d shortened to expire with its issuer at %s\n",
+			version, util.TimeToString(util.SecsToTime(maxExp)))
+	}
+	return &c, nil
+}
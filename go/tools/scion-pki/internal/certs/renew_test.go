@@ -0,0 +1,237 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certs
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/conf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/keys"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+var (
+	coreIA = xtest.MustParseIA("1-ff00:0:110")
+	leafIA = xtest.MustParseIA("1-ff00:0:111")
+)
+
+const (
+	trcValidity = 10 * 24 * time.Hour
+
+	coreAsIniFmt = `[AS Certificate]
+Issuer = 1-ff00:0:110
+TRCVersion = 1
+Version = 1
+Validity = %s
+[Issuer Certificate]
+TRCVersion = 1
+Version = 1
+Validity = %s
+[Key Algorithms]
+Online = ed25519
+Offline = ed25519
+`
+	leafAsIniFmt = `[AS Certificate]
+Issuer = 1-ff00:0:110
+TRCVersion = 1
+Version = 1
+Validity = %s
+`
+)
+
+// setupPKI creates the TRC, the keys and the first certificate chains of the
+// core AS coreIA and the non-core AS leafIA in a temporary directory, which
+// is used as root and output directory. Afterwards, the configured validities
+// are extended beyond the validity of the issuers, such that renewing has to
+// shorten them.
+func setupPKI(t *testing.T) func() {
+	dir, cleanF := xtest.MustTempDir("", "certs")
+	pkicmn.RootDir, pkicmn.OutDir, pkicmn.Quiet = dir, dir, true
+	writeAsIni(t, coreIA, fmt.Sprintf(coreAsIniFmt, "5d", "8d"))
+	writeAsIni(t, leafIA, fmt.Sprintf(leafAsIniFmt, "5d"))
+	xtest.FailOnErr(t, keys.RotateCertKeys(coreIA, true))
+	xtest.FailOnErr(t, keys.RotateCertKeys(leafIA, false))
+	writeTRC(t, writeOnlineKey(t, coreIA))
+	xtest.FailOnErr(t, genCert(coreIA, true))
+	xtest.FailOnErr(t, genCert(leafIA, false))
+	writeAsIni(t, coreIA, fmt.Sprintf(coreAsIniFmt, "30d", "20d"))
+	writeAsIni(t, leafIA, fmt.Sprintf(leafAsIniFmt, "30d"))
+	return cleanF
+}
+
+func writeAsIni(t *testing.T, ia addr.IA, content string) {
+	dir := pkicmn.GetAsPath(pkicmn.RootDir, ia)
+	xtest.FailOnErr(t, os.MkdirAll(dir, 0755))
+	err := ioutil.WriteFile(filepath.Join(dir, conf.AsConfFileName), []byte(content), 0644)
+	xtest.FailOnErr(t, err)
+}
+
+// writeOnlineKey writes a new online root key of the core AS and returns it.
+func writeOnlineKey(t *testing.T, ia addr.IA) ed25519.PrivateKey {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	xtest.FailOnErr(t, err)
+	file := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir,
+		keyconf.OnKeyFile)
+	raw := base64.StdEncoding.EncodeToString(priv.Seed())
+	xtest.FailOnErr(t, ioutil.WriteFile(file, []byte(raw), 0600))
+	key, err := keyconf.LoadKey(file, scrypto.Ed25519)
+	xtest.FailOnErr(t, err)
+	return ed25519.PrivateKey(key)
+}
+
+// writeTRC writes the base TRC of ISD 1 with the core AS coreIA, signed with
+// its online key.
+func writeTRC(t *testing.T, onlineKey ed25519.PrivateKey) {
+	pub := common.RawBytes(onlineKey.Public().(ed25519.PublicKey))
+	now := util.TimeToSecs(time.Now())
+	base := &trc.TRC{
+		CreationTime:   now,
+		ExpirationTime: now + uint32(trcValidity.Seconds()),
+		ISD:            coreIA.I,
+		Version:        1,
+		QuorumTRC:      1,
+		CoreASes: map[addr.IA]*trc.CoreAS{
+			coreIA: {
+				OnlineKey:     pub,
+				OnlineKeyAlg:  scrypto.Ed25519,
+				OfflineKey:    pub,
+				OfflineKeyAlg: scrypto.Ed25519,
+			},
+		},
+		Signatures: make(map[string]common.RawBytes),
+		RAINS:      &trc.Rains{},
+		RootCAs:    make(map[string]*trc.RootCA),
+		CertLogs:   make(map[string]*trc.CertLog),
+	}
+	xtest.FailOnErr(t, base.Sign(coreIA.String(), common.RawBytes(onlineKey),
+		scrypto.Ed25519))
+	raw, err := base.JSON(true)
+	xtest.FailOnErr(t, err)
+	dir := filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, coreIA.I), pkicmn.TRCsDir)
+	xtest.FailOnErr(t, os.MkdirAll(dir, 0755))
+	fname := fmt.Sprintf(pkicmn.TrcNameFmt, coreIA.I, base.Version)
+	xtest.FailOnErr(t, ioutil.WriteFile(filepath.Join(dir, fname), raw, 0644))
+}
+
+func loadTestChains(t *testing.T, ia addr.IA) []chainFile {
+	files, err := loadChains(ia)
+	xtest.FailOnErr(t, err)
+	return files
+}
+
+func TestRenewCert(t *testing.T) {
+	Convey("renewCert issues the next version of the chain", t, func() {
+		cleanF := setupPKI(t)
+		defer cleanF()
+		base, err := loadTRC(coreIA, 1)
+		xtest.FailOnErr(t, err)
+
+		Convey("Non-core AS", func() {
+			SoMsg("err", renewCert(leafIA, false), ShouldBeNil)
+			files := loadTestChains(t, leafIA)
+			SoMsg("chains", len(files), ShouldEqual, 2)
+			prev, chain := files[0].chain, files[1].chain
+			SoMsg("version", chain.Leaf.Version, ShouldEqual, 2)
+			SoMsg("issuer", chain.Issuer.Version, ShouldEqual, 1)
+			SoMsg("verify", chain.Verify(leafIA, base), ShouldBeNil)
+			SoMsg("keys kept", chain.Leaf.SubjectSignKey, ShouldResemble,
+				prev.Leaf.SubjectSignKey)
+			// The validity of 30d is shortened to the validity of the issuer.
+			SoMsg("expiration", chain.Leaf.ExpirationTime, ShouldEqual,
+				chain.Issuer.ExpirationTime)
+		})
+		Convey("Core AS", func() {
+			SoMsg("err", renewCert(coreIA, true), ShouldBeNil)
+			files := loadTestChains(t, coreIA)
+			SoMsg("chains", len(files), ShouldEqual, 2)
+			chain := files[1].chain
+			SoMsg("version", chain.Leaf.Version, ShouldEqual, 2)
+			SoMsg("issuer", chain.Issuer.Version, ShouldEqual, 2)
+			SoMsg("verify", chain.Verify(coreIA, base), ShouldBeNil)
+			// The validity of 20d is shortened to the validity of the TRC.
+			SoMsg("expiration", chain.Issuer.ExpirationTime, ShouldEqual,
+				base.ExpirationTime)
+			Convey("Non-core ASes are issued by the renewed issuer certificate", func() {
+				SoMsg("err", renewCert(leafIA, false), ShouldBeNil)
+				files := loadTestChains(t, leafIA)
+				SoMsg("issuer", files[len(files)-1].chain.Issuer.Version, ShouldEqual, 2)
+			})
+		})
+		Convey("Rotated keys", func() {
+			rotateKeys = true
+			defer func() { rotateKeys = false }()
+			SoMsg("err", renewCert(leafIA, false), ShouldBeNil)
+			files := loadTestChains(t, leafIA)
+			SoMsg("new key", files[1].chain.Leaf.SubjectSignKey, ShouldNotResemble,
+				files[0].chain.Leaf.SubjectSignKey)
+		})
+		Convey("AS without chain", func() {
+			other := xtest.MustParseIA("1-ff00:0:112")
+			writeAsIni(t, other, fmt.Sprintf(leafAsIniFmt, "5d"))
+			SoMsg("err", renewCert(other, false), ShouldNotBeNil)
+		})
+		Convey("AS without as.ini is skipped", func() {
+			SoMsg("err", renewCert(xtest.MustParseIA("1-ff00:0:113"), false), ShouldBeNil)
+		})
+	})
+}
+
+func TestNextVersion(t *testing.T) {
+	Convey("nextVersion", t, func() {
+		SoMsg("newest", nextVersion(1, 3), ShouldEqual, 4)
+		SoMsg("configured", nextVersion(5, 3), ShouldEqual, 5)
+		SoMsg("equal", nextVersion(3, 3), ShouldEqual, 4)
+	})
+}
+
+func TestRenewConf(t *testing.T) {
+	Convey("renewConf", t, func() {
+		bc := &conf.BaseCert{Version: 1, Validity: time.Hour}
+		Convey("Validity is kept if the issuer expires later", func() {
+			c, err := renewConf(bc, 2, 1000, 1000+7200)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("version", c.Version, ShouldEqual, 2)
+			SoMsg("issuing time", c.IssuingTime, ShouldEqual, 1000)
+			SoMsg("validity", c.Validity, ShouldEqual, time.Hour)
+			SoMsg("original", bc.Version, ShouldEqual, 1)
+		})
+		Convey("Validity is shortened to the expiration of the issuer", func() {
+			c, err := renewConf(bc, 2, 1000, 1000+60)
+			SoMsg("err", err, ShouldBeNil)
+			SoMsg("validity", c.Validity, ShouldEqual, time.Minute)
+		})
+		Convey("Expired issuer", func() {
+			_, err := renewConf(bc, 2, 1000, 1000)
+			SoMsg("err", err, ShouldNotBeNil)
+		})
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/scionproto/scion/go/tools/scion-pki/internal/certs"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/inspect"
 	"github.com/scionproto/scion/go/tools/scion-pki/internal/keys"
 	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
 	"github.com/scionproto/scion/go/tools/scion-pki/internal/tmpl"
@@ -106,5 +107,6 @@ func init() {
 	RootCmd.AddCommand(version.Cmd)
 	RootCmd.AddCommand(trc.Cmd)
 	RootCmd.AddCommand(tmpl.Cmd)
+	RootCmd.AddCommand(inspect.Cmd)
 	RootCmd.AddCommand(autoCompleteCmd)
 }
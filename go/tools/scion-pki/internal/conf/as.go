@@ -47,7 +47,7 @@ const (
 )
 
 var (
-	validSignAlgorithms = []string{scrypto.Ed25519}
+	validSignAlgorithms = []string{scrypto.Ed25519, scrypto.ECDSAP256, scrypto.ECDSAP384}
 	validEncAlgorithms  = []string{scrypto.Curve25519xSalsa20Poly1305}
 )
 
@@ -0,0 +1,62 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	trcFiles []string
+	jsonOut  bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "inspect [flags] <file>...",
+	Short: "Inspect and verify certificate chains and TRCs",
+	Long: `
+'inspect' prints the content of certificate chain and TRC files in a human readable
+form and validates them. The file type is detected automatically. The following
+checks are performed:
+
+Certificate chains:
+	- the leaf and issuer certificates are valid at the current time
+	- the key algorithms are supported and the keys have the correct size
+	- the chain is signed by the core AS keys of the TRC referenced by the
+	  issuer certificate
+TRCs:
+	- the TRC is active, i.e., it is valid at the current time and not superseded
+	  by a newer TRC whose grace period has passed
+	- the key algorithms of the core ASes are supported
+	- a base TRC (version 1) is signed by a quorum of its own core ASes, an updated
+	  TRC is a valid successor of its predecessor and is signed by a quorum of the
+	  core ASes in the predecessor
+	- there are no gaps between the versions of the known TRCs
+
+TRCs are looked up among the inspected files, the files passed with --trc and in
+<root>/ISDx/trcs. The exit status is 2 if any check fails.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runInspect(args)
+	},
+}
+
+func init() {
+	Cmd.Flags().StringSliceVarP(&trcFiles, "trc", "t", nil,
+		"additional TRC files used for verification")
+	Cmd.Flags().BoolVarP(&jsonOut, "json", "j", false,
+		"print the reports as JSON")
+}
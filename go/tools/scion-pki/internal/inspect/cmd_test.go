@@ -0,0 +1,36 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestCmd(t *testing.T) {
+	Convey("Cmd", t, func() {
+		SoMsg("no args", Cmd.Args(Cmd, nil), ShouldNotBeNil)
+		SoMsg("args", Cmd.Args(Cmd, []string{"ISD1-V1.trc"}), ShouldBeNil)
+		xtest.FailOnErr(t, Cmd.ParseFlags([]string{"-t", "a.trc,b.trc", "--json"}))
+		defer func() {
+			trcFiles, jsonOut = nil, false
+		}()
+		SoMsg("trc", trcFiles, ShouldResemble, []string{"a.trc", "b.trc"})
+		SoMsg("json", jsonOut, ShouldBeTrue)
+	})
+}
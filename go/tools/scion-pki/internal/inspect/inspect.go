@@ -0,0 +1,348 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+const (
+	typeChain   = "chain"
+	typeTRC     = "trc"
+	typeUnknown = "unknown"
+)
+
+// signKeySizes and encKeySizes contain the supported key algorithms and the
+// size of their public keys.
+var (
+	signKeySizes = map[string]int{
+		scrypto.Ed25519:   ed25519.PublicKeySize,
+		scrypto.ECDSAP256: scrypto.ECDSAP256PubKeySize,
+		scrypto.ECDSAP384: scrypto.ECDSAP384PubKeySize,
+	}
+	encKeySizes = map[string]int{
+		scrypto.Curve25519xSalsa20Poly1305: scrypto.NaClBoxKeySize,
+	}
+)
+
+func runInspect(args []string) {
+	pool := make(trcPool)
+	for _, path := range trcFiles {
+		t, err := trc.TRCFromFile(path, false)
+		if err != nil {
+			pkicmn.ErrorAndExit("Error loading TRC %s: %s\n", path, err)
+		}
+		pool.add(t)
+	}
+	reports := make([]*report, 0, len(args))
+	for _, path := range args {
+		r := load(path)
+		if r.TRC != nil {
+			pool.add(r.TRC)
+		}
+		reports = append(reports, r)
+	}
+	exitStatus := 0
+	for _, r := range reports {
+		switch r.Type {
+		case typeChain:
+			inspectChain(r, pool)
+		case typeTRC:
+			inspectTRC(r, pool)
+		}
+		if !r.ok() {
+			exitStatus = 2
+		}
+	}
+	if jsonOut {
+		raw, err := json.MarshalIndent(reports, "", strings.Repeat(" ", 4))
+		if err != nil {
+			pkicmn.ErrorAndExit("Error json-encoding reports: %s\n", err)
+		}
+		fmt.Println(string(raw))
+	} else {
+		for _, r := range reports {
+			fmt.Print(r)
+		}
+	}
+	os.Exit(exitStatus)
+}
+
+// load parses the file at path as certificate chain or TRC. Chains are
+// identified by the keys of the leaf and issuer certificate.
+func load(path string) *report {
+	r := &report{File: path, Type: typeUnknown}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		r.add("parse", err)
+		return r
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		r.add("parse", common.NewBasicError("Unable to parse JSON", err))
+		return r
+	}
+	if _, ok := m["0"]; ok {
+		r.Type = typeChain
+		r.Chain, err = cert.ChainFromRaw(raw, false)
+	} else {
+		r.Type = typeTRC
+		r.TRC, err = trc.TRCFromRaw(raw, false)
+	}
+	if err != nil {
+		r.Type = typeUnknown
+		r.add("parse", err)
+	}
+	return r
+}
+
+func inspectChain(r *report, pool trcPool) {
+	c := r.Chain
+	now := util.TimeToSecs(time.Now())
+	r.add("leaf validity", c.Leaf.VerifyTime(now))
+	r.add("issuer validity", c.Issuer.VerifyTime(now))
+	r.add("leaf key algorithms", checkCertKeys(c.Leaf))
+	r.add("issuer key algorithms", checkCertKeys(c.Issuer))
+	t := pool.get(c.Issuer.Issuer.I, c.Issuer.TRCVersion)
+	if t == nil {
+		r.add("chain signatures", common.NewBasicError("TRC not found", nil,
+			"isd", c.Issuer.Issuer.I, "version", c.Issuer.TRCVersion))
+		return
+	}
+	r.add(fmt.Sprintf("chain signatures (%s)", t), c.Verify(c.Leaf.Subject, t))
+}
+
+func inspectTRC(r *report, pool trcPool) {
+	t := r.TRC
+	r.add("active", t.IsActive(pool.newest(t.ISD)))
+	for _, ia := range t.CoreASes.ASList() {
+		entry := t.CoreASes[ia]
+		r.add(fmt.Sprintf("%s online key algorithm", ia),
+			checkKey(entry.OnlineKeyAlg, entry.OnlineKey, signKeySizes))
+		r.add(fmt.Sprintf("%s offline key algorithm", ia),
+			checkKey(entry.OfflineKeyAlg, entry.OfflineKey, signKeySizes))
+	}
+	if t.Version == 1 {
+		_, err := t.VerifySignatures(t)
+		r.add("signatures (base TRC)", err)
+		return
+	}
+	prev := pool.prev(t.ISD, t.Version)
+	if prev == nil {
+		r.add("signatures", common.NewBasicError("Predecessor TRC not found", nil,
+			"isd", t.ISD, "version", t.Version-1))
+		return
+	}
+	if prev.Version+1 != t.Version {
+		r.add("version gap", common.NewBasicError("Missing TRC versions", nil,
+			"previous", prev.Version, "actual", t.Version))
+		return
+	}
+	_, err := t.Verify(prev)
+	r.add(fmt.Sprintf("signatures (%s)", prev), err)
+}
+
+func checkCertKeys(c *cert.Certificate) error {
+	if err := checkKey(c.SignAlgorithm, c.SubjectSignKey, signKeySizes); err != nil {
+		return err
+	}
+	return checkKey(c.EncAlgorithm, c.SubjectEncKey, encKeySizes)
+}
+
+// checkKey checks that the algorithm is supported and the key has the
+// expected size.
+func checkKey(alg string, key common.RawBytes, sizes map[string]int) error {
+	size, ok := sizes[alg]
+	if !ok {
+		return common.NewBasicError(scrypto.UnsupportedAlgo, nil, "algo", alg)
+	}
+	if len(key) != size {
+		return common.NewBasicError(scrypto.InvalidPubKeySize, nil, "algo", alg,
+			"expected", size, "actual", len(key))
+	}
+	return nil
+}
+
+// trcPool contains the known TRCs indexed by ISD and version.
+type trcPool map[addr.ISD]map[uint64]*trc.TRC
+
+func (p trcPool) add(t *trc.TRC) {
+	if _, ok := p[t.ISD]; !ok {
+		p[t.ISD] = make(map[uint64]*trc.TRC)
+	}
+	p[t.ISD][t.Version] = t
+}
+
+// get returns the TRC with the given version. If the TRC is not in the pool,
+// it is loaded from the TRCs directory of the ISD, if it exists. Nil is
+// returned if the TRC cannot be found.
+func (p trcPool) get(isd addr.ISD, version uint64) *trc.TRC {
+	if t, ok := p[isd][version]; ok {
+		return t
+	}
+	fname := fmt.Sprintf(pkicmn.TrcNameFmt, isd, version)
+	path := filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, isd), pkicmn.TRCsDir, fname)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	t, err := trc.TRCFromFile(path, false)
+	if err != nil {
+		return nil
+	}
+	p.add(t)
+	return t
+}
+
+// prev returns the TRC with the highest version below version. The direct
+// predecessor is looked up on disk if it is not in the pool.
+func (p trcPool) prev(isd addr.ISD, version uint64) *trc.TRC {
+	if t := p.get(isd, version-1); t != nil {
+		return t
+	}
+	var prev *trc.TRC
+	for v, t := range p[isd] {
+		if v < version && (prev == nil || v > prev.Version) {
+			prev = t
+		}
+	}
+	return prev
+}
+
+// newest returns the TRC with the highest version in the pool.
+func (p trcPool) newest(isd addr.ISD) *trc.TRC {
+	var newest *trc.TRC
+	for v, t := range p[isd] {
+		if newest == nil || v > newest.Version {
+			newest = t
+		}
+	}
+	return newest
+}
+
+// report is the result of inspecting a single file.
+type report struct {
+	File   string
+	Type   string
+	Chain  *cert.Chain `json:",omitempty"`
+	TRC    *trc.TRC    `json:",omitempty"`
+	Checks []*check
+}
+
+// check is the result of a single validation step.
+type check struct {
+	Name  string
+	OK    bool
+	Error string `json:",omitempty"`
+}
+
+func (r *report) add(name string, err error) {
+	c := &check{Name: name, OK: err == nil}
+	if err != nil {
+		c.Error = strings.Replace(err.Error(), "\n", " ", -1)
+	}
+	r.Checks = append(r.Checks, c)
+}
+
+func (r *report) ok() bool {
+	for _, c := range r.Checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *report) String() string {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "%s:\n", r.File)
+	switch {
+	case r.Chain != nil:
+		fmt.Fprintf(b, "  %s\n", r.Chain)
+		writeCert(b, "Leaf", r.Chain.Leaf)
+		writeCert(b, "Issuer", r.Chain.Issuer)
+	case r.TRC != nil:
+		writeTRC(b, r.TRC)
+	}
+	fmt.Fprintf(b, "  Checks:\n")
+	for _, c := range r.Checks {
+		if c.OK {
+			fmt.Fprintf(b, "    OK    %s\n", c.Name)
+		} else {
+			fmt.Fprintf(b, "    FAIL  %s: %s\n", c.Name, c.Error)
+		}
+	}
+	return b.String()
+}
+
+func writeCert(b *bytes.Buffer, name string, c *cert.Certificate) {
+	fmt.Fprintf(b, "  %s:\n", name)
+	fmt.Fprintf(b, "    Subject:     %s\n", c.Subject)
+	fmt.Fprintf(b, "    Version:     %d\n", c.Version)
+	fmt.Fprintf(b, "    Issuer:      %s\n", c.Issuer)
+	fmt.Fprintf(b, "    TRC version: %d\n", c.TRCVersion)
+	fmt.Fprintf(b, "    CanIssue:    %t\n", c.CanIssue)
+	fmt.Fprintf(b, "    Issued:      %s\n", secsToString(c.IssuingTime))
+	fmt.Fprintf(b, "    Expires:     %s\n", secsToString(c.ExpirationTime))
+	fmt.Fprintf(b, "    Sign key:    %s %s\n", c.SignAlgorithm, c.SubjectSignKey)
+	fmt.Fprintf(b, "    Enc key:     %s %s\n", c.EncAlgorithm, c.SubjectEncKey)
+	if c.Comment != "" {
+		fmt.Fprintf(b, "    Comment:     %s\n", c.Comment)
+	}
+}
+
+func writeTRC(b *bytes.Buffer, t *trc.TRC) {
+	fmt.Fprintf(b, "  %s\n", t)
+	fmt.Fprintf(b, "    Description:  %s\n", t.Description)
+	fmt.Fprintf(b, "    Created:      %s\n", secsToString(t.CreationTime))
+	fmt.Fprintf(b, "    Expires:      %s\n", secsToString(t.ExpirationTime))
+	fmt.Fprintf(b, "    Grace period: %s\n", time.Duration(t.GracePeriod)*time.Second)
+	fmt.Fprintf(b, "    Quarantine:   %t\n", t.Quarantine)
+	fmt.Fprintf(b, "    Quorum TRC:   %d\n", t.QuorumTRC)
+	fmt.Fprintf(b, "    Core ASes:\n")
+	for _, ia := range t.CoreASes.ASList() {
+		entry := t.CoreASes[ia]
+		fmt.Fprintf(b, "      %s\n", ia)
+		fmt.Fprintf(b, "        Online key:  %s %s\n", entry.OnlineKeyAlg, entry.OnlineKey)
+		fmt.Fprintf(b, "        Offline key: %s %s\n", entry.OfflineKeyAlg, entry.OfflineKey)
+	}
+	signers := make([]string, 0, len(t.Signatures))
+	for signer := range t.Signatures {
+		signers = append(signers, signer)
+	}
+	sort.Strings(signers)
+	fmt.Fprintf(b, "    Signed by:    %s\n", strings.Join(signers, ", "))
+}
+
+func secsToString(secs uint32) string {
+	return util.TimeToString(util.SecsToTime(secs))
+}
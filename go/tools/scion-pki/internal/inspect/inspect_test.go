@@ -0,0 +1,221 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		File     string
+		Type     string
+		ParseErr bool
+	}{
+		{Name: "Certificate chain", File: "ISD2-ASff00_0_212-V1.crt", Type: typeChain},
+		{Name: "TRC", File: "ISD2-V1.trc", Type: typeTRC},
+		{Name: "Key file", File: "master0.key", Type: typeUnknown, ParseErr: true},
+		{Name: "Missing file", File: "ISD3-V1.trc", Type: typeUnknown, ParseErr: true},
+	}
+	Convey("load", t, func() {
+		for _, tc := range testCases {
+			Convey(tc.Name, func() {
+				r := load(filepath.Join("testdata", tc.File))
+				SoMsg("type", r.Type, ShouldEqual, tc.Type)
+				SoMsg("chain", r.Chain != nil, ShouldEqual, tc.Type == typeChain)
+				SoMsg("trc", r.TRC != nil, ShouldEqual, tc.Type == typeTRC)
+				SoMsg("ok", r.ok(), ShouldEqual, !tc.ParseErr)
+				if tc.ParseErr {
+					SoMsg("checks", checks(r), ShouldResemble, map[string]bool{"parse": false})
+				}
+			})
+		}
+	})
+}
+
+func TestInspectTRC(t *testing.T) {
+	Convey("inspectTRC", t, func() {
+		pool := make(trcPool)
+		Convey("Base TRC with valid signatures", func() {
+			r := mustLoad(t, "ISD2-V1.trc", pool)
+			inspectTRC(r, pool)
+			SoMsg("checks", checks(r), ShouldResemble, map[string]bool{
+				"active":                             false,
+				"2-ff00:0:210 online key algorithm":  true,
+				"2-ff00:0:210 offline key algorithm": true,
+				"2-ff00:0:220 online key algorithm":  true,
+				"2-ff00:0:220 offline key algorithm": true,
+				"signatures (base TRC)":              true,
+			})
+		})
+		Convey("Base TRC with modified content", func() {
+			r := mustLoad(t, "ISD2-V1.trc", pool)
+			r.TRC.Description = "modified"
+			inspectTRC(r, pool)
+			SoMsg("signatures", checks(r)["signatures (base TRC)"], ShouldBeFalse)
+		})
+		Convey("Base TRC with unsupported key algorithm", func() {
+			r := mustLoad(t, "ISD2-V1.trc", pool)
+			r.TRC.CoreASes[xtest.MustParseIA("2-ff00:0:210")].OnlineKeyAlg = "rsa"
+			inspectTRC(r, pool)
+			SoMsg("online", checks(r)["2-ff00:0:210 online key algorithm"], ShouldBeFalse)
+			SoMsg("offline", checks(r)["2-ff00:0:210 offline key algorithm"], ShouldBeTrue)
+		})
+		Convey("Updated TRC is verified against its predecessor", func() {
+			mustLoad(t, "ISD1-V1.trc", pool)
+			r := mustLoad(t, "ISD1-V2.trc", pool)
+			inspectTRC(r, pool)
+			res := checks(r)
+			_, ok := res["signatures (TRC 1v1)"]
+			SoMsg("verified against predecessor", ok, ShouldBeTrue)
+			// The TRC in the testdata is created before its predecessor.
+			SoMsg("signatures", res["signatures (TRC 1v1)"], ShouldBeFalse)
+		})
+		Convey("Updated TRC without predecessor", func() {
+			r := mustLoad(t, "ISD1-V2.trc", pool)
+			inspectTRC(r, pool)
+			SoMsg("signatures", checks(r)["signatures"], ShouldBeFalse)
+		})
+		Convey("Updated TRC with version gap", func() {
+			mustLoad(t, "ISD1-V1.trc", pool)
+			r := mustLoad(t, "ISD1-V2.trc", pool)
+			r.TRC.Version = 3
+			inspectTRC(r, pool)
+			SoMsg("gap", checks(r)["version gap"], ShouldBeFalse)
+		})
+	})
+}
+
+func TestInspectChain(t *testing.T) {
+	Convey("inspectChain", t, func() {
+		pool := make(trcPool)
+		Convey("Chain is verified against the TRC of the issuer", func() {
+			mustLoad(t, "ISD2-V1.trc", pool)
+			r := mustLoad(t, "ISD2-ASff00_0_212-V1.crt", pool)
+			inspectChain(r, pool)
+			SoMsg("checks", checks(r), ShouldResemble, map[string]bool{
+				"leaf validity":         false,
+				"issuer validity":       false,
+				"leaf key algorithms":   true,
+				"issuer key algorithms": true,
+				// The certificates in the testdata are expired.
+				"chain signatures (TRC 2v1)": false,
+			})
+		})
+		Convey("Chain with invalid key size", func() {
+			mustLoad(t, "ISD2-V1.trc", pool)
+			r := mustLoad(t, "ISD2-ASff00_0_212-V1.crt", pool)
+			r.Chain.Leaf.SubjectEncKey = r.Chain.Leaf.SubjectEncKey[1:]
+			inspectChain(r, pool)
+			SoMsg("leaf", checks(r)["leaf key algorithms"], ShouldBeFalse)
+			SoMsg("issuer", checks(r)["issuer key algorithms"], ShouldBeTrue)
+		})
+		Convey("Chain without TRC", func() {
+			r := mustLoad(t, "ISD1-ASff00_0_311-V1.crt", pool)
+			inspectChain(r, pool)
+			SoMsg("signatures", checks(r)["chain signatures"], ShouldBeFalse)
+		})
+		Convey("TRC is loaded from the output directory", func() {
+			dir, cleanF := xtest.MustTempDir("", "inspect")
+			defer cleanF()
+			oldOutDir := pkicmn.OutDir
+			pkicmn.OutDir = dir
+			defer func() { pkicmn.OutDir = oldOutDir }()
+			trcsDir := filepath.Join(pkicmn.GetIsdPath(dir, 1), pkicmn.TRCsDir)
+			xtest.FailOnErr(t, os.MkdirAll(trcsDir, 0755))
+			raw, err := ioutil.ReadFile(filepath.Join("testdata", "ISD1-V1.trc"))
+			xtest.FailOnErr(t, err)
+			xtest.FailOnErr(t, ioutil.WriteFile(filepath.Join(trcsDir, "ISD1-V1.trc"), raw, 0644))
+
+			r := mustLoad(t, "ISD1-ASff00_0_311-V1.crt", pool)
+			inspectChain(r, pool)
+			_, ok := checks(r)["chain signatures (TRC 1v1)"]
+			SoMsg("TRC found", ok, ShouldBeTrue)
+			SoMsg("pool", pool.get(1, 1), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestCheckKey(t *testing.T) {
+	Convey("checkKey", t, func() {
+		key := make(common.RawBytes, scrypto.NaClBoxKeySize)
+		SoMsg("valid", checkKey(scrypto.Curve25519xSalsa20Poly1305, key, encKeySizes),
+			ShouldBeNil)
+		SoMsg("unsupported", checkKey(scrypto.Ed25519, key, encKeySizes), ShouldNotBeNil)
+		SoMsg("size", checkKey(scrypto.Curve25519xSalsa20Poly1305, key[1:], encKeySizes),
+			ShouldNotBeNil)
+	})
+}
+
+func TestTRCPool(t *testing.T) {
+	Convey("trcPool", t, func() {
+		pool := make(trcPool)
+		for _, v := range []uint64{1, 2, 4} {
+			pool.add(&trc.TRC{ISD: 1, Version: v})
+		}
+		SoMsg("newest", pool.newest(1).Version, ShouldEqual, 4)
+		SoMsg("newest unknown ISD", pool.newest(2), ShouldBeNil)
+		SoMsg("prev direct", pool.prev(1, 2).Version, ShouldEqual, 1)
+		SoMsg("prev gap", pool.prev(1, 4).Version, ShouldEqual, 2)
+		SoMsg("prev none", pool.prev(1, 1), ShouldBeNil)
+		SoMsg("get missing", pool.get(1, 3), ShouldBeNil)
+	})
+}
+
+func TestReport(t *testing.T) {
+	Convey("report", t, func() {
+		r := &report{File: "test.trc", Type: typeUnknown}
+		r.add("first", nil)
+		SoMsg("ok", r.ok(), ShouldBeTrue)
+		r.add("second", errors.New("multi\nline"))
+		SoMsg("not ok", r.ok(), ShouldBeFalse)
+		SoMsg("string", r.String(), ShouldEqual, "test.trc:\n  Checks:\n"+
+			"    OK    first\n    FAIL  second: multi line\n")
+	})
+}
+
+// mustLoad loads the file in testdata and adds it to the pool if it is a TRC.
+func mustLoad(t *testing.T, file string, pool trcPool) *report {
+	r := load(filepath.Join("testdata", file))
+	if !r.ok() {
+		t.Fatalf("Unable to load %s: %s", file, r)
+	}
+	if r.TRC != nil {
+		pool.add(r.TRC)
+	}
+	return r
+}
+
+// checks returns whether the checks of the report succeeded, keyed by name.
+func checks(r *report) map[string]bool {
+	m := make(map[string]bool, len(r.Checks))
+	for _, c := range r.Checks {
+		m[c.Name] = c.OK
+	}
+	return m
+}
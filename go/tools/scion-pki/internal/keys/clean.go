@@ -0,0 +1,69 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+// keyFiles contains the names of all key files generated by 'keys gen'.
+var keyFiles = []string{
+	keyconf.SigKeyFile,
+	keyconf.DecKeyFile,
+	keyconf.MasterKey0,
+	keyconf.MasterKey1,
+	keyconf.IssSigKeyFile,
+	keyconf.OffKeyFile,
+	keyconf.OnKeyFile,
+}
+
+func runCleanKeys(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for _, ases := range asMap {
+		for _, ia := range ases {
+			if err := cleanKeys(ia); err != nil {
+				pkicmn.ErrorAndExit("Error removing keys for %s: %s\n", ia, err)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// cleanKeys removes the key files generated by 'keys gen' from the keys
+// directory of the AS in the output directory. Other files are kept.
+func cleanKeys(ia addr.IA) error {
+	dir := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir)
+	var paths []string
+	for _, fname := range keyFiles {
+		path := filepath.Join(dir, fname)
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		pkicmn.QuietPrint("No keys found for %s\n", ia)
+		return nil
+	}
+	pkicmn.QuietPrint("Cleaning keys for %s\n", ia)
+	return pkicmn.RemoveFiles(paths, dryRun)
+}
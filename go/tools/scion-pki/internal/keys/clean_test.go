@@ -0,0 +1,82 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func TestCleanKeys(t *testing.T) {
+	Convey("cleanKeys removes the generated key files", t, func() {
+		dir, cleanF := xtest.MustTempDir("", "keys")
+		defer cleanF()
+		pkicmn.OutDir, pkicmn.Quiet = dir, true
+		ia := xtest.MustParseIA("1-ff00:0:110")
+		keysDir := filepath.Join(pkicmn.GetAsPath(dir, ia), pkicmn.KeysDir)
+		xtest.FailOnErr(t, os.MkdirAll(keysDir, 0700))
+		others := []string{keyconf.ProviderConfFile, keyconf.EncKeysFile, "master0.key.bak"}
+		for _, fname := range append(append([]string{}, keyFiles...), others...) {
+			err := ioutil.WriteFile(filepath.Join(keysDir, fname), []byte("key"), 0600)
+			xtest.FailOnErr(t, err)
+		}
+		Convey("Dry-run keeps all files", func() {
+			dryRun = true
+			defer func() { dryRun = false }()
+			SoMsg("err", cleanKeys(ia), ShouldBeNil)
+			SoMsg("files", listFiles(t, keysDir), ShouldResemble,
+				sorted(append(append([]string{}, keyFiles...), others...)))
+		})
+		Convey("Only the key files are removed", func() {
+			SoMsg("err", cleanKeys(ia), ShouldBeNil)
+			SoMsg("files", listFiles(t, keysDir), ShouldResemble, sorted(others))
+		})
+		Convey("Missing key files are skipped", func() {
+			xtest.FailOnErr(t, os.Remove(filepath.Join(keysDir, keyconf.OnKeyFile)))
+			SoMsg("err", cleanKeys(ia), ShouldBeNil)
+			SoMsg("files", listFiles(t, keysDir), ShouldResemble, sorted(others))
+			SoMsg("again", cleanKeys(ia), ShouldBeNil)
+		})
+		Convey("A missing keys directory is not an error", func() {
+			other := xtest.MustParseIA("1-ff00:0:111")
+			SoMsg("err", cleanKeys(other), ShouldBeNil)
+		})
+	})
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	xtest.FailOnErr(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return sorted(names)
+}
+
+func sorted(names []string) []string {
+	s := append([]string{}, names...)
+	sort.Strings(s)
+	return s
+}
@@ -15,11 +15,15 @@
 package keys
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
+var (
+	dryRun         bool
+	passphraseFile string
+	activate       bool
+)
+
 var Cmd = &cobra.Command{
 	Use:   "keys",
 	Short: "Generate keys for the SCION control plane PKI.",
@@ -40,7 +44,12 @@ Selector:
 var genCmd = &cobra.Command{
 	Use:   "gen",
 	Short: "Generate new keys",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `
+'gen' generates the keys for the ASes matching the selector. The signing keys are
+generated for the algorithms configured in the as.ini of the AS (see 'scion-pki help certs').
+If the AS has no as.ini, ed25519 is used.
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		runGenKey(args)
 	},
@@ -48,13 +57,70 @@ var genCmd = &cobra.Command{
 
 var cleanKeysCmd = &cobra.Command{
 	Use:   "clean",
-	Short: "Remove all the keys [NOT IMPLEMENTED]",
+	Short: "Remove all the keys",
+	Long: `
+'clean' removes all keys generated by 'keys gen' for the ASes matching the selector.
+Use --dry-run to list the keys that would be removed.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCleanKeys(args)
+	},
+}
+
+var encryptKeysCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the keys with a passphrase",
+	Long: `
+'encrypt' stores the keys of the ASes matching the selector in a passphrase-encrypted
+key file (keys/keys.enc) and configures the services to read the keys from it
+(keys/keystore.json). The key used for encryption is derived from the passphrase with
+scrypt, the keys are encrypted with AES-GCM. The passphrase is read from the file passed
+with --passphrase-file. The services read the passphrase from the same path at startup.
+
+The plain key files are kept, since the other scion-pki commands use them. They should
+not be deployed together with the encrypted key file.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runEncryptKeys(args)
+	},
+}
+
+var rotateMasterCmd = &cobra.Command{
+	Use:   "rotate-master",
+	Short: "Rotate the AS master key",
+	Long: `
+'rotate-master' rotates the master key of the ASes matching the selector. The master
+key authenticates the hop fields. Each AS has two master keys: The active key
+(master0.key) is used by the beacon server to create new hop fields. The border routers
+accept hop fields authenticated with the active key or the secondary key (master1.key).
+
+A rotation is done in two steps, such that no path becomes invalid:
+	1. 'rotate-master' replaces the secondary key with a fresh key. Deploy the keys to
+	   all border routers and reload them (SIGHUP).
+	2. 'rotate-master --activate' swaps the active and the secondary key. Deploy the
+	   keys to the border routers and the beacon servers, and reload or restart them.
+	   The previous key stays valid as secondary key.
+Step 1 of the next rotation invalidates the hop fields created with the previous key.
+It must not be done before these hop fields have expired. If the keys are stored in an
+encrypted key file, run 'keys encrypt' after each step.
+`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("clean sub command is not implemented")
+		runRotateMaster(args)
 	},
 }
 
 func init() {
+	rotateMasterCmd.Flags().BoolVarP(&activate, "activate", "a", false,
+		"swap the active and the secondary master key")
+	encryptKeysCmd.Flags().StringVarP(&passphraseFile, "passphrase-file", "p", "",
+		"file containing the passphrase")
+	cleanKeysCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false,
+		"only list the keys that would be removed")
 	Cmd.AddCommand(genCmd)
 	Cmd.AddCommand(cleanKeysCmd)
+	Cmd.AddCommand(encryptKeysCmd)
+	Cmd.AddCommand(rotateMasterCmd)
 }
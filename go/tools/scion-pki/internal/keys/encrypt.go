@@ -0,0 +1,98 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func runEncryptKeys(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	if passphraseFile == "" {
+		pkicmn.ErrorAndExit("Error: --passphrase-file is required\n")
+	}
+	passFile, err := filepath.Abs(passphraseFile)
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	raw, err := ioutil.ReadFile(passFile)
+	if err != nil {
+		pkicmn.ErrorAndExit("Error reading passphrase file: %s\n", err)
+	}
+	pass := strings.TrimRight(string(raw), " \t\r\n")
+	if pass == "" {
+		pkicmn.ErrorAndExit("Error: empty passphrase\n")
+	}
+	for _, ases := range asMap {
+		for _, ia := range ases {
+			if err := encryptKeys(ia, []byte(pass), passFile); err != nil {
+				pkicmn.ErrorAndExit("Error encrypting keys for %s: %s\n", ia, err)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// encryptKeys writes the existing keys of the AS to an encrypted key file and
+// configures the encrypted key provider for the keys directory. The plain key
+// files are not removed.
+func encryptKeys(ia addr.IA, pass []byte, passFile string) error {
+	dir := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir)
+	p := &keyconf.FileProvider{Dir: dir}
+	keys := make(map[string]common.RawBytes)
+	for _, fname := range keyFiles {
+		if _, err := os.Stat(filepath.Join(dir, fname)); os.IsNotExist(err) {
+			continue
+		}
+		key, err := p.Key(fname)
+		if err != nil {
+			return err
+		}
+		keys[fname] = key
+	}
+	if len(keys) == 0 {
+		pkicmn.QuietPrint("No keys found for %s\n", ia)
+		return nil
+	}
+	pkicmn.QuietPrint("Encrypting keys for %s\n", ia)
+	raw, err := keyconf.EncryptKeys(keys, pass)
+	if err != nil {
+		return err
+	}
+	if err := pkicmn.WriteToFile(raw, filepath.Join(dir, keyconf.EncKeysFile), 0600); err != nil {
+		return err
+	}
+	cfg := &keyconf.ProviderConf{
+		Type:           keyconf.ProviderEncrypted,
+		File:           keyconf.EncKeysFile,
+		PassphraseFile: passFile,
+	}
+	if raw, err = json.MarshalIndent(cfg, "", "    "); err != nil {
+		return err
+	}
+	return pkicmn.WriteToFile(raw, filepath.Join(dir, keyconf.ProviderConfFile), 0644)
+}
@@ -24,6 +24,7 @@ import (
 
 	"golang.org/x/crypto/ed25519"
 
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/keyconf"
 	"github.com/scionproto/scion/go/lib/scrypto"
@@ -44,8 +45,13 @@ func runGenKey(args []string) {
 		for _, ia := range ases {
 			dir := pkicmn.GetAsPath(pkicmn.OutDir, ia)
 			core := pkicmn.Contains(iconf.Trc.CoreIAs, ia)
+			algos, err := loadKeyAlgos(ia)
+			if err != nil {
+				pkicmn.ErrorAndExit("Error reading as.ini: %s\n", err)
+			}
 			pkicmn.QuietPrint("Generating keys for %s\n", ia)
-			if err = genAll(filepath.Join(dir, pkicmn.KeysDir), core); err != nil {
+			err = genAll(filepath.Join(dir, pkicmn.KeysDir), algos, core, pkicmn.Force)
+			if err != nil {
 				pkicmn.ErrorAndExit("Error generating keys: %s\n", err)
 			}
 		}
@@ -53,38 +59,102 @@ func runGenKey(args []string) {
 	os.Exit(0)
 }
 
-func genAll(outDir string, core bool) error {
-	// Generate AS sigining and decryption keys.
-	if err := genKey(keyconf.SigKeyFile, outDir, genSignKey); err != nil {
-		return err
+// keyAlgos contains the signing algorithms of the keys of an AS.
+type keyAlgos struct {
+	sign    string
+	issSig  string
+	online  string
+	offline string
+}
+
+// loadKeyAlgos determines the signing algorithms from the as.ini of the AS.
+// Keys whose algorithm is not configured are generated for ed25519.
+func loadKeyAlgos(ia addr.IA) (*keyAlgos, error) {
+	algos := &keyAlgos{
+		sign:    scrypto.Ed25519,
+		issSig:  scrypto.Ed25519,
+		online:  scrypto.Ed25519,
+		offline: scrypto.Ed25519,
+	}
+	dir := pkicmn.GetAsPath(pkicmn.RootDir, ia)
+	if _, err := os.Stat(filepath.Join(dir, conf.AsConfFileName)); os.IsNotExist(err) {
+		return algos, nil
+	}
+	a, err := conf.LoadAsConf(dir)
+	if err != nil {
+		return nil, err
+	}
+	algos.sign = a.AsCert.SignAlgorithm
+	if a.IssuerCert != nil && a.IssuerCert.BaseCert != nil {
+		algos.issSig = a.IssuerCert.SignAlgorithm
+	}
+	if a.KeyAlgorithms != nil {
+		algos.online = a.KeyAlgorithms.Online
+		algos.offline = a.KeyAlgorithms.Offline
 	}
-	if err := genKey(keyconf.DecKeyFile, outDir, genEncKey); err != nil {
+	return algos, nil
+}
+
+func genAll(outDir string, algos *keyAlgos, core, force bool) error {
+	// Generate AS sigining and decryption keys.
+	if err := genCertKeys(outDir, algos, false, force); err != nil {
 		return err
 	}
 	// Generate AS master keys.
-	if err := genKey(keyconf.MasterKey0, outDir, genMasterKey); err != nil {
+	if err := genKey(keyconf.MasterKey0, outDir, genMasterKey, force); err != nil {
 		return err
 	}
-	if err := genKey(keyconf.MasterKey1, outDir, genMasterKey); err != nil {
+	if err := genKey(keyconf.MasterKey1, outDir, genMasterKey, force); err != nil {
 		return err
 	}
 	if !core {
 		return nil
 	}
 	// Generate core signing key.
-	if err := genKey(keyconf.IssSigKeyFile, outDir, genSignKey); err != nil {
+	err := genKey(keyconf.IssSigKeyFile, outDir, signKeyGen(algos.issSig), force)
+	if err != nil {
 		return err
 	}
 	// Generate offline and online root keys if core was specified.
-	if err := genKey(keyconf.OffKeyFile, outDir, genSignKey); err != nil {
+	err = genKey(keyconf.OffKeyFile, outDir, signKeyGen(algos.offline), force)
+	if err != nil {
 		return err
 	}
-	return genKey(keyconf.OnKeyFile, outDir, genSignKey)
+	return genKey(keyconf.OnKeyFile, outDir, signKeyGen(algos.online), force)
+}
+
+// RotateCertKeys replaces the keys of the AS that are authenticated by its
+// certificate chain, i.e., the signing and decryption keys, and the issuer
+// signing key if the AS is an issuer. The master keys and the TRC keys are
+// not modified.
+func RotateCertKeys(ia addr.IA, issuer bool) error {
+	outDir := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir)
+	algos, err := loadKeyAlgos(ia)
+	if err != nil {
+		return err
+	}
+	pkicmn.QuietPrint("Rotating keys for %s\n", ia)
+	return genCertKeys(outDir, algos, issuer, true)
+}
+
+// genCertKeys generates the signing and decryption keys, and the issuer
+// signing key if issuer is set.
+func genCertKeys(outDir string, algos *keyAlgos, issuer, force bool) error {
+	if err := genKey(keyconf.SigKeyFile, outDir, signKeyGen(algos.sign), force); err != nil {
+		return err
+	}
+	if err := genKey(keyconf.DecKeyFile, outDir, genEncKey, force); err != nil {
+		return err
+	}
+	if !issuer {
+		return nil
+	}
+	return genKey(keyconf.IssSigKeyFile, outDir, signKeyGen(algos.issSig), force)
 }
 
 type keyGenFunc func(io.Reader) ([]byte, error)
 
-func genKey(fname, outDir string, keyGenF keyGenFunc) error {
+func genKey(fname, outDir string, keyGenF keyGenFunc, force bool) error {
 	// Check if out directory exists and if not create it.
 	_, err := os.Stat(outDir)
 	if os.IsNotExist(err) {
@@ -108,18 +178,29 @@ func genKey(fname, outDir string, keyGenF keyGenFunc) error {
 	// Write private key to file.
 	privKeyPath := filepath.Join(outDir, fname)
 	privKeyEnc := base64.StdEncoding.EncodeToString(privKey)
-	if err = pkicmn.WriteToFile([]byte(privKeyEnc), privKeyPath, 0600); err != nil {
+	write := pkicmn.WriteToFile
+	if force {
+		write = pkicmn.OverwriteFile
+	}
+	if err = write([]byte(privKeyEnc), privKeyPath, 0600); err != nil {
 		return common.NewBasicError("Cannot write key file", err, "key", fname)
 	}
 	return nil
 }
 
-func genSignKey(rand io.Reader) ([]byte, error) {
-	_, private, err := scrypto.GenKeyPair(scrypto.Ed25519)
-	if err != nil {
-		return nil, err
+// signKeyGen returns a function that generates signing keys for the
+// algorithm. Ed25519 keys are stored as seed, ECDSA keys as private scalar.
+func signKeyGen(algo string) keyGenFunc {
+	return func(rand io.Reader) ([]byte, error) {
+		_, private, err := scrypto.GenKeyPair(algo)
+		if err != nil {
+			return nil, err
+		}
+		if algo == scrypto.Ed25519 {
+			return ed25519.PrivateKey(private).Seed(), nil
+		}
+		return private, nil
 	}
-	return ed25519.PrivateKey(private).Seed(), nil
 }
 
 func genEncKey(rand io.Reader) ([]byte, error) {
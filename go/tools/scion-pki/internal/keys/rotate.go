@@ -0,0 +1,82 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keys
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func runRotateMaster(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for _, ases := range asMap {
+		for _, ia := range ases {
+			if activate {
+				err = activateMaster(ia)
+			} else {
+				err = prepareMaster(ia)
+			}
+			if err != nil {
+				pkicmn.ErrorAndExit("Error rotating master key for %s: %s\n", ia, err)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// prepareMaster replaces the secondary master key with a fresh key. The border
+// routers accept hop fields authenticated with both keys, but the beacon server
+// keeps using the active key.
+func prepareMaster(ia addr.IA) error {
+	dir := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir)
+	path0 := filepath.Join(dir, keyconf.MasterKey0)
+	if _, err := os.Stat(path0); err != nil {
+		return common.NewBasicError("Active master key not found, use 'keys gen'", err,
+			"path", path0)
+	}
+	pkicmn.QuietPrint("Generating secondary master key for %s\n", ia)
+	return genKey(keyconf.MasterKey1, dir, genMasterKey, true)
+}
+
+// activateMaster swaps the active and the secondary master key. The beacon
+// server uses the new key for new hop fields, while the border routers still
+// accept the hop fields authenticated with the previous key.
+func activateMaster(ia addr.IA) error {
+	dir := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir)
+	path0 := filepath.Join(dir, keyconf.MasterKey0)
+	path1 := filepath.Join(dir, keyconf.MasterKey1)
+	key0, err := ioutil.ReadFile(path0)
+	if err != nil {
+		return common.NewBasicError("Unable to read master key", err, "path", path0)
+	}
+	key1, err := ioutil.ReadFile(path1)
+	if err != nil {
+		return common.NewBasicError("Unable to read master key", err, "path", path1)
+	}
+	pkicmn.QuietPrint("Activating secondary master key for %s\n", ia)
+	if err := pkicmn.OverwriteFile(key1, path0, 0600); err != nil {
+		return err
+	}
+	return pkicmn.OverwriteFile(key0, path1, 0600)
+}
@@ -133,9 +133,19 @@ func Contains(ases []addr.IA, as addr.IA) bool {
 }
 
 func WriteToFile(raw common.RawBytes, path string, perm os.FileMode) error {
+	return writeToFile(raw, path, perm, Force)
+}
+
+// OverwriteFile writes raw to the file at path. In contrast to WriteToFile, an
+// existing file is overwritten regardless of Force.
+func OverwriteFile(raw common.RawBytes, path string, perm os.FileMode) error {
+	return writeToFile(raw, path, perm, true)
+}
+
+func writeToFile(raw common.RawBytes, path string, perm os.FileMode, force bool) error {
 	// Check if file already exists.
 	if _, err := os.Stat(path); err == nil {
-		if !Force {
+		if !force {
 			QuietPrint("%s already exists. Use -f to overwrite.\n", path)
 			return nil
 		}
@@ -151,6 +161,22 @@ func WriteToFile(raw common.RawBytes, path string, perm os.FileMode) error {
 	return nil
 }
 
+// RemoveFiles removes the files at paths. In dry-run mode, the files are only
+// listed.
+func RemoveFiles(paths []string, dryRun bool) error {
+	for _, path := range paths {
+		if dryRun {
+			fmt.Printf("Would remove %s\n", path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		QuietPrint("Removed %s\n", path)
+	}
+	return nil
+}
+
 func GetAsPath(baseDir string, ia addr.IA) string {
 	return filepath.Join(baseDir, fmt.Sprintf("ISD%d/AS%s", ia.I, ia.A.FileFmt()))
 }
@@ -171,3 +171,65 @@ func getIAFromASes(isd addr.ISD, asList []addr.AS) []addr.IA {
 	}
 	return result
 }
+
+func TestRemoveFiles(t *testing.T) {
+	Convey("Given files in a directory", t, func() {
+		dir, err := ioutil.TempDir("", "pkicmn")
+		xtest.FailOnErr(t, err)
+		paths := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}
+		keep := filepath.Join(dir, "keep")
+		for _, path := range append(paths, keep) {
+			xtest.FailOnErr(t, ioutil.WriteFile(path, []byte("test"), 0644))
+		}
+		Convey("Dry-run keeps the files", func() {
+			So(RemoveFiles(paths, true), ShouldBeNil)
+			for _, path := range paths {
+				_, err := os.Stat(path)
+				So(err, ShouldBeNil)
+			}
+		})
+		Convey("Only the given files are removed", func() {
+			So(RemoveFiles(paths, false), ShouldBeNil)
+			for _, path := range paths {
+				_, err := os.Stat(path)
+				So(os.IsNotExist(err), ShouldBeTrue)
+			}
+			_, err := os.Stat(keep)
+			So(err, ShouldBeNil)
+		})
+		Convey("Missing files return an error", func() {
+			So(RemoveFiles([]string{filepath.Join(dir, "c")}, false), ShouldNotBeNil)
+		})
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+	})
+}
+
+func TestOverwriteFile(t *testing.T) {
+	Convey("Given an existing file", t, func() {
+		dir, err := ioutil.TempDir("", "pkicmn")
+		xtest.FailOnErr(t, err)
+		path := filepath.Join(dir, "file")
+		xtest.FailOnErr(t, ioutil.WriteFile(path, []byte("old"), 0644))
+		Force = false
+		Convey("WriteToFile keeps the file", func() {
+			So(WriteToFile([]byte("new"), path, 0600), ShouldBeNil)
+			raw, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(raw), ShouldEqual, "old")
+		})
+		Convey("OverwriteFile replaces the file", func() {
+			So(OverwriteFile([]byte("new"), path, 0600), ShouldBeNil)
+			raw, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(raw), ShouldEqual, "new\n")
+			info, err := os.Stat(path)
+			So(err, ShouldBeNil)
+			So(info.Mode().Perm(), ShouldEqual, os.FileMode(0600))
+		})
+		Reset(func() {
+			os.RemoveAll(dir)
+		})
+	})
+}
@@ -20,10 +20,10 @@ import (
 
 var Cmd = &cobra.Command{
 	Use:   "trc",
-	Short: "Generate TRCs for the SCION control plane PKI",
+	Short: "Generate and update TRCs for the SCION control plane PKI",
 	Long: `
-'trc' can be used to generate Trust Root Configuration (TRC) files used in the SCION control
-plane PKI.
+'trc' can be used to generate and update Trust Root Configuration (TRC) files used in
+the SCION control plane PKI.
 
 Selector:
 	*
@@ -62,6 +62,14 @@ and a section 'TRC' with the following values:
 		integer reprensenting the time the previous TRC is still valid in seconds
 	QuorumTRC [required]
 		integer reprensenting the number of core ASes needed to sign a new TRC.
+
+Updating a TRC is done in three steps:
+	1. 'trc update' proposes the successor of the newest TRC in <root>/ISDx/trcs.
+	2. 'trc sign' signs the proposal with the online key of each selected core AS.
+	3. 'trc combine' collects the signatures and writes the new TRC once it is
+	   signed by a quorum of the core ASes of the current TRC.
+The proposal and the signatures are stored in <root>/ISDx/trcs/pending. Only the
+signed proposal files need to be exchanged between the core ASes.
 `,
 }
 
@@ -74,6 +82,55 @@ var gen = &cobra.Command{
 	},
 }
 
+var update = &cobra.Command{
+	Use:   "update",
+	Short: "Propose TRC updates",
+	Long: `
+'update' proposes the successor of the newest TRC of the selected ISDs according to
+isd.ini. The new TRC has the next version and is created now, the Version and
+IssuingTime values in isd.ini are ignored. The keys of core ASes that are already in
+the current TRC are carried over, the keys of new core ASes are loaded from disk.
+The proposal is checked to be a valid successor of the current TRC.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runUpdateTrc(args)
+	},
+}
+
+var sign = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign proposed TRC updates",
+	Long: `
+'sign' signs the proposed TRC update with the online key of the selected ASes. The
+online key must match the key in the current TRC. ASes that are not core ASes in the
+current TRC are skipped. The signed TRC is written to
+<root>/ISDx/trcs/pending/ISDx-Vy-ASz.trc.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSignTrc(args)
+	},
+}
+
+var combine = &cobra.Command{
+	Use:   "combine",
+	Short: "Combine signatures of TRC updates",
+	Long: `
+'combine' merges the signatures of the core ASes into the proposed TRC update and
+verifies it against the current TRC. Signatures that cannot be verified are dropped.
+The TRC is only written if it is signed by a quorum of the core ASes in the current
+TRC.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCombineTrc(args)
+	},
+}
+
 func init() {
 	Cmd.AddCommand(gen)
+	Cmd.AddCommand(update)
+	Cmd.AddCommand(sign)
+	Cmd.AddCommand(combine)
 }
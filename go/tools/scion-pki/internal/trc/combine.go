@@ -0,0 +1,87 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func runCombineTrc(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for isd := range asMap {
+		if err = combineTrc(isd); err != nil {
+			pkicmn.ErrorAndExit("Error combining TRC: %s\n", err)
+		}
+	}
+	os.Exit(0)
+}
+
+// combineTrc merges the signatures of the core ASes into the proposed TRC
+// update and verifies it against the current TRC. The TRC is only written to
+// the TRCs directory if it is a valid successor that is signed by a quorum of
+// the core ASes in the current TRC. Signatures that cannot be verified are
+// dropped.
+func combineTrc(isd addr.ISD) error {
+	prev, err := loadLatestTrc(isd)
+	if err != nil {
+		return err
+	}
+	t, err := loadProposal(prev)
+	if err != nil {
+		return err
+	}
+	pattern := filepath.Join(pendingPath(isd), fmt.Sprintf(signedNameFmt, isd, t.Version, "*"))
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	t.Signatures = make(map[string]common.RawBytes)
+	for _, file := range files {
+		signed, err := trc.TRCFromFile(file, false)
+		if err != nil {
+			return common.NewBasicError("Error loading signed TRC", err, "path", file)
+		}
+		for signer, sig := range signed.Signatures {
+			t.Signatures[signer] = sig
+		}
+	}
+	pkicmn.QuietPrint("Combining %d signatures for %s\n", len(t.Signatures), t)
+	tvr, err := t.Verify(prev)
+	if tvr != nil {
+		for signer, verr := range tvr.Failed {
+			pkicmn.QuietPrint("Not counting signature of %s: %s\n", signer, verr)
+		}
+		verified := make(map[string]common.RawBytes, len(tvr.Verified))
+		for _, signer := range tvr.Verified {
+			verified[signer.String()] = t.Signatures[signer.String()]
+		}
+		t.Signatures = verified
+	}
+	if err != nil {
+		return common.NewBasicError("TRC update verification failed", err, "trc", t)
+	}
+	dir := filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, isd), pkicmn.TRCsDir)
+	return writeTrc(t, dir, fmt.Sprintf(pkicmn.TrcNameFmt, isd, t.Version))
+}
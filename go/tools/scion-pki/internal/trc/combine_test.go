@@ -0,0 +1,72 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trc
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestSignAndCombineTrc(t *testing.T) {
+	Convey("The TRC update is written once signed by a quorum", t, func() {
+		cleanF := setupISD(t)
+		defer cleanF()
+		SoMsg("no proposal", signTrc(coreA), ShouldNotBeNil)
+		SoMsg("no proposal combine", combineTrc(1), ShouldNotBeNil)
+		xtest.FailOnErr(t, updateTrc(1))
+
+		SoMsg("sign A", signTrc(coreA), ShouldBeNil)
+		signedA := loadTestTrc(t, filepath.Join(pendingPath(1), "ISD1-V2-ASff00_0_110.trc"))
+		SoMsg("signers A", signers(signedA), ShouldResemble, []string{coreA.String()})
+		SoMsg("non-core AS is skipped", signTrc(leaf), ShouldBeNil)
+		SoMsg("pending", listFiles(t, pendingPath(1)), ShouldResemble,
+			[]string{"ISD1-V2-ASff00_0_110.trc", "ISD1-V2.trc"})
+
+		Convey("Without quorum, no TRC is written", func() {
+			SoMsg("err", combineTrc(1), ShouldNotBeNil)
+			SoMsg("trcs", listFiles(t, trcsDir()), ShouldResemble, []string{"ISD1-V1.trc"})
+		})
+		Convey("A core AS with a key that is not in the current TRC cannot sign", func() {
+			writeRootKey(t, coreB, keyconf.OnKeyFile)
+			SoMsg("err", signTrc(coreB), ShouldNotBeNil)
+			SoMsg("pending", listFiles(t, pendingPath(1)), ShouldResemble,
+				[]string{"ISD1-V2-ASff00_0_110.trc", "ISD1-V2.trc"})
+			SoMsg("combine", combineTrc(1), ShouldNotBeNil)
+		})
+		Convey("With quorum, the TRC is written", func() {
+			SoMsg("sign B", signTrc(coreB), ShouldBeNil)
+			SoMsg("err", combineTrc(1), ShouldBeNil)
+			SoMsg("trcs", listFiles(t, trcsDir()), ShouldResemble,
+				[]string{"ISD1-V1.trc", "ISD1-V2.trc"})
+			base := loadTestTrc(t, filepath.Join(trcsDir(), "ISD1-V1.trc"))
+			next := loadTestTrc(t, filepath.Join(trcsDir(), "ISD1-V2.trc"))
+			SoMsg("signers", signers(next), ShouldResemble,
+				[]string{coreA.String(), coreB.String()})
+			_, err := next.Verify(base)
+			SoMsg("verify", err, ShouldBeNil)
+			Convey("The next update is signed against the new TRC", func() {
+				xtest.FailOnErr(t, updateTrc(1))
+				SoMsg("sign A", signTrc(coreA), ShouldBeNil)
+				SoMsg("pending", listFiles(t, pendingPath(1)), ShouldContain,
+					"ISD1-V3-ASff00_0_110.trc")
+			})
+		})
+	})
+}
@@ -20,8 +20,6 @@ import (
 	"path/filepath"
 	"time"
 
-	"golang.org/x/crypto/ed25519"
-
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/keyconf"
@@ -83,7 +81,7 @@ func newTrc(isd addr.ISD, iconf *conf.Isd, path string) (*trc.TRC, error) {
 		issuingTime = util.TimeToSecs(time.Now())
 	}
 	t := &trc.TRC{
-		CreationTime:   iconf.Trc.IssuingTime,
+		CreationTime:   issuingTime,
 		Description:    iconf.Desc,
 		ExpirationTime: issuingTime + uint32(iconf.Trc.Validity.Seconds()),
 		GracePeriod:    uint32(iconf.Trc.GracePeriod.Seconds()),
@@ -97,56 +95,16 @@ func newTrc(isd addr.ISD, iconf *conf.Isd, path string) (*trc.TRC, error) {
 		CertLogs:       make(map[string]*trc.CertLog),
 	}
 	// Load the online/offline root keys.
-	var ases []coreAS
+	var ases []*coreAS
 	for _, cia := range iconf.Trc.CoreIAs {
-		var as coreAS
-		var err error
-		as.IA = cia
-		cpath := filepath.Join(pkicmn.GetAsPath(pkicmn.RootDir, cia), conf.AsConfFileName)
-		a, err := conf.LoadAsConf(filepath.Dir(cpath))
-		if err != nil {
-			return nil, common.NewBasicError("Error loading as.ini", err, "path", cpath)
-		}
-		if a.KeyAlgorithms == nil {
-			return nil, common.NewBasicError(fmt.Sprintf("'%s' section missing from as.ini",
-				conf.KeyAlgSectionName), nil, "path", cpath)
-		}
-		as.OnlineKeyAlg = scrypto.Ed25519
-		if a.KeyAlgorithms.Online != "" {
-			as.OnlineKeyAlg = a.KeyAlgorithms.Online
-		}
-		as.OfflineKeyAlg = scrypto.Ed25519
-		if a.KeyAlgorithms.Offline != "" {
-			as.OfflineKeyAlg = a.KeyAlgorithms.Offline
-		}
-		keysPath := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, cia), pkicmn.KeysDir)
-		as.OnlineKey, err = keyconf.LoadKey(filepath.Join(keysPath, keyconf.OnKeyFile),
-			as.OnlineKeyAlg)
-		if err != nil {
-			return nil, common.NewBasicError("Error loading online key", err)
-		}
-		as.OfflineKey, err = keyconf.LoadKey(
-			filepath.Join(keysPath, keyconf.OffKeyFile), as.OfflineKeyAlg)
-		if err != nil {
-			return nil, common.NewBasicError("Error loading offline key", err)
-		}
-		ases = append(ases, as)
-	}
-	for _, as := range ases {
-		pubKeyOnline, err := getPubKey(as.OnlineKey, as.OnlineKeyAlg)
+		as, err := loadCoreAS(cia)
 		if err != nil {
 			return nil, err
 		}
-		pubKeyOffline, err := getPubKey(as.OfflineKey, as.OfflineKeyAlg)
-		if err != nil {
+		if t.CoreASes[as.IA], err = as.entry(); err != nil {
 			return nil, err
 		}
-		t.CoreASes[as.IA] = &trc.CoreAS{
-			OnlineKey:     pubKeyOnline,
-			OnlineKeyAlg:  as.OnlineKeyAlg,
-			OfflineKey:    pubKeyOffline,
-			OfflineKeyAlg: as.OfflineKeyAlg,
-		}
+		ases = append(ases, as)
 	}
 	// Sign the TRC.
 	for _, as := range ases {
@@ -157,14 +115,6 @@ func newTrc(isd addr.ISD, iconf *conf.Isd, path string) (*trc.TRC, error) {
 	return t, nil
 }
 
-func getPubKey(privKey common.RawBytes, keyType string) (common.RawBytes, error) {
-	switch keyType {
-	case scrypto.Ed25519:
-		return common.RawBytes(ed25519.PrivateKey(privKey).Public().(ed25519.PublicKey)), nil
-	}
-	return nil, common.NewBasicError("Unsupported key type", nil, "type", keyType)
-}
-
 type coreAS struct {
 	IA            addr.IA
 	OnlineKey     common.RawBytes
@@ -172,3 +122,56 @@ type coreAS struct {
 	OnlineKeyAlg  string
 	OfflineKeyAlg string
 }
+
+// loadCoreAS loads the online and offline root keys of the core AS according
+// to the key algorithms in its as.ini.
+func loadCoreAS(cia addr.IA) (*coreAS, error) {
+	as := &coreAS{IA: cia}
+	cpath := filepath.Join(pkicmn.GetAsPath(pkicmn.RootDir, cia), conf.AsConfFileName)
+	a, err := conf.LoadAsConf(filepath.Dir(cpath))
+	if err != nil {
+		return nil, common.NewBasicError("Error loading as.ini", err, "path", cpath)
+	}
+	if a.KeyAlgorithms == nil {
+		return nil, common.NewBasicError(fmt.Sprintf("'%s' section missing from as.ini",
+			conf.KeyAlgSectionName), nil, "path", cpath)
+	}
+	as.OnlineKeyAlg = scrypto.Ed25519
+	if a.KeyAlgorithms.Online != "" {
+		as.OnlineKeyAlg = a.KeyAlgorithms.Online
+	}
+	as.OfflineKeyAlg = scrypto.Ed25519
+	if a.KeyAlgorithms.Offline != "" {
+		as.OfflineKeyAlg = a.KeyAlgorithms.Offline
+	}
+	keysPath := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, cia), pkicmn.KeysDir)
+	as.OnlineKey, err = keyconf.LoadKey(filepath.Join(keysPath, keyconf.OnKeyFile),
+		as.OnlineKeyAlg)
+	if err != nil {
+		return nil, common.NewBasicError("Error loading online key", err)
+	}
+	as.OfflineKey, err = keyconf.LoadKey(
+		filepath.Join(keysPath, keyconf.OffKeyFile), as.OfflineKeyAlg)
+	if err != nil {
+		return nil, common.NewBasicError("Error loading offline key", err)
+	}
+	return as, nil
+}
+
+// entry returns the TRC entry with the public keys of the core AS.
+func (as *coreAS) entry() (*trc.CoreAS, error) {
+	pubKeyOnline, err := scrypto.PubKey(as.OnlineKey, as.OnlineKeyAlg)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyOffline, err := scrypto.PubKey(as.OfflineKey, as.OfflineKeyAlg)
+	if err != nil {
+		return nil, err
+	}
+	return &trc.CoreAS{
+		OnlineKey:     pubKeyOnline,
+		OnlineKeyAlg:  as.OnlineKeyAlg,
+		OfflineKey:    pubKeyOffline,
+		OfflineKeyAlg: as.OfflineKeyAlg,
+	}, nil
+}
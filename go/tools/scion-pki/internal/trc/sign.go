@@ -0,0 +1,84 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+func runSignTrc(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for _, ases := range asMap {
+		for _, ia := range ases {
+			if err = signTrc(ia); err != nil {
+				pkicmn.ErrorAndExit("Error signing TRC for %s: %s\n", ia, err)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// signTrc signs the proposed TRC update with the online key of the core AS.
+// The signed TRC only contains the signature of the core AS and has to be
+// combined with the other signatures by 'trc combine'. ASes that are not core
+// ASes in the current TRC are skipped, since their signatures do not count
+// towards the quorum.
+func signTrc(ia addr.IA) error {
+	prev, err := loadLatestTrc(ia.I)
+	if err != nil {
+		return err
+	}
+	entry, ok := prev.CoreASes[ia]
+	if !ok {
+		pkicmn.QuietPrint("Skipping %s. Not a core AS in %s\n", ia, prev)
+		return nil
+	}
+	t, err := loadProposal(prev)
+	if err != nil {
+		return err
+	}
+	if err := t.ValidateUpdate(prev); err != nil {
+		return common.NewBasicError("Refusing to sign invalid TRC update", err)
+	}
+	as, err := loadCoreAS(ia)
+	if err != nil {
+		return err
+	}
+	pub, err := scrypto.PubKey(as.OnlineKey, as.OnlineKeyAlg)
+	if err != nil {
+		return err
+	}
+	if as.OnlineKeyAlg != entry.OnlineKeyAlg || !bytes.Equal(pub, entry.OnlineKey) {
+		return common.NewBasicError("Online key does not match current TRC", nil,
+			"trc", prev)
+	}
+	pkicmn.QuietPrint("Signing %s for %s\n", t, ia)
+	t.Signatures = make(map[string]common.RawBytes)
+	if err := t.Sign(ia.String(), as.OnlineKey, as.OnlineKeyAlg); err != nil {
+		return common.NewBasicError("Error signing TRC", err)
+	}
+	fname := fmt.Sprintf(signedNameFmt, ia.I, t.Version, ia.A.FileFmt())
+	return writeTrc(t, pendingPath(ia.I), fname)
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
+	"github.com/scionproto/scion/go/lib/util"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/conf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+const (
+	// pendingDir is the directory in the TRCs directory that contains the
+	// proposed TRC updates and the signatures of the core ASes.
+	pendingDir = "pending"
+	// signedNameFmt is the file name format of a proposed TRC update that is
+	// signed by a single core AS.
+	signedNameFmt = "ISD%d-V%d-AS%s.trc"
+)
+
+func runUpdateTrc(args []string) {
+	asMap, err := pkicmn.ProcessSelector(args[0])
+	if err != nil {
+		pkicmn.ErrorAndExit("Error: %s\n", err)
+	}
+	for isd := range asMap {
+		if err = updateTrc(isd); err != nil {
+			pkicmn.ErrorAndExit("Error updating TRC: %s\n", err)
+		}
+	}
+	os.Exit(0)
+}
+
+// updateTrc proposes the successor of the newest TRC of the ISD. The proposal
+// is unsigned and has to be signed by the core ASes with 'trc sign'.
+func updateTrc(isd addr.ISD) error {
+	confDir := pkicmn.GetIsdPath(pkicmn.RootDir, isd)
+	// Check that isd.ini exists, otherwise skip directory.
+	cpath := filepath.Join(confDir, conf.IsdConfFileName)
+	if _, err := os.Stat(cpath); os.IsNotExist(err) {
+		return nil
+	}
+	iconf, err := conf.LoadIsdConf(confDir)
+	if err != nil {
+		return common.NewBasicError("Error loading TRC conf", err)
+	}
+	prev, err := loadLatestTrc(isd)
+	if err != nil {
+		return err
+	}
+	pkicmn.QuietPrint("Generating TRC update for ISD %d version %d\n", isd, prev.Version+1)
+	t, err := newUpdate(prev, iconf)
+	if err != nil {
+		return err
+	}
+	if err := t.ValidateUpdate(prev); err != nil {
+		return common.NewBasicError("Invalid TRC update", err, "isd", isd)
+	}
+	return writeTrc(t, pendingPath(isd), fmt.Sprintf(pkicmn.TrcNameFmt, isd, t.Version))
+}
+
+// newUpdate creates the unsigned successor of prev according to iconf. The
+// core ASes that are also in prev keep their keys, the keys of new core ASes
+// are loaded from disk. The version in iconf is ignored and the TRC is created
+// now.
+func newUpdate(prev *trc.TRC, iconf *conf.Isd) (*trc.TRC, error) {
+	now := util.TimeToSecs(time.Now())
+	t := &trc.TRC{
+		CreationTime:   now,
+		Description:    iconf.Desc,
+		ExpirationTime: now + uint32(iconf.Trc.Validity.Seconds()),
+		GracePeriod:    uint32(iconf.Trc.GracePeriod.Seconds()),
+		ISD:            prev.ISD,
+		QuorumTRC:      iconf.Trc.QuorumTRC,
+		QuorumCAs:      prev.QuorumCAs,
+		ThresholdEEPKI: prev.ThresholdEEPKI,
+		Version:        prev.Version + 1,
+		CoreASes:       make(map[addr.IA]*trc.CoreAS),
+		Signatures:     make(map[string]common.RawBytes),
+		RAINS:          prev.RAINS,
+		RootCAs:        prev.RootCAs,
+		CertLogs:       prev.CertLogs,
+	}
+	for _, cia := range iconf.Trc.CoreIAs {
+		if entry, ok := prev.CoreASes[cia]; ok {
+			t.CoreASes[cia] = entry
+			continue
+		}
+		as, err := loadCoreAS(cia)
+		if err != nil {
+			return nil, err
+		}
+		if t.CoreASes[cia], err = as.entry(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// loadLatestTrc returns the newest TRC of the ISD in the output directory.
+func loadLatestTrc(isd addr.ISD) (*trc.TRC, error) {
+	dir := filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, isd), pkicmn.TRCsDir)
+	t, err := trc.TRCFromDir(dir, isd, func(err error) {
+		pkicmn.QuietPrint("Ignoring TRC: %s\n", err)
+	})
+	if err != nil {
+		return nil, common.NewBasicError("Error loading TRCs", err, "dir", dir)
+	}
+	if t == nil {
+		return nil, common.NewBasicError("No TRC found, use 'trc gen'", nil, "dir", dir)
+	}
+	return t, nil
+}
+
+// loadProposal loads the proposed successor of prev.
+func loadProposal(prev *trc.TRC) (*trc.TRC, error) {
+	fname := fmt.Sprintf(pkicmn.TrcNameFmt, prev.ISD, prev.Version+1)
+	path := filepath.Join(pendingPath(prev.ISD), fname)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, common.NewBasicError("No TRC update proposed, use 'trc update'", nil,
+			"path", path)
+	}
+	t, err := trc.TRCFromFile(path, false)
+	if err != nil {
+		return nil, common.NewBasicError("Error loading proposed TRC", err, "path", path)
+	}
+	return t, nil
+}
+
+func pendingPath(isd addr.ISD) string {
+	return filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, isd), pkicmn.TRCsDir, pendingDir)
+}
+
+// writeTrc writes the TRC to the file in dir. The directory is created if it
+// does not exist.
+func writeTrc(t *trc.TRC, dir, fname string) error {
+	raw, err := t.JSON(true)
+	if err != nil {
+		return common.NewBasicError("Error json-encoding TRC", err)
+	}
+	if _, err = os.Stat(dir); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return common.NewBasicError("Cannot create output dir", err, "path", dir)
+		}
+	}
+	return pkicmn.WriteToFile(raw, filepath.Join(dir, fname), 0644)
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trc
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/scrypto/trc"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/conf"
+	"github.com/scionproto/scion/go/tools/scion-pki/internal/pkicmn"
+)
+
+var (
+	coreA = xtest.MustParseIA("1-ff00:0:110")
+	coreB = xtest.MustParseIA("1-ff00:0:111")
+	leaf  = xtest.MustParseIA("1-ff00:0:112")
+)
+
+const (
+	asIni = `[AS Certificate]
+Issuer = 1-ff00:0:110
+TRCVersion = 1
+Version = 1
+Validity = 3d
+[Key Algorithms]
+Online = ed25519
+Offline = ed25519
+`
+	isdIniFmt = `Description = Test ISD
+[TRC]
+Version = 1
+Validity = 10d
+CoreASes = %s
+GracePeriod = 0s
+QuorumTRC = 2
+`
+)
+
+// setupISD creates the keys of the core ASes coreA and coreB and the non-core
+// AS leaf, and generates the base TRC of ISD 1 in a temporary directory,
+// which is used as root and output directory.
+func setupISD(t *testing.T) func() {
+	dir, cleanF := xtest.MustTempDir("", "trc")
+	pkicmn.RootDir, pkicmn.OutDir, pkicmn.Quiet = dir, dir, true
+	for _, ia := range []addr.IA{coreA, coreB, leaf} {
+		asDir := pkicmn.GetAsPath(pkicmn.RootDir, ia)
+		xtest.FailOnErr(t, os.MkdirAll(filepath.Join(asDir, pkicmn.KeysDir), 0755))
+		err := ioutil.WriteFile(filepath.Join(asDir, conf.AsConfFileName), []byte(asIni), 0644)
+		xtest.FailOnErr(t, err)
+		writeRootKey(t, ia, keyconf.OnKeyFile)
+		writeRootKey(t, ia, keyconf.OffKeyFile)
+	}
+	writeIsdIni(t, coreA, coreB)
+	xtest.FailOnErr(t, genTrc(1))
+	return cleanF
+}
+
+func writeRootKey(t *testing.T, ia addr.IA, fname string) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	xtest.FailOnErr(t, err)
+	file := filepath.Join(pkicmn.GetAsPath(pkicmn.OutDir, ia), pkicmn.KeysDir, fname)
+	raw := base64.StdEncoding.EncodeToString(priv.Seed())
+	xtest.FailOnErr(t, ioutil.WriteFile(file, []byte(raw), 0600))
+}
+
+func writeIsdIni(t *testing.T, cores ...addr.IA) {
+	var raw []string
+	for _, ia := range cores {
+		raw = append(raw, ia.String())
+	}
+	content := fmt.Sprintf(isdIniFmt, strings.Join(raw, ","))
+	file := filepath.Join(pkicmn.GetIsdPath(pkicmn.RootDir, 1), conf.IsdConfFileName)
+	xtest.FailOnErr(t, ioutil.WriteFile(file, []byte(content), 0644))
+}
+
+func trcsDir() string {
+	return filepath.Join(pkicmn.GetIsdPath(pkicmn.OutDir, 1), pkicmn.TRCsDir)
+}
+
+func loadTestTrc(t *testing.T, path string) *trc.TRC {
+	decoded, err := trc.TRCFromFile(path, false)
+	xtest.FailOnErr(t, err)
+	return decoded
+}
+
+func signers(t *trc.TRC) []string {
+	var s []string
+	for signer := range t.Signatures {
+		s = append(s, signer)
+	}
+	sort.Strings(s)
+	return s
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	xtest.FailOnErr(t, err)
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	return names
+}
+
+func TestUpdateTrc(t *testing.T) {
+	Convey("updateTrc proposes the next TRC version", t, func() {
+		cleanF := setupISD(t)
+		defer cleanF()
+		base := loadTestTrc(t, filepath.Join(trcsDir(), "ISD1-V1.trc"))
+		SoMsg("base signers", signers(base), ShouldResemble,
+			[]string{coreA.String(), coreB.String()})
+
+		Convey("Keys of existing core ASes are kept", func() {
+			writeRootKey(t, coreA, keyconf.OnKeyFile)
+			SoMsg("err", updateTrc(1), ShouldBeNil)
+			next := loadTestTrc(t, filepath.Join(pendingPath(1), "ISD1-V2.trc"))
+			SoMsg("version", next.Version, ShouldEqual, 2)
+			SoMsg("signatures", next.Signatures, ShouldBeEmpty)
+			SoMsg("keys", next.CoreASes, ShouldResemble, base.CoreASes)
+			SoMsg("valid", next.ValidateUpdate(base), ShouldBeNil)
+			SoMsg("trcs", listFiles(t, trcsDir()), ShouldResemble, []string{"ISD1-V1.trc"})
+		})
+		Convey("Keys of new core ASes are loaded", func() {
+			writeIsdIni(t, coreA, coreB, leaf)
+			SoMsg("err", updateTrc(1), ShouldBeNil)
+			next := loadTestTrc(t, filepath.Join(pendingPath(1), "ISD1-V2.trc"))
+			SoMsg("core ASes", len(next.CoreASes), ShouldEqual, 3)
+			SoMsg("new key", next.CoreASes[leaf], ShouldNotBeNil)
+		})
+		Convey("ISD without TRC", func() {
+			xtest.FailOnErr(t, os.Remove(filepath.Join(trcsDir(), "ISD1-V1.trc")))
+			SoMsg("err", updateTrc(1), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,288 @@
+// Copyright 2019 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// scion-translog audits the transparency log of issued certificate chains
+// that is maintained by an issuer AS.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scrypto/cert"
+	"github.com/scionproto/scion/go/lib/scrypto/translog"
+)
+
+var (
+	logURL = flag.String("url", "", "Base URL of the log, e.g., http://192.0.2.1:30454 (required)")
+	issuer = flag.String("issuer", "", "Certificate chain file containing the issuer "+
+		"certificate of the log (required)")
+	state = flag.String("state", "", "File storing the last verified signed tree head "+
+		"(audit only)")
+	timeout = flag.Duration("timeout", 10*time.Second, "Timeout per request")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: %s [flags] <command> [args]
+
+Commands:
+  sth                    Fetch and verify the current signed tree head and
+                         print it as JSON.
+  inclusion <chain file> Verify that the certificate chain is included in
+                         the log.
+  audit <IA>             Verify that the log is consistent with the signed
+                         tree head in the state file, and list all chains
+                         issued for IA that were appended since. The state
+                         file is updated with the current signed tree head.
+                         Without a state file, all entries are audited.
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	os.Exit(realMain())
+}
+
+func realMain() int {
+	flag.Usage = usage
+	flag.Parse()
+	if *logURL == "" || *issuer == "" || flag.NArg() < 1 {
+		flag.Usage()
+		return 1
+	}
+	chain, err := cert.ChainFromFile(*issuer, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to load issuer certificate: %s\n", err)
+		return 1
+	}
+	a := &auditor{
+		client: &translog.Client{URL: *logURL},
+		issuer: chain.Issuer,
+	}
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; {
+	case cmd == "sth" && len(args) == 0:
+		err = a.printSTH()
+	case cmd == "inclusion" && len(args) == 1:
+		err = a.inclusion(args[0])
+	case cmd == "audit" && len(args) == 1:
+		err = a.audit(args[0], *state)
+	default:
+		flag.Usage()
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 2
+	}
+	return 0
+}
+
+type auditor struct {
+	client *translog.Client
+	issuer *cert.Certificate
+}
+
+// sth fetches the current signed tree head and verifies its signature.
+func (a *auditor) sth() (*translog.SignedTreeHead, error) {
+	ctx, cancelF := context.WithTimeout(context.Background(), *timeout)
+	defer cancelF()
+	sth, err := a.client.STH(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := sth.Verify(a.issuer); err != nil {
+		return nil, common.NewBasicError("Invalid signed tree head", err)
+	}
+	return sth, nil
+}
+
+func (a *auditor) printSTH() error {
+	sth, err := a.sth()
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(sth, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(raw))
+	return nil
+}
+
+func (a *auditor) inclusion(file string) error {
+	chain, err := cert.ChainFromFile(file, false)
+	if err != nil {
+		return common.NewBasicError("Unable to load certificate chain", err)
+	}
+	entry, err := chain.JSON(false)
+	if err != nil {
+		return err
+	}
+	sth, err := a.sth()
+	if err != nil {
+		return err
+	}
+	index, err := a.verifyEntry(entry, sth)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s included at index %d in %s\n", chain, index, sth)
+	return nil
+}
+
+// verifyEntry verifies that the entry is included in the tree of the signed
+// tree head and returns its index.
+func (a *auditor) verifyEntry(entry common.RawBytes,
+	sth *translog.SignedTreeHead) (uint64, error) {
+
+	ctx, cancelF := context.WithTimeout(context.Background(), *timeout)
+	defer cancelF()
+	leafHash := translog.LeafHash(entry)
+	proof, err := a.client.InclusionProof(ctx, leafHash, sth.TreeSize)
+	if err != nil {
+		return 0, err
+	}
+	if proof.TreeSize != sth.TreeSize {
+		return 0, common.NewBasicError("Proof for wrong tree size", nil,
+			"expected", sth.TreeSize, "actual", proof.TreeSize)
+	}
+	if err := proof.Verify(leafHash, sth.RootHash); err != nil {
+		return 0, common.NewBasicError("Invalid inclusion proof", err)
+	}
+	return proof.LeafIndex, nil
+}
+
+func (a *auditor) audit(rawIA, stateFile string) error {
+	ia, err := addr.IAFromString(rawIA)
+	if err != nil {
+		return err
+	}
+	old, err := loadSTH(stateFile)
+	if err != nil {
+		return err
+	}
+	sth, err := a.sth()
+	if err != nil {
+		return err
+	}
+	var start uint64
+	if old != nil {
+		if err := a.verifyConsistency(old, sth); err != nil {
+			return err
+		}
+		start = old.TreeSize
+	}
+	var found int
+	for index := start; index < sth.TreeSize; index++ {
+		chain, err := a.fetchChain(index, sth)
+		if err != nil {
+			return common.NewBasicError("Unable to audit entry", err, "index", index)
+		}
+		if chain.Leaf.Subject.Equal(ia) {
+			fmt.Printf("%d: %s\n", index, chain)
+			found++
+		}
+	}
+	fmt.Printf("Audited entries [%d, %d) of %s: %d chain(s) for %s\n",
+		start, sth.TreeSize, sth, found, ia)
+	if stateFile == "" {
+		return nil
+	}
+	return storeSTH(stateFile, sth)
+}
+
+func (a *auditor) verifyConsistency(old, sth *translog.SignedTreeHead) error {
+	if !old.IA.Equal(sth.IA) {
+		return common.NewBasicError("Signed tree heads of different logs", nil,
+			"old", old.IA, "new", sth.IA)
+	}
+	if old.TreeSize > sth.TreeSize {
+		return common.NewBasicError("Log shrunk", nil,
+			"old", old.TreeSize, "new", sth.TreeSize)
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), *timeout)
+	defer cancelF()
+	proof, err := a.client.ConsistencyProof(ctx, old.TreeSize, sth.TreeSize)
+	if err != nil {
+		return err
+	}
+	if proof.FirstSize != old.TreeSize || proof.SecondSize != sth.TreeSize {
+		return common.NewBasicError("Proof for wrong tree sizes", nil,
+			"first", proof.FirstSize, "second", proof.SecondSize)
+	}
+	if err := proof.Verify(old.RootHash, sth.RootHash); err != nil {
+		return common.NewBasicError("Log is not consistent", err,
+			"old", old, "new", sth)
+	}
+	return nil
+}
+
+// fetchChain fetches the entry at index and verifies that it is included at
+// that index in the tree of the signed tree head.
+func (a *auditor) fetchChain(index uint64, sth *translog.SignedTreeHead) (*cert.Chain, error) {
+	ctx, cancelF := context.WithTimeout(context.Background(), *timeout)
+	defer cancelF()
+	entry, err := a.client.Entry(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := a.verifyEntry(entry, sth)
+	if err != nil {
+		return nil, err
+	}
+	// The log proves inclusion of the first occurrence of an entry, thus a
+	// lower index indicates a duplicate.
+	if actual > index {
+		return nil, common.NewBasicError("Entry included at wrong index", nil,
+			"actual", actual)
+	}
+	return cert.ChainFromRaw(entry, false)
+}
+
+// loadSTH loads the signed tree head from the file. A missing file results
+// in a nil tree head.
+func loadSTH(file string) (*translog.SignedTreeHead, error) {
+	if file == "" {
+		return nil, nil
+	}
+	raw, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sth := &translog.SignedTreeHead{}
+	if err := json.Unmarshal(raw, sth); err != nil {
+		return nil, common.NewBasicError("Unable to parse state file", err, "file", file)
+	}
+	return sth, nil
+}
+
+func storeSTH(file string, sth *translog.SignedTreeHead) error {
+	raw, err := json.MarshalIndent(sth, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, raw, 0644)
+}
@@ -17,8 +17,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"time"
@@ -27,11 +29,13 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/scmp"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/sock/reliable"
 	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
 )
 
 var (
@@ -45,6 +49,8 @@ var (
 	refresh      = flag.Bool("refresh", false, "Set refresh flag for SCIOND path request")
 	status       = flag.Bool("p", false, "Probe the paths and print out the statuses")
 	version      = flag.Bool("version", false, "Output version information and exit.")
+	policyFile   = flag.String("policy", "", "Path policy file (JSON) to filter and order paths")
+	attrFile     = flag.String("attributes", "", "AS attribute database file used by the policy")
 )
 
 var (
@@ -83,12 +89,17 @@ func main() {
 		LogFatal("SCIOND unable to retrieve paths", "ErrorCode", reply.ErrorCode)
 	}
 
+	entries := reply.Entries
+	if *policyFile != "" {
+		entries = filterPaths(loadPolicy(), entries)
+	}
+
 	fmt.Println("Available paths to", dstIA)
 	var pathStatuses map[string]string
 	if *status {
-		pathStatuses = getStatuses(reply.Entries)
+		pathStatuses = getStatuses(entries)
 	}
-	for i, path := range reply.Entries {
+	for i, path := range entries {
 		fmt.Printf("[%2d] %s", i, path.Path.String())
 		if *expiration {
 			fmt.Printf(" Expires: %s (%s)", path.Path.Expiry(),
@@ -138,6 +149,47 @@ func validateFlags() {
 	if *status && (local.IA.IsZero() || local.Host == nil) {
 		LogFatal("Local address is required for health checks")
 	}
+
+	if *attrFile != "" && *policyFile == "" {
+		LogFatal("-attributes can only be used together with -policy")
+	}
+}
+
+// loadPolicy loads the path policy and the attribute database specified in
+// the flags.
+func loadPolicy() *pathpol.Policy {
+	b, err := ioutil.ReadFile(*policyFile)
+	if err != nil {
+		LogFatal("Unable to read policy file", "err", err)
+	}
+	extPolicy := &pathpol.ExtPolicy{}
+	if err := json.Unmarshal(b, extPolicy); err != nil {
+		LogFatal("Unable to parse policy", "err", err)
+	}
+	policy, err := pathpol.PolicyFromExtPolicy(extPolicy, nil)
+	if err != nil {
+		LogFatal("Unable to create policy", "err", err)
+	}
+	if *attrFile != "" {
+		if policy.Attributes, err = pathpol.LoadAttributeDB(*attrFile); err != nil {
+			LogFatal("Unable to load attribute database", "err", err)
+		}
+	}
+	return policy
+}
+
+// filterPaths returns the paths that match the policy, in the order defined
+// by the policy.
+func filterPaths(policy *pathpol.Policy,
+	entries []sciond.PathReplyEntry) []sciond.PathReplyEntry {
+
+	aps := spathmeta.NewAppPathSet(&sciond.PathReply{Entries: entries})
+	aps = policy.Act(aps).(spathmeta.AppPathSet)
+	var filtered []sciond.PathReplyEntry
+	for _, path := range policy.Order(aps) {
+		filtered = append(filtered, *path.Entry)
+	}
+	return filtered
 }
 
 func flagUsage() {